@@ -0,0 +1,161 @@
+package authenticator
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrNoVerificationKey = errors.New("no key configured to verify access tokens")
+	ErrUnknownKey        = errors.New("access token signed with an unknown key")
+)
+
+// Claims are the access token claims the API relies on.
+type Claims struct {
+	jwt.RegisteredClaims
+	Email        string         `json:"email,omitempty"`
+	Role         string         `json:"role,omitempty"`
+	UserMetadata map[string]any `json:"user_metadata,omitempty"`
+	AppMetadata  map[string]any `json:"app_metadata,omitempty"`
+}
+
+// UserID parses the subject of the token.
+func (c Claims) UserID() (uuid.UUID, error) {
+	return uuid.Parse(c.Subject)
+}
+
+// AppRole is the platform role the app metadata gives the user, or "" when it gives none.
+// Only the app metadata is trusted: users can edit their own user metadata, and the
+// top-level role claim is the database role, "authenticated" for every signed-in user.
+func (c Claims) AppRole() string {
+	role, _ := c.AppMetadata["role"].(string)
+	return role
+}
+
+// TokenConfig configures how access tokens are verified.
+type TokenConfig struct {
+	// Secret verifies HS256 tokens.
+	Secret string
+	// JWKSURL serves the public keys that verify RS256/ES256 tokens.
+	JWKSURL string
+	// JWKSRefresh is how long fetched keys are trusted before they are fetched again.
+	JWKSRefresh time.Duration
+	Audience    string
+	Issuer      string
+	// RemoteFallback asks the auth server about tokens that fail local verification.
+	RemoteFallback bool
+}
+
+// TokenConfigFromEnv reads the token settings:
+//
+//	JWT_SECRET            HS256 secret
+//	JWT_JWKS_URL          JWKS endpoint for asymmetric keys
+//	JWT_JWKS_REFRESH      key cache lifetime (Go duration, default 10m)
+//	JWT_AUDIENCE          expected `aud` (default "authenticated")
+//	JWT_ISSUER            expected `iss` (default the project's auth URL)
+//	JWT_REMOTE_FALLBACK   "true" to fall back to the auth server
+func TokenConfigFromEnv() TokenConfig {
+	config := TokenConfig{
+		Secret:         os.Getenv("JWT_SECRET"),
+		JWKSURL:        os.Getenv("JWT_JWKS_URL"),
+		JWKSRefresh:    10 * time.Minute,
+		Audience:       os.Getenv("JWT_AUDIENCE"),
+		Issuer:         os.Getenv("JWT_ISSUER"),
+		RemoteFallback: os.Getenv("JWT_REMOTE_FALLBACK") == "true",
+	}
+	if refresh, err := time.ParseDuration(os.Getenv("JWT_JWKS_REFRESH")); err == nil && refresh > 0 {
+		config.JWKSRefresh = refresh
+	}
+	if config.Audience == "" {
+		config.Audience = "authenticated"
+	}
+	if reference := os.Getenv("PROJECT_REFERENCE"); config.Issuer == "" && reference != "" {
+		config.Issuer = "https://" + reference + ".supabase.co/auth/v1"
+	}
+	return config
+}
+
+// TokenVerifier verifies access tokens locally, without a round-trip to the auth server.
+type TokenVerifier struct {
+	secret   []byte
+	jwks     *jwksCache
+	parser   *jwt.Parser
+	fallback bool
+}
+
+// NewTokenVerifier builds a verifier from config. It fails when there is neither a key
+// to verify with nor the remote fallback.
+func NewTokenVerifier(config TokenConfig) (*TokenVerifier, error) {
+	if config.Secret == "" && config.JWKSURL == "" && !config.RemoteFallback {
+		return nil, ErrNoVerificationKey
+	}
+
+	var methods []string
+	verifier := &TokenVerifier{fallback: config.RemoteFallback}
+	if config.Secret != "" {
+		verifier.secret = []byte(config.Secret)
+		methods = append(methods, jwt.SigningMethodHS256.Alg())
+	}
+	if config.JWKSURL != "" {
+		verifier.jwks = newJWKSCache(config.JWKSURL, config.JWKSRefresh)
+		methods = append(methods, jwt.SigningMethodRS256.Alg(), jwt.SigningMethodES256.Alg())
+	}
+
+	options := []jwt.ParserOption{
+		jwt.WithValidMethods(methods),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(30 * time.Second),
+	}
+	if config.Audience != "" {
+		options = append(options, jwt.WithAudience(config.Audience))
+	}
+	if config.Issuer != "" {
+		options = append(options, jwt.WithIssuer(strings.TrimSuffix(config.Issuer, "/")))
+	}
+	verifier.parser = jwt.NewParser(options...)
+	return verifier, nil
+}
+
+// Verify checks the signature, `exp`, `aud` and `iss` of token and returns its claims.
+func (v *TokenVerifier) Verify(token string) (Claims, error) {
+	if v.secret == nil && v.jwks == nil {
+		return Claims{}, ErrNoVerificationKey
+	}
+
+	var claims Claims
+	_, err := v.parser.ParseWithClaims(token, &claims, v.key)
+	if err != nil {
+		return Claims{}, err
+	}
+	if _, err := claims.UserID(); err != nil {
+		return Claims{}, errors.New("access token subject is not a user ID")
+	}
+	return claims, nil
+}
+
+// RemoteFallback reports whether tokens failing local verification may be checked
+// with the auth server.
+func (v *TokenVerifier) RemoteFallback() bool {
+	return v.fallback
+}
+
+func (v *TokenVerifier) key(token *jwt.Token) (any, error) {
+	switch token.Method.(type) {
+	case *jwt.SigningMethodHMAC:
+		if v.secret == nil {
+			return nil, ErrUnknownKey
+		}
+		return v.secret, nil
+	default:
+		if v.jwks == nil {
+			return nil, ErrUnknownKey
+		}
+		kid, _ := token.Header["kid"].(string)
+		return v.jwks.key(kid)
+	}
+}
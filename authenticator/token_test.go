@@ -0,0 +1,112 @@
+package authenticator
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func testClaims(audience string, issuer string) Claims {
+	return Claims{RegisteredClaims: jwt.RegisteredClaims{
+		Subject:   uuid.NewString(),
+		Audience:  jwt.ClaimStrings{audience},
+		Issuer:    issuer,
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+	}}
+}
+
+func TestTokenVerifier_HS256_Checks_Audience_And_Issuer(t *testing.T) {
+	verifier, err := NewTokenVerifier(TokenConfig{Secret: "secret", Audience: "authenticated", Issuer: "https://auth.example.com"})
+	assert.NoError(t, err)
+
+	sign := func(claims Claims, secret string) string {
+		token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secret))
+		assert.NoError(t, err)
+		return token
+	}
+
+	claims := testClaims("authenticated", "https://auth.example.com")
+	verified, err := verifier.Verify(sign(claims, "secret"))
+	assert.NoError(t, err)
+	assert.Equal(t, claims.Subject, verified.Subject)
+
+	_, err = verifier.Verify(sign(claims, "other"))
+	assert.ErrorIs(t, err, jwt.ErrTokenSignatureInvalid)
+	_, err = verifier.Verify(sign(testClaims("anon", "https://auth.example.com"), "secret"))
+	assert.ErrorIs(t, err, jwt.ErrTokenInvalidAudience)
+	_, err = verifier.Verify(sign(testClaims("authenticated", "https://evil.example.com"), "secret"))
+	assert.ErrorIs(t, err, jwt.ErrTokenInvalidIssuer)
+
+	noExpiry := claims
+	noExpiry.ExpiresAt = nil
+	_, err = verifier.Verify(sign(noExpiry, "secret"))
+	assert.ErrorIs(t, err, jwt.ErrTokenRequiredClaimMissing)
+}
+
+func TestTokenVerifier_RS256_Uses_Cached_JWKS(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+
+	fetches := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches++
+		json.NewEncoder(w).Encode(map[string]any{"keys": []map[string]string{{
+			"kid": "key-1",
+			"kty": "RSA",
+			"use": "sig",
+			"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	}))
+	defer server.Close()
+
+	verifier, err := NewTokenVerifier(TokenConfig{JWKSURL: server.URL, JWKSRefresh: time.Hour, Audience: "authenticated"})
+	assert.NoError(t, err)
+
+	sign := func(kid string) string {
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, testClaims("authenticated", ""))
+		token.Header["kid"] = kid
+		signed, err := token.SignedString(key)
+		assert.NoError(t, err)
+		return signed
+	}
+
+	_, err = verifier.Verify(sign("key-1"))
+	assert.NoError(t, err)
+	_, err = verifier.Verify(sign("key-1"))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, fetches)
+
+	// An unknown key ID right after a fetch does not hit the endpoint again.
+	_, err = verifier.Verify(sign("key-2"))
+	assert.ErrorIs(t, err, ErrUnknownKey)
+	assert.Equal(t, 1, fetches)
+}
+
+func TestNewTokenVerifier_Requires_A_Key_Or_Fallback(t *testing.T) {
+	_, err := NewTokenVerifier(TokenConfig{})
+	assert.ErrorIs(t, err, ErrNoVerificationKey)
+
+	verifier, err := NewTokenVerifier(TokenConfig{RemoteFallback: true})
+	assert.NoError(t, err)
+	_, err = verifier.Verify("token")
+	assert.ErrorIs(t, err, ErrNoVerificationKey)
+}
+
+func TestClaims_AppRole_Only_Trusts_App_Metadata(t *testing.T) {
+	claims := Claims{Role: "authenticated", UserMetadata: map[string]any{"role": "admin"}}
+	assert.Equal(t, "", claims.AppRole())
+
+	claims.AppMetadata = map[string]any{"role": "seller"}
+	assert.Equal(t, "seller", claims.AppRole())
+}
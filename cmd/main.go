@@ -5,10 +5,20 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"github.com/abdulmalikraji/e-commerce/authenticator"
 	"github.com/abdulmalikraji/e-commerce/config"
 	"github.com/abdulmalikraji/e-commerce/db/connection"
+	"github.com/abdulmalikraji/e-commerce/db/dao/categoryDao"
+	"github.com/abdulmalikraji/e-commerce/db/dao/importJobDao"
+	"github.com/abdulmalikraji/e-commerce/db/dao/orderDao"
+	"github.com/abdulmalikraji/e-commerce/db/dao/productDao"
+	productvariantdao "github.com/abdulmalikraji/e-commerce/db/dao/productVariantDao"
+	"github.com/abdulmalikraji/e-commerce/db/dao/stockReservationDao"
+	"github.com/abdulmalikraji/e-commerce/db/dao/tagDao"
+	"github.com/abdulmalikraji/e-commerce/services"
+	"github.com/abdulmalikraji/e-commerce/utils"
 	"github.com/gofiber/fiber/v2"
 	"github.com/joho/godotenv"
 )
@@ -23,14 +33,30 @@ func main() {
 
 	client := connection.New()
 
-	app := fiber.New()
+	// Leave room in the body limit for image uploads and import files, and the rest of their form
+	app := fiber.New(fiber.Config{BodyLimit: utils.MaxImageSize + 1<<20})
 
 	auth, err := authenticator.New()
 	if err != nil {
 		log.Fatalf("Failed to initialize the authenticator: %v", err)
 	}
 
-	config.InitializeRoutes(app, client, auth)
+	// Access tokens are verified locally; see authenticator.TokenConfigFromEnv
+	tokens, err := authenticator.NewTokenVerifier(authenticator.TokenConfigFromEnv())
+	if err != nil {
+		log.Fatalf("Failed to initialize the token verifier: %v", err)
+	}
+
+	config.InitializeRoutes(app, client, auth, tokens)
+
+	// Expire stock holds whose checkout was never paid
+	inventoryService := services.NewInventoryService(stockReservationDao.New(client), orderDao.New(client))
+	stopSweeper := services.StartReservationSweeper(inventoryService, time.Minute)
+
+	// Process queued product imports
+	importService := services.NewProductImportService(importJobDao.New(client), productDao.New(client),
+		productvariantdao.New(client), categoryDao.New(client), tagDao.New(client))
+	stopImports := services.StartImportWorker(importService, 5*time.Second)
 
 	// Start the server in a goroutine
 	go func() {
@@ -40,16 +66,20 @@ func main() {
 	}()
 
 	// Call gracefulShutdown to handle cleanup
-	gracefulShutdown(app, client)
+	gracefulShutdown(app, client, stopSweeper, stopImports)
 }
 
-func gracefulShutdown(app *fiber.App, client connection.Client) {
+func gracefulShutdown(app *fiber.App, client connection.Client, stopSweeper func(), stopImports func()) {
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
 
 	<-quit
 	log.Println("Shutting down server...")
 
+	// Stop background jobs before the database goes away
+	stopSweeper()
+	stopImports()
+
 	// Close the PostgreSQL database connection
 	database, err := client.PostgresConnection.DB()
 	if err != nil {
@@ -3,10 +3,13 @@ package middleware
 import (
 	"strings"
 
+	"github.com/abdulmalikraji/e-commerce/db/dao/storeUserDao"
 	"github.com/abdulmalikraji/e-commerce/services"
+	"github.com/abdulmalikraji/e-commerce/utils"
 	"github.com/abdulmalikraji/e-commerce/utils/genericResponse"
 	"github.com/abdulmalikraji/e-commerce/utils/messages"
 	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/log"
 )
 
 const bearerPrefix = "Bearer "
@@ -24,8 +27,10 @@ func extractToken(authHeader string) (string, error) {
 	return authHeader[len(bearerPrefix):], nil
 }
 
-// TokenValidationMiddleware checks if the token is valid and refreshes if needed
-func TokenValidationMiddleware(authService services.AuthService) fiber.Handler {
+// TokenValidationMiddleware checks if the token is valid and refreshes if needed. The
+// verified caller and their store memberships are stored as the request's principal
+// (see utils.GetPrincipal).
+func TokenValidationMiddleware(authService services.AuthService, storeUsers storeUserDao.DataAccess) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		// Skip auth for login and refresh routes
 		// if c.Path() == "/auth/login" || c.Path() == "/auth/refresh" || c.Path() == "/auth/register" {
@@ -51,8 +56,34 @@ func TokenValidationMiddleware(authService services.AuthService) fiber.Handler {
 			// Update request header for downstream handlers
 			newToken := refreshData.AccessToken
 			c.Request().Header.Set("Authorization", bearerPrefix+newToken)
+			if _, err := authService.ValidateToken(c, newToken); err != nil {
+				return genericResponse.ErrorResponse(c, fiber.StatusUnauthorized,
+					messages.CreateMsg(c, messages.InvalidToken, nil))
+			}
+		}
+
+		principal, _ := utils.GetPrincipal(c)
+		stores, err := storeUsers.FindMemberships(principal.UserID)
+		if err != nil {
+			log.Errorf("failed to load store memberships for user_id=%s: %v", principal.UserID.String(), err)
+			return genericResponse.ErrorResponse(c, fiber.StatusInternalServerError, "failed to load user permissions")
 		}
+		principal.Stores = stores
+		utils.SetPrincipal(c, principal)
 
 		return c.Next()
 	}
 }
+
+// OptionalTokenMiddleware lets guests through while still authenticating users who send
+// a token. Guests have no principal, so downstream handlers may treat a resolvable user
+// ID as a logged-in user.
+func OptionalTokenMiddleware(authService services.AuthService, storeUsers storeUserDao.DataAccess) fiber.Handler {
+	validate := TokenValidationMiddleware(authService, storeUsers)
+	return func(c *fiber.Ctx) error {
+		if c.Get("Authorization") == "" {
+			return c.Next()
+		}
+		return validate(c)
+	}
+}
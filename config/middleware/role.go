@@ -0,0 +1,27 @@
+package middleware
+
+import (
+	"slices"
+
+	"github.com/abdulmalikraji/e-commerce/utils"
+	"github.com/abdulmalikraji/e-commerce/utils/genericResponse"
+	"github.com/abdulmalikraji/e-commerce/utils/messages"
+	"github.com/gofiber/fiber/v2"
+)
+
+// RequireRole only lets through callers whose platform role (see utils.Principal) is
+// one of roles. It must run after TokenValidationMiddleware.
+//
+// Example usage: app.Group("/admin", RequireRole(models.UserRoleAdmin))
+func RequireRole(roles ...string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		principal, ok := utils.GetPrincipal(c)
+		if !ok {
+			return genericResponse.ErrorResponse(c, fiber.StatusUnauthorized, messages.CreateMsg(c, messages.Unauthorized))
+		}
+		if !slices.Contains(roles, principal.Role) {
+			return genericResponse.ErrorResponse(c, fiber.StatusForbidden, messages.CreateMsg(c, messages.Unauthorized))
+		}
+		return c.Next()
+	}
+}
@@ -1,27 +1,132 @@
 package config
 
 import (
+	"github.com/abdulmalikraji/e-commerce/authenticator"
 	"github.com/abdulmalikraji/e-commerce/config/middleware"
 	"github.com/abdulmalikraji/e-commerce/db/connection"
+	"github.com/abdulmalikraji/e-commerce/db/dao/analyticsDao"
+	cartdao "github.com/abdulmalikraji/e-commerce/db/dao/cartDao"
+	"github.com/abdulmalikraji/e-commerce/db/dao/categoryDao"
+	"github.com/abdulmalikraji/e-commerce/db/dao/couponDao"
+	"github.com/abdulmalikraji/e-commerce/db/dao/currencyDao"
+	"github.com/abdulmalikraji/e-commerce/db/dao/fulfillmentDao"
+	"github.com/abdulmalikraji/e-commerce/db/dao/importJobDao"
+	"github.com/abdulmalikraji/e-commerce/db/dao/languageDao"
+	"github.com/abdulmalikraji/e-commerce/db/dao/orderDao"
+	"github.com/abdulmalikraji/e-commerce/db/dao/orderItemDao"
+	"github.com/abdulmalikraji/e-commerce/db/dao/orderStatusHistoryDao"
+	"github.com/abdulmalikraji/e-commerce/db/dao/paymentDao"
+	"github.com/abdulmalikraji/e-commerce/db/dao/paymentEventDao"
+	"github.com/abdulmalikraji/e-commerce/db/dao/productDao"
+	productimagedao "github.com/abdulmalikraji/e-commerce/db/dao/productImageDao"
+	productvariantdao "github.com/abdulmalikraji/e-commerce/db/dao/productVariantDao"
+	"github.com/abdulmalikraji/e-commerce/db/dao/refundDao"
+	"github.com/abdulmalikraji/e-commerce/db/dao/stockReservationDao"
+	"github.com/abdulmalikraji/e-commerce/db/dao/storeDao"
+	"github.com/abdulmalikraji/e-commerce/db/dao/storeInvitationDao"
+	"github.com/abdulmalikraji/e-commerce/db/dao/storeRoleDao"
+	"github.com/abdulmalikraji/e-commerce/db/dao/storeUserDao"
+	"github.com/abdulmalikraji/e-commerce/db/dao/tagDao"
 	"github.com/abdulmalikraji/e-commerce/db/dao/userDao"
 	"github.com/abdulmalikraji/e-commerce/db/dao/userTokenDao"
+	"github.com/abdulmalikraji/e-commerce/db/models"
+	"github.com/abdulmalikraji/e-commerce/handler/admin"
 	"github.com/abdulmalikraji/e-commerce/handler/authentication"
+	"github.com/abdulmalikraji/e-commerce/handler/cart"
+	"github.com/abdulmalikraji/e-commerce/handler/checkout"
+	"github.com/abdulmalikraji/e-commerce/handler/coupon"
+	"github.com/abdulmalikraji/e-commerce/handler/fulfillment"
+	"github.com/abdulmalikraji/e-commerce/handler/order"
+	"github.com/abdulmalikraji/e-commerce/handler/payment"
+	"github.com/abdulmalikraji/e-commerce/handler/product"
+	"github.com/abdulmalikraji/e-commerce/handler/productImage"
+	"github.com/abdulmalikraji/e-commerce/handler/productImport"
+	"github.com/abdulmalikraji/e-commerce/handler/refund"
+	"github.com/abdulmalikraji/e-commerce/handler/search"
+	"github.com/abdulmalikraji/e-commerce/handler/staff"
+	"github.com/abdulmalikraji/e-commerce/handler/store"
+	"github.com/abdulmalikraji/e-commerce/handler/storeRole"
+	"github.com/abdulmalikraji/e-commerce/handler/taxonomy"
+	"github.com/abdulmalikraji/e-commerce/mailer"
+	"github.com/abdulmalikraji/e-commerce/payments"
 	"github.com/abdulmalikraji/e-commerce/services"
+	"github.com/abdulmalikraji/e-commerce/storage"
 	"github.com/gofiber/fiber/v2"
 	"github.com/supabase-community/auth-go"
 )
 
-func InitializeRoutes(app *fiber.App, client connection.Client, auth auth.Client) {
+func InitializeRoutes(app *fiber.App, client connection.Client, auth auth.Client, tokens *authenticator.TokenVerifier) {
 	// Initialize DB DAOs
 	userDao := userDao.New(client)
 	userTokenDao := userTokenDao.New(client)
+	cartDao := cartdao.New(client.PostgresConnection)
+	orderDao := orderDao.New(client)
+	couponDao := couponDao.New(client)
+	stockReservationDao := stockReservationDao.New(client)
+	orderStatusHistoryDao := orderStatusHistoryDao.New(client)
+	storeUsers := storeUserDao.New(client)
+	storeDao := storeDao.New(client)
+	storeInvitationDao := storeInvitationDao.New(client)
+	storeRoleDao := storeRoleDao.New(client)
+	orderItemDao := orderItemDao.New(client)
+	fulfillmentDao := fulfillmentDao.New(client)
+	paymentDao := paymentDao.New(client)
+	paymentEventDao := paymentEventDao.New(client)
+	refundDao := refundDao.New(client)
+	productDao := productDao.New(client)
+	productVariantDao := productvariantdao.New(client)
+	productImageDao := productimagedao.New(client)
+	currencyDao := currencyDao.New(client)
+	languageDao := languageDao.New(client)
+	categoryDao := categoryDao.New(client)
+	tagDao := tagDao.New(client)
+	analyticsDao := analyticsDao.New(client)
+	importJobDao := importJobDao.New(client)
+
+	// Payment providers enabled in the environment
+	paymentProviders := payments.New()
+	mail := mailer.New()
+	blobs := storage.New()
 
 	// Initialize Services
-	authService := services.NewAuthService(userDao, auth, userTokenDao)
+	authService := services.NewAuthService(userDao, auth, userTokenDao, cartDao, tokens)
 	authHandler := authentication.New(authService)
+	adminService := services.NewAdminService(userDao, auth)
+	adminHandler := admin.New(adminService)
+	storeService := services.NewStoreService(userDao, auth, storeDao, currencyDao, languageDao, blobs)
+	storeHandler := store.New(storeService)
+	staffService := services.NewStaffService(storeDao, storeUsers, storeInvitationDao, storeRoleDao, userDao, mail)
+	staffHandler := staff.New(staffService)
+	storeRoleService := services.NewStoreRoleService(storeRoleDao, storeUsers)
+	storeRoleHandler := storeRole.New(storeRoleService)
+	checkoutService := services.NewCheckoutService(cartDao, orderDao, couponDao, stockReservationDao)
+	checkoutHandler := checkout.New(checkoutService)
+	couponService := services.NewCouponService(cartDao, couponDao)
+	couponHandler := coupon.New(couponService)
+	productService := services.NewProductService(productDao, productVariantDao, categoryDao, tagDao)
+	productHandler := product.New(productService)
+	productImageService := services.NewProductImageService(productDao, productImageDao, blobs)
+	productImageHandler := productImage.New(productImageService)
+	productImportService := services.NewProductImportService(importJobDao, productDao, productVariantDao, categoryDao, tagDao)
+	productImportHandler := productImport.New(productImportService)
+	searchService := services.NewSearchService(productDao, analyticsDao)
+	searchHandler := search.New(searchService)
+	taxonomyService := services.NewTaxonomyService(categoryDao, tagDao, productDao)
+	taxonomyHandler := taxonomy.New(taxonomyService)
+	cartService := services.NewCartService(cartDao, productDao, productVariantDao)
+	cartHandler := cart.New(cartService)
+	orderService := services.NewOrderService(orderDao, orderStatusHistoryDao, stockReservationDao)
+	orderHandler := order.New(orderService)
+	fulfillmentService := services.NewFulfillmentService(fulfillmentDao, orderDao, orderItemDao, refundDao, paymentDao, stockReservationDao, paymentProviders)
+	fulfillmentHandler := fulfillment.New(fulfillmentService)
+	paymentService := services.NewPaymentService(orderDao, paymentDao, paymentEventDao, stockReservationDao, paymentProviders)
+	paymentHandler := payment.New(paymentService)
+	refundService := services.NewRefundService(orderDao, refundDao, paymentDao, stockReservationDao, paymentProviders)
+	refundHandler := refund.New(refundService)
 
 	// Create auth middleware
-	tokenMiddleware := middleware.TokenValidationMiddleware(authService)
+	tokenMiddleware := middleware.TokenValidationMiddleware(authService, storeUsers)
+	optionalTokenMiddleware := middleware.OptionalTokenMiddleware(authService, storeUsers)
 
 	// Auth routes (no token required)
 	app.Get("/reset-password", authHandler.ResetPasswordPage)
@@ -32,9 +137,123 @@ func InitializeRoutes(app *fiber.App, client connection.Client, auth auth.Client
 	authGroup.Get("/validate", tokenMiddleware, authHandler.ValidateToken)
 	authGroup.Post("/forgot-password", authHandler.ForgotPassword)
 	authGroup.Post("/reset-password", authHandler.ResetPassword)
-	
+
+	// Cart routes work for guests (session cookie) and logged-in users alike
+	cartGroup := app.Group("/cart", optionalTokenMiddleware)
+	cartGroup.Get("/", cartHandler.GetCart)
+	cartGroup.Post("/items", cartHandler.AddItem)
+	cartGroup.Patch("/items/:item_id", cartHandler.UpdateItem)
+	cartGroup.Delete("/items/:item_id", cartHandler.RemoveItem)
+	cartGroup.Delete("/", cartHandler.ClearCart)
+
+	// Uploaded files are served by the API itself while they are kept on the local disk
+	if local, ok := blobs.(storage.LocalStorage); ok {
+		app.Static(local.Route(), local.Dir)
+	}
+
+	// Public store pages; /stores/mine is declared first so it is not read as a store id
+	app.Get("/stores/mine", tokenMiddleware, storeHandler.GetMyStores)
+	publicStoreGroup := app.Group("/stores")
+	publicStoreGroup.Get("/", storeHandler.FindStores)
+	publicStoreGroup.Get("/:store_id", storeHandler.GetStoreByID)
+	publicStoreGroup.Get("/:store_id/products", storeHandler.GetStoreProducts)
+
+	// Public catalog
+	app.Get("/products", productHandler.ListProducts)
+	app.Get("/products/search", optionalTokenMiddleware, searchHandler.SearchProducts)
+	app.Get("/products/:id/breadcrumbs", taxonomyHandler.GetBreadcrumbs)
+	app.Get("/categories", taxonomyHandler.GetTree)
+	app.Get("/categories/:id", taxonomyHandler.GetSubtree)
+	app.Get("/tags", taxonomyHandler.ListTags)
+
+	// Provider callbacks authenticate with a signature instead of a user token
+	app.Post("/webhooks/payments/:provider", paymentHandler.HandleWebhook)
 
 	// Protected routes (require valid token)
 	app.Use(tokenMiddleware) // Apply to all routes after this point
 	authGroup.Post("/logout", authHandler.Logout)
+
+	checkoutGroup := app.Group("/checkout")
+	checkoutGroup.Get("/preview", checkoutHandler.PreviewCheckout)
+	checkoutGroup.Post("/", checkoutHandler.Checkout)
+
+	app.Get("/coupons/:code/validate", couponHandler.ValidateCoupon)
+
+	orderGroup := app.Group("/orders")
+	orderGroup.Patch("/:id/status", orderHandler.UpdateOrderStatus)
+	orderGroup.Get("/:id/history", orderHandler.GetOrderHistory)
+	orderGroup.Post("/:id/payments", paymentHandler.CreatePayment)
+	orderGroup.Get("/:id/payments", paymentHandler.GetOrderPayments)
+	orderGroup.Post("/:id/refunds", refundHandler.RequestRefund)
+	orderGroup.Get("/:id/refunds", refundHandler.GetOrderRefunds)
+
+	paymentGroup := app.Group("/payments")
+	paymentGroup.Post("/:id/capture", paymentHandler.CapturePayment)
+	paymentGroup.Post("/:id/cancel", paymentHandler.CancelPayment)
+
+	// Store lifecycle: sellers open stores; only the owner deletes or restores one
+	manageOrders := middleware.StorePermissionMiddleware(models.ActionManageOrders)
+	manageStoreSettings := middleware.StorePermissionMiddleware(models.ActionManageStoreSettings)
+	app.Post("/stores", middleware.RequireRole(models.UserRoleSeller, models.UserRoleAdmin), storeHandler.CreateStore)
+	app.Patch("/stores/:store_id", manageStoreSettings, storeHandler.UpdateStore)
+	app.Put("/stores/:store_id/image", manageStoreSettings, storeHandler.UpdateStoreImage)
+	app.Post("/stores/:store_id/image", manageStoreSettings, storeHandler.UploadStoreImage)
+	app.Get("/stores/:store_id/settings", manageStoreSettings, storeHandler.GetStoreSettings)
+	app.Patch("/stores/:store_id/settings", manageStoreSettings, storeHandler.UpdateStoreSettings)
+	app.Delete("/stores/:store_id", storeHandler.DeleteStore)
+	app.Post("/stores/:store_id/restore", storeHandler.RestoreStore)
+
+	// Product management; the public listing stays at GET /stores/:store_id/products
+	storeProductGroup := app.Group("/stores/:store_id/products")
+	storeProductGroup.Post("/", middleware.StorePermissionMiddleware(models.ActionAddProduct), productHandler.CreateProduct)
+	// Imports both add and update products; /export and /import are declared before /:id
+	storeProductGroup.Get("/export", middleware.StorePermissionMiddleware(models.ActionUpdateProduct), productImportHandler.ExportProducts)
+	storeProductGroup.Post("/import", middleware.StorePermissionMiddleware(models.ActionAddProduct), middleware.StorePermissionMiddleware(models.ActionUpdateProduct), productImportHandler.StartImport)
+	storeProductGroup.Get("/import/:job_id", middleware.StorePermissionMiddleware(models.ActionUpdateProduct), productImportHandler.GetImportJob)
+	storeProductGroup.Get("/:id", middleware.StorePermissionMiddleware(models.ActionUpdateProduct), productHandler.GetStoreProduct)
+	storeProductGroup.Patch("/:id", middleware.StorePermissionMiddleware(models.ActionUpdateProduct), productHandler.UpdateProduct)
+	storeProductGroup.Delete("/:id", middleware.StorePermissionMiddleware(models.ActionDeleteProduct), productHandler.DeleteProduct)
+	storeProductGroup.Post("/:id/variants/matrix", middleware.StorePermissionMiddleware(models.ActionUpdateProduct), productHandler.GenerateVariants)
+	storeProductGroup.Post("/:id/images", middleware.StorePermissionMiddleware(models.ActionUpdateProduct), productImageHandler.UploadImage)
+	storeProductGroup.Put("/:id/images/order", middleware.StorePermissionMiddleware(models.ActionUpdateProduct), productImageHandler.ReorderImages)
+	storeProductGroup.Put("/:id/images/:image_id/primary", middleware.StorePermissionMiddleware(models.ActionUpdateProduct), productImageHandler.SetPrimaryImage)
+	storeProductGroup.Delete("/:id/images/:image_id", middleware.StorePermissionMiddleware(models.ActionUpdateProduct), productImageHandler.DeleteImage)
+
+	// Seller routes: each store only sees and handles its own slice of an order
+	fulfillmentGroup := app.Group("/stores/:store_id/fulfillments", manageOrders)
+	fulfillmentGroup.Get("/", fulfillmentHandler.ListStoreFulfillments)
+	fulfillmentGroup.Get("/:id", fulfillmentHandler.GetFulfillment)
+	fulfillmentGroup.Patch("/:id", fulfillmentHandler.UpdateFulfillment)
+
+	storeRefundGroup := app.Group("/stores/:store_id/refunds", manageOrders)
+	storeRefundGroup.Get("/", refundHandler.ListStoreRefunds)
+	storeRefundGroup.Post("/:id/review", refundHandler.ReviewRefund)
+
+	// Store staff: owners and members allowed to manage the store's settings
+	staffGroup := app.Group("/stores/:store_id/staff", manageStoreSettings)
+	staffGroup.Get("/", staffHandler.ListStaff)
+	staffGroup.Get("/invitations", staffHandler.ListInvitations)
+	staffGroup.Post("/invitations", staffHandler.InviteStaff)
+	staffGroup.Delete("/invitations/:id", staffHandler.RevokeInvitation)
+	staffGroup.Patch("/:user_id", staffHandler.UpdateStaff)
+	staffGroup.Delete("/:user_id", staffHandler.RemoveStaff)
+
+	app.Post("/staff-invitations/accept", staffHandler.AcceptInvitation)
+
+	storeRoleGroup := app.Group("/stores/:store_id/roles", manageStoreSettings)
+	storeRoleGroup.Get("/", storeRoleHandler.ListRoles)
+	storeRoleGroup.Get("/actions", storeRoleHandler.ListActions)
+	storeRoleGroup.Post("/", storeRoleHandler.CreateRole)
+	storeRoleGroup.Patch("/:id", storeRoleHandler.UpdateRole)
+	storeRoleGroup.Delete("/:id", storeRoleHandler.DeleteRole)
+
+	// Platform administration
+	adminGroup := app.Group("/admin", middleware.RequireRole(models.UserRoleAdmin))
+	adminGroup.Patch("/users/:id/role", adminHandler.UpdateUserRole)
+	adminGroup.Get("/search/zero-results", searchHandler.ZeroResultSearches)
+	adminGroup.Post("/categories", taxonomyHandler.CreateCategory)
+	adminGroup.Patch("/categories/:id", taxonomyHandler.UpdateCategory)
+	adminGroup.Patch("/categories/:id/parent", taxonomyHandler.MoveCategory)
+	adminGroup.Delete("/categories/:id", taxonomyHandler.DeleteCategory)
+	adminGroup.Post("/products/recategorize", taxonomyHandler.RecategorizeProducts)
 }
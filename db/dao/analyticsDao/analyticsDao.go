@@ -1,21 +1,34 @@
 package analyticsDao
 
 import (
+	"time"
+
 	"github.com/abdulmalikraji/e-commerce/db/connection"
 	"github.com/abdulmalikraji/e-commerce/db/models"
 	"gorm.io/gorm"
 )
 
+//go:generate mockgen -destination=../../../mocks/dao/analyticsDao/mockAnalyticsDao.go -package=analyticsDao -source=analyticsDao.go
 type DataAccess interface {
 	LogProductView(view models.ProductView) error
 	LogAddToCart(event models.AddToCartEvent) error
 	LogAbandonedCart(event models.AbandonedCart) error
 	LogSalesStat(stat models.SalesStat) error
+	LogSearch(search models.SearchAnalytics) error
 
 	FindProductViews(productId string, sessionId *string, userId *string) ([]models.ProductView, error)
 	FindAddToCartEvents(productId string, sessionId *string, userId *string) ([]models.AddToCartEvent, error)
 	FindAbandonedCarts(userId *string, sessionId *string) ([]models.AbandonedCart, error)
 	FindSalesStats(productId string, sessionId *string, userId *string) ([]models.SalesStat, error)
+	// FindZeroResultSearches groups the searches since the given time that found
+	// nothing by query, case-insensitively, most frequent first.
+	FindZeroResultSearches(since time.Time, limit int) ([]ZeroResultSearch, error)
+}
+
+type ZeroResultSearch struct {
+	Query          string
+	Searches       int64
+	LastSearchedAt time.Time
 }
 
 type dataAccess struct {
@@ -40,6 +53,9 @@ func (d dataAccess) LogAbandonedCart(event models.AbandonedCart) error {
 func (d dataAccess) LogSalesStat(stat models.SalesStat) error {
 	return d.db.Table(stat.TableName()).Create(&stat).Error
 }
+func (d dataAccess) LogSearch(search models.SearchAnalytics) error {
+	return d.db.Table(search.TableName()).Create(&search).Error
+}
 
 func (d dataAccess) FindProductViews(productId string, sessionId *string, userId *string) ([]models.ProductView, error) {
 	var views []models.ProductView
@@ -92,3 +108,15 @@ func (d dataAccess) FindSalesStats(productId string, sessionId *string, userId *
 	err := query.Find(&stats).Error
 	return stats, err
 }
+
+func (d dataAccess) FindZeroResultSearches(since time.Time, limit int) ([]ZeroResultSearch, error) {
+	var searches []ZeroResultSearch
+	err := d.db.Table(models.SearchAnalytics{}.TableName()).
+		Select("lower(query) AS query, COUNT(*) AS searches, MAX(searched_at) AS last_searched_at").
+		Where("results = ? AND searched_at >= ?", 0, since).
+		Group("lower(query)").
+		Order("searches DESC, last_searched_at DESC").
+		Limit(limit).
+		Scan(&searches).Error
+	return searches, err
+}
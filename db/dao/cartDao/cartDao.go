@@ -5,20 +5,31 @@ import (
 	"gorm.io/gorm"
 )
 
+//go:generate mockgen -destination=../../../mocks/dao/cartDao/mockCartDao.go -package=cartDao -source=cartDao.go
 type DataAccess interface {
 	FindAll() ([]models.Cart, error)
 	FindById(id string) (models.Cart, error)
 	FindByUserId(userId string) (models.Cart, error)
+	FindBySessionId(sessionId string) (models.Cart, error)
 	Insert(item models.Cart) (models.Cart, error)
 	Update(item models.Cart) error
 	SoftDelete(id string) error
 	Delete(id string) error
 	// Cart item management
+	// FindItems returns the cart's lines with their product, its store and the variant,
+	// deleted ones included, so checkout can turn them down.
 	FindItems(cartId string) ([]models.CartItem, error)
+	FindItem(cartId string, itemId string) (models.CartItem, error)
+	// FindProductItem returns the cart line holding the product (and variant, if any).
+	FindProductItem(cartId string, productId string, variantId *string) (models.CartItem, error)
 	AddItem(item models.CartItem) (models.CartItem, error)
 	UpdateItem(item models.CartItem) error
 	RemoveItem(itemId string) error
 	ClearCart(cartId string) error
+	// Transaction runs fn inside a DB transaction; use WithTx to bind the DAO to tx.
+	Transaction(fn func(tx *gorm.DB) error) error
+	// WithTx returns a DataAccess bound to tx so cart writes join the caller's transaction.
+	WithTx(tx *gorm.DB) DataAccess
 }
 
 type dataAccess struct {
@@ -30,6 +41,17 @@ func New(db *gorm.DB) DataAccess {
 		db: db,
 	}
 }
+
+func (d dataAccess) Transaction(fn func(tx *gorm.DB) error) error {
+	return d.db.Transaction(fn)
+}
+
+func (d dataAccess) WithTx(tx *gorm.DB) DataAccess {
+	return dataAccess{
+		db: tx,
+	}
+}
+
 func (d dataAccess) FindAll() ([]models.Cart, error) {
 	var carts []models.Cart
 	result := d.db.Table(models.Cart{}.TableName()).
@@ -69,6 +91,17 @@ func (d dataAccess) FindByUserId(userId string) (models.Cart, error) {
 	return cart, nil
 }
 
+func (d dataAccess) FindBySessionId(sessionId string) (models.Cart, error) {
+	var cart models.Cart
+	result := d.db.Table(models.Cart{}.TableName()).
+		Where("session_id = ? AND del_flg = ?", sessionId, false).
+		First(&cart)
+	if result.Error != nil {
+		return models.Cart{}, result.Error
+	}
+	return cart, nil
+}
+
 func (d dataAccess) Insert(item models.Cart) (models.Cart, error) {
 	result := d.db.Table(item.TableName()).Create(&item)
 	if result.Error != nil {
@@ -115,6 +148,8 @@ func (d dataAccess) FindItems(cartId string) ([]models.CartItem, error) {
 	result := d.db.Table(models.CartItem{}.TableName()).
 		Where("cart_id = ? AND del_flg = ?", cartId, false).
 		Preload("Product").
+		Preload("Product.Store").
+		Preload("Variant").
 		Find(&items)
 	if result.Error != nil {
 		return []models.CartItem{}, result.Error
@@ -122,6 +157,35 @@ func (d dataAccess) FindItems(cartId string) ([]models.CartItem, error) {
 	return items, nil
 }
 
+func (d dataAccess) FindItem(cartId string, itemId string) (models.CartItem, error) {
+	var item models.CartItem
+	result := d.db.Table(models.CartItem{}.TableName()).
+		Where("id = ? AND cart_id = ? AND del_flg = ?", itemId, cartId, false).
+		Preload("Product").
+		Preload("Variant").
+		First(&item)
+	if result.Error != nil {
+		return models.CartItem{}, result.Error
+	}
+	return item, nil
+}
+
+func (d dataAccess) FindProductItem(cartId string, productId string, variantId *string) (models.CartItem, error) {
+	var item models.CartItem
+	query := d.db.Table(models.CartItem{}.TableName()).
+		Where("cart_id = ? AND product_id = ? AND del_flg = ?", cartId, productId, false)
+	if variantId != nil {
+		query = query.Where("variant_id = ?", *variantId)
+	} else {
+		query = query.Where("variant_id IS NULL")
+	}
+	result := query.First(&item)
+	if result.Error != nil {
+		return models.CartItem{}, result.Error
+	}
+	return item, nil
+}
+
 func (d dataAccess) AddItem(item models.CartItem) (models.CartItem, error) {
 	result := d.db.Table(item.TableName()).Create(&item)
 	if result.Error != nil {
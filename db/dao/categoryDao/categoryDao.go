@@ -1,19 +1,44 @@
 package categoryDao
 
 import (
+	"errors"
+
 	"github.com/abdulmalikraji/e-commerce/db/connection"
 	"github.com/abdulmalikraji/e-commerce/db/models"
+	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// ErrMoveIntoSubtree is returned by Move when the new parent is the category itself or
+// sits below it.
+var ErrMoveIntoSubtree = errors.New("category cannot be moved under itself or its descendants")
+
+//go:generate mockgen -destination=../../../mocks/dao/categoryDao/mockCategoryDao.go -package=categoryDao -source=categoryDao.go
 type DataAccess interface {
 	FindAll() ([]models.Category, error)
 	FindById(id string) (models.Category, error)
 	FindByName(name string) (models.Category, error)
 	FindChildren(parentId string) ([]models.Category, error)
 	FindParent(id string) (models.Category, error)
+	// FindSubtree returns the live category and every live category below it, parents
+	// before their children.
+	FindSubtree(id string) ([]models.Category, error)
+	// FindAncestors returns the live categories from the root down to the category itself.
+	FindAncestors(id string) ([]models.Category, error)
+	// NameTaken reports whether another category, deleted or not, is named name.
+	NameTaken(name string, exceptId string) (bool, error)
+	// CountProducts counts the live products with the category as main category or
+	// subcategory.
+	CountProducts(id string) (int64, error)
+	// Insert creates the category under its ParentID, filling in its path and depth.
 	Insert(item models.Category) (models.Category, error)
+	// Update writes the category's details; use Move to change its parent.
 	Update(item models.Category) error
+	// Move puts the category, with its whole subtree, under parent, or at the root when
+	// parent is nil. It re-reads both under lock and fails with ErrMoveIntoSubtree when
+	// parent is the category itself or one of its descendants.
+	Move(item models.Category, parent *models.Category) error
 	SoftDelete(id string) error
 	Delete(id string) error
 }
@@ -32,6 +57,7 @@ func (d dataAccess) FindAll() ([]models.Category, error) {
 	var categories []models.Category
 	result := d.db.Table(models.Category{}.TableName()).
 		Where("del_flg = ?", false).
+		Order("depth, name").
 		Find(&categories)
 	if result.Error != nil {
 		return []models.Category{}, result.Error
@@ -88,6 +114,20 @@ func (d dataAccess) FindParent(id string) (models.Category, error) {
 }
 
 func (d dataAccess) Insert(item models.Category) (models.Category, error) {
+	if item.ID == uuid.Nil {
+		item.ID = uuid.New()
+	}
+	var parent *models.Category
+	if item.ParentID != nil {
+		found, err := d.FindById(item.ParentID.String())
+		if err != nil {
+			return models.Category{}, err
+		}
+		parent = &found
+		item.Depth = found.Depth + 1
+	}
+	item.Path = models.CategoryPath(item.ID, parent)
+
 	result := d.db.Table(item.TableName()).Create(&item)
 	if result.Error != nil {
 		return models.Category{}, result.Error
@@ -100,6 +140,7 @@ const idWhere = "id = ? "
 func (d dataAccess) Update(item models.Category) error {
 	result := d.db.Table(item.TableName()).
 		Where(idWhere, item.ID).
+		Omit("parent_id", "path", "depth").
 		Updates(&item)
 	if result.Error != nil {
 		return result.Error
@@ -128,3 +169,127 @@ func (d dataAccess) Delete(id string) error {
 	}
 	return nil
 }
+
+func (d dataAccess) FindSubtree(id string) ([]models.Category, error) {
+	var categories []models.Category
+	result := d.db.Table(models.Category{}.TableName()).
+		Where("path LIKE (SELECT path FROM ecom.categories WHERE id = ? AND del_flg = ?) || '%'", id, false).
+		Where("del_flg = ?", false).
+		Order("depth, name").
+		Find(&categories)
+	if result.Error != nil {
+		return []models.Category{}, result.Error
+	}
+	return categories, nil
+}
+
+func (d dataAccess) FindAncestors(id string) ([]models.Category, error) {
+	var categories []models.Category
+	result := d.db.Table(models.Category{}.TableName()).
+		Where("(SELECT path FROM ecom.categories WHERE id = ?) LIKE path || '%'", id).
+		Where("del_flg = ?", false).
+		Order("depth").
+		Find(&categories)
+	if result.Error != nil {
+		return []models.Category{}, result.Error
+	}
+	return categories, nil
+}
+
+func (d dataAccess) Move(item models.Category, parent *models.Category) error {
+	if parent != nil && parent.ID == item.ID {
+		return ErrMoveIntoSubtree
+	}
+
+	return d.db.Transaction(func(tx *gorm.DB) error {
+		// Lock the category, the new parent and every ancestor of the parent. Two moves
+		// that could close a loop between them share one of these rows, so they run one
+		// after the other and the check below sees the tree the first one left behind.
+		ids := []uuid.UUID{item.ID}
+		if parent != nil {
+			ids = append(ids, parent.ID)
+		}
+		var locked []models.Category
+		res := tx.Table(item.TableName()).
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id IN ? AND del_flg = ?", ids, false).
+			Order("id").
+			Find(&locked)
+		if res.Error != nil {
+			return res.Error
+		}
+		if len(locked) != len(ids) {
+			return gorm.ErrRecordNotFound
+		}
+
+		var current models.Category
+		var target *models.Category
+		for i := range locked {
+			if locked[i].ID == item.ID {
+				current = locked[i]
+			} else {
+				target = &locked[i]
+			}
+		}
+		if current.Path == "" {
+			return errors.New("category path is not set")
+		}
+
+		depth := 0
+		var parentID *uuid.UUID
+		if target != nil {
+			res := tx.Table(item.TableName()).
+				Clauses(clause.Locking{Strength: "UPDATE"}).
+				Where("? LIKE path || '%' AND id <> ?", target.Path, target.ID).
+				Order("id").
+				Find(&[]models.Category{})
+			if res.Error != nil {
+				return res.Error
+			}
+			if target.IsDescendantOf(current) {
+				return ErrMoveIntoSubtree
+			}
+			depth = target.Depth + 1
+			parentID = &target.ID
+		}
+		path := models.CategoryPath(current.ID, target)
+
+		err := tx.Table(item.TableName()).
+			Where(idWhere, current.ID).
+			Updates(map[string]interface{}{"parent_id": parentID, "updated_by": item.UpdatedBy}).Error
+		if err != nil {
+			return err
+		}
+		// Re-root the paths of the category and its descendants.
+		return tx.Table(item.TableName()).
+			Where("path LIKE ?", current.Path+"%").
+			Updates(map[string]interface{}{
+				"path":  gorm.Expr("? || substr(path, ?)", path, len(current.Path)+1),
+				"depth": gorm.Expr("depth + ?", depth-current.Depth),
+			}).Error
+	})
+}
+
+func (d dataAccess) NameTaken(name string, exceptId string) (bool, error) {
+	var count int64
+	query := d.db.Table(models.Category{}.TableName()).Where("lower(name) = lower(?)", name)
+	if exceptId != "" {
+		query = query.Where("id <> ?", exceptId)
+	}
+	if err := query.Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (d dataAccess) CountProducts(id string) (int64, error) {
+	var count int64
+	result := d.db.Table(models.Product{}.TableName()).
+		Where("del_flg = ?", false).
+		Where("category_id = ? OR id IN (SELECT product_id FROM product_subcategories WHERE subcategory_id = ?)", id, id).
+		Count(&count)
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	return count, nil
+}
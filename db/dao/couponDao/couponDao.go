@@ -1,11 +1,22 @@
 package couponDao
 
 import (
+	"errors"
+
 	"github.com/abdulmalikraji/e-commerce/db/connection"
 	"github.com/abdulmalikraji/e-commerce/db/models"
+	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
+var (
+	// ErrUsageLimitReached is returned by Redeem when the coupon has no uses left.
+	ErrUsageLimitReached = errors.New("coupon usage limit reached")
+	// ErrUserLimitReached is returned by Redeem when the user has used the coupon as often as allowed.
+	ErrUserLimitReached = errors.New("coupon already used the maximum number of times by this user")
+)
+
+//go:generate mockgen -destination=../../../mocks/dao/couponDao/mockCouponDao.go -package=couponDao -source=couponDao.go
 type DataAccess interface {
 	FindAll() ([]models.Coupon, error)
 	FindById(id string) (models.Coupon, error)
@@ -14,6 +25,14 @@ type DataAccess interface {
 	Update(item models.Coupon) error
 	SoftDelete(id string) error
 	Delete(id string) error
+	CountUserRedemptions(couponId string, userId string) (int64, error)
+	// Redeem uses the coupon once for the user's order. The usage counter is only
+	// incremented while under MaxUses, and the row lock that increment takes serialises
+	// concurrent redemptions, so neither limit can be overshot. Run it inside the
+	// transaction that creates the order (see WithTx).
+	Redeem(coupon models.Coupon, userId uuid.UUID, orderId uuid.UUID) error
+	// WithTx returns a DataAccess bound to tx so redemptions join the caller's transaction.
+	WithTx(tx *gorm.DB) DataAccess
 }
 
 type dataAccess struct {
@@ -26,6 +45,12 @@ func New(client connection.Client) DataAccess {
 	}
 }
 
+func (d dataAccess) WithTx(tx *gorm.DB) DataAccess {
+	return dataAccess{
+		db: tx,
+	}
+}
+
 func (d dataAccess) FindAll() ([]models.Coupon, error) {
 	var coupons []models.Coupon
 	result := d.db.Table(models.Coupon{}.TableName()).
@@ -100,3 +125,44 @@ func (d dataAccess) Delete(id string) error {
 	}
 	return nil
 }
+
+func (d dataAccess) CountUserRedemptions(couponId string, userId string) (int64, error) {
+	var count int64
+	result := d.db.Table(models.CouponRedemption{}.TableName()).
+		Where("coupon_id = ? AND user_id = ?", couponId, userId).
+		Count(&count)
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	return count, nil
+}
+
+func (d dataAccess) Redeem(coupon models.Coupon, userId uuid.UUID, orderId uuid.UUID) error {
+	result := d.db.Table(coupon.TableName()).
+		Where("id = ? AND del_flg = ? AND (max_uses IS NULL OR used_count < max_uses)", coupon.ID, false).
+		Update("used_count", gorm.Expr("used_count + 1"))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrUsageLimitReached
+	}
+
+	if coupon.MaxUsesPerUser != nil {
+		used, err := d.CountUserRedemptions(coupon.ID.String(), userId.String())
+		if err != nil {
+			return err
+		}
+		if used >= int64(*coupon.MaxUsesPerUser) {
+			return ErrUserLimitReached
+		}
+	}
+
+	redemption := models.CouponRedemption{
+		CouponID: coupon.ID,
+		UserID:   userId,
+		OrderID:  orderId,
+	}
+	result = d.db.Table(redemption.TableName()).Create(&redemption)
+	return result.Error
+}
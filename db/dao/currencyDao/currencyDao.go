@@ -0,0 +1,46 @@
+package currencyDao
+
+import (
+	"github.com/abdulmalikraji/e-commerce/db/connection"
+	"github.com/abdulmalikraji/e-commerce/db/models"
+	"gorm.io/gorm"
+)
+
+//go:generate mockgen -destination=../../../mocks/dao/currencyDao/mockCurrencyDao.go -package=currencyDao -source=currencyDao.go
+type DataAccess interface {
+	FindAll() ([]models.Currency, error)
+	FindById(id string) (models.Currency, error)
+}
+
+type dataAccess struct {
+	db *gorm.DB
+}
+
+func New(client connection.Client) DataAccess {
+	return dataAccess{
+		db: client.PostgresConnection,
+	}
+}
+
+func (d dataAccess) FindAll() ([]models.Currency, error) {
+	var items []models.Currency
+	result := d.db.Table(models.Currency{}.TableName()).
+		Where("del_flg = ?", false).
+		Order("code").
+		Find(&items)
+	if result.Error != nil {
+		return []models.Currency{}, result.Error
+	}
+	return items, nil
+}
+
+func (d dataAccess) FindById(id string) (models.Currency, error) {
+	var item models.Currency
+	result := d.db.Table(models.Currency{}.TableName()).
+		Where("id = ? AND del_flg = ?", id, false).
+		First(&item)
+	if result.Error != nil {
+		return models.Currency{}, result.Error
+	}
+	return item, nil
+}
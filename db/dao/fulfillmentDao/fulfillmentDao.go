@@ -0,0 +1,111 @@
+package fulfillmentDao
+
+import (
+	"github.com/abdulmalikraji/e-commerce/db/connection"
+	"github.com/abdulmalikraji/e-commerce/db/models"
+	"gorm.io/gorm"
+)
+
+//go:generate mockgen -destination=../../../mocks/dao/fulfillmentDao/mockFulfillmentDao.go -package=fulfillmentDao -source=fulfillmentDao.go
+type DataAccess interface {
+	FindById(id string) (models.Fulfillment, error)
+	FindByOrderId(orderId string) ([]models.Fulfillment, error)
+	FindByStoreId(storeId string, status *string) ([]models.Fulfillment, error)
+	FindByIdAndStore(id string, storeId string) (models.Fulfillment, error)
+	Insert(item models.Fulfillment) (models.Fulfillment, error)
+	Update(item models.Fulfillment) error
+	SoftDelete(id string) error
+}
+
+type dataAccess struct {
+	db *gorm.DB
+}
+
+func New(client connection.Client) DataAccess {
+	return dataAccess{
+		db: client.PostgresConnection,
+	}
+}
+
+func (d dataAccess) FindById(id string) (models.Fulfillment, error) {
+	var item models.Fulfillment
+	result := d.db.Table(models.Fulfillment{}.TableName()).
+		Where("id = ? AND del_flg = ?", id, false).
+		Preload("Order").
+		First(&item)
+	if result.Error != nil {
+		return models.Fulfillment{}, result.Error
+	}
+	return item, nil
+}
+
+func (d dataAccess) FindByOrderId(orderId string) ([]models.Fulfillment, error) {
+	var items []models.Fulfillment
+	result := d.db.Table(models.Fulfillment{}.TableName()).
+		Where("order_id = ? AND del_flg = ?", orderId, false).
+		Find(&items)
+	if result.Error != nil {
+		return []models.Fulfillment{}, result.Error
+	}
+	return items, nil
+}
+
+func (d dataAccess) FindByStoreId(storeId string, status *string) ([]models.Fulfillment, error) {
+	var items []models.Fulfillment
+	query := d.db.Table(models.Fulfillment{}.TableName()).
+		Where("store_id = ? AND del_flg = ?", storeId, false)
+	if status != nil {
+		query = query.Where("status = ?", *status)
+	}
+	result := query.
+		Preload("Order").
+		Order("created_at DESC").
+		Find(&items)
+	if result.Error != nil {
+		return []models.Fulfillment{}, result.Error
+	}
+	return items, nil
+}
+
+func (d dataAccess) FindByIdAndStore(id string, storeId string) (models.Fulfillment, error) {
+	var item models.Fulfillment
+	result := d.db.Table(models.Fulfillment{}.TableName()).
+		Where("id = ? AND store_id = ? AND del_flg = ?", id, storeId, false).
+		Preload("Order").
+		First(&item)
+	if result.Error != nil {
+		return models.Fulfillment{}, result.Error
+	}
+	return item, nil
+}
+
+func (d dataAccess) Insert(item models.Fulfillment) (models.Fulfillment, error) {
+	result := d.db.Table(item.TableName()).Create(&item)
+	if result.Error != nil {
+		return models.Fulfillment{}, result.Error
+	}
+	return item, nil
+}
+
+const idWhere = "id = ? "
+
+func (d dataAccess) Update(item models.Fulfillment) error {
+	result := d.db.Table(item.TableName()).
+		Where(idWhere, item.ID).
+		Updates(&item)
+	if result.Error != nil {
+		return result.Error
+	}
+	return nil
+}
+
+func (d dataAccess) SoftDelete(id string) error {
+	var item models.Fulfillment
+	result := d.db.Table(item.TableName()).
+		Where(idWhere, id).
+		Update("del_flg", true)
+	if result.Error != nil {
+		return result.Error
+	}
+	return nil
+}
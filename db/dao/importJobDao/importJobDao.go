@@ -0,0 +1,115 @@
+package importJobDao
+
+import (
+	"errors"
+	"time"
+
+	"github.com/abdulmalikraji/e-commerce/db/connection"
+	"github.com/abdulmalikraji/e-commerce/db/models"
+	"gorm.io/gorm"
+)
+
+// ErrJobLost is returned when a job is no longer running under the claim the caller
+// holds, because another worker took it over after it went stale.
+var ErrJobLost = errors.New("import job was taken over by another worker")
+
+//go:generate mockgen -destination=../../../mocks/dao/importJobDao/mockImportJobDao.go -package=importJobDao -source=importJobDao.go
+type DataAccess interface {
+	Insert(item models.ImportJob) (models.ImportJob, error)
+	// FindByIdAndStore loads one of the store's jobs, without its payload.
+	FindByIdAndStore(id string, storeId string) (models.ImportJob, error)
+	// ClaimNext marks the oldest pending job as running and returns it. Running jobs
+	// not updated since staleAfter are claimed again, as their worker has stopped.
+	// found is false when there is no job to run.
+	ClaimNext(staleAfter time.Time) (job models.ImportJob, found bool, err error)
+	// UpdateProgress writes the job's counters and row errors.
+	UpdateProgress(item models.ImportJob) error
+	// Requeue hands a running job back to the queue, e.g. when its worker stops.
+	Requeue(item models.ImportJob) error
+	// Finish writes the job's final status and counters and drops its payload.
+	Finish(item models.ImportJob) error
+}
+
+type dataAccess struct {
+	db *gorm.DB
+}
+
+func New(client connection.Client) DataAccess {
+	return dataAccess{
+		db: client.PostgresConnection,
+	}
+}
+
+func (d dataAccess) Insert(item models.ImportJob) (models.ImportJob, error) {
+	result := d.db.Table(item.TableName()).Create(&item)
+	if result.Error != nil {
+		return models.ImportJob{}, result.Error
+	}
+	return item, nil
+}
+
+func (d dataAccess) FindByIdAndStore(id string, storeId string) (models.ImportJob, error) {
+	var job models.ImportJob
+	result := d.db.Table(models.ImportJob{}.TableName()).
+		Omit("payload").
+		Where("id = ? AND store_id = ?", id, storeId).
+		First(&job)
+	if result.Error != nil {
+		return models.ImportJob{}, result.Error
+	}
+	return job, nil
+}
+
+// ClaimNext locks the job with SKIP LOCKED, so several API instances never claim the
+// same job.
+func (d dataAccess) ClaimNext(staleAfter time.Time) (models.ImportJob, bool, error) {
+	var jobs []models.ImportJob
+	result := d.db.Raw(`UPDATE ecom.import_jobs SET status = @running, started_at = now(), updated_at = now()
+		WHERE id = (SELECT id FROM ecom.import_jobs
+			WHERE status = @pending OR (status = @running AND updated_at < @stale)
+			ORDER BY created_at LIMIT 1 FOR UPDATE SKIP LOCKED)
+		RETURNING *`, map[string]interface{}{
+		"running": models.ImportStatusRunning,
+		"pending": models.ImportStatusPending,
+		"stale":   staleAfter,
+	}).Scan(&jobs)
+	if result.Error != nil {
+		return models.ImportJob{}, false, result.Error
+	}
+	if len(jobs) == 0 {
+		return models.ImportJob{}, false, nil
+	}
+	return jobs[0], true, nil
+}
+
+func (d dataAccess) UpdateProgress(item models.ImportJob) error {
+	return d.updateClaimed(item, "total", "processed", "created", "updated", "failed", "row_errors", "updated_at")
+}
+
+func (d dataAccess) Requeue(item models.ImportJob) error {
+	item.Status = models.ImportStatusPending
+	return d.updateClaimed(item, "status", "updated_at")
+}
+
+func (d dataAccess) Finish(item models.ImportJob) error {
+	now := time.Now()
+	item.FinishedAt = &now
+	item.Payload = nil
+	return d.updateClaimed(item, "status", "total", "processed", "created", "updated", "failed", "row_errors", "error", "payload", "finished_at", "updated_at")
+}
+
+// updateClaimed writes the columns only while the job still runs under the claim item
+// was returned with, its StartedAt, and fails with ErrJobLost otherwise.
+func (d dataAccess) updateClaimed(item models.ImportJob, columns ...string) error {
+	result := d.db.Table(item.TableName()).
+		Where("id = ? AND status = ? AND started_at = ?", item.ID, models.ImportStatusRunning, item.StartedAt).
+		Select(columns).
+		Updates(&item)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrJobLost
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package languageDao
+
+import (
+	"github.com/abdulmalikraji/e-commerce/db/connection"
+	"github.com/abdulmalikraji/e-commerce/db/models"
+	"gorm.io/gorm"
+)
+
+//go:generate mockgen -destination=../../../mocks/dao/languageDao/mockLanguageDao.go -package=languageDao -source=languageDao.go
+type DataAccess interface {
+	FindAll() ([]models.Language, error)
+	FindById(id string) (models.Language, error)
+}
+
+type dataAccess struct {
+	db *gorm.DB
+}
+
+func New(client connection.Client) DataAccess {
+	return dataAccess{
+		db: client.PostgresConnection,
+	}
+}
+
+func (d dataAccess) FindAll() ([]models.Language, error) {
+	var items []models.Language
+	result := d.db.Table(models.Language{}.TableName()).
+		Where("del_flg = ?", false).
+		Order("code").
+		Find(&items)
+	if result.Error != nil {
+		return []models.Language{}, result.Error
+	}
+	return items, nil
+}
+
+func (d dataAccess) FindById(id string) (models.Language, error) {
+	var item models.Language
+	result := d.db.Table(models.Language{}.TableName()).
+		Where("id = ? AND del_flg = ?", id, false).
+		First(&item)
+	if result.Error != nil {
+		return models.Language{}, result.Error
+	}
+	return item, nil
+}
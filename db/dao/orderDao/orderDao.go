@@ -1,12 +1,15 @@
 package orderDao
 
 import (
+	"time"
+
 	"github.com/abdulmalikraji/e-commerce/db/connection"
 	"github.com/abdulmalikraji/e-commerce/db/models"
 	"github.com/abdulmalikraji/e-commerce/dto/orderDto"
 	"gorm.io/gorm"
 )
 
+//go:generate mockgen -destination=../../../mocks/dao/orderDao/mockOrderDao.go -package=orderDao -source=orderDao.go
 type DataAccess interface {
 	// Postgres Data Access Object Methods
 	FindAll() ([]models.Order, error)
@@ -14,10 +17,16 @@ type DataAccess interface {
 	FindByBuyerId(userId string) ([]models.Order, error)
 	FindOrderItems(id string) (models.Order, error)
 	FindWithFilters(filter orderDto.OrderFilter) ([]models.Order, error)
+	// FindStalePending returns up to limit pending orders placed before placedBefore,
+	// oldest first.
+	FindStalePending(placedBefore time.Time, limit int) ([]models.Order, error)
 	Insert(item models.Order) (models.Order, error)
 	Update(item models.Order) error
 	SoftDelete(id string) error
 	Delete(id string) error
+	// Transaction runs the provided function inside a DB transaction. If fn returns an error,
+	// the transaction is rolled back. Use this to perform multiple DB operations atomically.
+	Transaction(fn func(tx *gorm.DB) error) error
 }
 
 type dataAccess struct {
@@ -30,6 +39,11 @@ func New(client connection.Client) DataAccess {
 	}
 }
 
+// Transaction executes fn inside a gorm transaction using the DAO's DB connection.
+func (d dataAccess) Transaction(fn func(tx *gorm.DB) error) error {
+	return d.db.Transaction(fn)
+}
+
 func (d dataAccess) FindAll() ([]models.Order, error) {
 	var orders []models.Order
 	result := d.db.Table(models.Order{}.TableName()).
@@ -106,6 +120,19 @@ func (d dataAccess) FindWithFilters(filter orderDto.OrderFilter) ([]models.Order
 	return orders, nil
 }
 
+func (d dataAccess) FindStalePending(placedBefore time.Time, limit int) ([]models.Order, error) {
+	var orders []models.Order
+	result := d.db.Table(models.Order{}.TableName()).
+		Where("status = ? AND created_at < ? AND del_flg = ?", models.OrderStatusPending, placedBefore, false).
+		Order("created_at").
+		Limit(limit).
+		Find(&orders)
+	if result.Error != nil {
+		return []models.Order{}, result.Error
+	}
+	return orders, nil
+}
+
 func (d dataAccess) Insert(item models.Order) (models.Order, error) {
 	result := d.db.Table(item.TableName()).Create(&item)
 	if result.Error != nil {
@@ -116,9 +143,12 @@ func (d dataAccess) Insert(item models.Order) (models.Order, error) {
 
 const idWhere = "id = ? "
 
+// Update writes every field except Status; status changes must go through the
+// order service so they are validated and recorded in the status history.
 func (d dataAccess) Update(item models.Order) error {
 	result := d.db.Table(item.TableName()).
 		Where(idWhere, item.ID).
+		Omit("status").
 		Updates(&item)
 	if result.Error != nil {
 		return result.Error
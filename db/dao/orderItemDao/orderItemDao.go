@@ -6,10 +6,12 @@ import (
 	"gorm.io/gorm"
 )
 
+//go:generate mockgen -destination=../../../mocks/dao/orderItemDao/mockOrderItemDao.go -package=orderItemDao -source=orderItemDao.go
 type DataAccess interface {
 	FindAll() ([]models.OrderItem, error)
 	FindById(id string) (models.OrderItem, error)
 	FindByOrderId(orderId string) ([]models.OrderItem, error)
+	FindByOrderAndStore(orderId string, storeId string) ([]models.OrderItem, error)
 	FindOrderItemReview(id string) (models.OrderItem, error)
 	Insert(item models.OrderItem) (models.OrderItem, error)
 	Update(item models.OrderItem) error
@@ -69,6 +71,19 @@ func (d dataAccess) FindByOrderId(orderId string) ([]models.OrderItem, error) {
 	return items, nil
 }
 
+func (d dataAccess) FindByOrderAndStore(orderId string, storeId string) ([]models.OrderItem, error) {
+	var items []models.OrderItem
+	result := d.db.Table(models.OrderItem{}.TableName()).
+		Where("order_id = ? AND store_id = ? AND del_flg = ?", orderId, storeId, false).
+		Preload("Product").
+		Preload("Variant").
+		Find(&items)
+	if result.Error != nil {
+		return []models.OrderItem{}, result.Error
+	}
+	return items, nil
+}
+
 func (d dataAccess) FindOrderItemReview(id string) (models.OrderItem, error) {
 	var item models.OrderItem
 	result := d.db.Table(models.OrderItem{}.TableName()).
@@ -0,0 +1,43 @@
+package orderStatusHistoryDao
+
+import (
+	"github.com/abdulmalikraji/e-commerce/db/connection"
+	"github.com/abdulmalikraji/e-commerce/db/models"
+	"gorm.io/gorm"
+)
+
+//go:generate mockgen -destination=../../../mocks/dao/orderStatusHistoryDao/mockOrderStatusHistoryDao.go -package=orderStatusHistoryDao -source=orderStatusHistoryDao.go
+type DataAccess interface {
+	FindByOrderId(orderId string) ([]models.OrderStatusHistory, error)
+	Insert(item models.OrderStatusHistory) (models.OrderStatusHistory, error)
+}
+
+type dataAccess struct {
+	db *gorm.DB
+}
+
+func New(client connection.Client) DataAccess {
+	return dataAccess{
+		db: client.PostgresConnection,
+	}
+}
+
+func (d dataAccess) FindByOrderId(orderId string) ([]models.OrderStatusHistory, error) {
+	var items []models.OrderStatusHistory
+	result := d.db.Table(models.OrderStatusHistory{}.TableName()).
+		Where("order_id = ?", orderId).
+		Order("created_at ASC").
+		Find(&items)
+	if result.Error != nil {
+		return []models.OrderStatusHistory{}, result.Error
+	}
+	return items, nil
+}
+
+func (d dataAccess) Insert(item models.OrderStatusHistory) (models.OrderStatusHistory, error) {
+	result := d.db.Table(item.TableName()).Create(&item)
+	if result.Error != nil {
+		return models.OrderStatusHistory{}, result.Error
+	}
+	return item, nil
+}
@@ -6,15 +6,18 @@ import (
 	"gorm.io/gorm"
 )
 
+//go:generate mockgen -destination=../../../mocks/dao/paymentDao/mockPaymentDao.go -package=paymentDao -source=paymentDao.go
 type DataAccess interface {
 	FindAll() ([]models.Payment, error)
 	FindById(id string) (models.Payment, error)
 	FindByOrderId(orderId string) ([]models.Payment, error)
 	FindLatestByOrderId(orderId string) (models.Payment, error)
+	FindByTransactionRef(provider string, transactionRef string) (models.Payment, error)
 	Insert(item models.Payment) (models.Payment, error)
 	Update(item models.Payment) error
 	SoftDelete(id string) error
 	Delete(id string) error
+	UpdateStatus(id string, status string) error
 }
 
 type dataAccess struct {
@@ -76,6 +79,17 @@ func (d dataAccess) FindLatestByOrderId(orderId string) (models.Payment, error)
 	return payment, nil
 }
 
+func (d dataAccess) FindByTransactionRef(provider string, transactionRef string) (models.Payment, error) {
+	var payment models.Payment
+	result := d.db.Table(models.Payment{}.TableName()).
+		Where("provider = ? AND transaction_ref = ? AND del_flg = ?", provider, transactionRef, false).
+		First(&payment)
+	if result.Error != nil {
+		return models.Payment{}, result.Error
+	}
+	return payment, nil
+}
+
 func (d dataAccess) Insert(item models.Payment) (models.Payment, error) {
 	result := d.db.Table(item.TableName()).Create(&item)
 	if result.Error != nil {
@@ -117,3 +131,14 @@ func (d dataAccess) Delete(id string) error {
 	}
 	return nil
 }
+
+func (d dataAccess) UpdateStatus(id string, status string) error {
+	var item models.Payment
+	result := d.db.Table(item.TableName()).
+		Where(idWhere, id).
+		Update("status", status)
+	if result.Error != nil {
+		return result.Error
+	}
+	return nil
+}
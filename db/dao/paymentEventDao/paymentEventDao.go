@@ -0,0 +1,58 @@
+package paymentEventDao
+
+import (
+	"github.com/abdulmalikraji/e-commerce/db/connection"
+	"github.com/abdulmalikraji/e-commerce/db/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+//go:generate mockgen -destination=../../../mocks/dao/paymentEventDao/mockPaymentEventDao.go -package=paymentEventDao -source=paymentEventDao.go
+type DataAccess interface {
+	FindByEventId(provider string, eventId string) (models.PaymentEvent, error)
+	// Record stores the event unless the provider already delivered it. It reports
+	// whether the event is new, so callers process each event exactly once.
+	Record(item models.PaymentEvent) (bool, error)
+	// WithTx returns a DataAccess bound to tx so events are recorded with the changes they cause.
+	WithTx(tx *gorm.DB) DataAccess
+}
+
+type dataAccess struct {
+	db *gorm.DB
+}
+
+func New(client connection.Client) DataAccess {
+	return dataAccess{
+		db: client.PostgresConnection,
+	}
+}
+
+func (d dataAccess) WithTx(tx *gorm.DB) DataAccess {
+	return dataAccess{
+		db: tx,
+	}
+}
+
+func (d dataAccess) FindByEventId(provider string, eventId string) (models.PaymentEvent, error) {
+	var event models.PaymentEvent
+	result := d.db.Table(event.TableName()).
+		Where("provider = ? AND event_id = ?", provider, eventId).
+		First(&event)
+	if result.Error != nil {
+		return models.PaymentEvent{}, result.Error
+	}
+	return event, nil
+}
+
+func (d dataAccess) Record(item models.PaymentEvent) (bool, error) {
+	result := d.db.Table(item.TableName()).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "provider"}, {Name: "event_id"}},
+			DoNothing: true,
+		}).
+		Create(&item)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
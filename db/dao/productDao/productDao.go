@@ -4,9 +4,11 @@ import (
 	"github.com/abdulmalikraji/e-commerce/db/connection"
 	"github.com/abdulmalikraji/e-commerce/db/models"
 	"github.com/abdulmalikraji/e-commerce/dto/productDto"
+	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
+//go:generate mockgen -destination=../../../mocks/dao/productDao/mockProductDao.go -package=productDao -source=productDao.go
 type DataAccess interface {
 	// Postgres Data Access Object Methods
 	FindAll() ([]models.Product, error)
@@ -22,8 +24,64 @@ type DataAccess interface {
 	SoftDelete(id string) error
 	Delete(id string) error
 	FindByFilter(filter productDto.ProductFilter) ([]models.Product, int64, error)
+	// FindByIdAndStore loads one of the store's products with its live variants.
+	FindByIdAndStore(id string, storeId string) (models.Product, error)
+	// FindByBarcodeAndStore loads the store's live product with barcode, with its live
+	// variants.
+	FindByBarcodeAndStore(barcode string, storeId string) (models.Product, error)
+	// FindBatchByStore returns up to limit of the store's live products with an ID after
+	// afterId, in ID order, for walking the whole catalog in batches.
+	FindBatchByStore(storeId string, afterId uuid.UUID, limit int) ([]models.Product, error)
+	// BarcodeTaken reports whether another product, deleted or not, uses barcode.
+	BarcodeTaken(barcode string, exceptId string) (bool, error)
+	// UpdateDetails writes every editable product column, including zero values.
+	UpdateDetails(item models.Product) error
+	// ReplaceImages replaces the images of the product itself that were given by URL;
+	// uploaded and variant images are kept. A primary image in images takes over from
+	// the current one.
+	ReplaceImages(productId uuid.UUID, images []models.ProductImage) error
+	// SaveOptions makes options the product's options, matching existing options and
+	// values by name so variants keep their values, and deleting the ones left out. It
+	// returns options with their IDs.
+	SaveOptions(productId uuid.UUID, options []models.ProductOption) ([]models.ProductOption, error)
+	// FindByIds loads live products with their tags and subcategories.
+	FindByIds(ids []string) ([]models.Product, error)
+	// SetClassification writes the product's main category, subcategories and tags and
+	// refreshes its search document. It is the only writer of the product_subcategories
+	// and product_tags join tables.
+	SetClassification(item models.Product) error
+	// Search ranks live products against a web-style query, matching misspelled names
+	// by trigram similarity, and returns one page of results with the total.
+	Search(query string, page int, pageSize int) ([]SearchResult, int64, error)
+	// RefreshSearchVector recomputes the product's search document; call it after its
+	// name or description change.
+	RefreshSearchVector(id string) error
+	// RefreshCategorySearchVectors recomputes the search document of every product
+	// classified under the category; call it after the category is renamed.
+	RefreshCategorySearchVectors(categoryId string) error
+	// Transaction runs fn inside a DB transaction.
+	Transaction(fn func(tx *gorm.DB) error) error
+	// WithTx returns a DataAccess bound to tx.
+	WithTx(tx *gorm.DB) DataAccess
 }
 
+// SearchResult is a product matched by Search, with its name and description
+// fragments. Matches are wrapped in HighlightStart and HighlightStop; the fragments
+// are the stored text as is and must be escaped before they are rendered as HTML.
+type SearchResult struct {
+	Product              models.Product
+	NameHighlight        string
+	DescriptionHighlight string
+	Rank                 float64
+}
+
+// HighlightStart and HighlightStop delimit the matches in SearchResult fragments. They are
+// control characters so they cannot be confused with markup in the product text.
+const (
+	HighlightStart = "\x02"
+	HighlightStop  = "\x03"
+)
+
 type dataAccess struct {
 	db *gorm.DB
 }
@@ -39,7 +97,7 @@ func (d dataAccess) FindAll() ([]models.Product, error) {
 	result := d.db.Table(models.Product{}.TableName()).
 		Where("del_flg = ?", false).
 		Preload("Category").
-		Preload("Images").
+		Preload("Images", productLevelImages).
 		Preload("Variants").
 		Preload("WarehouseStock").
 		Preload("Tags").
@@ -54,9 +112,9 @@ func (d dataAccess) FindAll() ([]models.Product, error) {
 func (d dataAccess) FindByStoreId(storeId string) ([]models.Product, error) {
 	var products []models.Product
 	result := d.db.Table(models.Product{}.TableName()).
-		Where("store_id = ? AND del_flg = ?", storeId, true, false).
+		Where("store_id = ? AND del_flg = ?", storeId, false).
 		Preload("Category").
-		Preload("Images").
+		Preload("Images", productLevelImages).
 		Preload("Variants").
 		Preload("WarehouseStock").
 		Preload("Tags").
@@ -73,7 +131,7 @@ func (d dataAccess) FindById(id string) (models.Product, error) {
 	result := d.db.Table(models.Product{}.TableName()).
 		Where("id = ? AND del_flg = ?", id, false).
 		Preload("Category").
-		Preload("Images").
+		Preload("Images", productLevelImages).
 		Preload("Variants").
 		Preload("WarehouseStock").
 		Preload("Tags").
@@ -91,7 +149,7 @@ func (d dataAccess) FindPopular() ([]models.Product, error) {
 	result := d.db.Table(models.Product{}.TableName()).
 		Where("is_popular = ? AND del_flg = ?", true, false).
 		Preload("Category").
-		Preload("Images").
+		Preload("Images", productLevelImages).
 		Preload("Variants").
 		Preload("WarehouseStock").
 		Preload("Tags").
@@ -109,7 +167,7 @@ func (d dataAccess) FindPopularByStoreId(storeId string) ([]models.Product, erro
 	result := d.db.Table(models.Product{}.TableName()).
 		Where("store_id = ? AND is_popular = ? AND del_flg = ?", storeId, true, false).
 		Preload("Category").
-		Preload("Images").
+		Preload("Images", productLevelImages).
 		Preload("Variants").
 		Preload("WarehouseStock").
 		Preload("Tags").
@@ -127,7 +185,7 @@ func (d dataAccess) FindByCategoryId(categoryId string) ([]models.Product, error
 	result := d.db.Table(models.Product{}.TableName()).
 		Where("category_id = ? AND del_flg = ?", categoryId, false).
 		Preload("Category").
-		Preload("Images").
+		Preload("Images", productLevelImages).
 		Preload("Reviews.User").
 		Preload("Reviews.Variant").
 		Preload("SubCategories").
@@ -165,9 +223,11 @@ func (d dataAccess) FindProductReviews(id string) (models.Product, error) {
 	return product, nil
 }
 
+// Insert creates the product with its images and variants. Its tags and subcategories
+// are left out; write them with SetClassification.
 func (d dataAccess) Insert(item models.Product) (models.Product, error) {
 
-	result := d.db.Table(item.TableName()).Create(&item)
+	result := d.db.Table(item.TableName()).Omit("Tags", "SubCategories", "Options", "Variants").Create(&item)
 
 	if result.Error != nil {
 		return models.Product{}, result.Error
@@ -220,35 +280,55 @@ func (d dataAccess) Delete(id string) error {
 // Add more fields as needed
 // Pagination: Page (1-based), PageSize
 
+// productSortOrders maps each ProductFilter sort to its ORDER BY. Best-selling counts
+// the units of orders that were paid for.
+var productSortOrders = map[string]string{
+	productDto.SortNewest:    "products.created_at DESC",
+	productDto.SortPriceAsc:  "products.price ASC",
+	productDto.SortPriceDesc: "products.price DESC",
+	productDto.SortRating:    "products.rating_average DESC, products.rating_count DESC",
+	productDto.SortBestSelling: `(SELECT COALESCE(SUM(order_items.quantity), 0) FROM order_items
+		JOIN orders ON orders.id = order_items.order_id
+		WHERE order_items.product_id = products.id AND order_items.del_flg = false
+		AND orders.status IN ('paid', 'fulfilled', 'shipped', 'delivered')) DESC`,
+}
+
+// FindByFilter returns a page of the live products of live stores matching filter,
+// and the number of matches across all pages.
 func (d dataAccess) FindByFilter(filter productDto.ProductFilter) ([]models.Product, int64, error) {
-	query := d.db.Table(models.Product{}.TableName()).Where("del_flg = ?", false)
+	query := d.db.Table(models.Product{}.TableName()).
+		Where("products.del_flg = ?", false).
+		Where("products.store_id IN (SELECT id FROM stores WHERE del_flg = ?)", false)
 
 	if filter.MinPrice != nil {
-		query = query.Where("price >= ?", *filter.MinPrice)
+		query = query.Where("products.price >= ?", *filter.MinPrice)
 	}
 	if filter.MaxPrice != nil {
-		query = query.Where("price <= ?", *filter.MaxPrice)
+		query = query.Where("products.price <= ?", *filter.MaxPrice)
 	}
 	if filter.CategoryID != nil {
-		query = query.Where("category_id = ?", *filter.CategoryID)
+		query = query.Where("products.category_id = ?", *filter.CategoryID)
 	}
 	if len(filter.SubCatIDs) > 0 {
-		query = query.Joins("JOIN product_subcategories ON products.id = product_subcategories.product_id").
-			Where("product_subcategories.subcategory_id IN ?", filter.SubCatIDs)
+		// EXISTS rather than a join, so a product in several of the subcategories is
+		// neither listed nor counted twice.
+		query = query.Where("EXISTS (SELECT 1 FROM product_subcategories WHERE product_subcategories.product_id = products.id AND product_subcategories.subcategory_id IN ?)", filter.SubCatIDs)
 	}
 	if filter.MinRating != nil {
-		query = query.Where("rating_average >= ?", *filter.MinRating)
+		query = query.Where("products.rating_average >= ?", *filter.MinRating)
 	}
 	if filter.DiscountOnly {
-		query = query.Where("is_discounted = ?", true)
+		query = query.Where("products.is_discounted = ?", true)
 	}
 	if filter.IsPopular != nil {
-		query = query.Where("is_popular = ?", *filter.IsPopular)
+		query = query.Where("products.is_popular = ?", *filter.IsPopular)
 	}
 
 	// Count total for pagination
 	var total int64
-	query.Count(&total)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
 
 	// Pagination
 	page := filter.Page
@@ -257,18 +337,26 @@ func (d dataAccess) FindByFilter(filter productDto.ProductFilter) ([]models.Prod
 	}
 	pageSize := filter.PageSize
 	if pageSize <= 0 {
-		pageSize = 20 // default page size
+		pageSize = productDto.DefaultPageSize
 	}
 	offset := (page - 1) * pageSize
 
+	order, ok := productSortOrders[filter.Sort]
+	if !ok {
+		order = productSortOrders[productDto.SortNewest]
+	}
+
 	query = query.
 		Preload("Category").
-		Preload("Images").
-		Preload("Variants").
-		Preload("WarehouseStock").
+		Preload("Images", productLevelImages).
+		Preload("Options", byPosition).
+		Preload("Options.Values", byPosition).
+		Preload("Variants", "del_flg = ?", false).
+		Preload("Variants.OptionValues").
+		Preload("Variants.Images", byPosition).
 		Preload("Tags").
 		Preload("SubCategories").
-		Preload("Reviews.User").
+		Order(order + ", products.id").
 		Offset(offset).
 		Limit(pageSize)
 
@@ -280,30 +368,313 @@ func (d dataAccess) FindByFilter(filter productDto.ProductFilter) ([]models.Prod
 	return products, total, nil
 }
 
-// Returns the category hierarchy for a product as an array of names, from root to leaf
-func (d dataAccess) GetCategoryHierarchy(productId string) ([]string, error) {
+func (d dataAccess) Transaction(fn func(tx *gorm.DB) error) error {
+	return d.db.Transaction(fn)
+}
+
+func (d dataAccess) WithTx(tx *gorm.DB) DataAccess {
+	return dataAccess{db: tx}
+}
+
+func (d dataAccess) FindByIdAndStore(id string, storeId string) (models.Product, error) {
 	var product models.Product
-	err := d.db.Table(models.Product{}.TableName()).
-		Where("id = ? AND del_flg = ?", productId, false).
-		Preload("Category.Parent.Parent"). // Preload up to 3 levels
-		Preload("SubCategories.Parent.Parent").
-		First(&product).Error
+	result := d.db.Table(models.Product{}.TableName()).
+		Where("id = ? AND store_id = ? AND del_flg = ?", id, storeId, false).
+		Preload("Images", productLevelImages).
+		Preload("Options", byPosition).
+		Preload("Options.Values", byPosition).
+		Preload("Variants", "del_flg = ?", false).
+		Preload("Variants.OptionValues").
+		Preload("Variants.Images", byPosition).
+		Preload("Tags").
+		Preload("SubCategories").
+		First(&product)
+	if result.Error != nil {
+		return models.Product{}, result.Error
+	}
+	return product, nil
+}
+
+func (d dataAccess) BarcodeTaken(barcode string, exceptId string) (bool, error) {
+	var count int64
+	query := d.db.Table(models.Product{}.TableName()).
+		Where("barcode = ?", barcode)
+	if exceptId != "" {
+		query = query.Where("id <> ?", exceptId)
+	}
+	result := query.Count(&count)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return count > 0, nil
+}
+
+func (d dataAccess) UpdateDetails(item models.Product) error {
+	result := d.db.Table(item.TableName()).
+		Where("id = ?", item.ID).
+		Select("category_id", "name", "description", "price", "stock", "has_variants",
+			"is_discounted", "discount_pct", "barcode", "updated_by").
+		Updates(&item)
+	if result.Error != nil {
+		return result.Error
+	}
+	return nil
+}
+
+func (d dataAccess) ReplaceImages(productId uuid.UUID, images []models.ProductImage) error {
+	return d.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Table(models.ProductImage{}.TableName()).
+			Where("product_id = ? AND variant_id IS NULL AND coalesce(storage_key, '') = ''", productId).
+			Delete(&models.ProductImage{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if len(images) == 0 {
+			return tx.Exec(promoteFirstImage, productId, productId).Error
+		}
+
+		// Linked images go after the uploaded ones.
+		var last int
+		err := tx.Table(models.ProductImage{}.TableName()).
+			Where("product_id = ? AND variant_id IS NULL", productId).
+			Select("coalesce(max(position), -1)").
+			Scan(&last).Error
+		if err != nil {
+			return err
+		}
+		for i := range images {
+			if !images[i].IsPrimary {
+				continue
+			}
+			err := tx.Table(models.ProductImage{}.TableName()).
+				Where("product_id = ? AND variant_id IS NULL AND is_primary", productId).
+				Update("is_primary", false).Error
+			if err != nil {
+				return err
+			}
+			break
+		}
+		for i := range images {
+			images[i].ProductID = productId
+			images[i].Position = last + 1 + i
+		}
+		return tx.Table(models.ProductImage{}.TableName()).Create(&images).Error
+	})
+}
+
+func (d dataAccess) FindByIds(ids []string) ([]models.Product, error) {
+	var products []models.Product
+	result := d.db.Table(models.Product{}.TableName()).
+		Where("id IN ? AND del_flg = ?", ids, false).
+		Preload("Tags").
+		Preload("SubCategories").
+		Find(&products)
+	if result.Error != nil {
+		return []models.Product{}, result.Error
+	}
+	return products, nil
+}
+
+func (d dataAccess) SetClassification(item models.Product) error {
+	return d.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Table(item.TableName()).
+			Where("id = ?", item.ID).
+			Updates(map[string]interface{}{"category_id": item.CategoryID, "updated_by": item.UpdatedBy}).Error
+		if err != nil {
+			return err
+		}
+		// Only the join rows are written; the categories and tags must already exist.
+		if err := tx.Model(&item).Omit("SubCategories.*").Association("SubCategories").Replace(item.SubCategories); err != nil {
+			return err
+		}
+		if err := tx.Model(&item).Omit("Tags.*").Association("Tags").Replace(item.Tags); err != nil {
+			return err
+		}
+		return dataAccess{db: tx}.RefreshSearchVector(item.ID.String())
+	})
+}
+
+// productSearchMatch selects the live products of live stores matching the full-text
+// query or, for typos, close enough to the product name.
+const productSearchMatch = `FROM ecom.products p, websearch_to_tsquery('english', @query) q
+	WHERE p.del_flg = false
+	AND p.store_id IN (SELECT id FROM ecom.stores WHERE del_flg = false)
+	AND (p.search_vector @@ q OR @query <% p.name)`
+
+func (d dataAccess) Search(query string, page int, pageSize int) ([]SearchResult, int64, error) {
+	args := map[string]interface{}{
+		"query":                query,
+		"limit":                pageSize,
+		"offset":               (page - 1) * pageSize,
+		"name_headline":        `StartSel="` + HighlightStart + `", StopSel="` + HighlightStop + `", HighlightAll=true`,
+		"description_headline": `StartSel="` + HighlightStart + `", StopSel="` + HighlightStop + `", MaxFragments=2, MaxWords=20, MinWords=5`,
+	}
+
+	var total int64
+	if err := d.db.Raw(`SELECT COUNT(*) `+productSearchMatch, args).Scan(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	if total == 0 {
+		return []SearchResult{}, 0, nil
+	}
+
+	var hits []struct {
+		ID                   uuid.UUID
+		NameHighlight        string
+		DescriptionHighlight string
+		Rank                 float64
+	}
+	err := d.db.Raw(`SELECT p.id,
+		ts_headline('english', p.name, q, @name_headline) AS name_highlight,
+		ts_headline('english', coalesce(p.description, ''), q, @description_headline) AS description_highlight,
+		coalesce(ts_rank_cd(p.search_vector, q), 0) + word_similarity(@query, p.name) AS rank
+		`+productSearchMatch+`
+		ORDER BY rank DESC, p.id
+		LIMIT @limit OFFSET @offset`, args).Scan(&hits).Error
 	if err != nil {
-		return nil, err
+		return nil, 0, err
+	}
+
+	ids := make([]uuid.UUID, 0, len(hits))
+	for _, hit := range hits {
+		ids = append(ids, hit.ID)
+	}
+	var products []models.Product
+	err = d.db.Table(models.Product{}.TableName()).
+		Where("id IN ?", ids).
+		Preload("Category").
+		Preload("Images", productLevelImages).
+		Preload("Options", byPosition).
+		Preload("Options.Values", byPosition).
+		Preload("Variants", "del_flg = ?", false).
+		Preload("Variants.OptionValues").
+		Preload("Variants.Images", byPosition).
+		Preload("Tags").
+		Preload("SubCategories").
+		Find(&products).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	byID := map[uuid.UUID]models.Product{}
+	for _, product := range products {
+		byID[product.ID] = product
+	}
+
+	results := make([]SearchResult, 0, len(hits))
+	for _, hit := range hits {
+		results = append(results, SearchResult{
+			Product:              byID[hit.ID],
+			NameHighlight:        hit.NameHighlight,
+			DescriptionHighlight: hit.DescriptionHighlight,
+			Rank:                 hit.Rank,
+		})
 	}
+	return results, total, nil
+}
+
+func (d dataAccess) RefreshSearchVector(id string) error {
+	return d.db.Exec(`UPDATE ecom.products p SET search_vector = `+models.ProductSearchDocument+` WHERE p.id = ?`, id).Error
+}
+
+func (d dataAccess) RefreshCategorySearchVectors(categoryId string) error {
+	return d.db.Exec(`UPDATE ecom.products p SET search_vector = `+models.ProductSearchDocument+`
+		WHERE p.category_id = @category
+		OR p.id IN (SELECT product_id FROM product_subcategories WHERE subcategory_id = @category)`,
+		map[string]interface{}{"category": categoryId}).Error
+}
+
+func byPosition(db *gorm.DB) *gorm.DB {
+	return db.Order("position")
+}
 
-	// Main category chain
-	catChain := []string{}
-	cat := &product.Category
-	for cat != nil {
-		if cat.Name != "" {
-			catChain = append([]string{cat.Name}, catChain...)
+// productLevelImages leaves out the images of variants.
+func productLevelImages(db *gorm.DB) *gorm.DB {
+	return db.Where("variant_id IS NULL").Order("position, created_at")
+}
+
+// promoteFirstImage makes the product's first image primary when none is.
+const promoteFirstImage = `UPDATE ecom.product_images SET is_primary = true
+	WHERE id = (SELECT id FROM ecom.product_images WHERE product_id = ? AND variant_id IS NULL
+		ORDER BY position, created_at LIMIT 1)
+	AND NOT EXISTS (SELECT 1 FROM ecom.product_images WHERE product_id = ? AND variant_id IS NULL AND is_primary)`
+
+func (d dataAccess) SaveOptions(productId uuid.UUID, options []models.ProductOption) ([]models.ProductOption, error) {
+	optionIds := []uuid.UUID{}
+	for i := range options {
+		values := options[i].Values
+		option := models.ProductOption{ProductID: productId, Name: options[i].Name}
+		err := d.db.Table(option.TableName()).
+			Where("product_id = ? AND name = ?", productId, option.Name).
+			Assign(map[string]interface{}{"position": options[i].Position}).
+			FirstOrCreate(&option).Error
+		if err != nil {
+			return nil, err
+		}
+
+		valueIds := []uuid.UUID{}
+		for j := range values {
+			value := models.ProductOptionValue{OptionID: option.ID, Value: values[j].Value}
+			err := d.db.Table(value.TableName()).
+				Where("option_id = ? AND value = ?", option.ID, value.Value).
+				Assign(map[string]interface{}{"position": values[j].Position}).
+				FirstOrCreate(&value).Error
+			if err != nil {
+				return nil, err
+			}
+			values[j] = value
+			valueIds = append(valueIds, value.ID)
 		}
-		cat = cat.Parent
+		query := d.db.Table(models.ProductOptionValue{}.TableName()).Where("option_id = ?", option.ID)
+		if len(valueIds) > 0 {
+			query = query.Where("id NOT IN ?", valueIds)
+		}
+		if err := query.Delete(&models.ProductOptionValue{}).Error; err != nil {
+			return nil, err
+		}
+
+		option.Values = values
+		options[i] = option
+		optionIds = append(optionIds, option.ID)
 	}
 
-	// If you want to include subcategory chains, you can loop through product.SubCategories
-	// and build similar arrays for each subcategory
+	query := d.db.Table(models.ProductOption{}.TableName()).Where("product_id = ?", productId)
+	if len(optionIds) > 0 {
+		query = query.Where("id NOT IN ?", optionIds)
+	}
+	if err := query.Delete(&models.ProductOption{}).Error; err != nil {
+		return nil, err
+	}
+	return options, nil
+}
 
-	return catChain, nil
+func (d dataAccess) FindByBarcodeAndStore(barcode string, storeId string) (models.Product, error) {
+	var product models.Product
+	result := d.db.Table(models.Product{}.TableName()).
+		Where("barcode = ? AND store_id = ? AND del_flg = ?", barcode, storeId, false).
+		First(&product)
+	if result.Error != nil {
+		return models.Product{}, result.Error
+	}
+	return d.FindByIdAndStore(product.ID.String(), storeId)
+}
+
+func (d dataAccess) FindBatchByStore(storeId string, afterId uuid.UUID, limit int) ([]models.Product, error) {
+	var products []models.Product
+	result := d.db.Table(models.Product{}.TableName()).
+		Where("store_id = ? AND del_flg = ? AND id > ?", storeId, false, afterId).
+		Preload("Category").
+		Preload("Images", productLevelImages).
+		Preload("Options", byPosition).
+		Preload("Options.Values", byPosition).
+		Preload("Variants", func(db *gorm.DB) *gorm.DB { return db.Where("del_flg = ?", false).Order("sku") }).
+		Preload("Variants.OptionValues").
+		Preload("Tags").
+		Preload("SubCategories").
+		Order("id").
+		Limit(limit).
+		Find(&products)
+	if result.Error != nil {
+		return []models.Product{}, result.Error
+	}
+	return products, nil
 }
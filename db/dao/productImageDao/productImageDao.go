@@ -1,19 +1,42 @@
 package productimagedao
 
 import (
+	"errors"
+
 	"github.com/abdulmalikraji/e-commerce/db/connection"
 	"github.com/abdulmalikraji/e-commerce/db/models"
+	"github.com/abdulmalikraji/e-commerce/utils"
+	"github.com/jackc/pgx/v5/pgconn"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	// ErrTooManyImages is returned by Append when the product already has its limit of images.
+	ErrTooManyImages = errors.New("product has too many images")
+	// ErrConcurrentChange is returned when another change to the product's images won a
+	// race for the primary image; the caller may retry.
+	ErrConcurrentChange = errors.New("product images changed concurrently")
 )
 
+//go:generate mockgen -destination=../../../mocks/dao/productImageDao/mockProductImageDao.go -package=productImageDao -source=productImageDao.go
 type DataAccess interface {
-	FindAll() ([]models.ProductImage, error)
-	FindById(id string) (models.ProductImage, error)
+	// FindByProductId returns the images of the product itself, leaving out variant
+	// images, in display order.
 	FindByProductId(productId string) ([]models.ProductImage, error)
+	// FindById finds one of the images of the product itself.
+	FindById(id string, productId string) (models.ProductImage, error)
 	Insert(item models.ProductImage) (models.ProductImage, error)
-	Update(item models.ProductImage) error
-	SoftDelete(id string) error
-	Delete(id string) error
+	// Append adds the image after the product's last one and makes it primary when the
+	// product has no primary image. The product row is locked while the images are
+	// counted, so concurrent uploads see each other.
+	Append(item models.ProductImage, limit int) (models.ProductImage, error)
+	// Delete removes the image; when it was primary, the first image left takes over.
+	Delete(item models.ProductImage) error
+	// SetPrimary makes the image the product's only primary image.
+	SetPrimary(productId string, id string) error
+	// Reorder gives the product's images the positions of their IDs in ids.
+	Reorder(productId string, ids []string) error
 }
 
 type dataAccess struct {
@@ -26,11 +49,11 @@ func New(client connection.Client) DataAccess {
 	}
 }
 
-func (d dataAccess) FindAll() ([]models.ProductImage, error) {
+func (d dataAccess) FindByProductId(productId string) ([]models.ProductImage, error) {
 	var items []models.ProductImage
 	result := d.db.Table(models.ProductImage{}.TableName()).
-		Where("del_flg = ?", false).
-		Preload("Product").
+		Where("product_id = ? AND variant_id IS NULL", productId).
+		Order("position, created_at").
 		Find(&items)
 	if result.Error != nil {
 		return []models.ProductImage{}, result.Error
@@ -38,11 +61,10 @@ func (d dataAccess) FindAll() ([]models.ProductImage, error) {
 	return items, nil
 }
 
-func (d dataAccess) FindById(id string) (models.ProductImage, error) {
+func (d dataAccess) FindById(id string, productId string) (models.ProductImage, error) {
 	var item models.ProductImage
 	result := d.db.Table(models.ProductImage{}.TableName()).
-		Where("id = ? AND del_flg = ?", id, false).
-		Preload("Product").
+		Where("id = ? AND product_id = ? AND variant_id IS NULL", id, productId).
 		First(&item)
 	if result.Error != nil {
 		return models.ProductImage{}, result.Error
@@ -50,55 +72,98 @@ func (d dataAccess) FindById(id string) (models.ProductImage, error) {
 	return item, nil
 }
 
-func (d dataAccess) FindByProductId(productId string) ([]models.ProductImage, error) {
-	var items []models.ProductImage
-	result := d.db.Table(models.ProductImage{}.TableName()).
-		Where("product_id = ? AND del_flg = ?", productId, false).
-		Preload("Product").
-		Find(&items)
-	if result.Error != nil {
-		return []models.ProductImage{}, result.Error
-	}
-	return items, nil
-}
-
 func (d dataAccess) Insert(item models.ProductImage) (models.ProductImage, error) {
-	result := d.db.Table(item.TableName()).
-		Create(&item)
+	result := d.db.Table(item.TableName()).Create(&item)
 	if result.Error != nil {
 		return models.ProductImage{}, result.Error
 	}
 	return item, nil
 }
 
-func (d dataAccess) Update(item models.ProductImage) error {
-	result := d.db.Table(item.TableName()).
-		Where("id = ?", item.ID).
-		Updates(&item)
-	if result.Error != nil {
-		return result.Error
+func (d dataAccess) Append(item models.ProductImage, limit int) (models.ProductImage, error) {
+	err := d.db.Transaction(func(tx *gorm.DB) error {
+		res := tx.Table(models.Product{}.TableName()).
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id").
+			Where("id = ?", item.ProductID).
+			Take(&models.Product{})
+		if res.Error != nil {
+			return res.Error
+		}
+
+		var current struct {
+			Count        int
+			LastPosition int
+			HasPrimary   bool
+		}
+		res = tx.Table(item.TableName()).
+			Select("COUNT(*) AS count, COALESCE(MAX(position), -1) AS last_position, COALESCE(BOOL_OR(is_primary), false) AS has_primary").
+			Where("product_id = ? AND variant_id IS NULL", item.ProductID).
+			Scan(&current)
+		if res.Error != nil {
+			return res.Error
+		}
+		if current.Count >= limit {
+			return ErrTooManyImages
+		}
+
+		item.Position = current.LastPosition + 1
+		item.IsPrimary = !current.HasPrimary
+		return tx.Table(item.TableName()).Create(&item).Error
+	})
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == utils.PgDuplicateErrorCode {
+			return models.ProductImage{}, ErrConcurrentChange
+		}
+		return models.ProductImage{}, err
 	}
-	return nil
+	return item, nil
 }
 
-func (d dataAccess) SoftDelete(id string) error {
-	var item models.ProductImage
-	result := d.db.Table(item.TableName()).
-		Where("id = ?", id).
-		Update("del_flg", true)
-	if result.Error != nil {
-		return result.Error
-	}
-	return nil
+func (d dataAccess) Delete(item models.ProductImage) error {
+	return d.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Table(item.TableName()).
+			Where("id = ?", item.ID).
+			Delete(&models.ProductImage{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if !item.IsPrimary {
+			return nil
+		}
+		return tx.Exec(`UPDATE ecom.product_images SET is_primary = true
+			WHERE id = (SELECT id FROM ecom.product_images WHERE product_id = ? AND variant_id IS NULL
+				ORDER BY position, created_at LIMIT 1)`, item.ProductID).Error
+	})
 }
 
-func (d dataAccess) Delete(id string) error {
-	var item models.ProductImage
-	result := d.db.Table(item.TableName()).
-		Where("id = ?", id).
-		Delete(&item)
-	if result.Error != nil {
-		return result.Error
-	}
-	return nil
+// SetPrimary clears the old primary image before setting the new one: the unique
+// index on primary images is checked row by row.
+func (d dataAccess) SetPrimary(productId string, id string) error {
+	return d.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Table(models.ProductImage{}.TableName()).
+			Where("product_id = ? AND variant_id IS NULL AND is_primary AND id <> ?", productId, id).
+			Update("is_primary", false).Error
+		if err != nil {
+			return err
+		}
+		return tx.Table(models.ProductImage{}.TableName()).
+			Where("id = ? AND product_id = ?", id, productId).
+			Update("is_primary", true).Error
+	})
+}
+
+func (d dataAccess) Reorder(productId string, ids []string) error {
+	return d.db.Transaction(func(tx *gorm.DB) error {
+		for position, id := range ids {
+			err := tx.Table(models.ProductImage{}.TableName()).
+				Where("id = ? AND product_id = ?", id, productId).
+				Update("position", position).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
 }
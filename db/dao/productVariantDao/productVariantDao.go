@@ -6,6 +6,7 @@ import (
 	"gorm.io/gorm"
 )
 
+//go:generate mockgen -destination=../../../mocks/dao/productVariantDao/mockProductVariantDao.go -package=productVariantDao -source=productVariantDao.go
 type DataAccess interface {
 	FindAll() ([]models.ProductVariant, error)
 	FindById(id string) (models.ProductVariant, error)
@@ -14,6 +15,18 @@ type DataAccess interface {
 	Update(item models.ProductVariant) error
 	SoftDelete(id string) error
 	Delete(id string) error
+	// FindBySKUs returns the variants, deleted or not, using any of skus.
+	FindBySKUs(skus []string) ([]models.ProductVariant, error)
+	// FindByBarcodes returns the variants, deleted or not, using any of barcodes.
+	FindByBarcodes(barcodes []string) ([]models.ProductVariant, error)
+	// UpdateDetails writes every editable variant column, including zero values.
+	UpdateDetails(item models.ProductVariant) error
+	// ReplaceOptionValues makes the item's OptionValues its only option values.
+	ReplaceOptionValues(item models.ProductVariant) error
+	// ReplaceImages makes the item's Images its only images.
+	ReplaceImages(item models.ProductVariant) error
+	// WithTx returns a DataAccess bound to tx.
+	WithTx(tx *gorm.DB) DataAccess
 }
 type dataAccess struct {
 	db *gorm.DB
@@ -61,8 +74,10 @@ func (d dataAccess) FindByProductId(productId string) ([]models.ProductVariant,
 	return variants, nil
 }
 
+// Insert creates the variant with its images and links it to its option values, which
+// must already exist.
 func (d dataAccess) Insert(item models.ProductVariant) (models.ProductVariant, error) {
-	result := d.db.Table(item.TableName()).Create(&item)
+	result := d.db.Table(item.TableName()).Omit("OptionValues.*").Create(&item)
 	if result.Error != nil {
 		return models.ProductVariant{}, result.Error
 	}
@@ -102,3 +117,67 @@ func (d dataAccess) Delete(id string) error {
 	}
 	return nil
 }
+
+func (d dataAccess) WithTx(tx *gorm.DB) DataAccess {
+	return dataAccess{db: tx}
+}
+
+func (d dataAccess) FindBySKUs(skus []string) ([]models.ProductVariant, error) {
+	var variants []models.ProductVariant
+	if len(skus) == 0 {
+		return variants, nil
+	}
+	result := d.db.Table(models.ProductVariant{}.TableName()).
+		Where("sku IN ?", skus).
+		Find(&variants)
+	if result.Error != nil {
+		return []models.ProductVariant{}, result.Error
+	}
+	return variants, nil
+}
+
+func (d dataAccess) UpdateDetails(item models.ProductVariant) error {
+	result := d.db.Table(item.TableName()).
+		Where(idWhere, item.ID).
+		Select("sku", "barcode", "price_override", "stock", "updated_by").
+		Updates(&item)
+	if result.Error != nil {
+		return result.Error
+	}
+	return nil
+}
+
+func (d dataAccess) FindByBarcodes(barcodes []string) ([]models.ProductVariant, error) {
+	var variants []models.ProductVariant
+	if len(barcodes) == 0 {
+		return variants, nil
+	}
+	result := d.db.Table(models.ProductVariant{}.TableName()).
+		Where("barcode IN ?", barcodes).
+		Find(&variants)
+	if result.Error != nil {
+		return []models.ProductVariant{}, result.Error
+	}
+	return variants, nil
+}
+
+func (d dataAccess) ReplaceOptionValues(item models.ProductVariant) error {
+	return d.db.Model(&item).Omit("OptionValues.*").Association("OptionValues").Replace(item.OptionValues)
+}
+
+func (d dataAccess) ReplaceImages(item models.ProductVariant) error {
+	result := d.db.Table(models.ProductImage{}.TableName()).
+		Where("variant_id = ?", item.ID).
+		Delete(&models.ProductImage{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if len(item.Images) == 0 {
+		return nil
+	}
+	for i := range item.Images {
+		item.Images[i].ProductID = item.ProductID
+		item.Images[i].VariantID = &item.ID
+	}
+	return d.db.Table(models.ProductImage{}.TableName()).Create(&item.Images).Error
+}
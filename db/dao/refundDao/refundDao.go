@@ -11,6 +11,8 @@ type DataAccess interface {
 	FindById(id string) (models.Refund, error)
 	FindByPaymentId(paymentId string) ([]models.Refund, error)
 	FindByOrderId(orderId string) ([]models.Refund, error)
+	FindByStoreId(storeId string, status *string) ([]models.Refund, error)
+	FindByIdAndStore(id string, storeId string) (models.Refund, error)
 	Insert(item models.Refund) (models.Refund, error)
 	Update(item models.Refund) error
 	SoftDelete(id string) error
@@ -32,6 +34,7 @@ func (d dataAccess) FindAll() ([]models.Refund, error) {
 	result := d.db.Table(models.Refund{}.TableName()).
 		Where("del_flg = ?", false).
 		Preload("Payment").
+		Preload("Items").
 		Find(&refunds)
 	if result.Error != nil {
 		return []models.Refund{}, result.Error
@@ -44,6 +47,7 @@ func (d dataAccess) FindById(id string) (models.Refund, error) {
 	result := d.db.Table(models.Refund{}.TableName()).
 		Where("id = ? AND del_flg = ?", id, false).
 		Preload("Payment").
+		Preload("Items").
 		First(&refund)
 	if result.Error != nil {
 		return models.Refund{}, result.Error
@@ -56,6 +60,7 @@ func (d dataAccess) FindByPaymentId(paymentId string) ([]models.Refund, error) {
 	result := d.db.Table(models.Refund{}.TableName()).
 		Where("payment_id = ? AND del_flg = ?", paymentId, false).
 		Preload("Payment").
+		Preload("Items").
 		Find(&refunds)
 	if result.Error != nil {
 		return []models.Refund{}, result.Error
@@ -68,6 +73,7 @@ func (d dataAccess) FindByOrderId(orderId string) ([]models.Refund, error) {
 	result := d.db.Table(models.Refund{}.TableName()).
 		Where("order_id = ? AND del_flg = ?", orderId, false).
 		Preload("Payment").
+		Preload("Items").
 		Find(&refunds)
 	if result.Error != nil {
 		return []models.Refund{}, result.Error
@@ -75,6 +81,36 @@ func (d dataAccess) FindByOrderId(orderId string) ([]models.Refund, error) {
 	return refunds, nil
 }
 
+func (d dataAccess) FindByStoreId(storeId string, status *string) ([]models.Refund, error) {
+	var refunds []models.Refund
+	query := d.db.Table(models.Refund{}.TableName()).
+		Where("store_id = ? AND del_flg = ?", storeId, false)
+	if status != nil {
+		query = query.Where("status = ?", *status)
+	}
+	result := query.
+		Preload("Items").
+		Order("created_at DESC").
+		Find(&refunds)
+	if result.Error != nil {
+		return []models.Refund{}, result.Error
+	}
+	return refunds, nil
+}
+
+func (d dataAccess) FindByIdAndStore(id string, storeId string) (models.Refund, error) {
+	var refund models.Refund
+	result := d.db.Table(models.Refund{}.TableName()).
+		Where("id = ? AND store_id = ? AND del_flg = ?", id, storeId, false).
+		Preload("Payment").
+		Preload("Items").
+		First(&refund)
+	if result.Error != nil {
+		return models.Refund{}, result.Error
+	}
+	return refund, nil
+}
+
 func (d dataAccess) Insert(item models.Refund) (models.Refund, error) {
 	result := d.db.Table(item.TableName()).Create(&item)
 	if result.Error != nil {
@@ -0,0 +1,291 @@
+package stockReservationDao
+
+import (
+	"errors"
+	"time"
+
+	"github.com/abdulmalikraji/e-commerce/db/connection"
+	"github.com/abdulmalikraji/e-commerce/db/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	// ErrInsufficientStock is returned when the warehouses of a product cannot cover a hold.
+	ErrInsufficientStock = errors.New("insufficient warehouse stock")
+	// ErrReservationExpired is returned when an order's holds lapsed before they were committed.
+	ErrReservationExpired = errors.New("stock reservation expired")
+)
+
+//go:generate mockgen -destination=../../../mocks/dao/stockReservationDao/mockStockReservationDao.go -package=stockReservationDao -source=stockReservationDao.go
+type DataAccess interface {
+	FindById(id string) (models.StockReservation, error)
+	FindByOrderId(orderId string) ([]models.StockReservation, error)
+	// Reserve places holds for quantity units of the product across its warehouses.
+	// Products without warehouse stock rows are not tracked and get no holds.
+	Reserve(productID uuid.UUID, orderID *uuid.UUID, quantity int, ttl time.Duration) ([]models.StockReservation, error)
+	// Commit marks the order's held reservations as sold; they keep their units out of
+	// the warehouse's available stock until released.
+	Commit(orderID uuid.UUID) error
+	// Release frees the order's held and committed reservations without touching stock.
+	Release(orderID uuid.UUID) error
+	// ReleaseUnits frees quantity units of the order's committed reservations for the
+	// product, e.g. when refunded goods are put back on the shelf. A reservation that is
+	// only partly freed is split so the released units stay on record.
+	ReleaseUnits(orderID, productID uuid.UUID, quantity int) error
+	// ReleaseExpired marks every held reservation past its expiry as expired.
+	ReleaseExpired(now time.Time) (int64, error)
+	// WithTx returns a DataAccess bound to tx so holds join the caller's transaction.
+	WithTx(tx *gorm.DB) DataAccess
+}
+
+type dataAccess struct {
+	db *gorm.DB
+}
+
+func New(client connection.Client) DataAccess {
+	return dataAccess{
+		db: client.PostgresConnection,
+	}
+}
+
+func (d dataAccess) WithTx(tx *gorm.DB) DataAccess {
+	return dataAccess{
+		db: tx,
+	}
+}
+
+func (d dataAccess) FindById(id string) (models.StockReservation, error) {
+	var item models.StockReservation
+	result := d.db.Table(models.StockReservation{}.TableName()).
+		Where("id = ?", id).
+		First(&item)
+	if result.Error != nil {
+		return models.StockReservation{}, result.Error
+	}
+	return item, nil
+}
+
+func (d dataAccess) FindByOrderId(orderId string) ([]models.StockReservation, error) {
+	var items []models.StockReservation
+	result := d.db.Table(models.StockReservation{}.TableName()).
+		Where("order_id = ?", orderId).
+		Find(&items)
+	if result.Error != nil {
+		return []models.StockReservation{}, result.Error
+	}
+	return items, nil
+}
+
+func (d dataAccess) Reserve(productID uuid.UUID, orderID *uuid.UUID, quantity int, ttl time.Duration) ([]models.StockReservation, error) {
+	var reservations []models.StockReservation
+	err := d.db.Transaction(func(tx *gorm.DB) error {
+		// Lock every stock row of the product; concurrent holds on the same product
+		// queue here, so the availability computed below cannot go stale.
+		var stocks []models.WarehouseStock
+		res := tx.Table(models.WarehouseStock{}.TableName()).
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("product_id = ? AND del_flg = ?", productID, false).
+			Order("stock DESC, id").
+			Find(&stocks)
+		if res.Error != nil {
+			return res.Error
+		}
+		if len(stocks) == 0 {
+			return nil
+		}
+
+		held, err := heldQuantities(tx, stocks, time.Now())
+		if err != nil {
+			return err
+		}
+
+		expiresAt := time.Now().Add(ttl)
+		remaining := quantity
+		for _, stock := range stocks {
+			if remaining == 0 {
+				break
+			}
+			available := stock.Stock - held[stock.ID]
+			if available <= 0 {
+				continue
+			}
+			take := min(available, remaining)
+			reservation := models.StockReservation{
+				WarehouseStockID: stock.ID,
+				ProductID:        productID,
+				OrderID:          orderID,
+				Quantity:         take,
+				Status:           models.ReservationHeld,
+				ExpiresAt:        expiresAt,
+			}
+			if res := tx.Table(reservation.TableName()).Create(&reservation); res.Error != nil {
+				return res.Error
+			}
+			reservations = append(reservations, reservation)
+			remaining -= take
+		}
+		if remaining > 0 {
+			return ErrInsufficientStock
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return reservations, nil
+}
+
+// heldQuantities sums the units taken from each of the given stock rows: live holds
+// and committed reservations.
+func heldQuantities(tx *gorm.DB, stocks []models.WarehouseStock, now time.Time) (map[uuid.UUID]int, error) {
+	ids := make([]uuid.UUID, 0, len(stocks))
+	for _, stock := range stocks {
+		ids = append(ids, stock.ID)
+	}
+
+	var rows []struct {
+		WarehouseStockID uuid.UUID
+		Held             int
+	}
+	res := tx.Table(models.StockReservation{}.TableName()).
+		Select("warehouse_stock_id, COALESCE(SUM(quantity), 0) AS held").
+		Where("warehouse_stock_id IN ? AND (status = ? OR (status = ? AND expires_at > ?))",
+			ids, models.ReservationCommitted, models.ReservationHeld, now).
+		Group("warehouse_stock_id").
+		Scan(&rows)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+
+	held := make(map[uuid.UUID]int, len(rows))
+	for _, row := range rows {
+		held[row.WarehouseStockID] = row.Held
+	}
+	return held, nil
+}
+
+func (d dataAccess) Commit(orderID uuid.UUID) error {
+	return d.db.Transaction(func(tx *gorm.DB) error {
+		var reservations []models.StockReservation
+		res := tx.Table(models.StockReservation{}.TableName()).
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("order_id = ?", orderID).
+			Order("warehouse_stock_id").
+			Find(&reservations)
+		if res.Error != nil {
+			return res.Error
+		}
+
+		now := time.Now()
+		for _, reservation := range reservations {
+			switch reservation.Status {
+			case models.ReservationCommitted:
+				continue
+			case models.ReservationHeld:
+			default:
+				return ErrReservationExpired
+			}
+
+			// A lapsed hold that the sweeper has not reached yet no longer protects its
+			// units; it can only be honoured if the stock is still free.
+			if !reservation.ExpiresAt.After(now) {
+				var stock models.WarehouseStock
+				res := tx.Table(stock.TableName()).
+					Clauses(clause.Locking{Strength: "UPDATE"}).
+					Where("id = ?", reservation.WarehouseStockID).
+					First(&stock)
+				if res.Error != nil {
+					return res.Error
+				}
+				held, err := heldQuantities(tx, []models.WarehouseStock{stock}, now)
+				if err != nil {
+					return err
+				}
+				if stock.Stock-held[stock.ID] < reservation.Quantity {
+					return ErrReservationExpired
+				}
+			}
+
+			res := tx.Table(reservation.TableName()).
+				Where("id = ?", reservation.ID).
+				Update("status", models.ReservationCommitted)
+			if res.Error != nil {
+				return res.Error
+			}
+		}
+		return nil
+	})
+}
+
+func (d dataAccess) Release(orderID uuid.UUID) error {
+	result := d.db.Table(models.StockReservation{}.TableName()).
+		Where("order_id = ? AND status IN ?", orderID, []string{models.ReservationHeld, models.ReservationCommitted}).
+		Update("status", models.ReservationReleased)
+	if result.Error != nil {
+		return result.Error
+	}
+	return nil
+}
+
+func (d dataAccess) ReleaseUnits(orderID, productID uuid.UUID, quantity int) error {
+	return d.db.Transaction(func(tx *gorm.DB) error {
+		var reservations []models.StockReservation
+		res := tx.Table(models.StockReservation{}.TableName()).
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("order_id = ? AND product_id = ? AND status = ?", orderID, productID, models.ReservationCommitted).
+			Order("created_at, id").
+			Find(&reservations)
+		if res.Error != nil {
+			return res.Error
+		}
+
+		remaining := quantity
+		for _, reservation := range reservations {
+			if remaining == 0 {
+				break
+			}
+			if reservation.Quantity <= remaining {
+				res := tx.Table(reservation.TableName()).
+					Where("id = ?", reservation.ID).
+					Update("status", models.ReservationReleased)
+				if res.Error != nil {
+					return res.Error
+				}
+				remaining -= reservation.Quantity
+				continue
+			}
+
+			res := tx.Table(reservation.TableName()).
+				Where("id = ?", reservation.ID).
+				Update("quantity", reservation.Quantity-remaining)
+			if res.Error != nil {
+				return res.Error
+			}
+			released := models.StockReservation{
+				WarehouseStockID: reservation.WarehouseStockID,
+				ProductID:        reservation.ProductID,
+				OrderID:          reservation.OrderID,
+				Quantity:         remaining,
+				Status:           models.ReservationReleased,
+				ExpiresAt:        reservation.ExpiresAt,
+			}
+			if res := tx.Table(released.TableName()).Create(&released); res.Error != nil {
+				return res.Error
+			}
+			remaining = 0
+		}
+		return nil
+	})
+}
+
+func (d dataAccess) ReleaseExpired(now time.Time) (int64, error) {
+	result := d.db.Table(models.StockReservation{}.TableName()).
+		Where("status = ? AND expires_at <= ?", models.ReservationHeld, now).
+		Update("status", models.ReservationExpired)
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	return result.RowsAffected, nil
+}
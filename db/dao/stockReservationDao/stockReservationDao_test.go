@@ -0,0 +1,118 @@
+package stockReservationDao
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/abdulmalikraji/e-commerce/db/connection"
+	"github.com/abdulmalikraji/e-commerce/db/models"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func mockDB(t *testing.T) (DataAccess, sqlmock.Sqlmock) {
+	conn, sqlMock, err := sqlmock.New()
+	assert.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: conn}), &gorm.Config{SkipDefaultTransaction: true})
+	assert.NoError(t, err)
+	return New(connection.Client{PostgresConnection: db}), sqlMock
+}
+
+// expectStock expects Reserve to lock the product's stock rows and sum what is taken
+// from them.
+func expectStock(sqlMock sqlmock.Sqlmock, productID uuid.UUID, stocks []models.WarehouseStock, held map[uuid.UUID]int) {
+	sqlMock.ExpectBegin()
+	rows := sqlmock.NewRows([]string{"id", "product_id", "stock"})
+	for _, stock := range stocks {
+		rows.AddRow(stock.ID, productID, stock.Stock)
+	}
+	sqlMock.ExpectQuery(`FROM "ecom"."warehouse_stock" WHERE .* FOR UPDATE`).WithArgs(productID, false).WillReturnRows(rows)
+	heldRows := sqlmock.NewRows([]string{"warehouse_stock_id", "held"})
+	for id, quantity := range held {
+		heldRows.AddRow(id, quantity)
+	}
+	sqlMock.ExpectQuery(`SELECT warehouse_stock_id, COALESCE\(SUM\(quantity\), 0\) AS held`).WillReturnRows(heldRows)
+}
+
+func TestReserve_Spreads_The_Hold_Over_Available_Stock(t *testing.T) {
+	reservations, sqlMock := mockDB(t)
+
+	productID, orderID := uuid.New(), uuid.New()
+	main := models.WarehouseStock{ID: uuid.New(), Stock: 3}
+	backup := models.WarehouseStock{ID: uuid.New(), Stock: 5}
+	expectStock(sqlMock, productID, []models.WarehouseStock{main, backup}, map[uuid.UUID]int{main.ID: 1})
+	sqlMock.ExpectQuery(`INSERT INTO "ecom"."stock_reservations"`).
+		WithArgs(main.ID, productID, orderID, 2, models.ReservationHeld, sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(uuid.New()))
+	sqlMock.ExpectQuery(`INSERT INTO "ecom"."stock_reservations"`).
+		WithArgs(backup.ID, productID, orderID, 2, models.ReservationHeld, sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(uuid.New()))
+	sqlMock.ExpectCommit()
+
+	held, err := reservations.Reserve(productID, &orderID, 4, time.Minute)
+
+	assert.NoError(t, err)
+	assert.Len(t, held, 2)
+	assert.NoError(t, sqlMock.ExpectationsWereMet())
+}
+
+// Two checkouts of the same product queue on the stock row lock, so the second one
+// counts the first one's hold: it only finds one unit left and rolls its hold back.
+func TestReserve_Second_Hold_Sees_The_First(t *testing.T) {
+	reservations, sqlMock := mockDB(t)
+
+	productID := uuid.New()
+	stock := models.WarehouseStock{ID: uuid.New(), Stock: 3}
+	expectStock(sqlMock, productID, []models.WarehouseStock{stock}, nil)
+	sqlMock.ExpectQuery(`INSERT INTO "ecom"."stock_reservations"`).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(uuid.New()))
+	sqlMock.ExpectCommit()
+	expectStock(sqlMock, productID, []models.WarehouseStock{stock}, map[uuid.UUID]int{stock.ID: 2})
+	sqlMock.ExpectQuery(`INSERT INTO "ecom"."stock_reservations"`).
+		WithArgs(stock.ID, productID, nil, 1, models.ReservationHeld, sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(uuid.New()))
+	sqlMock.ExpectRollback()
+
+	first, firstErr := reservations.Reserve(productID, nil, 2, time.Minute)
+	_, secondErr := reservations.Reserve(productID, nil, 2, time.Minute)
+
+	assert.NoError(t, firstErr)
+	assert.Len(t, first, 1)
+	assert.ErrorIs(t, secondErr, ErrInsufficientStock)
+	assert.NoError(t, sqlMock.ExpectationsWereMet())
+}
+
+func TestReserve_Product_Without_Warehouse_Stock_Gets_No_Hold(t *testing.T) {
+	reservations, sqlMock := mockDB(t)
+
+	productID := uuid.New()
+	sqlMock.ExpectBegin()
+	sqlMock.ExpectQuery(`FROM "ecom"."warehouse_stock"`).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	sqlMock.ExpectCommit()
+
+	held, err := reservations.Reserve(productID, nil, 2, time.Minute)
+
+	assert.NoError(t, err)
+	assert.Empty(t, held)
+	assert.NoError(t, sqlMock.ExpectationsWereMet())
+}
+
+func TestReleaseExpired_Expires_Lapsed_Holds(t *testing.T) {
+	reservations, sqlMock := mockDB(t)
+
+	now := time.Now()
+	sqlMock.ExpectExec(`UPDATE "ecom"."stock_reservations" SET "status"=\$1 WHERE status = \$2 AND expires_at <= \$3`).
+		WithArgs(models.ReservationExpired, models.ReservationHeld, now).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+
+	count, err := reservations.ReleaseExpired(now)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), count)
+	assert.NoError(t, sqlMock.ExpectationsWereMet())
+}
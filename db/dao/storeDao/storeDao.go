@@ -13,9 +13,20 @@ type DataAccess interface {
 	FindByOwnerID(ownerId string) ([]models.Store, error)
 	FindByName(name string) ([]models.Store, error)
 	FindStoreProducts(storeId string) (models.Store, error)
+	// FindDeletedById finds a soft-deleted store.
+	FindDeletedById(id string) (models.Store, error)
+	// NameTaken reports whether another store, deleted or not, uses name.
+	NameTaken(name string, exceptId string) (bool, error)
 	Insert(item models.Store) (models.Store, error)
 	Update(item models.Store) error
+	// UpdateDetails writes the name and description, including empty values.
+	UpdateDetails(item models.Store) error
+	UpdateSettings(id string, settings models.StoreSettings) error
+	// UpdateImage sets the logo URL and the storage key of an uploaded logo; key is
+	// empty for a logo given by URL.
+	UpdateImage(id string, image *string, key string) error
 	SoftDelete(id string) error
+	Restore(id string) error
 	Delete(id string) error
 }
 
@@ -93,6 +104,31 @@ func (d dataAccess) FindStoreProducts(storeId string) (models.Store, error) {
 	return store, nil
 }
 
+func (d dataAccess) FindDeletedById(id string) (models.Store, error) {
+	var store models.Store
+	result := d.db.Table(models.Store{}.TableName()).
+		Where("id = ? AND del_flg = ?", id, true).
+		First(&store)
+	if result.Error != nil {
+		return models.Store{}, result.Error
+	}
+	return store, nil
+}
+
+func (d dataAccess) NameTaken(name string, exceptId string) (bool, error) {
+	var count int64
+	query := d.db.Table(models.Store{}.TableName()).
+		Where("lower(name) = lower(?)", name)
+	if exceptId != "" {
+		query = query.Where("id <> ?", exceptId)
+	}
+	result := query.Count(&count)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return count > 0, nil
+}
+
 func (d dataAccess) Insert(item models.Store) (models.Store, error) {
 	result := d.db.Table(item.TableName()).Create(&item)
 	if result.Error != nil {
@@ -113,6 +149,39 @@ func (d dataAccess) Update(item models.Store) error {
 	return nil
 }
 
+func (d dataAccess) UpdateDetails(item models.Store) error {
+	result := d.db.Table(item.TableName()).
+		Where(idWhere, item.ID).
+		Select("name", "description").
+		Updates(&item)
+	if result.Error != nil {
+		return result.Error
+	}
+	return nil
+}
+
+func (d dataAccess) UpdateSettings(id string, settings models.StoreSettings) error {
+	var item models.Store
+	result := d.db.Table(item.TableName()).
+		Where(idWhere, id).
+		Update("settings", settings)
+	if result.Error != nil {
+		return result.Error
+	}
+	return nil
+}
+
+func (d dataAccess) UpdateImage(id string, image *string, key string) error {
+	var item models.Store
+	result := d.db.Table(item.TableName()).
+		Where(idWhere, id).
+		Updates(map[string]interface{}{"image": image, "image_key": key})
+	if result.Error != nil {
+		return result.Error
+	}
+	return nil
+}
+
 func (d dataAccess) SoftDelete(id string) error {
 	var item models.Store
 	result := d.db.Table(item.TableName()).
@@ -124,6 +193,17 @@ func (d dataAccess) SoftDelete(id string) error {
 	return nil
 }
 
+func (d dataAccess) Restore(id string) error {
+	var item models.Store
+	result := d.db.Table(item.TableName()).
+		Where(idWhere, id).
+		Update("del_flg", false)
+	if result.Error != nil {
+		return result.Error
+	}
+	return nil
+}
+
 func (d dataAccess) Delete(id string) error {
 	var item models.Store
 	result := d.db.Table(item.TableName()).
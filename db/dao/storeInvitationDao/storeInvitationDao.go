@@ -0,0 +1,137 @@
+package storeInvitationDao
+
+import (
+	"github.com/abdulmalikraji/e-commerce/db/connection"
+	"github.com/abdulmalikraji/e-commerce/db/models"
+	"gorm.io/gorm"
+)
+
+//go:generate mockgen -destination=../../../mocks/dao/storeInvitationDao/mockStoreInvitationDao.go -package=storeInvitationDao -source=storeInvitationDao.go
+type DataAccess interface {
+	FindById(id string) (models.StoreInvitation, error)
+	FindByIdAndStore(id string, storeId string) (models.StoreInvitation, error)
+	FindByStoreId(storeId string, status *string) ([]models.StoreInvitation, error)
+	// FindPendingByEmail returns the store's pending invitation for email, if any.
+	FindPendingByEmail(storeId string, email string) (models.StoreInvitation, error)
+	FindByTokenHash(tokenHash string) (models.StoreInvitation, error)
+	Insert(item models.StoreInvitation) (models.StoreInvitation, error)
+	Update(item models.StoreInvitation) error
+	UpdateStatus(id string, status string) error
+	SoftDelete(id string) error
+	// Transaction runs fn inside a DB transaction.
+	Transaction(fn func(tx *gorm.DB) error) error
+}
+
+type dataAccess struct {
+	db *gorm.DB
+}
+
+func New(client connection.Client) DataAccess {
+	return dataAccess{
+		db: client.PostgresConnection,
+	}
+}
+
+func (d dataAccess) Transaction(fn func(tx *gorm.DB) error) error {
+	return d.db.Transaction(fn)
+}
+
+func (d dataAccess) FindById(id string) (models.StoreInvitation, error) {
+	var invitation models.StoreInvitation
+	result := d.db.Table(models.StoreInvitation{}.TableName()).
+		Where("id = ? AND del_flg = ?", id, false).
+		Preload("Store").
+		First(&invitation)
+	if result.Error != nil {
+		return models.StoreInvitation{}, result.Error
+	}
+	return invitation, nil
+}
+
+func (d dataAccess) FindByIdAndStore(id string, storeId string) (models.StoreInvitation, error) {
+	var invitation models.StoreInvitation
+	result := d.db.Table(models.StoreInvitation{}.TableName()).
+		Where("id = ? AND store_id = ? AND del_flg = ?", id, storeId, false).
+		First(&invitation)
+	if result.Error != nil {
+		return models.StoreInvitation{}, result.Error
+	}
+	return invitation, nil
+}
+
+func (d dataAccess) FindByStoreId(storeId string, status *string) ([]models.StoreInvitation, error) {
+	var invitations []models.StoreInvitation
+	query := d.db.Table(models.StoreInvitation{}.TableName()).
+		Where("store_id = ? AND del_flg = ?", storeId, false)
+	if status != nil {
+		query = query.Where("status = ?", *status)
+	}
+	result := query.Order("created_at DESC").Find(&invitations)
+	if result.Error != nil {
+		return []models.StoreInvitation{}, result.Error
+	}
+	return invitations, nil
+}
+
+func (d dataAccess) FindPendingByEmail(storeId string, email string) (models.StoreInvitation, error) {
+	var invitation models.StoreInvitation
+	result := d.db.Table(models.StoreInvitation{}.TableName()).
+		Where("store_id = ? AND lower(email) = lower(?) AND status = ? AND del_flg = ?", storeId, email, models.InvitationPending, false).
+		First(&invitation)
+	if result.Error != nil {
+		return models.StoreInvitation{}, result.Error
+	}
+	return invitation, nil
+}
+
+func (d dataAccess) FindByTokenHash(tokenHash string) (models.StoreInvitation, error) {
+	var invitation models.StoreInvitation
+	result := d.db.Table(models.StoreInvitation{}.TableName()).
+		Where("token_hash = ? AND del_flg = ?", tokenHash, false).
+		Preload("Store").
+		First(&invitation)
+	if result.Error != nil {
+		return models.StoreInvitation{}, result.Error
+	}
+	return invitation, nil
+}
+
+func (d dataAccess) Insert(item models.StoreInvitation) (models.StoreInvitation, error) {
+	result := d.db.Table(item.TableName()).Create(&item)
+	if result.Error != nil {
+		return models.StoreInvitation{}, result.Error
+	}
+	return item, nil
+}
+
+const idWhere = "id = ?"
+
+func (d dataAccess) Update(item models.StoreInvitation) error {
+	result := d.db.Table(item.TableName()).
+		Where(idWhere, item.ID).
+		Updates(&item)
+	if result.Error != nil {
+		return result.Error
+	}
+	return nil
+}
+
+func (d dataAccess) UpdateStatus(id string, status string) error {
+	result := d.db.Table(models.StoreInvitation{}.TableName()).
+		Where(idWhere, id).
+		Update("status", status)
+	if result.Error != nil {
+		return result.Error
+	}
+	return nil
+}
+
+func (d dataAccess) SoftDelete(id string) error {
+	result := d.db.Table(models.StoreInvitation{}.TableName()).
+		Where(idWhere, id).
+		Update("del_flg", true)
+	if result.Error != nil {
+		return result.Error
+	}
+	return nil
+}
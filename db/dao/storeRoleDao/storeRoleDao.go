@@ -0,0 +1,144 @@
+package storeRoleDao
+
+import (
+	"github.com/abdulmalikraji/e-commerce/db/connection"
+	"github.com/abdulmalikraji/e-commerce/db/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+//go:generate mockgen -destination=../../../mocks/dao/storeRoleDao/mockStoreRoleDao.go -package=storeRoleDao -source=storeRoleDao.go
+type DataAccess interface {
+	// FindForStore returns the system roles followed by the store's own roles.
+	FindForStore(storeId string) ([]models.StoreRole, error)
+	// FindByIdForStore finds a system role or one of the store's roles.
+	FindByIdForStore(id string, storeId string) (models.StoreRole, error)
+	// FindByName prefers the store's own role over a system role of the same name.
+	FindByName(storeId string, name string) (models.StoreRole, error)
+	Insert(item models.StoreRole) (models.StoreRole, error)
+	Update(item models.StoreRole) error
+	// ReplacePermissions sets the role's granted actions to exactly actions.
+	ReplacePermissions(roleId uuid.UUID, actions []string) error
+	Delete(id string) error
+	// FindActions lists the names in the action catalogue, in name order.
+	FindActions() ([]string, error)
+	// Transaction runs fn inside a DB transaction.
+	Transaction(fn func(tx *gorm.DB) error) error
+	// WithTx returns a DataAccess bound to tx.
+	WithTx(tx *gorm.DB) DataAccess
+}
+
+type dataAccess struct {
+	db *gorm.DB
+}
+
+func New(client connection.Client) DataAccess {
+	return dataAccess{
+		db: client.PostgresConnection,
+	}
+}
+
+func (d dataAccess) Transaction(fn func(tx *gorm.DB) error) error {
+	return d.db.Transaction(fn)
+}
+
+func (d dataAccess) WithTx(tx *gorm.DB) DataAccess {
+	return dataAccess{db: tx}
+}
+
+func (d dataAccess) FindForStore(storeId string) ([]models.StoreRole, error) {
+	var roles []models.StoreRole
+	result := d.db.Table(models.StoreRole{}.TableName()).
+		Where("store_id IS NULL OR store_id = ?", storeId).
+		Preload("Permissions").
+		Order("store_id NULLS FIRST, name").
+		Find(&roles)
+	if result.Error != nil {
+		return []models.StoreRole{}, result.Error
+	}
+	return roles, nil
+}
+
+func (d dataAccess) FindByIdForStore(id string, storeId string) (models.StoreRole, error) {
+	var role models.StoreRole
+	result := d.db.Table(models.StoreRole{}.TableName()).
+		Where("id = ? AND (store_id IS NULL OR store_id = ?)", id, storeId).
+		Preload("Permissions").
+		First(&role)
+	if result.Error != nil {
+		return models.StoreRole{}, result.Error
+	}
+	return role, nil
+}
+
+func (d dataAccess) FindByName(storeId string, name string) (models.StoreRole, error) {
+	var role models.StoreRole
+	result := d.db.Table(models.StoreRole{}.TableName()).
+		Where("name = ? AND (store_id IS NULL OR store_id = ?)", name, storeId).
+		Preload("Permissions").
+		Order("store_id NULLS LAST").
+		First(&role)
+	if result.Error != nil {
+		return models.StoreRole{}, result.Error
+	}
+	return role, nil
+}
+
+// Insert creates the role together with its Permissions.
+func (d dataAccess) Insert(item models.StoreRole) (models.StoreRole, error) {
+	result := d.db.Table(item.TableName()).Create(&item)
+	if result.Error != nil {
+		return models.StoreRole{}, result.Error
+	}
+	return item, nil
+}
+
+func (d dataAccess) Update(item models.StoreRole) error {
+	result := d.db.Table(item.TableName()).
+		Where("id = ?", item.ID).
+		Select("name", "description").
+		Updates(&item)
+	if result.Error != nil {
+		return result.Error
+	}
+	return nil
+}
+
+func (d dataAccess) ReplacePermissions(roleId uuid.UUID, actions []string) error {
+	result := d.db.Table(models.StoreRolePermission{}.TableName()).
+		Where("role_id = ?", roleId).
+		Delete(&models.StoreRolePermission{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if len(actions) == 0 {
+		return nil
+	}
+
+	permissions := make([]models.StoreRolePermission, 0, len(actions))
+	for _, action := range actions {
+		permissions = append(permissions, models.StoreRolePermission{RoleID: roleId, Action: action})
+	}
+	return d.db.Table(models.StoreRolePermission{}.TableName()).Create(&permissions).Error
+}
+
+func (d dataAccess) Delete(id string) error {
+	result := d.db.Table(models.StoreRole{}.TableName()).
+		Where("id = ?", id).
+		Delete(&models.StoreRole{})
+	if result.Error != nil {
+		return result.Error
+	}
+	return nil
+}
+
+func (d dataAccess) FindActions() ([]string, error) {
+	var actions []string
+	result := d.db.Table(models.StoreAction{}.TableName()).
+		Order("name").
+		Pluck("name", &actions)
+	if result.Error != nil {
+		return []string{}, result.Error
+	}
+	return actions, nil
+}
@@ -10,17 +10,25 @@ import (
 	"gorm.io/gorm"
 )
 
+//go:generate mockgen -destination=../../../mocks/dao/storeUserDao/mockStoreUserDao.go -package=storeUserDao -source=storeUserDao.go
 type DataAccess interface {
 	FindAll() ([]models.StoreUser, error)
 	FindById(id string) (models.StoreUser, error)
 	FindByStoreID(storeId string) ([]models.StoreUser, error)
 	FindByUserId(userId string) ([]models.StoreUser, error)
+	// FindMemberships returns every store the user works for, including the stores
+	// they own as implicit RoleOwner members.
+	FindMemberships(userID uuid.UUID) ([]models.StoreUser, error)
 	FindByStoreAndUser(storeID, userID uuid.UUID) (models.StoreUser, error)
 	Insert(item models.StoreUser) (models.StoreUser, error)
 	Update(item models.StoreUser) error
+	// UpdateAccess writes the member's role.
+	UpdateAccess(item models.StoreUser) error
 	SoftDelete(id string) error
 	Delete(id string) error
 	HasPermission(storeID, userID uuid.UUID, action string) bool
+	// CountByRole counts the active members holding the role.
+	CountByRole(roleId string) (int64, error)
 }
 
 type dataAccess struct {
@@ -64,6 +72,7 @@ func (d dataAccess) FindByStoreID(storeId string) ([]models.StoreUser, error) {
 	result := d.db.Table(models.StoreUser{}.TableName()).
 		Where("store_id = ? AND del_flg = ?", storeId, false).
 		Preload("User").
+		Preload("StoreRole.Permissions").
 		Find(&items)
 	if result.Error != nil {
 		return []models.StoreUser{}, result.Error
@@ -83,12 +92,49 @@ func (d dataAccess) FindByUserId(userId string) ([]models.StoreUser, error) {
 	return items, nil
 }
 
+func (d dataAccess) FindMemberships(userID uuid.UUID) ([]models.StoreUser, error) {
+	var owned []models.Store
+	result := d.db.Table(models.Store{}.TableName()).
+		Where("owner_id = ? AND del_flg = ?", userID, false).
+		Find(&owned)
+	if result.Error != nil {
+		return []models.StoreUser{}, result.Error
+	}
+
+	var items []models.StoreUser
+	for _, store := range owned {
+		items = append(items, ownerMembership(store))
+	}
+
+	var staff []models.StoreUser
+	result = d.db.Table(models.StoreUser{}.TableName()).
+		Where("user_id = ? AND del_flg = ?", userID, false).
+		Where("store_id IN (?)", d.db.Table(models.Store{}.TableName()).Select("id").Where("owner_id <> ? AND del_flg = ?", userID, false)).
+		Preload("StoreRole.Permissions").
+		Find(&staff)
+	if result.Error != nil {
+		return []models.StoreUser{}, result.Error
+	}
+	return append(items, staff...), nil
+}
+
+// ownerMembership is the implicit membership of a store's owner.
+func ownerMembership(store models.Store) models.StoreUser {
+	return models.StoreUser{
+		StoreID: store.ID,
+		UserID:  store.OwnerID,
+		Role:    models.RoleOwner,
+		Store:   store,
+	}
+}
+
 func (d dataAccess) FindByStoreAndUser(storeID, userID uuid.UUID) (models.StoreUser, error) {
 	var item models.StoreUser
 	result := d.db.Table(models.StoreUser{}.TableName()).
 		Where("store_id = ? AND user_id = ? AND del_flg = ?", storeID, userID, false).
 		Preload("User").
 		Preload("Store").
+		Preload("StoreRole.Permissions").
 		First(&item)
 	if result.Error != nil {
 		return models.StoreUser{}, result.Error
@@ -96,6 +142,17 @@ func (d dataAccess) FindByStoreAndUser(storeID, userID uuid.UUID) (models.StoreU
 	return item, nil
 }
 
+func (d dataAccess) CountByRole(roleId string) (int64, error) {
+	var count int64
+	result := d.db.Table(models.StoreUser{}.TableName()).
+		Where("role_id = ? AND del_flg = ?", roleId, false).
+		Count(&count)
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	return count, nil
+}
+
 func (d dataAccess) Insert(item models.StoreUser) (models.StoreUser, error) {
 	result := d.db.Table(item.TableName()).Create(&item)
 	if result.Error != nil {
@@ -116,6 +173,17 @@ func (d dataAccess) Update(item models.StoreUser) error {
 	return nil
 }
 
+func (d dataAccess) UpdateAccess(item models.StoreUser) error {
+	result := d.db.Table(item.TableName()).
+		Where(suIdWhere, item.ID).
+		Select("role_id", "role").
+		Updates(&item)
+	if result.Error != nil {
+		return result.Error
+	}
+	return nil
+}
+
 func (d dataAccess) SoftDelete(id string) error {
 	var item models.StoreUser
 	result := d.db.Table(item.TableName()).
@@ -138,19 +206,21 @@ func (d dataAccess) Delete(id string) error {
 	return nil
 }
 
-// Action constants
-const (
-	ActionAddProduct          = "add_product"
-	ActionUpdateProduct       = "update_product"
-	ActionDeleteProduct       = "delete_product"
-	ActionManageOrders        = "manage_orders"
-	ActionManageStoreSettings = "manage_store_settings"
-)
-
+// HasPermission reports whether the user may perform action (one of the models.Action*
+// constants) on the store, as its owner or through their membership.
 func (d dataAccess) HasPermission(storeID, userID uuid.UUID, action string) bool {
+	var owners int64
+	res := d.db.Table(models.Store{}.TableName()).
+		Where("id = ? AND owner_id = ? AND del_flg = ?", storeID, userID, false).
+		Count(&owners)
+	if res.Error == nil && owners > 0 {
+		return true
+	}
+
 	var su models.StoreUser
-	res := d.db.Table(models.StoreUser{}.TableName()).
+	res = d.db.Table(models.StoreUser{}.TableName()).
 		Where("store_id = ? AND user_id = ? AND del_flg = ?", storeID, userID, false).
+		Preload("StoreRole.Permissions").
 		First(&su)
 	if res.Error != nil {
 		if errors.Is(res.Error, gorm.ErrRecordNotFound) {
@@ -160,37 +230,5 @@ func (d dataAccess) HasPermission(storeID, userID uuid.UUID, action string) bool
 		return false
 	}
 
-	// role defaults
-	var rd map[string]bool
-	switch su.Role {
-	case models.RoleManager:
-		rd = map[string]bool{
-			ActionAddProduct:          true,
-			ActionUpdateProduct:       true,
-			ActionDeleteProduct:       true,
-			ActionManageOrders:        true,
-			ActionManageStoreSettings: true,
-		}
-	case models.RoleWorker:
-		rd = map[string]bool{
-			ActionManageOrders: true,
-		}
-	default:
-		rd = map[string]bool{}
-	}
-
-	switch action {
-	case ActionAddProduct:
-		return su.CanAddProducts || rd[ActionAddProduct]
-	case ActionUpdateProduct:
-		return su.CanUpdateProducts || rd[ActionUpdateProduct]
-	case ActionDeleteProduct:
-		return su.CanDeleteProducts || rd[ActionDeleteProduct]
-	case ActionManageOrders:
-		return su.CanManageOrders || rd[ActionManageOrders]
-	case ActionManageStoreSettings:
-		return su.CanManageStoreSettings || rd[ActionManageStoreSettings]
-	default:
-		return false
-	}
+	return su.Permissions()[action]
 }
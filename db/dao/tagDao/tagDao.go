@@ -3,9 +3,11 @@ package tagDao
 import (
 	"github.com/abdulmalikraji/e-commerce/db/connection"
 	"github.com/abdulmalikraji/e-commerce/db/models"
+	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
+//go:generate mockgen -destination=../../../mocks/dao/tagDao/mockTagDao.go -package=tagDao -source=tagDao.go
 type DataAccess interface {
 	FindAll() ([]models.Tag, error)
 	FindById(id string) (models.Tag, error)
@@ -15,6 +17,10 @@ type DataAccess interface {
 	Update(item models.Tag) error
 	SoftDelete(id string) error
 	Delete(id string) error
+	// FindOrCreate returns the tags named names, creating the missing ones.
+	FindOrCreate(names []string, createdBy uuid.UUID) ([]models.Tag, error)
+	// WithTx returns a DataAccess bound to tx.
+	WithTx(tx *gorm.DB) DataAccess
 }
 
 type dataAccess struct {
@@ -31,7 +37,7 @@ func (d dataAccess) FindAll() ([]models.Tag, error) {
 	var tags []models.Tag
 	result := d.db.Table(models.Tag{}.TableName()).
 		Where("del_flg = ?", false).
-		Preload("Products").
+		Order("name").
 		Find(&tags)
 	if result.Error != nil {
 		return []models.Tag{}, result.Error
@@ -121,3 +127,23 @@ func (d dataAccess) Delete(id string) error {
 	}
 	return nil
 }
+
+func (d dataAccess) FindOrCreate(names []string, createdBy uuid.UUID) ([]models.Tag, error) {
+	tags := []models.Tag{}
+	for _, name := range names {
+		var tag models.Tag
+		result := d.db.Table(tag.TableName()).
+			Where("name = ?", name).
+			Attrs(models.Tag{Name: name, CreatedBy: &createdBy}).
+			FirstOrCreate(&tag)
+		if result.Error != nil {
+			return []models.Tag{}, result.Error
+		}
+		tags = append(tags, tag)
+	}
+	return tags, nil
+}
+
+func (d dataAccess) WithTx(tx *gorm.DB) DataAccess {
+	return dataAccess{db: tx}
+}
@@ -1,10 +1,14 @@
 package migration
 
 import (
+	"fmt"
 	"log"
+	"slices"
+	"strings"
 	"sync"
 
 	"github.com/abdulmalikraji/e-commerce/db/models"
+	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
@@ -29,6 +33,15 @@ func Migrate(connection *gorm.DB) {
 			}
 		}()
 
+		// Role permissions reference the action catalogue, so it is filled in before
+		// their table (and its foreign key) is migrated.
+		log.Println("Seeding store actions...")
+
+		if err := seedStoreActions(tx); err != nil {
+			tx.Rollback()
+			log.Fatalf("Could not seed store actions, rolling back: %v", err)
+		}
+
 		log.Println("Creating tables...")
 
 		if err := tx.AutoMigrate(
@@ -38,19 +51,25 @@ func Migrate(connection *gorm.DB) {
 			&models.Category{},
 			&models.Product{},
 			&models.ProductImage{},
+			&models.ProductOption{},
+			&models.ProductOptionValue{},
 			&models.ProductVariant{},
 			&models.Order{},
 			&models.OrderItem{},
+			&models.OrderStatusHistory{},
+			&models.Fulfillment{},
 			&models.Payment{},
+			&models.PaymentEvent{},
 			&models.Address{},
 			&models.Review{},
 			&models.Store{},
 			&models.Cart{},
 			&models.CartItem{},
 			&models.Coupon{},
+			&models.CouponRedemption{},
 			&models.Notification{},
 			&models.Refund{},
-			&models.Subcategory{},
+			&models.RefundItem{},
 			&models.Tag{},
 			&models.Warehouse{},
 			&models.WarehouseStock{},
@@ -59,14 +78,68 @@ func Migrate(connection *gorm.DB) {
 			&models.AddToCartEvent{},
 			&models.SearchAnalytics{},
 			&models.StoreVisit{},
+			&models.StoreRole{},
+			&models.StoreRolePermission{},
 			&models.StoreUser{},
+			&models.StoreInvitation{},
+			&models.StockReservation{},
 			&models.MarketingAnalytics{},
 			&models.SalesStat{},
+			&models.ImportJob{},
 		); err != nil {
 			tx.Rollback()
 			log.Fatalf("Could not migrate, rolling back: %v", err)
 		}
 
+		log.Println("Seeding store roles...")
+
+		if err := seedStoreRoles(tx); err != nil {
+			tx.Rollback()
+			log.Fatalf("Could not seed store roles, rolling back: %v", err)
+		}
+
+		log.Println("Moving staff permission flags into roles...")
+
+		if err := migrateStaffFlags(tx); err != nil {
+			tx.Rollback()
+			log.Fatalf("Could not move staff permission flags into roles, rolling back: %v", err)
+		}
+
+		log.Println("Copying legacy subcategory links...")
+
+		if err := copyLegacySubcategories(tx); err != nil {
+			tx.Rollback()
+			log.Fatalf("Could not copy legacy subcategory links, rolling back: %v", err)
+		}
+
+		log.Println("Building category paths...")
+
+		if err := buildCategoryPaths(tx); err != nil {
+			tx.Rollback()
+			log.Fatalf("Could not build category paths, rolling back: %v", err)
+		}
+
+		log.Println("Converting variant attributes to options...")
+
+		if err := convertVariantAttributes(tx); err != nil {
+			tx.Rollback()
+			log.Fatalf("Could not convert variant attributes, rolling back: %v", err)
+		}
+
+		log.Println("Indexing primary product images...")
+
+		if err := indexPrimaryImages(tx); err != nil {
+			tx.Rollback()
+			log.Fatalf("Could not index primary product images, rolling back: %v", err)
+		}
+
+		log.Println("Setting up product search...")
+
+		if err := setupProductSearch(tx); err != nil {
+			tx.Rollback()
+			log.Fatalf("Could not set up product search, rolling back: %v", err)
+		}
+
 		// Commit transaction
 		if err := tx.Commit().Error; err != nil {
 			tx.Rollback()
@@ -76,3 +149,315 @@ func Migrate(connection *gorm.DB) {
 		log.Println("Database migration completed successfully and committed.")
 	})
 }
+
+// seedStoreActions creates the action catalogue and adds the actions of
+// models.StoreActions it does not have yet.
+func seedStoreActions(tx *gorm.DB) error {
+	if err := tx.AutoMigrate(&models.StoreAction{}); err != nil {
+		return err
+	}
+	for _, name := range models.StoreActions {
+		action := models.StoreAction{Name: name}
+		if err := tx.Table(action.TableName()).Where("name = ?", name).FirstOrCreate(&action).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// seedStoreRoles creates the system store roles with their default actions and links
+// store users that only have a role name to the matching system role.
+func seedStoreRoles(tx *gorm.DB) error {
+	for name, actions := range models.SystemStoreRoles {
+		var role models.StoreRole
+		res := tx.Table(role.TableName()).
+			Where("store_id IS NULL AND name = ?", name).
+			Limit(1).
+			Find(&role)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			role = models.StoreRole{Name: name, Description: "System role"}
+			if err := tx.Table(role.TableName()).Create(&role).Error; err != nil {
+				return err
+			}
+		}
+
+		// Grant actions added since the last run; grants are never revoked here.
+		for _, action := range actions {
+			permission := models.StoreRolePermission{RoleID: role.ID, Action: action}
+			err := tx.Table(permission.TableName()).
+				Where("role_id = ? AND action = ?", role.ID, action).
+				FirstOrCreate(&permission).Error
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return tx.Exec(`UPDATE ecom.store_users su SET role_id = r.id
+		FROM ecom.store_roles r
+		WHERE su.role_id IS NULL AND r.store_id IS NULL AND r.name = su.role`).Error
+}
+
+// staffFlagColumns are the permission flags store members and invitations had on top
+// of their role, with the action each one granted.
+var staffFlagColumns = []struct {
+	column string
+	action string
+}{
+	{"can_add_products", models.ActionAddProduct},
+	{"can_update_products", models.ActionUpdateProduct},
+	{"can_delete_products", models.ActionDeleteProduct},
+	{"can_manage_orders", models.ActionManageOrders},
+	{"can_manage_store_settings", models.ActionManageStoreSettings},
+}
+
+// flaggedAccess is a store member or pending invitation with at least one flag set.
+type flaggedAccess struct {
+	ID                     uuid.UUID
+	StoreID                uuid.UUID
+	RoleID                 *uuid.UUID
+	Role                   string
+	CanAddProducts         bool
+	CanUpdateProducts      bool
+	CanDeleteProducts      bool
+	CanManageOrders        bool
+	CanManageStoreSettings bool
+}
+
+func (a flaggedAccess) flags() []bool {
+	return []bool{a.CanAddProducts, a.CanUpdateProducts, a.CanDeleteProducts, a.CanManageOrders, a.CanManageStoreSettings}
+}
+
+// migrateStaffFlags moves the permission flags of store members and pending invitations
+// into their role, then drops the flag columns so roles are the only source of grants.
+// A member whose role and flags together grant the same actions as a role of the store
+// (or a system role) is given that role; otherwise a store role named after the old one
+// is created with those actions.
+func migrateStaffFlags(tx *gorm.DB) error {
+	var roles []models.StoreRole
+	if err := tx.Table(models.StoreRole{}.TableName()).Preload("Permissions").Find(&roles).Error; err != nil {
+		return err
+	}
+
+	for _, table := range []struct {
+		name  string
+		where string
+	}{
+		{models.StoreUser{}.TableName(), "del_flg = false"},
+		{models.StoreInvitation{}.TableName(), "del_flg = false AND status = '" + models.InvitationPending + "'"},
+	} {
+		var hasFlags bool
+		err := tx.Raw(`SELECT EXISTS (SELECT 1 FROM information_schema.columns
+			WHERE table_schema || '.' || table_name = ? AND column_name = ?)`, table.name, staffFlagColumns[0].column).
+			Scan(&hasFlags).Error
+		if err != nil {
+			return err
+		}
+		if !hasFlags {
+			continue
+		}
+
+		columns, drops := []string{}, []string{}
+		for _, flag := range staffFlagColumns {
+			columns = append(columns, flag.column)
+			drops = append(drops, "DROP COLUMN "+flag.column)
+		}
+		var rows []flaggedAccess
+		err = tx.Raw(`SELECT id, store_id, role_id, role, ` + strings.Join(columns, ", ") + ` FROM ` + table.name +
+			` WHERE ` + table.where + ` AND (` + strings.Join(columns, " OR ") + `)`).
+			Scan(&rows).Error
+		if err != nil {
+			return err
+		}
+
+		for _, row := range rows {
+			role, err := roleWithFlags(tx, &roles, row)
+			if err != nil {
+				return err
+			}
+			err = tx.Table(table.name).
+				Where("id = ?", row.ID).
+				Updates(map[string]interface{}{"role_id": role.ID, "role": role.Name}).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Exec(`ALTER TABLE ` + table.name + ` ` + strings.Join(drops, ", ")).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// roleWithFlags finds or creates the role granting row's role actions plus its flags.
+// Created roles are added to roles so later rows of the store reuse them.
+func roleWithFlags(tx *gorm.DB, roles *[]models.StoreRole, row flaggedAccess) (models.StoreRole, error) {
+	visible := func(role models.StoreRole) bool {
+		return role.StoreID == nil || *role.StoreID == row.StoreID
+	}
+
+	var current *models.StoreRole
+	for i, role := range *roles {
+		if !visible(role) {
+			continue
+		}
+		if (row.RoleID != nil && role.ID == *row.RoleID) || (row.RoleID == nil && role.IsSystem() && role.Name == row.Role) {
+			current = &(*roles)[i]
+			break
+		}
+	}
+
+	actions := []string{}
+	if current != nil {
+		actions = append(actions, current.Actions()...)
+	}
+	for i, on := range row.flags() {
+		if on && !slices.Contains(actions, staffFlagColumns[i].action) {
+			actions = append(actions, staffFlagColumns[i].action)
+		}
+	}
+	slices.Sort(actions)
+
+	for _, role := range *roles {
+		granted := role.Actions()
+		slices.Sort(granted)
+		if visible(role) && slices.Equal(granted, actions) {
+			return role, nil
+		}
+	}
+
+	base := row.Role
+	if current != nil {
+		base = current.Name
+	}
+	// Leave room for the suffix in the 50 characters a role name has.
+	if runes := []rune(base); len(runes) > 36 {
+		base = string(runes[:36])
+	}
+	name := base + " (custom)"
+	for n := 2; ; n++ {
+		taken := false
+		for _, role := range *roles {
+			if role.StoreID != nil && *role.StoreID == row.StoreID && role.Name == name {
+				taken = true
+				break
+			}
+		}
+		if !taken {
+			break
+		}
+		name = fmt.Sprintf("%s (custom %d)", base, n)
+	}
+
+	role := models.StoreRole{StoreID: &row.StoreID, Name: name, Description: "Created from legacy permission flags"}
+	for _, action := range actions {
+		role.Permissions = append(role.Permissions, models.StoreRolePermission{Action: action})
+	}
+	if err := tx.Table(role.TableName()).Create(&role).Error; err != nil {
+		return models.StoreRole{}, err
+	}
+	*roles = append(*roles, role)
+	return role, nil
+}
+
+// setupProductSearch indexes products for full-text and trigram search and fills in the
+// search vector of products that have none yet.
+func setupProductSearch(tx *gorm.DB) error {
+	statements := []string{
+		`CREATE EXTENSION IF NOT EXISTS pg_trgm`,
+		`CREATE INDEX IF NOT EXISTS idx_products_search_vector ON ecom.products USING GIN (search_vector)`,
+		`CREATE INDEX IF NOT EXISTS idx_products_name_trgm ON ecom.products USING GIN (name gin_trgm_ops)`,
+		`UPDATE ecom.products p SET search_vector = ` + models.ProductSearchDocument + ` WHERE p.search_vector IS NULL`,
+	}
+	for _, statement := range statements {
+		if err := tx.Exec(statement).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// buildCategoryPaths recomputes every category's materialized path and depth from the
+// parent links, and indexes the paths for prefix (subtree) lookups.
+func buildCategoryPaths(tx *gorm.DB) error {
+	err := tx.Exec(`WITH RECURSIVE tree AS (
+			SELECT id, '/' || id || '/' AS path, 0 AS depth
+			FROM ecom.categories WHERE parent_id IS NULL
+			UNION ALL
+			SELECT c.id, tree.path || c.id || '/', tree.depth + 1
+			FROM ecom.categories c JOIN tree ON c.parent_id = tree.id
+		)
+		UPDATE ecom.categories c SET path = tree.path, depth = tree.depth
+		FROM tree WHERE c.id = tree.id AND (c.path <> tree.path OR c.depth <> tree.depth)`).Error
+	if err != nil {
+		return err
+	}
+	return tx.Exec(`CREATE INDEX IF NOT EXISTS idx_categories_path ON ecom.categories (path text_pattern_ops)`).Error
+}
+
+// copyLegacySubcategories copies the links of the retired ecom.subcategories table into
+// the product_subcategories join table. The old table is left in place.
+func copyLegacySubcategories(tx *gorm.DB) error {
+	return tx.Exec(`DO $$
+		BEGIN
+			IF to_regclass('ecom.subcategories') IS NOT NULL THEN
+				INSERT INTO product_subcategories (product_id, subcategory_id)
+				SELECT DISTINCT product_id, subcategory_id FROM ecom.subcategories
+				ON CONFLICT DO NOTHING;
+			END IF;
+		END $$`).Error
+}
+
+// convertVariantAttributes turns the single attribute name and value of variants made
+// before product options into an option and option value, then drops the old columns.
+func convertVariantAttributes(tx *gorm.DB) error {
+	return tx.Exec(`DO $$
+		BEGIN
+			IF EXISTS (SELECT 1 FROM information_schema.columns
+				WHERE table_schema = 'ecom' AND table_name = 'product_variants' AND column_name = 'attribute_name') THEN
+				INSERT INTO ecom.product_options (product_id, name)
+				SELECT DISTINCT product_id, attribute_name FROM ecom.product_variants
+				WHERE coalesce(attribute_name, '') <> '' AND coalesce(attribute_value, '') <> ''
+				ON CONFLICT DO NOTHING;
+
+				INSERT INTO ecom.product_option_values (option_id, value)
+				SELECT DISTINCT o.id, v.attribute_value FROM ecom.product_variants v
+				JOIN ecom.product_options o ON o.product_id = v.product_id AND o.name = v.attribute_name
+				WHERE coalesce(v.attribute_value, '') <> ''
+				ON CONFLICT DO NOTHING;
+
+				INSERT INTO product_variant_option_values (product_variant_id, product_option_value_id)
+				SELECT v.id, ov.id FROM ecom.product_variants v
+				JOIN ecom.product_options o ON o.product_id = v.product_id AND o.name = v.attribute_name
+				JOIN ecom.product_option_values ov ON ov.option_id = o.id AND ov.value = v.attribute_value
+				ON CONFLICT DO NOTHING;
+
+				ALTER TABLE ecom.product_variants DROP COLUMN attribute_name, DROP COLUMN attribute_value;
+			END IF;
+		END $$`).Error
+}
+
+// indexPrimaryImages keeps only the oldest primary image of each product and of each
+// variant, then enforces one primary image per product and per variant.
+func indexPrimaryImages(tx *gorm.DB) error {
+	statements := []string{
+		`UPDATE ecom.product_images i SET is_primary = false
+		WHERE i.is_primary AND EXISTS (SELECT 1 FROM ecom.product_images o
+			WHERE o.product_id = i.product_id AND o.variant_id IS NOT DISTINCT FROM i.variant_id
+			AND o.is_primary AND (o.created_at, o.id) < (i.created_at, i.id))`,
+		`CREATE UNIQUE INDEX IF NOT EXISTS idx_product_images_primary ON ecom.product_images (product_id)
+		WHERE is_primary AND variant_id IS NULL`,
+		`CREATE UNIQUE INDEX IF NOT EXISTS idx_variant_images_primary ON ecom.product_images (variant_id)
+		WHERE is_primary AND variant_id IS NOT NULL`,
+	}
+	for _, statement := range statements {
+		if err := tx.Exec(statement).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
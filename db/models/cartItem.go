@@ -17,8 +17,9 @@ type CartItem struct {
 	DelFlg    bool       `gorm:"default:false" json:"del_flg"`
 
 	// Relations
-	Cart    Cart    `gorm:"foreignKey:CartID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"cart,omitempty"`
-	Product Product `gorm:"foreignKey:ProductID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;" json:"product,omitempty"`
+	Cart    Cart            `gorm:"foreignKey:CartID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"cart,omitempty"`
+	Product Product         `gorm:"foreignKey:ProductID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;" json:"product,omitempty"`
+	Variant *ProductVariant `gorm:"foreignKey:VariantID;references:ID" json:"variant,omitempty"`
 }
 
 func (CartItem) TableName() string {
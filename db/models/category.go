@@ -1,6 +1,7 @@
 package models
 
 import (
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -11,6 +12,8 @@ type Category struct {
 	Name        string     `gorm:"type:varchar(100);not null;unique" json:"name"`
 	Description string     `gorm:"type:text" json:"description,omitempty"`
 	ParentID    *uuid.UUID `gorm:"type:uuid;index" json:"parent_id,omitempty"`
+	Path        string     `gorm:"type:text;not null;default:''" json:"path"` // "/root-id/.../own-id/"
+	Depth       int        `gorm:"default:0" json:"depth"`                    // 0 for root categories
 	DelFlg      bool       `gorm:"default:false" json:"del_flg"`
 	CreatedAt   time.Time  `gorm:"autoCreateTime" json:"created_at"`
 	UpdatedAt   time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
@@ -27,3 +30,17 @@ type Category struct {
 func (Category) TableName() string {
 	return "ecom.categories"
 }
+
+// CategoryPath returns the materialized path of a category under parent, or of a root
+// category when parent is nil.
+func CategoryPath(id uuid.UUID, parent *Category) string {
+	if parent == nil {
+		return "/" + id.String() + "/"
+	}
+	return parent.Path + id.String() + "/"
+}
+
+// IsDescendantOf reports whether c sits anywhere below ancestor in the tree.
+func (c Category) IsDescendantOf(ancestor Category) bool {
+	return ancestor.Path != "" && c.ID != ancestor.ID && strings.HasPrefix(c.Path, ancestor.Path)
+}
@@ -15,6 +15,10 @@ type Coupon struct {
 	UsedCount      int        `gorm:"default:0" json:"used_count"`
 	ValidFrom      *time.Time `json:"valid_from"`
 	ValidUntil     *time.Time `json:"valid_until"`
+	MaxUsesPerUser *int       `json:"max_uses_per_user"`
+	MinOrderValue  *float64   `gorm:"type:numeric(10,2)" json:"min_order_value"`
+	StoreID        *uuid.UUID `gorm:"type:uuid;index" json:"store_id,omitempty"`    // nullable: only items of this store count
+	CategoryID     *uuid.UUID `gorm:"type:uuid;index" json:"category_id,omitempty"` // nullable: only items of this main category count
 	CreatedBy *uuid.UUID `gorm:"type:uuid;index" json:"created_by,omitempty"`
 	UpdatedBy *uuid.UUID `gorm:"type:uuid;index" json:"updated_by,omitempty"`
 	CreatedAt      time.Time  `gorm:"autoCreateTime" json:"created_at"`
@@ -26,3 +30,19 @@ type Coupon struct {
 func (Coupon) TableName() string {
 	return "ecom.coupons"
 }
+
+// CouponRedemption records a coupon used on an order, for per-user limits.
+type CouponRedemption struct {
+	ID        uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	CouponID  uuid.UUID `gorm:"type:uuid;index:idx_coupon_redemption_user;not null" json:"coupon_id"`
+	UserID    uuid.UUID `gorm:"type:uuid;index:idx_coupon_redemption_user;not null" json:"user_id"`
+	OrderID   uuid.UUID `gorm:"type:uuid;uniqueIndex;not null" json:"order_id"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+
+	// Relations
+	Coupon Coupon `gorm:"foreignKey:CouponID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"coupon,omitempty"`
+}
+
+func (CouponRedemption) TableName() string {
+	return "ecom.coupon_redemptions"
+}
@@ -0,0 +1,61 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Fulfillment is one store's slice of a buyer order. An order spanning several
+// stores gets one fulfillment per store, each shipped and tracked independently.
+type Fulfillment struct {
+	ID             uuid.UUID  `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	OrderID        uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex:idx_fulfillments_order_store" json:"order_id"`
+	StoreID        uuid.UUID  `gorm:"type:uuid;not null;index;uniqueIndex:idx_fulfillments_order_store" json:"store_id"`
+	Status         string     `gorm:"type:varchar(20);not null;default:'pending'" json:"status"` // pending | processing | shipped | delivered | cancelled
+	Carrier        string     `gorm:"type:varchar(50)" json:"carrier,omitempty"`
+	TrackingNumber string     `gorm:"type:varchar(100)" json:"tracking_number,omitempty"`
+	ShippingCost   float64    `gorm:"type:numeric(10,2);default:0" json:"shipping_cost"`
+	ShippedAt      *time.Time `json:"shipped_at,omitempty"`
+	DeliveredAt    *time.Time `json:"delivered_at,omitempty"`
+	CreatedAt      time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt      time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
+	UpdatedBy      *uuid.UUID `gorm:"type:uuid;index" json:"updated_by,omitempty"`
+	DelFlg         bool       `gorm:"default:false" json:"del_flg"`
+
+	// Relations
+	Order Order `gorm:"foreignKey:OrderID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"order,omitempty"`
+	Store Store `gorm:"foreignKey:StoreID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"store,omitempty"`
+}
+
+func (Fulfillment) TableName() string {
+	return "ecom.fulfillments"
+}
+
+// Status constants for Fulfillment.Status
+const (
+	FulfillmentPending    = "pending"
+	FulfillmentProcessing = "processing"
+	FulfillmentShipped    = "shipped"
+	FulfillmentDelivered  = "delivered"
+	FulfillmentCancelled  = "cancelled"
+)
+
+// FulfillmentStatusTransitions lists, for every fulfillment status, the statuses it may move to.
+var FulfillmentStatusTransitions = map[string][]string{
+	FulfillmentPending:    {FulfillmentProcessing, FulfillmentShipped, FulfillmentCancelled},
+	FulfillmentProcessing: {FulfillmentShipped, FulfillmentCancelled},
+	FulfillmentShipped:    {FulfillmentDelivered},
+	FulfillmentDelivered:  {},
+	FulfillmentCancelled:  {},
+}
+
+// CanTransitionFulfillment reports whether a fulfillment may move from one status to another.
+func CanTransitionFulfillment(from, to string) bool {
+	for _, next := range FulfillmentStatusTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,86 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Status constants for ImportJob.Status
+const (
+	ImportStatusPending   = "pending"
+	ImportStatusRunning   = "running"
+	ImportStatusCompleted = "completed"
+	ImportStatusFailed    = "failed"
+)
+
+// Formats of a product import file.
+const (
+	ImportFormatCSV   = "csv"
+	ImportFormatJSONL = "jsonl"
+)
+
+// ImportJob is a bulk product import processed in the background. A dry run validates
+// every product, including against the database, without keeping any change.
+type ImportJob struct {
+	ID         uuid.UUID       `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	StoreID    uuid.UUID       `gorm:"type:uuid;index;not null" json:"store_id"`
+	Format     string          `gorm:"type:varchar(10);not null" json:"format"`
+	DryRun     bool            `gorm:"default:false" json:"dry_run"`
+	Status     string          `gorm:"type:varchar(20);index;not null;default:'pending'" json:"status"`
+	Payload    []byte          `gorm:"type:bytea" json:"-"`    // the uploaded file, cleared when the job finishes
+	Total      int             `gorm:"default:0" json:"total"` // products in the file
+	Processed  int             `gorm:"default:0" json:"processed"`
+	Created    int             `gorm:"default:0" json:"created"`
+	Updated    int             `gorm:"default:0" json:"updated"`
+	Failed     int             `gorm:"default:0" json:"failed"`
+	RowErrors  ImportRowErrors `gorm:"type:jsonb" json:"row_errors"`
+	Error      string          `gorm:"type:text" json:"error,omitempty"` // why the whole job failed
+	CreatedBy  uuid.UUID       `gorm:"type:uuid;not null" json:"created_by"`
+	CreatedAt  time.Time       `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt  time.Time       `gorm:"autoUpdateTime" json:"updated_at"`
+	StartedAt  *time.Time      `json:"started_at,omitempty"`
+	FinishedAt *time.Time      `json:"finished_at,omitempty"`
+}
+
+func (ImportJob) TableName() string {
+	return "ecom.import_jobs"
+}
+
+// ImportRowError is a product of an import file that could not be imported. Line is
+// the line of the file the product starts on.
+type ImportRowError struct {
+	Line    int    `json:"line"`
+	Message string `json:"message"`
+}
+
+// ImportRowErrors is stored as jsonb in ecom.import_jobs.row_errors.
+type ImportRowErrors []ImportRowError
+
+func (e ImportRowErrors) Value() (driver.Value, error) {
+	if e == nil {
+		e = ImportRowErrors{}
+	}
+	data, err := json.Marshal(e)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+func (e *ImportRowErrors) Scan(value any) error {
+	*e = ImportRowErrors{}
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case []byte:
+		return json.Unmarshal(v, e)
+	case string:
+		return json.Unmarshal([]byte(v), e)
+	default:
+		return fmt.Errorf("cannot scan %T into ImportRowErrors", value)
+	}
+}
@@ -10,7 +10,7 @@ type Order struct {
 	ID                uuid.UUID  `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
 	BuyerID           uuid.UUID  `gorm:"type:uuid;index;not null" json:"buyer_id"`
 	ShippingAddressID *uuid.UUID `gorm:"type:uuid;index" json:"shipping_address_id"`
-	Status            string     `gorm:"type:varchar(20);not null;default:'pending'" json:"status"` // pending | paid | shipped | delivered | cancelled
+	Status            string     `gorm:"type:varchar(20);not null;default:'pending'" json:"status"` // pending | paid | fulfilled | shipped | delivered | cancelled | refunded
 	TotalAmount       float64    `gorm:"type:numeric(10,2)" json:"total_amount"`
 	CouponID          *uuid.UUID `gorm:"type:uuid;index" json:"coupon_id,omitempty"` // nullable, FK to Coupon
 	Discount          float64    `gorm:"type:numeric(10,2);default:0" json:"discount"`
@@ -29,3 +29,37 @@ type Order struct {
 func (Order) TableName() string {
 	return "ecom.orders"
 }
+
+// Status constants for Order.Status
+const (
+	OrderStatusPending   = "pending"
+	OrderStatusPaid      = "paid"
+	OrderStatusFulfilled = "fulfilled"
+	OrderStatusShipped   = "shipped"
+	OrderStatusDelivered = "delivered"
+	OrderStatusCancelled = "cancelled"
+	OrderStatusRefunded  = "refunded"
+)
+
+// OrderStatusTransitions lists, for every order status, the statuses it may move to.
+// Cancelled and refunded are terminal. Only unpaid orders are cancelled; a paid order
+// gives the buyer's money back through a refund, which ends in refunded.
+var OrderStatusTransitions = map[string][]string{
+	OrderStatusPending:   {OrderStatusPaid, OrderStatusCancelled},
+	OrderStatusPaid:      {OrderStatusFulfilled, OrderStatusShipped, OrderStatusRefunded},
+	OrderStatusFulfilled: {OrderStatusShipped, OrderStatusRefunded},
+	OrderStatusShipped:   {OrderStatusDelivered, OrderStatusRefunded},
+	OrderStatusDelivered: {OrderStatusRefunded},
+	OrderStatusCancelled: {},
+	OrderStatusRefunded:  {},
+}
+
+// CanTransitionOrder reports whether an order may move from one status to another.
+func CanTransitionOrder(from, to string) bool {
+	for _, next := range OrderStatusTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
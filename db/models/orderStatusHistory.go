@@ -0,0 +1,26 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// OrderStatusHistory records every status change of an order: who made it, from
+// which status to which, and when.
+type OrderStatusHistory struct {
+	ID         uuid.UUID  `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	OrderID    uuid.UUID  `gorm:"type:uuid;index;not null" json:"order_id"`
+	FromStatus string     `gorm:"type:varchar(20)" json:"from_status"` // empty for the initial status
+	ToStatus   string     `gorm:"type:varchar(20);not null" json:"to_status"`
+	ChangedBy  *uuid.UUID `gorm:"type:uuid;index" json:"changed_by,omitempty"` // nullable for system changes (webhooks, sweeper)
+	Note       string     `gorm:"type:text" json:"note,omitempty"`
+	CreatedAt  time.Time  `gorm:"autoCreateTime;index" json:"created_at"`
+
+	// Relations
+	Order Order `gorm:"foreignKey:OrderID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"order,omitempty"`
+}
+
+func (OrderStatusHistory) TableName() string {
+	return "ecom.order_status_history"
+}
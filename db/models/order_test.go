@@ -0,0 +1,32 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCanTransitionOrder(t *testing.T) {
+	allowed := map[string][]string{
+		OrderStatusPending:   {OrderStatusPaid, OrderStatusCancelled},
+		OrderStatusPaid:      {OrderStatusFulfilled, OrderStatusShipped, OrderStatusRefunded},
+		OrderStatusFulfilled: {OrderStatusShipped, OrderStatusRefunded},
+		OrderStatusShipped:   {OrderStatusDelivered, OrderStatusRefunded},
+		OrderStatusDelivered: {OrderStatusRefunded},
+	}
+	statuses := []string{
+		OrderStatusPending, OrderStatusPaid, OrderStatusFulfilled, OrderStatusShipped,
+		OrderStatusDelivered, OrderStatusCancelled, OrderStatusRefunded,
+	}
+
+	for _, from := range statuses {
+		for _, to := range statuses {
+			want := false
+			for _, next := range allowed[from] {
+				want = want || next == to
+			}
+			assert.Equal(t, want, CanTransitionOrder(from, to), "%s -> %s", from, to)
+		}
+	}
+	assert.False(t, CanTransitionOrder("teleported", OrderStatusPaid))
+}
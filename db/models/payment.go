@@ -12,7 +12,7 @@ type Payment struct {
 	Provider       string    `gorm:"type:varchar(50);not null" json:"provider"` // e.g., Stripe, Paystack
 	Method         string    `gorm:"type:varchar(50);not null" json:"method"`   // card, bank_transfer, wallet
 	Amount         float64   `gorm:"type:numeric(10,2);not null" json:"amount"`
-	Status         string    `gorm:"type:varchar(20);not null;default:'initiated'" json:"status"` // initiated | successful | failed
+	Status         string    `gorm:"type:varchar(20);not null;default:'initiated'" json:"status"` // initiated | successful | failed | cancelled | refunded
 	TransactionRef string    `gorm:"type:text;uniqueIndex;not null" json:"transaction_ref"`
 	CreatedAt      time.Time `gorm:"autoCreateTime" json:"created_at"`
 	DelFlg         bool      `gorm:"default:false" json:"del_flg"`
@@ -25,3 +25,12 @@ type Payment struct {
 func (Payment) TableName() string {
 	return "ecom.payments"
 }
+
+// Status constants for Payment.Status
+const (
+	PaymentInitiated  = "initiated"
+	PaymentSuccessful = "successful"
+	PaymentFailed     = "failed"
+	PaymentCancelled  = "cancelled"
+	PaymentRefunded   = "refunded"
+)
@@ -0,0 +1,24 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PaymentEvent records every webhook event a payment provider has delivered. The
+// (provider, event_id) pair is unique, so a redelivered event is recognised and skipped.
+type PaymentEvent struct {
+	ID             uuid.UUID  `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	Provider       string     `gorm:"type:varchar(50);not null;uniqueIndex:idx_payment_event_provider_event" json:"provider"`
+	EventID        string     `gorm:"type:varchar(255);not null;uniqueIndex:idx_payment_event_provider_event" json:"event_id"`
+	Type           string     `gorm:"type:varchar(50);not null" json:"type"`
+	TransactionRef string     `gorm:"type:text;index" json:"transaction_ref"`
+	PaymentID      *uuid.UUID `gorm:"type:uuid;index" json:"payment_id,omitempty"`
+	Payload        string     `gorm:"type:jsonb" json:"payload"`
+	ProcessedAt    time.Time  `gorm:"autoCreateTime" json:"processed_at"`
+}
+
+func (PaymentEvent) TableName() string {
+	return "ecom.payment_events"
+}
@@ -18,9 +18,10 @@ type Product struct {
 	IsDiscounted  bool       `gorm:"default:false" json:"is_discounted"`
 	DiscountPct   float64    `gorm:"type:numeric(5,2);default:0" json:"discount_percent"`
 	IsPopular     bool       `gorm:"default:false" json:"is_popular"`
-	Barcode       string     `gorm:"type:varchar(64);uniqueIndex" json:"barcode"`
+	Barcode       *string    `gorm:"type:varchar(64);uniqueIndex" json:"barcode,omitempty"`
 	RatingAverage float64    `gorm:"type:numeric(3,2);default:0" json:"rating_average"`
 	RatingCount   int        `gorm:"default:0" json:"rating_count"`
+	SearchVector  string     `gorm:"type:tsvector;->:false;<-:false" json:"-"` // written by ProductSearchDocument only
 	CreatedAt     time.Time  `gorm:"autoCreateTime" json:"created_at"`
 	UpdatedAt     time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
 	CreatedBy     *uuid.UUID `gorm:"type:uuid;index" json:"created_by,omitempty"`
@@ -31,6 +32,7 @@ type Product struct {
 	Store          Store            `gorm:"foreignKey:StoreID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;" json:"store,omitempty"`
 	Category       Category         `gorm:"foreignKey:CategoryID;references:ID" json:"category,omitempty"`
 	Images         []ProductImage   `gorm:"foreignKey:ProductID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"images,omitempty"`
+	Options        []ProductOption  `gorm:"foreignKey:ProductID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"options,omitempty"`
 	Variants       []ProductVariant `gorm:"foreignKey:ProductID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"variants,omitempty"`
 	SubCategories  []Category       `gorm:"many2many:product_subcategories;joinForeignKey:ProductID;joinReferences:SubcategoryID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"subcategories,omitempty"`
 	Reviews        []Review         `gorm:"foreignKey:ProductID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"reviews,omitempty"`
@@ -41,3 +43,14 @@ type Product struct {
 func (Product) TableName() string {
 	return "ecom.products"
 }
+
+// ProductSearchDocument computes the search_vector of the products row p with the
+// english text search configuration: the name weighs most, then its tags and category
+// names, then the description.
+const ProductSearchDocument = `setweight(to_tsvector('english', coalesce(p.name, '')), 'A') ||
+	setweight(to_tsvector('english', coalesce((SELECT string_agg(t.name, ' ') FROM product_tags pt
+		JOIN ecom.tags t ON t.id = pt.tag_id WHERE pt.product_id = p.id), '')), 'B') ||
+	setweight(to_tsvector('english', coalesce((SELECT string_agg(c.name, ' ') FROM ecom.categories c
+		WHERE c.id = p.category_id OR c.id IN (SELECT ps.subcategory_id FROM product_subcategories ps
+		WHERE ps.product_id = p.id)), '')), 'B') ||
+	setweight(to_tsvector('english', coalesce(p.description, '')), 'C')`
@@ -6,12 +6,25 @@ import (
 	"github.com/google/uuid"
 )
 
+// Thumbnail sizes generated for uploaded product images, as the longest side in pixels.
+const (
+	ThumbnailSmall  = 200
+	ThumbnailMedium = 800
+)
+
+// ProductImage is an image of a product, or of one of its variants. Uploaded images
+// have a StorageKey and thumbnails; images given by URL have neither.
 type ProductImage struct {
-	ID        uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
-	ProductID uuid.UUID `gorm:"type:uuid;index;not null" json:"product_id"`
-	ImageURL  string    `gorm:"type:text;not null" json:"image_url"`
-	IsPrimary bool      `gorm:"default:false" json:"is_primary"`
-	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+	ID         uuid.UUID  `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	ProductID  uuid.UUID  `gorm:"type:uuid;index;not null" json:"product_id"`
+	VariantID  *uuid.UUID `gorm:"type:uuid;index" json:"variant_id,omitempty"` // nil for images of the product itself
+	ImageURL   string     `gorm:"type:text;not null" json:"image_url"`
+	SmallURL   string     `gorm:"type:text" json:"small_url,omitempty"`
+	MediumURL  string     `gorm:"type:text" json:"medium_url,omitempty"`
+	StorageKey string     `gorm:"type:text" json:"-"`
+	IsPrimary  bool       `gorm:"default:false" json:"is_primary"` // one per product, and one per variant
+	Position   int        `gorm:"default:0" json:"position"`
+	CreatedAt  time.Time  `gorm:"autoCreateTime" json:"created_at"`
 
 	// Relations
 	Product Product `gorm:"foreignKey:ProductID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"product,omitempty"`
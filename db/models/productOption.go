@@ -0,0 +1,35 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ProductOption is a dimension the product's variants differ in, e.g. size or colour.
+// Each variant picks one of its values.
+type ProductOption struct {
+	ID        uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	ProductID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_product_option_name" json:"product_id"`
+	Name      string    `gorm:"type:varchar(50);not null;uniqueIndex:idx_product_option_name" json:"name"`
+	Position  int       `gorm:"default:0" json:"position"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+
+	// Relations
+	Values []ProductOptionValue `gorm:"foreignKey:OptionID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"values,omitempty"`
+}
+
+func (ProductOption) TableName() string {
+	return "ecom.product_options"
+}
+
+type ProductOptionValue struct {
+	ID       uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	OptionID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_product_option_value" json:"option_id"`
+	Value    string    `gorm:"type:varchar(50);not null;uniqueIndex:idx_product_option_value" json:"value"`
+	Position int       `gorm:"default:0" json:"position"`
+}
+
+func (ProductOptionValue) TableName() string {
+	return "ecom.product_option_values"
+}
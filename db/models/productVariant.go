@@ -6,22 +6,25 @@ import (
 	"github.com/google/uuid"
 )
 
+// ProductVariant is one combination of the product's option values, e.g. size M and
+// colour red, with its own SKU, stock and images.
 type ProductVariant struct {
-	ID             uuid.UUID  `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
-	ProductID      uuid.UUID  `gorm:"type:uuid;index;not null" json:"product_id"`
-	SKU            string     `gorm:"type:varchar(64);uniqueIndex;not null" json:"sku"`
-	AttributeName  string     `gorm:"type:varchar(50)" json:"attribute_name"` // e.g., size, color, material
-	AttributeValue string     `gorm:"type:varchar(50)" json:"attribute_value"`
-	PriceOverride  *float64   `gorm:"type:numeric(10,2)" json:"price_override"` // nullable
-	Stock          int        `gorm:"default:0" json:"stock"`
-	CreatedAt      time.Time  `gorm:"autoCreateTime" json:"created_at"`
-	UpdatedAt      time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
-	CreatedBy      *uuid.UUID `gorm:"type:uuid;index" json:"created_by,omitempty"`
-	UpdatedBy      *uuid.UUID `gorm:"type:uuid;index" json:"updated_by,omitempty"`
-	DelFlg         bool       `gorm:"default:false" json:"del_flg"`
+	ID            uuid.UUID  `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	ProductID     uuid.UUID  `gorm:"type:uuid;index;not null" json:"product_id"`
+	SKU           string     `gorm:"type:varchar(64);uniqueIndex;not null" json:"sku"`
+	Barcode       *string    `gorm:"type:varchar(64);uniqueIndex" json:"barcode,omitempty"`
+	PriceOverride *float64   `gorm:"type:numeric(10,2)" json:"price_override"` // nullable
+	Stock         int        `gorm:"default:0" json:"stock"`
+	CreatedAt     time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt     time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
+	CreatedBy     *uuid.UUID `gorm:"type:uuid;index" json:"created_by,omitempty"`
+	UpdatedBy     *uuid.UUID `gorm:"type:uuid;index" json:"updated_by,omitempty"`
+	DelFlg        bool       `gorm:"default:false" json:"del_flg"`
 
 	// Relations
-	Product Product `gorm:"foreignKey:ProductID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"product,omitempty"`
+	Product      Product              `gorm:"foreignKey:ProductID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"product,omitempty"`
+	OptionValues []ProductOptionValue `gorm:"many2many:product_variant_option_values;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"option_values,omitempty"`
+	Images       []ProductImage       `gorm:"foreignKey:VariantID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"images,omitempty"`
 }
 
 func (ProductVariant) TableName() string {
@@ -9,16 +9,48 @@ import (
 type Refund struct {
 	ID          uuid.UUID  `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
 	PaymentID   uuid.UUID  `gorm:"type:uuid;index;not null" json:"payment_id"`
+	OrderID     uuid.UUID  `gorm:"type:uuid;index;not null" json:"order_id"`
+	StoreID     uuid.UUID  `gorm:"type:uuid;index;not null" json:"store_id"` // the seller that approves the refund
 	Amount      float64    `gorm:"type:numeric(10,2);not null" json:"amount"`
 	Reason      string     `gorm:"type:text" json:"reason"`
 	Status      string     `gorm:"type:varchar(20);not null;default:'pending'" json:"status"` // pending | approved | rejected | processed
+	RequestedBy uuid.UUID  `gorm:"type:uuid;index;not null" json:"requested_by"`
+	ReviewedBy  *uuid.UUID `gorm:"type:uuid" json:"reviewed_by,omitempty"`
+	ReviewNote  string     `gorm:"type:text" json:"review_note,omitempty"`
+	Restocked   bool       `gorm:"default:false" json:"restocked"` // returned units were put back on the shelf
 	CreatedAt   time.Time  `gorm:"autoCreateTime" json:"created_at"`
 	ProcessedAt *time.Time `json:"processed_at"`
+	DelFlg      bool       `gorm:"default:false" json:"del_flg"`
 
 	// Relations
-	Payment Payment `gorm:"foreignKey:PaymentID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"payment,omitempty"`
+	Payment Payment      `gorm:"foreignKey:PaymentID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"payment,omitempty"`
+	Items   []RefundItem `gorm:"foreignKey:RefundID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"items,omitempty"`
 }
 
 func (Refund) TableName() string {
 	return "ecom.refunds"
 }
+
+// Status constants for Refund.Status
+const (
+	RefundPending   = "pending"
+	RefundApproved  = "approved"
+	RefundRejected  = "rejected"
+	RefundProcessed = "processed"
+)
+
+// RefundItem is the part of an order item covered by a refund.
+type RefundItem struct {
+	ID          uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	RefundID    uuid.UUID `gorm:"type:uuid;index;not null" json:"refund_id"`
+	OrderItemID uuid.UUID `gorm:"type:uuid;index;not null" json:"order_item_id"`
+	Quantity    int       `gorm:"not null" json:"quantity"`
+	Amount      float64   `gorm:"type:numeric(10,2);not null" json:"amount"`
+
+	// Relations
+	OrderItem OrderItem `gorm:"foreignKey:OrderItemID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"order_item,omitempty"`
+}
+
+func (RefundItem) TableName() string {
+	return "ecom.refund_items"
+}
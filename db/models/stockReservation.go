@@ -0,0 +1,42 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// StockReservation is a time-limited hold on warehouse stock placed when a buyer
+// checks out. A held reservation counts against the available stock of its
+// WarehouseStock row until it expires or is released (order cancelled); once
+// committed (payment succeeded) it keeps counting until the order is cancelled or its
+// units are refunded and restocked.
+// WarehouseStock.Stock itself is the units on hand and is never changed by orders:
+// the sellable count is the product or variant stock, which checkout decrements.
+type StockReservation struct {
+	ID               uuid.UUID  `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	WarehouseStockID uuid.UUID  `gorm:"type:uuid;index;not null" json:"warehouse_stock_id"`
+	ProductID        uuid.UUID  `gorm:"type:uuid;index;not null" json:"product_id"`
+	OrderID          *uuid.UUID `gorm:"type:uuid;index" json:"order_id,omitempty"`
+	Quantity         int        `gorm:"not null;check:quantity > 0" json:"quantity"`
+	Status           string     `gorm:"type:varchar(20);not null;default:'held';index" json:"status"` // held | committed | released | expired
+	ExpiresAt        time.Time  `gorm:"not null;index" json:"expires_at"`
+	CreatedAt        time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt        time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
+
+	// Relations
+	WarehouseStock WarehouseStock `gorm:"foreignKey:WarehouseStockID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"warehouse_stock,omitempty"`
+	Order          *Order         `gorm:"foreignKey:OrderID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;" json:"order,omitempty"`
+}
+
+func (StockReservation) TableName() string {
+	return "ecom.stock_reservations"
+}
+
+// Status constants for StockReservation.Status
+const (
+	ReservationHeld      = "held"
+	ReservationCommitted = "committed"
+	ReservationReleased  = "released"
+	ReservationExpired   = "expired"
+)
@@ -7,17 +7,18 @@ import (
 )
 
 type Store struct {
-	ID          uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
-	Name        string    `gorm:"type:varchar(100);not null;unique" json:"name"`
-	Description string    `gorm:"type:text" json:"description"`
-	OwnerID     uuid.UUID `gorm:"type:uuid;index;not null" json:"owner_id"` // FK to User
-	Settings    string    `gorm:"type:jsonb" json:"settings"`
-	Image       *string   `gorm:"type:text" json:"image,omitempty"`
-	Rating      *float64  `gorm:"type:numeric(2,1);default:0" json:"rating,omitempty"`
-	ReviewCount int       `gorm:"type:int;default:0" json:"review_count"`
-	CreatedAt   time.Time `gorm:"autoCreateTime" json:"created_at"`
-	UpdatedAt   time.Time `gorm:"autoUpdateTime" json:"updated_at"`
-	DelFlg      bool      `gorm:"default:false" json:"del_flg"`
+	ID          uuid.UUID     `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	Name        string        `gorm:"type:varchar(100);not null;unique" json:"name"`
+	Description string        `gorm:"type:text" json:"description"`
+	OwnerID     uuid.UUID     `gorm:"type:uuid;index;not null" json:"owner_id"` // FK to User
+	Settings    StoreSettings `gorm:"type:jsonb" json:"settings"`
+	Image       *string       `gorm:"type:text" json:"image,omitempty"`
+	ImageKey    string        `gorm:"type:text" json:"-"` // storage key of an uploaded logo
+	Rating      *float64      `gorm:"type:numeric(2,1);default:0" json:"rating,omitempty"`
+	ReviewCount int           `gorm:"type:int;default:0" json:"review_count"`
+	CreatedAt   time.Time     `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt   time.Time     `gorm:"autoUpdateTime" json:"updated_at"`
+	DelFlg      bool          `gorm:"default:false" json:"del_flg"`
 
 	Owner    User        `gorm:"foreignKey:OwnerID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;" json:"owner,omitempty"`
 	Products []Product   `gorm:"foreignKey:StoreID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;" json:"products,omitempty"`
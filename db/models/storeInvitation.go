@@ -0,0 +1,43 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// StoreInvitation invites someone by email to join a store's staff. Only the SHA-256
+// hash of the acceptance token is stored; the token itself is sent in the email.
+type StoreInvitation struct {
+	ID        uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	StoreID   uuid.UUID `gorm:"type:uuid;index;not null" json:"store_id"`
+	Email     string    `gorm:"type:text;index;not null" json:"email"`
+	TokenHash string    `gorm:"type:varchar(64);uniqueIndex;not null" json:"-"`
+	Status    string    `gorm:"type:varchar(20);not null;default:'pending'" json:"status"` // pending | accepted | revoked
+
+	// Role granted on acceptance (see StoreUser).
+	RoleID *uuid.UUID `gorm:"type:uuid" json:"role_id,omitempty"`
+	Role   string     `gorm:"type:text;default:'worker'" json:"role"`
+
+	InvitedBy  uuid.UUID  `gorm:"type:uuid;not null" json:"invited_by"`
+	AcceptedBy *uuid.UUID `gorm:"type:uuid" json:"accepted_by,omitempty"`
+	ExpiresAt  time.Time  `gorm:"not null" json:"expires_at"`
+	AcceptedAt *time.Time `json:"accepted_at,omitempty"`
+	CreatedAt  time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt  time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
+	DelFlg     bool       `gorm:"default:false" json:"del_flg"`
+
+	// Relations
+	Store Store `gorm:"foreignKey:StoreID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"store,omitempty"`
+}
+
+func (StoreInvitation) TableName() string {
+	return "ecom.store_invitations"
+}
+
+// Status constants for StoreInvitation.Status
+const (
+	InvitationPending  = "pending"
+	InvitationAccepted = "accepted"
+	InvitationRevoked  = "revoked"
+)
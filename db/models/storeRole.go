@@ -0,0 +1,106 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Store actions a role can grant. Adding an action only needs a constant here and an
+// entry in StoreActions (and, if system roles should have it, in SystemStoreRoles): the
+// migration seeds ecom.store_actions from StoreActions and grants are rows in
+// ecom.store_role_permissions referencing it.
+const (
+	ActionAddProduct          = "add_product"
+	ActionUpdateProduct       = "update_product"
+	ActionDeleteProduct       = "delete_product"
+	ActionManageOrders        = "manage_orders"
+	ActionManageStoreSettings = "manage_store_settings"
+	ActionManageCoupons       = "manage_coupons"
+	ActionViewAnalytics       = "view_analytics"
+	ActionManageWarehouses    = "manage_warehouses"
+)
+
+// StoreActions lists every action a store role may grant, as seeded into
+// ecom.store_actions.
+var StoreActions = []string{
+	ActionAddProduct,
+	ActionUpdateProduct,
+	ActionDeleteProduct,
+	ActionManageOrders,
+	ActionManageStoreSettings,
+	ActionManageCoupons,
+	ActionViewAnalytics,
+	ActionManageWarehouses,
+}
+
+// IsStoreAction reports whether action is one of StoreActions.
+func IsStoreAction(action string) bool {
+	for _, known := range StoreActions {
+		if known == action {
+			return true
+		}
+	}
+	return false
+}
+
+// SystemStoreRoles are seeded by the migration and shared by every store.
+var SystemStoreRoles = map[string][]string{
+	RoleManager: StoreActions,
+	RoleWorker:  {ActionManageOrders},
+}
+
+// StoreRole is a named set of store permissions. Roles without a StoreID are system
+// roles available to every store; stores define their own roles next to them.
+type StoreRole struct {
+	ID          uuid.UUID  `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	StoreID     *uuid.UUID `gorm:"type:uuid;uniqueIndex:idx_store_roles_store_name" json:"store_id,omitempty"` // nil for system roles
+	Name        string     `gorm:"type:varchar(50);not null;uniqueIndex:idx_store_roles_store_name" json:"name"`
+	Description string     `gorm:"type:text" json:"description"`
+	CreatedAt   time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt   time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
+
+	// Relations
+	Permissions []StoreRolePermission `gorm:"foreignKey:RoleID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"permissions,omitempty"`
+}
+
+func (StoreRole) TableName() string {
+	return "ecom.store_roles"
+}
+
+// IsSystem reports whether the role is a shared system role.
+func (r StoreRole) IsSystem() bool {
+	return r.StoreID == nil
+}
+
+// Actions returns the actions the role grants.
+func (r StoreRole) Actions() []string {
+	actions := make([]string, 0, len(r.Permissions))
+	for _, permission := range r.Permissions {
+		actions = append(actions, permission.Action)
+	}
+	return actions
+}
+
+// StoreAction is one entry of the catalogue of actions a role can grant.
+type StoreAction struct {
+	Name string `gorm:"type:varchar(50);primaryKey" json:"name"`
+}
+
+func (StoreAction) TableName() string {
+	return "ecom.store_actions"
+}
+
+// StoreRolePermission grants one action to a role.
+type StoreRolePermission struct {
+	ID     uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	RoleID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_store_role_permissions_role_action" json:"role_id"`
+	Action string    `gorm:"type:varchar(50);not null;uniqueIndex:idx_store_role_permissions_role_action" json:"action"`
+
+	// Relations
+	StoreAction StoreAction `gorm:"foreignKey:Action;references:Name;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT;" json:"-"`
+}
+
+func (StoreRolePermission) TableName() string {
+	return "ecom.store_role_permissions"
+}
@@ -0,0 +1,112 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// StoreSettingsVersion is the version of the StoreSettings schema. Bump it and append
+// to storeSettingsUpgrades when adding a setting that needs a non-zero default.
+const StoreSettingsVersion = 2
+
+// storeSettingsUpgrades[v] fills in the defaults of the settings added in version v+1,
+// so settings saved by an older version are upgraded when they are read.
+var storeSettingsUpgrades = []func(*StoreSettings){
+	// 1: currency, language and the inventory alert flag.
+	func(s *StoreSettings) {},
+	// 2: alert threshold, tax, shipping origin, returns and order auto-accept.
+	func(s *StoreSettings) {
+		s.InventoryAlertThreshold = 5
+		s.ReturnPolicyDays = 14
+	},
+}
+
+// StoreSettings is stored as jsonb in ecom.stores.settings.
+type StoreSettings struct {
+	Version                 int                 `json:"version"`
+	CurrencyID              uuid.UUID           `json:"currency_id"`
+	LanguageID              uuid.UUID           `json:"language_id"`
+	InventoryAlert          bool                `json:"inventory_alert"`
+	InventoryAlertThreshold int                 `json:"inventory_alert_threshold"` // alert when stock falls to this
+	Tax                     StoreTaxSettings    `json:"tax"`
+	ShippingOrigin          StoreShippingOrigin `json:"shipping_origin"`
+	ReturnPolicyDays        int                 `json:"return_policy_days"` // 0 means no returns
+	AutoAcceptOrders        bool                `json:"auto_accept_orders"`
+}
+
+type StoreTaxSettings struct {
+	Enabled          bool    `json:"enabled"`
+	Rate             float64 `json:"rate"` // percent
+	IncludedInPrices bool    `json:"included_in_prices"`
+	TaxID            string  `json:"tax_id,omitempty"`
+}
+
+// StoreShippingOrigin is the address orders ship from.
+type StoreShippingOrigin struct {
+	AddressLine1 string `json:"address_line1"`
+	AddressLine2 string `json:"address_line2,omitempty"`
+	City         string `json:"city"`
+	State        string `json:"state,omitempty"`
+	PostalCode   string `json:"postal_code"`
+	Country      string `json:"country"` // ISO 3166-1 alpha-2
+}
+
+// DefaultStoreSettings returns the settings of a new store.
+func DefaultStoreSettings() StoreSettings {
+	var settings StoreSettings
+	settings.Upgrade()
+	return settings
+}
+
+// Upgrade applies the defaults of every version newer than the settings' own.
+func (s *StoreSettings) Upgrade() {
+	for version := s.Version; version < StoreSettingsVersion; version++ {
+		storeSettingsUpgrades[version](s)
+	}
+	s.Version = StoreSettingsVersion
+}
+
+func (s StoreSettings) Value() (driver.Value, error) {
+	s.Version = StoreSettingsVersion
+	data, err := json.Marshal(s)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// Scan reads the jsonb column and upgrades settings written by older versions.
+func (s *StoreSettings) Scan(value any) error {
+	*s = StoreSettings{}
+	var data []byte
+	switch v := value.(type) {
+	case nil:
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("cannot scan %T into StoreSettings", value)
+	}
+
+	if len(data) > 0 {
+		// Stores created before the settings were validated may hold empty or
+		// malformed IDs; those read as unset instead of failing the whole row.
+		var stored struct {
+			StoreSettings
+			CurrencyID string `json:"currency_id"`
+			LanguageID string `json:"language_id"`
+		}
+		if err := json.Unmarshal(data, &stored); err != nil {
+			return err
+		}
+		*s = stored.StoreSettings
+		s.CurrencyID, _ = uuid.Parse(stored.CurrencyID)
+		s.LanguageID, _ = uuid.Parse(stored.LanguageID)
+	}
+	s.Upgrade()
+	return nil
+}
@@ -0,0 +1,34 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStoreSettings_Scan_Upgrades_Old_Settings(t *testing.T) {
+	currencyID := uuid.New()
+
+	var settings StoreSettings
+	err := settings.Scan([]byte(`{"currency_id":"` + currencyID.String() + `","language_id":"","inventory_alert":true}`))
+
+	assert.NoError(t, err)
+	assert.Equal(t, StoreSettingsVersion, settings.Version)
+	assert.Equal(t, currencyID, settings.CurrencyID)
+	assert.Equal(t, uuid.Nil, settings.LanguageID)
+	assert.True(t, settings.InventoryAlert)
+	assert.Equal(t, 5, settings.InventoryAlertThreshold)
+	assert.Equal(t, 14, settings.ReturnPolicyDays)
+}
+
+func TestStoreSettings_Scan_Keeps_Current_Values(t *testing.T) {
+	settings := DefaultStoreSettings()
+	settings.ReturnPolicyDays = 0
+	value, err := settings.Value()
+	assert.NoError(t, err)
+
+	var scanned StoreSettings
+	assert.NoError(t, scanned.Scan(value))
+	assert.Equal(t, 0, scanned.ReturnPolicyDays)
+}
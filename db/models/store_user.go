@@ -6,7 +6,7 @@ import (
 	"github.com/google/uuid"
 )
 
-// StoreUser represents a user granted explicit permissions on a store.
+// StoreUser represents a user granted permissions on a store through a role.
 // The store has one owner (Store.OwnerID) but additional users can be
 // granted fine-grained rights (add/update/delete products, manage orders, etc).
 type StoreUser struct {
@@ -14,32 +14,47 @@ type StoreUser struct {
 	StoreID uuid.UUID `gorm:"type:uuid;index;not null" json:"store_id"`
 	UserID  uuid.UUID `gorm:"type:uuid;index;not null" json:"user_id"`
 
-	// Role is a higher-level role that implies default permissions.
-	// Use values from the constants: RoleManager, RoleWorker
-	Role string `gorm:"type:text;default:'worker'" json:"role"`
-
-	// Explicit permission flags.
-	CanAddProducts         bool `gorm:"default:false" json:"can_add_products"`
-	CanUpdateProducts      bool `gorm:"default:false" json:"can_update_products"`
-	CanDeleteProducts      bool `gorm:"default:false" json:"can_delete_products"`
-	CanManageOrders        bool `gorm:"default:false" json:"can_manage_orders"`
-	CanManageStoreSettings bool `gorm:"default:false" json:"can_manage_store_settings"`
+	// RoleID is the StoreRole whose permissions the member has; Role holds its name.
+	RoleID *uuid.UUID `gorm:"type:uuid;index" json:"role_id,omitempty"`
+	Role   string     `gorm:"type:text;default:'worker'" json:"role"`
 
 	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
 	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
 	DelFlg    bool      `gorm:"default:false" json:"del_flg"`
 
 	// Relations
-	User  User  `gorm:"foreignKey:UserID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"user,omitempty"`
-	Store Store `gorm:"foreignKey:StoreID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"store,omitempty"`
+	User      User       `gorm:"foreignKey:UserID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"user,omitempty"`
+	Store     Store      `gorm:"foreignKey:StoreID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"store,omitempty"`
+	StoreRole *StoreRole `gorm:"foreignKey:RoleID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;" json:"store_role,omitempty"`
 }
 
 func (StoreUser) TableName() string {
 	return "ecom.store_users"
 }
 
-// Role constants for StoreUser.Role
+// Names of the system roles (see SystemStoreRoles). RoleOwner is never stored: the
+// store owner (Store.OwnerID) is treated as an implicit member with every permission.
 const (
+	RoleOwner   = "owner"
 	RoleManager = "manager"
 	RoleWorker  = "worker"
 )
+
+// Permissions resolves the actions the membership grants: every action for the owner,
+// otherwise the actions of its StoreRole (which must be loaded).
+func (su StoreUser) Permissions() map[string]bool {
+	granted := map[string]bool{}
+	if su.Role == RoleOwner {
+		for _, action := range StoreActions {
+			granted[action] = true
+		}
+		return granted
+	}
+
+	if su.StoreRole != nil {
+		for _, action := range su.StoreRole.Actions() {
+			granted[action] = true
+		}
+	}
+	return granted
+}
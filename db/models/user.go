@@ -28,3 +28,15 @@ type User struct {
 func (User) TableName() string {
 	return "ecom.users"
 }
+
+// Platform roles for User.Role
+const (
+	UserRoleBuyer  = "buyer"
+	UserRoleSeller = "seller"
+	UserRoleAdmin  = "admin"
+)
+
+// IsUserRole reports whether role is a known platform role.
+func IsUserRole(role string) bool {
+	return role == UserRoleBuyer || role == UserRoleSeller || role == UserRoleAdmin
+}
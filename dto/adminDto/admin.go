@@ -0,0 +1,13 @@
+package adminDto
+
+type UpdateUserRoleRequest struct {
+	UserID string `json:"-"`
+	Role   string `json:"role" validate:"required,oneof=buyer seller admin"`
+}
+
+type UserRoleResponse struct {
+	UserID       string `json:"user_id"`
+	Email        string `json:"email"`
+	PreviousRole string `json:"previous_role"`
+	Role         string `json:"role"`
+}
@@ -29,7 +29,7 @@ type SignUpByEmailRequest struct {
 	Password        string      `json:"password" validate:"required,min=8"`
 	ConfirmPassword string      `json:"confirm_password" validate:"required,eqfield=Password"`
 	Address         UserAddress `json:"address" validate:"required,dive"`
-	Role            string      `json:"role" validate:"required,oneof=buyer seller admin"`
+	Role            string      `json:"role" validate:"required,oneof=buyer seller"` // admin is only granted by another admin
 }
 
 type UserAddress struct {
@@ -0,0 +1,37 @@
+package cartDto
+
+type AddCartItemRequest struct {
+	ProductID string `json:"product_id"`
+	VariantID string `json:"variant_id"`
+	Quantity  int    `json:"quantity"`
+}
+
+type UpdateCartItemRequest struct {
+	ItemID   string `json:"-"`
+	Quantity int    `json:"quantity"` // 0 removes the item
+}
+
+type RemoveCartItemRequest struct {
+	ItemID string `json:"item_id"`
+}
+
+type CartLine struct {
+	ItemID    string  `json:"item_id"`
+	ProductID string  `json:"product_id"`
+	VariantID string  `json:"variant_id,omitempty"`
+	StoreID   string  `json:"store_id"`
+	Name      string  `json:"name"`
+	Quantity  int     `json:"quantity"`
+	UnitPrice float64 `json:"unit_price"`
+	LineTotal float64 `json:"line_total"`
+	Stock     int     `json:"stock"`
+	InStock   bool    `json:"in_stock"`
+}
+
+type CartResponse struct {
+	ID        string     `json:"id,omitempty"`
+	Guest     bool       `json:"guest"`
+	Items     []CartLine `json:"items"`
+	ItemCount int        `json:"item_count"`
+	Subtotal  float64    `json:"subtotal"`
+}
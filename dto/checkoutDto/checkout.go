@@ -0,0 +1,39 @@
+package checkoutDto
+
+type CheckoutRequest struct {
+	ShippingAddressID string `json:"shipping_address_id"`
+	CouponCode        string `json:"coupon_code"`
+}
+
+type CheckoutPreviewRequest struct {
+	CouponCode string `query:"coupon_code"`
+}
+
+type CheckoutLine struct {
+	CartItemID string  `json:"cart_item_id"`
+	ProductID  string  `json:"product_id"`
+	VariantID  string  `json:"variant_id,omitempty"`
+	StoreID    string  `json:"store_id"`
+	Name       string  `json:"name"`
+	Quantity   int     `json:"quantity"`
+	UnitPrice  float64 `json:"unit_price"`
+	LineTotal  float64 `json:"line_total"`
+	InStock    bool    `json:"in_stock"`
+}
+
+type CheckoutPreviewResponse struct {
+	Items      []CheckoutLine `json:"items"`
+	Subtotal   float64        `json:"subtotal"`
+	Discount   float64        `json:"discount"`
+	Total      float64        `json:"total"`
+	CouponCode string         `json:"coupon_code,omitempty"`
+}
+
+type CheckoutResponse struct {
+	OrderID  string         `json:"order_id"`
+	Status   string         `json:"status"`
+	Items    []CheckoutLine `json:"items"`
+	Subtotal float64        `json:"subtotal"`
+	Discount float64        `json:"discount"`
+	Total    float64        `json:"total"`
+}
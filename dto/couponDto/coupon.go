@@ -0,0 +1,16 @@
+package couponDto
+
+type ValidateCouponRequest struct {
+	Code string `json:"code"`
+}
+
+// ValidateCouponResponse tells the buyer whether a code applies to their cart and, when
+// it does not, why (Reason is one of the services.CouponReason* codes).
+type ValidateCouponResponse struct {
+	Code             string  `json:"code"`
+	Valid            bool    `json:"valid"`
+	Reason           string  `json:"reason,omitempty"`
+	Message          string  `json:"message,omitempty"`
+	EligibleSubtotal float64 `json:"eligible_subtotal"`
+	Discount         float64 `json:"discount"`
+}
@@ -0,0 +1,47 @@
+package fulfillmentDto
+
+import "time"
+
+type ListFulfillmentsRequest struct {
+	StoreID string `json:"-"`
+	Status  string `query:"status"`
+}
+
+type GetFulfillmentRequest struct {
+	StoreID       string `json:"-"`
+	FulfillmentID string `json:"-"`
+}
+
+// UpdateFulfillmentRequest carries a partial update; nil fields are left unchanged.
+type UpdateFulfillmentRequest struct {
+	StoreID        string   `json:"-"`
+	FulfillmentID  string   `json:"-"`
+	Status         *string  `json:"status"`
+	Carrier        *string  `json:"carrier"`
+	TrackingNumber *string  `json:"tracking_number"`
+	ShippingCost   *float64 `json:"shipping_cost"`
+}
+
+type FulfillmentItem struct {
+	OrderItemID string  `json:"order_item_id"`
+	ProductID   string  `json:"product_id"`
+	VariantID   string  `json:"variant_id,omitempty"`
+	Name        string  `json:"name"`
+	Quantity    int     `json:"quantity"`
+	UnitPrice   float64 `json:"unit_price"`
+}
+
+type FulfillmentResponse struct {
+	ID             string            `json:"id"`
+	OrderID        string            `json:"order_id"`
+	StoreID        string            `json:"store_id"`
+	OrderStatus    string            `json:"order_status"`
+	Status         string            `json:"status"`
+	Carrier        string            `json:"carrier,omitempty"`
+	TrackingNumber string            `json:"tracking_number,omitempty"`
+	ShippingCost   float64           `json:"shipping_cost"`
+	ShippedAt      *time.Time        `json:"shipped_at,omitempty"`
+	DeliveredAt    *time.Time        `json:"delivered_at,omitempty"`
+	CreatedAt      time.Time         `json:"created_at"`
+	Items          []FulfillmentItem `json:"items,omitempty"`
+}
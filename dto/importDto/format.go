@@ -0,0 +1,498 @@
+package importDto
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// listSeparator separates the items of list columns in CSV files.
+const listSeparator = "|"
+
+// CSVColumns are the columns of a CSV file. A product with variants takes one row per
+// variant; the product columns are read from its first row, and rows are grouped into
+// products by barcode, or by name when they have no barcode.
+var CSVColumns = []string{
+	"barcode", "name", "description", "price", "stock", "category", "subcategories",
+	"tags", "images", "is_discounted", "discount_percent",
+	"sku", "variant_barcode", "variant_price", "variant_stock",
+	"option1_name", "option1_value", "option2_name", "option2_value", "option3_name", "option3_value",
+}
+
+// optionColumns is the number of option name and value column pairs.
+const optionColumns = 3
+
+var errEmptyFile = errors.New("the file has no products")
+
+// Parse reads an import file. Products that cannot be read are returned as row errors;
+// an error is returned only when the file as a whole is unusable.
+func Parse(format string, data []byte) ([]ImportProduct, []RowError, error) {
+	var (
+		products  []ImportProduct
+		rowErrors []RowError
+		err       error
+	)
+	switch format {
+	case FormatCSV:
+		products, rowErrors, err = parseCSV(data)
+	case FormatJSONL:
+		products, rowErrors, err = parseJSONL(data)
+	default:
+		return nil, nil, fmt.Errorf("format must be %s or %s", FormatCSV, FormatJSONL)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(products)+len(rowErrors) == 0 {
+		return nil, nil, errEmptyFile
+	}
+	if len(products)+len(rowErrors) > MaxImportProducts {
+		return nil, nil, fmt.Errorf("the file cannot hold more than %d products", MaxImportProducts)
+	}
+	products, duplicates := dropDuplicates(products)
+	return products, append(rowErrors, duplicates...), nil
+}
+
+func parseCSV(data []byte) ([]ImportProduct, []RowError, error) {
+	reader := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte("\ufeff"))))
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, nil, errEmptyFile
+		}
+		return nil, nil, err
+	}
+	columns := map[string]int{}
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if !isCSVColumn(name) {
+			return nil, nil, fmt.Errorf("unknown column %q", name)
+		}
+		if _, ok := columns[name]; ok {
+			return nil, nil, fmt.Errorf("column %q appears twice", name)
+		}
+		columns[name] = i
+	}
+	_, hasBarcode := columns["barcode"]
+	_, hasName := columns["name"]
+	_, hasSKU := columns["sku"]
+	if !hasBarcode && !hasName && !hasSKU {
+		return nil, nil, errors.New("the file needs a barcode, name or sku column")
+	}
+
+	products := []ImportProduct{}
+	rowErrors := []RowError{}
+	groups := map[string]int{} // product key -> index in products
+	failed := map[int]bool{}   // products with a row that could not be read
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		line, _ := reader.FieldPos(0)
+		row := csvRow{record: record, columns: columns}
+		if row.empty() {
+			continue
+		}
+
+		key := row.productKey()
+		if key == "" {
+			rowErrors = append(rowErrors, RowError{Line: line, Message: "the row needs a barcode, name or sku"})
+			continue
+		}
+		index, seen := groups[key]
+		if !seen {
+			product, err := row.product()
+			product.Line = line
+			products = append(products, product)
+			index = len(products) - 1
+			groups[key] = index
+			if err != nil {
+				failed[index] = true
+				rowErrors = append(rowErrors, RowError{Line: line, Message: err.Error()})
+				continue
+			}
+		}
+		if failed[index] {
+			continue
+		}
+
+		variant, ok, err := row.variant()
+		if err != nil {
+			failed[index] = true
+			rowErrors = append(rowErrors, RowError{Line: line, Message: err.Error()})
+			continue
+		}
+		if ok {
+			product := &products[index]
+			if product.Variants == nil {
+				product.Variants = &[]ImportVariant{}
+			}
+			*product.Variants = append(*product.Variants, variant)
+			row.addOptions(product)
+		}
+	}
+
+	valid := []ImportProduct{}
+	for index, product := range products {
+		if !failed[index] {
+			valid = append(valid, product)
+		}
+	}
+	return valid, rowErrors, nil
+}
+
+func isCSVColumn(name string) bool {
+	return slices.Contains(CSVColumns, name)
+}
+
+type csvRow struct {
+	record  []string
+	columns map[string]int
+}
+
+func (r csvRow) get(column string) string {
+	index, ok := r.columns[column]
+	if !ok || index >= len(r.record) {
+		return ""
+	}
+	return strings.TrimSpace(r.record[index])
+}
+
+func (r csvRow) empty() bool {
+	for _, value := range r.record {
+		if strings.TrimSpace(value) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// productKey groups the rows of a product: by barcode, by name, or for a lone variant
+// row, by its SKU.
+func (r csvRow) productKey() string {
+	if barcode := r.get("barcode"); barcode != "" {
+		return "barcode:" + barcode
+	}
+	if name := r.get("name"); name != "" {
+		return "name:" + name
+	}
+	if sku := r.get("sku"); sku != "" {
+		return "sku:" + sku
+	}
+	return ""
+}
+
+func (r csvRow) product() (ImportProduct, error) {
+	product := ImportProduct{Barcode: r.get("barcode")}
+	product.Name = r.text("name")
+	product.Description = r.text("description")
+	product.Category = r.text("category")
+	product.SubCategories = r.list("subcategories")
+	product.Tags = r.list("tags")
+	product.Images = r.list("images")
+
+	var err error
+	if product.Price, err = r.float("price"); err != nil {
+		return ImportProduct{}, err
+	}
+	if product.Stock, err = r.int("stock"); err != nil {
+		return ImportProduct{}, err
+	}
+	if product.DiscountPct, err = r.float("discount_percent"); err != nil {
+		return ImportProduct{}, err
+	}
+	if value := r.get("is_discounted"); value != "" {
+		discounted, err := strconv.ParseBool(value)
+		if err != nil {
+			return ImportProduct{}, errors.New("is_discounted must be true or false")
+		}
+		product.IsDiscounted = &discounted
+	}
+	return product, nil
+}
+
+// variant reads the variant columns; ok is false for rows without a SKU.
+func (r csvRow) variant() (variant ImportVariant, ok bool, err error) {
+	variant.SKU = r.get("sku")
+	if variant.SKU == "" {
+		return ImportVariant{}, false, nil
+	}
+	variant.Barcode = r.get("variant_barcode")
+	if variant.PriceOverride, err = r.float("variant_price"); err != nil {
+		return ImportVariant{}, false, err
+	}
+	stock, err := r.int("variant_stock")
+	if err != nil {
+		return ImportVariant{}, false, err
+	}
+	if stock != nil {
+		variant.Stock = *stock
+	}
+	for i := 1; i <= optionColumns; i++ {
+		name := r.get(fmt.Sprintf("option%d_name", i))
+		value := r.get(fmt.Sprintf("option%d_value", i))
+		if name == "" && value == "" {
+			continue
+		}
+		if name == "" || value == "" {
+			return ImportVariant{}, false, fmt.Errorf("option%d needs both a name and a value", i)
+		}
+		if variant.Options == nil {
+			variant.Options = map[string]string{}
+		}
+		variant.Options[name] = value
+	}
+	return variant, true, nil
+}
+
+// addOptions adds the row's option names and values to the product's options, in
+// column order and in the order the values first appear.
+func (r csvRow) addOptions(product *ImportProduct) {
+	if product.Options == nil {
+		product.Options = &[]ImportOption{}
+	}
+	options := *product.Options
+	for i := 1; i <= optionColumns; i++ {
+		name := r.get(fmt.Sprintf("option%d_name", i))
+		value := r.get(fmt.Sprintf("option%d_value", i))
+		if name == "" || value == "" {
+			continue
+		}
+		index := slices.IndexFunc(options, func(option ImportOption) bool { return option.Name == name })
+		if index < 0 {
+			options = append(options, ImportOption{Name: name})
+			index = len(options) - 1
+		}
+		if !slices.Contains(options[index].Values, value) {
+			options[index].Values = append(options[index].Values, value)
+		}
+	}
+	*product.Options = options
+}
+
+func (r csvRow) text(column string) *string {
+	value := r.get(column)
+	if value == "" {
+		return nil
+	}
+	return &value
+}
+
+func (r csvRow) list(column string) *[]string {
+	value := r.get(column)
+	if value == "" {
+		return nil
+	}
+	items := []string{}
+	for _, item := range strings.Split(value, listSeparator) {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return &items
+}
+
+func (r csvRow) float(column string) (*float64, error) {
+	value := r.get(column)
+	if value == "" {
+		return nil, nil
+	}
+	number, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%s must be a number", column)
+	}
+	return &number, nil
+}
+
+func (r csvRow) int(column string) (*int, error) {
+	value := r.get(column)
+	if value == "" {
+		return nil, nil
+	}
+	number, err := strconv.Atoi(value)
+	if err != nil {
+		return nil, fmt.Errorf("%s must be a whole number", column)
+	}
+	return &number, nil
+}
+
+// parseJSONL reads one product per line; blank lines are skipped.
+func parseJSONL(data []byte) ([]ImportProduct, []RowError, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64<<10), MaxImportSize)
+
+	products := []ImportProduct{}
+	rowErrors := []RowError{}
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := bytes.TrimSpace(scanner.Bytes())
+		if len(text) == 0 {
+			continue
+		}
+		decoder := json.NewDecoder(bytes.NewReader(text))
+		decoder.DisallowUnknownFields()
+		var product ImportProduct
+		if err := decoder.Decode(&product); err != nil {
+			rowErrors = append(rowErrors, RowError{Line: line, Message: "invalid JSON: " + err.Error()})
+			continue
+		}
+		product.Barcode = strings.TrimSpace(product.Barcode)
+		if product.Variants != nil {
+			for i := range *product.Variants {
+				(*product.Variants)[i].SKU = strings.TrimSpace((*product.Variants)[i].SKU)
+			}
+		}
+		product.Line = line
+		products = append(products, product)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, err
+	}
+	return products, rowErrors, nil
+}
+
+// dropDuplicates turns products repeating an earlier product's barcode, or one of its
+// SKUs, into row errors, as they would overwrite each other.
+func dropDuplicates(products []ImportProduct) ([]ImportProduct, []RowError) {
+	barcodes := map[string]int{}
+	skus := map[string]int{}
+	valid := []ImportProduct{}
+	rowErrors := []RowError{}
+	for _, product := range products {
+		if line, ok := barcodes[product.Barcode]; ok && product.Barcode != "" {
+			rowErrors = append(rowErrors, RowError{Line: product.Line, Message: fmt.Sprintf("barcode %s is already used on line %d", product.Barcode, line)})
+			continue
+		}
+		duplicate := ""
+		if product.Variants != nil {
+			for _, variant := range *product.Variants {
+				if line, ok := skus[variant.SKU]; ok && variant.SKU != "" {
+					duplicate = fmt.Sprintf("sku %s is already used on line %d", variant.SKU, line)
+					break
+				}
+			}
+		}
+		if duplicate != "" {
+			rowErrors = append(rowErrors, RowError{Line: product.Line, Message: duplicate})
+			continue
+		}
+
+		if product.Barcode != "" {
+			barcodes[product.Barcode] = product.Line
+		}
+		if product.Variants != nil {
+			for _, variant := range *product.Variants {
+				if variant.SKU != "" {
+					skus[variant.SKU] = product.Line
+				}
+			}
+		}
+		valid = append(valid, product)
+	}
+	return valid, rowErrors
+}
+
+// CSVRows returns the rows of product in an export: one per variant, or a single row
+// for a product without variants. The product columns are only filled on the first.
+func CSVRows(product ImportProduct) [][]string {
+	first := map[string]string{
+		"barcode":       product.Barcode,
+		"name":          textValue(product.Name),
+		"description":   textValue(product.Description),
+		"category":      textValue(product.Category),
+		"subcategories": listValue(product.SubCategories),
+		"tags":          listValue(product.Tags),
+		"images":        listValue(product.Images),
+	}
+	if product.Price != nil {
+		first["price"] = strconv.FormatFloat(*product.Price, 'f', -1, 64)
+	}
+	if product.Stock != nil {
+		first["stock"] = strconv.Itoa(*product.Stock)
+	}
+	if product.IsDiscounted != nil {
+		first["is_discounted"] = strconv.FormatBool(*product.IsDiscounted)
+	}
+	if product.DiscountPct != nil {
+		first["discount_percent"] = strconv.FormatFloat(*product.DiscountPct, 'f', -1, 64)
+	}
+
+	var variants []ImportVariant
+	if product.Variants != nil {
+		variants = *product.Variants
+	}
+	if len(variants) == 0 {
+		return [][]string{csvRecord(first)}
+	}
+
+	var optionNames []string
+	if product.Options != nil {
+		for _, option := range *product.Options {
+			optionNames = append(optionNames, option.Name)
+		}
+	}
+	rows := make([][]string, 0, len(variants))
+	for i, variant := range variants {
+		values := map[string]string{}
+		if i == 0 {
+			values = first
+		} else {
+			// Later rows only carry the barcode or name that groups them with the first.
+			values["barcode"] = product.Barcode
+			if product.Barcode == "" {
+				values["name"] = first["name"]
+			}
+		}
+		values["sku"] = variant.SKU
+		values["variant_barcode"] = variant.Barcode
+		values["variant_stock"] = strconv.Itoa(variant.Stock)
+		if variant.PriceOverride != nil {
+			values["variant_price"] = strconv.FormatFloat(*variant.PriceOverride, 'f', -1, 64)
+		}
+		for j, name := range optionNames {
+			if j == optionColumns {
+				break
+			}
+			values[fmt.Sprintf("option%d_name", j+1)] = name
+			values[fmt.Sprintf("option%d_value", j+1)] = variant.Options[name]
+		}
+		rows = append(rows, csvRecord(values))
+	}
+	return rows
+}
+
+func csvRecord(values map[string]string) []string {
+	record := make([]string, len(CSVColumns))
+	for i, column := range CSVColumns {
+		record[i] = values[column]
+	}
+	return record
+}
+
+func textValue(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
+
+func listValue(items *[]string) string {
+	if items == nil {
+		return ""
+	}
+	return strings.Join(*items, listSeparator)
+}
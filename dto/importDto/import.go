@@ -0,0 +1,115 @@
+package importDto
+
+import (
+	"io"
+	"time"
+
+	"github.com/abdulmalikraji/e-commerce/utils"
+)
+
+// Formats of an import file or export.
+const (
+	FormatCSV   = "csv"
+	FormatJSONL = "jsonl"
+)
+
+const (
+	// MaxImportSize is the largest import file, which must fit the server's body limit.
+	MaxImportSize = 8 << 20
+	// MaxImportProducts is the most products a single file may hold.
+	MaxImportProducts = 5000
+	// MaxRowErrors caps the row errors kept on a job; Failed still counts every product.
+	MaxRowErrors = 500
+)
+
+// ImportProduct is one product of an import file, and of an export. Products are
+// matched to the store's catalog by Barcode, or else by the SKU of one of their
+// variants; unmatched products are created. Nil fields keep the current value of a
+// matched product. Category and SubCategories hold category IDs or names; the first
+// of Images is the primary image. Options may be left out when the variants carry
+// them, as they always do in CSV files.
+type ImportProduct struct {
+	Barcode       string           `json:"barcode,omitempty"`
+	Name          *string          `json:"name,omitempty"`
+	Description   *string          `json:"description,omitempty"`
+	Price         *float64         `json:"price,omitempty"`
+	Stock         *int             `json:"stock,omitempty"`
+	Category      *string          `json:"category,omitempty"`
+	SubCategories *[]string        `json:"subcategories,omitempty"`
+	Tags          *[]string        `json:"tags,omitempty"`
+	Images        *[]string        `json:"images,omitempty"`
+	IsDiscounted  *bool            `json:"is_discounted,omitempty"`
+	DiscountPct   *float64         `json:"discount_percent,omitempty"`
+	Options       *[]ImportOption  `json:"options,omitempty"`
+	Variants      *[]ImportVariant `json:"variants,omitempty"`
+	Line          int              `json:"-"` // line of the file the product starts on
+}
+
+type ImportOption struct {
+	Name   string   `json:"name"`
+	Values []string `json:"values"`
+}
+
+// ImportVariant is matched to the product's variants by SKU. Options maps every
+// option name of the product to one of its values.
+type ImportVariant struct {
+	SKU           string            `json:"sku"`
+	Barcode       string            `json:"barcode,omitempty"`
+	Options       map[string]string `json:"options,omitempty"`
+	PriceOverride *float64          `json:"price_override,omitempty"`
+	Stock         int               `json:"stock"`
+}
+
+// StartImportRequest queues File for import. With DryRun every product is checked,
+// against the catalog too, and reported without any change being kept.
+type StartImportRequest struct {
+	StoreID string     `json:"-"`
+	Format  string     `query:"format"` // csv or jsonl; taken from the file name when empty
+	DryRun  bool       `query:"dry_run"`
+	File    utils.File `json:"-"`
+}
+
+type GetImportJobRequest struct {
+	StoreID string `json:"-"`
+	JobID   string `json:"-"`
+}
+
+// ExportRequest streams the store's live catalog in Format, csv by default.
+type ExportRequest struct {
+	StoreID string `json:"-"`
+	Format  string `query:"format"`
+}
+
+// RowError is a product of the file that was not imported, by the line it starts on.
+type RowError struct {
+	Line    int    `json:"line"`
+	Message string `json:"message"`
+}
+
+// ImportJobResponse reports an import's progress. Created and Updated count what a
+// dry run would have done.
+type ImportJobResponse struct {
+	ID         string     `json:"id"`
+	Status     string     `json:"status"`
+	Format     string     `json:"format"`
+	DryRun     bool       `json:"dry_run"`
+	Total      int        `json:"total"`
+	Processed  int        `json:"processed"`
+	Created    int        `json:"created"`
+	Updated    int        `json:"updated"`
+	Failed     int        `json:"failed"`
+	RowErrors  []RowError `json:"row_errors"`
+	Error      string     `json:"error,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	StartedAt  *time.Time `json:"started_at,omitempty"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+}
+
+// ExportResponse streams an export with Write, which flushes after every batch of
+// products. An error from Write means the export was cut short; the body must not be
+// ended as if it were complete.
+type ExportResponse struct {
+	ContentType string
+	FileName    string
+	Write       func(w io.Writer) error
+}
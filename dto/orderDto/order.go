@@ -1,9 +1,34 @@
 package orderDto
 
+import "time"
+
 // OrderFilter struct for flexible filtering
 type OrderFilter struct {
- Status        *string
- DateFrom      *string // ISO8601 date string
- DateTo        *string // ISO8601 date string
- PaymentStatus *string
-}
\ No newline at end of file
+	Status        *string
+	DateFrom      *string // ISO8601 date string
+	DateTo        *string // ISO8601 date string
+	PaymentStatus *string
+}
+
+type UpdateOrderStatusRequest struct {
+	OrderID string `json:"-"`
+	Status  string `json:"status"`
+	Note    string `json:"note"`
+}
+
+type UpdateOrderStatusResponse struct {
+	OrderID string `json:"order_id"`
+	Status  string `json:"status"`
+}
+
+type GetOrderHistoryRequest struct {
+	OrderID string `json:"order_id"`
+}
+
+type OrderStatusHistoryResponse struct {
+	FromStatus string    `json:"from_status"`
+	ToStatus   string    `json:"to_status"`
+	ChangedBy  string    `json:"changed_by,omitempty"`
+	Note       string    `json:"note,omitempty"`
+	ChangedAt  time.Time `json:"changed_at"`
+}
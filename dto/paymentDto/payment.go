@@ -0,0 +1,43 @@
+package paymentDto
+
+import "time"
+
+type CreatePaymentRequest struct {
+	OrderID  string `json:"-"`
+	Provider string `json:"provider"`
+	Method   string `json:"method"`
+}
+
+type PaymentActionRequest struct {
+	PaymentID string `json:"payment_id"`
+}
+
+type GetOrderPaymentsRequest struct {
+	OrderID string `json:"order_id"`
+}
+
+type PaymentResponse struct {
+	ID             string    `json:"id"`
+	OrderID        string    `json:"order_id"`
+	OrderStatus    string    `json:"order_status,omitempty"`
+	Provider       string    `json:"provider"`
+	Method         string    `json:"method"`
+	Amount         float64   `json:"amount"`
+	Status         string    `json:"status"`
+	TransactionRef string    `json:"transaction_ref"`
+	ClientSecret   string    `json:"client_secret,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+type WebhookRequest struct {
+	Provider  string
+	Signature string
+	Payload   []byte
+}
+
+type WebhookResponse struct {
+	EventID   string `json:"event_id"`
+	Type      string `json:"type"`
+	Duplicate bool   `json:"duplicate"`
+	Refunded  bool   `json:"refunded,omitempty"`
+}
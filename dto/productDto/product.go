@@ -1,13 +1,229 @@
 package productDto
 
+import (
+	"time"
+
+	"github.com/abdulmalikraji/e-commerce/utils"
+)
+
+// Sort orders accepted by ProductFilter.Sort.
+const (
+	SortNewest      = "newest"
+	SortPriceAsc    = "price_asc"
+	SortPriceDesc   = "price_desc"
+	SortRating      = "rating"
+	SortBestSelling = "best_selling"
+)
+
+var ProductSorts = []string{SortNewest, SortPriceAsc, SortPriceDesc, SortRating, SortBestSelling}
+
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+)
+
+const (
+	MaxOptions  = 3
+	MaxVariants = 100
+	MaxImages   = 20
+)
+
+// ProductFilter is read from the query string of GET /products. Subcategories may be
+// repeated or comma separated.
 type ProductFilter struct {
-	MinPrice     *float64 `json:"min_price,omitempty"`
-	MaxPrice     *float64 `json:"max_price,omitempty"`
-	CategoryID   *string  `json:"category_id,omitempty"`
-	SubCatIDs    []string `json:"sub_cat_ids,omitempty"`
-	MinRating    *float64 `json:"min_rating,omitempty"`
-	DiscountOnly bool     `json:"discount_only,omitempty"`
-	IsPopular    *bool    `json:"is_popular,omitempty"`
-	Page         int      `json:"page,omitempty"`
-	PageSize     int      `json:"page_size,omitempty"`
+	MinPrice     *float64 `json:"min_price,omitempty" query:"min_price"`
+	MaxPrice     *float64 `json:"max_price,omitempty" query:"max_price"`
+	CategoryID   *string  `json:"category_id,omitempty" query:"category_id"`
+	SubCatIDs    []string `json:"sub_cat_ids,omitempty" query:"subcategory_ids"`
+	MinRating    *float64 `json:"min_rating,omitempty" query:"min_rating"`
+	DiscountOnly bool     `json:"discount_only,omitempty" query:"discount_only"`
+	IsPopular    *bool    `json:"is_popular,omitempty" query:"is_popular"`
+	Sort         string   `json:"sort,omitempty" query:"sort"`
+	Page         int      `json:"page,omitempty" query:"page"`
+	PageSize     int      `json:"page_size,omitempty" query:"page_size"`
+}
+
+// OptionInput is one of the product's options, e.g. size, with its values in display
+// order.
+type OptionInput struct {
+	Name   string   `json:"name"`
+	Values []string `json:"values"`
+}
+
+// VariantInput describes a variant in a create or update request. Variants sent with
+// an ID update that variant; variants without one are created. Options maps every
+// option name of the product to one of its values. Images, when given, replace the
+// variant's images.
+type VariantInput struct {
+	ID            string            `json:"id,omitempty"`
+	SKU           string            `json:"sku"`
+	Barcode       string            `json:"barcode"`
+	Options       map[string]string `json:"options"`
+	PriceOverride *float64          `json:"price_override"`
+	Stock         int               `json:"stock"`
+	Images        []ImageInput      `json:"images"`
+}
+
+type ImageInput struct {
+	ImageURL  string `json:"image_url"`
+	IsPrimary bool   `json:"is_primary"`
+}
+
+// CreateProductRequest creates a product with its variants, images and tags.
+type CreateProductRequest struct {
+	StoreID        string         `json:"-"`
+	Name           string         `json:"name"`
+	Description    string         `json:"description"`
+	Price          float64        `json:"price"`
+	Stock          int            `json:"stock"`
+	CategoryID     string         `json:"category_id"`
+	SubCategoryIDs []string       `json:"subcategory_ids"`
+	IsDiscounted   bool           `json:"is_discounted"`
+	DiscountPct    float64        `json:"discount_percent"`
+	Barcode        string         `json:"barcode"`
+	Options        []OptionInput  `json:"options"`
+	Variants       []VariantInput `json:"variants"`
+	Images         []ImageInput   `json:"images"`
+	Tags           []string       `json:"tags"`
+}
+
+// UpdateProductRequest changes a product; nil fields are left as they are. Options,
+// variants, images, tags and subcategories, when given, replace the current ones:
+// variants left out are deleted.
+type UpdateProductRequest struct {
+	StoreID        string          `json:"-"`
+	ProductID      string          `json:"-"`
+	Name           *string         `json:"name"`
+	Description    *string         `json:"description"`
+	Price          *float64        `json:"price"`
+	Stock          *int            `json:"stock"`
+	CategoryID     *string         `json:"category_id"`
+	SubCategoryIDs *[]string       `json:"subcategory_ids"`
+	IsDiscounted   *bool           `json:"is_discounted"`
+	DiscountPct    *float64        `json:"discount_percent"`
+	Barcode        *string         `json:"barcode"`
+	Options        *[]OptionInput  `json:"options"`
+	Variants       *[]VariantInput `json:"variants"`
+	Images         *[]ImageInput   `json:"images"`
+	Tags           *[]string       `json:"tags"`
+}
+
+type GetStoreProductRequest struct {
+	StoreID   string `json:"-"`
+	ProductID string `json:"-"`
+}
+
+type DeleteProductRequest struct {
+	StoreID   string `json:"-"`
+	ProductID string `json:"-"`
+}
+
+// GenerateVariantsRequest builds every combination of the product's option values.
+// Combinations without a variant get a SKU made of SKUPrefix and the values; with
+// Create they are added as variants with PriceOverride and Stock.
+type GenerateVariantsRequest struct {
+	StoreID       string   `json:"-"`
+	ProductID     string   `json:"-"`
+	SKUPrefix     string   `json:"sku_prefix"`
+	PriceOverride *float64 `json:"price_override"`
+	Stock         int      `json:"stock"`
+	Create        bool     `json:"create"`
+}
+
+// VariantCombination is one cell of the variant matrix. VariantID is set when a
+// variant already exists for the combination; Conflict explains why the suggested SKU
+// cannot be used.
+type VariantCombination struct {
+	Options   map[string]string `json:"options"`
+	SKU       string            `json:"sku"`
+	VariantID string            `json:"variant_id,omitempty"`
+	Conflict  string            `json:"conflict,omitempty"`
+}
+
+type VariantMatrixResponse struct {
+	Combinations []VariantCombination `json:"combinations"`
+	Created      int                  `json:"created"`
+}
+
+type OptionResponse struct {
+	ID     string   `json:"id"`
+	Name   string   `json:"name"`
+	Values []string `json:"values"`
+}
+
+type VariantResponse struct {
+	ID            string            `json:"id"`
+	SKU           string            `json:"sku"`
+	Barcode       string            `json:"barcode,omitempty"`
+	Options       map[string]string `json:"options"`
+	PriceOverride *float64          `json:"price_override,omitempty"`
+	Stock         int               `json:"stock"`
+	Images        []ImageResponse   `json:"images"`
+}
+
+// UploadImageRequest adds an uploaded image to the product, after its other images.
+// The product's first image is always primary.
+type UploadImageRequest struct {
+	StoreID   string     `json:"-"`
+	ProductID string     `json:"-"`
+	IsPrimary bool       `json:"is_primary" form:"is_primary"`
+	File      utils.File `json:"-"`
+}
+
+type ProductImageRequest struct {
+	StoreID   string `json:"-"`
+	ProductID string `json:"-"`
+	ImageID   string `json:"-"`
+}
+
+// ReorderImagesRequest lists every image of the product in the new display order.
+type ReorderImagesRequest struct {
+	StoreID   string   `json:"-"`
+	ProductID string   `json:"-"`
+	ImageIDs  []string `json:"image_ids"`
+}
+
+// ImageResponse describes an image. Uploaded images also have thumbnails.
+type ImageResponse struct {
+	ID        string `json:"id"`
+	ImageURL  string `json:"image_url"`
+	SmallURL  string `json:"small_url,omitempty"`
+	MediumURL string `json:"medium_url,omitempty"`
+	IsPrimary bool   `json:"is_primary"`
+	Position  int    `json:"position"`
+}
+
+type ProductResponse struct {
+	ID             string            `json:"id"`
+	StoreID        string            `json:"store_id"`
+	CategoryID     string            `json:"category_id"`
+	SubCategoryIDs []string          `json:"subcategory_ids"`
+	Name           string            `json:"name"`
+	Description    string            `json:"description"`
+	Price          float64           `json:"price"`
+	Stock          int               `json:"stock"`
+	HasVariants    bool              `json:"has_variants"`
+	IsDiscounted   bool              `json:"is_discounted"`
+	DiscountPct    float64           `json:"discount_percent"`
+	IsPopular      bool              `json:"is_popular"`
+	RatingAverage  float64           `json:"rating_average"`
+	RatingCount    int               `json:"rating_count"`
+	Barcode        string            `json:"barcode,omitempty"`
+	Options        []OptionResponse  `json:"options"`
+	Variants       []VariantResponse `json:"variants"`
+	Images         []ImageResponse   `json:"images"`
+	Tags           []string          `json:"tags"`
+	CreatedBy      string            `json:"created_by,omitempty"`
+	UpdatedBy      string            `json:"updated_by,omitempty"`
+	CreatedAt      time.Time         `json:"created_at"`
+	UpdatedAt      time.Time         `json:"updated_at"`
+}
+
+// ListProductsResponse is one page of products; the handler turns the counts into
+// the response's page metadata.
+type ListProductsResponse struct {
+	Products []ProductResponse
+	Total    int64
+	Page     int
+	PageSize int
 }
@@ -0,0 +1,279 @@
+package productDto
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// ValidateCreateProduct validates the CreateProductRequest fields. Whether the
+// categories exist is checked by the service.
+func ValidateCreateProduct(req CreateProductRequest) error {
+	if err := validateName(req.Name); err != nil {
+		return err
+	}
+	if req.Price <= 0 {
+		return errors.New("price must be greater than 0")
+	}
+	if req.Stock < 0 {
+		return errors.New("stock cannot be negative")
+	}
+	if _, err := uuid.Parse(req.CategoryID); err != nil {
+		return errors.New("category_id must be a valid UUID")
+	}
+	if err := validateDiscount(req.DiscountPct); err != nil {
+		return err
+	}
+	if err := validateSubCategories(req.SubCategoryIDs); err != nil {
+		return err
+	}
+	if err := validateOptions(req.Options); err != nil {
+		return err
+	}
+	if err := validateVariants(req.Variants); err != nil {
+		return err
+	}
+	if err := validateImages(req.Images); err != nil {
+		return err
+	}
+	return ValidateTags(req.Tags)
+}
+
+// ValidateUpdateProduct validates the fields present in the UpdateProductRequest.
+func ValidateUpdateProduct(req UpdateProductRequest) error {
+	if req.Name != nil {
+		if err := validateName(*req.Name); err != nil {
+			return err
+		}
+	}
+	if req.Price != nil && *req.Price <= 0 {
+		return errors.New("price must be greater than 0")
+	}
+	if req.Stock != nil && *req.Stock < 0 {
+		return errors.New("stock cannot be negative")
+	}
+	if req.CategoryID != nil {
+		if _, err := uuid.Parse(*req.CategoryID); err != nil {
+			return errors.New("category_id must be a valid UUID")
+		}
+	}
+	if req.DiscountPct != nil {
+		if err := validateDiscount(*req.DiscountPct); err != nil {
+			return err
+		}
+	}
+	if req.SubCategoryIDs != nil {
+		if err := validateSubCategories(*req.SubCategoryIDs); err != nil {
+			return err
+		}
+	}
+	if req.Options != nil {
+		if err := validateOptions(*req.Options); err != nil {
+			return err
+		}
+	}
+	if req.Variants != nil {
+		if err := validateVariants(*req.Variants); err != nil {
+			return err
+		}
+	}
+	if req.Images != nil {
+		if err := validateImages(*req.Images); err != nil {
+			return err
+		}
+	}
+	if req.Tags != nil {
+		return ValidateTags(*req.Tags)
+	}
+	return nil
+}
+
+func validateName(name string) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return errors.New("name is required")
+	}
+	if len(name) > 100 {
+		return errors.New("name cannot be longer than 100 characters")
+	}
+	return nil
+}
+
+func validateDiscount(pct float64) error {
+	if pct < 0 || pct > 100 {
+		return errors.New("discount_percent must be between 0 and 100")
+	}
+	return nil
+}
+
+func validateSubCategories(ids []string) error {
+	for _, id := range ids {
+		if _, err := uuid.Parse(id); err != nil {
+			return errors.New("subcategory_ids must be valid UUIDs")
+		}
+	}
+	return nil
+}
+
+func validateOptions(options []OptionInput) error {
+	if len(options) > MaxOptions {
+		return fmt.Errorf("a product can have at most %d options", MaxOptions)
+	}
+	names := map[string]bool{}
+	for i, option := range options {
+		name := strings.TrimSpace(option.Name)
+		if name == "" {
+			return fmt.Errorf("options[%d].name is required", i)
+		}
+		if len(name) > 50 {
+			return fmt.Errorf("options[%d].name cannot be longer than 50 characters", i)
+		}
+		if names[strings.ToLower(name)] {
+			return fmt.Errorf("option %s is used twice", name)
+		}
+		names[strings.ToLower(name)] = true
+
+		if len(option.Values) == 0 {
+			return fmt.Errorf("options[%d].values is required", i)
+		}
+		values := map[string]bool{}
+		for _, value := range option.Values {
+			value = strings.TrimSpace(value)
+			if value == "" {
+				return fmt.Errorf("options[%d].values cannot be empty", i)
+			}
+			if len(value) > 50 {
+				return fmt.Errorf("options[%d].values cannot be longer than 50 characters", i)
+			}
+			if values[strings.ToLower(value)] {
+				return fmt.Errorf("value %s of option %s is used twice", value, name)
+			}
+			values[strings.ToLower(value)] = true
+		}
+	}
+	return nil
+}
+
+func validateVariants(variants []VariantInput) error {
+	if len(variants) > MaxVariants {
+		return fmt.Errorf("a product can have at most %d variants", MaxVariants)
+	}
+	skus := map[string]bool{}
+	barcodes := map[string]bool{}
+	for i, variant := range variants {
+		sku := strings.TrimSpace(variant.SKU)
+		if sku == "" {
+			return fmt.Errorf("variants[%d].sku is required", i)
+		}
+		if skus[sku] {
+			return fmt.Errorf("variants[%d].sku %s is used twice", i, sku)
+		}
+		skus[sku] = true
+		if variant.ID != "" {
+			if _, err := uuid.Parse(variant.ID); err != nil {
+				return fmt.Errorf("variants[%d].id must be a valid UUID", i)
+			}
+		}
+		if variant.Stock < 0 {
+			return fmt.Errorf("variants[%d].stock cannot be negative", i)
+		}
+		if variant.PriceOverride != nil && *variant.PriceOverride <= 0 {
+			return fmt.Errorf("variants[%d].price_override must be greater than 0", i)
+		}
+		if barcode := strings.TrimSpace(variant.Barcode); barcode != "" {
+			if barcodes[barcode] {
+				return fmt.Errorf("variants[%d].barcode %s is used twice", i, barcode)
+			}
+			barcodes[barcode] = true
+		}
+		if err := validateImages(variant.Images); err != nil {
+			return fmt.Errorf("variants[%d]: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// ValidateGenerateVariants validates a variant matrix request.
+func ValidateGenerateVariants(req GenerateVariantsRequest) error {
+	if len(strings.TrimSpace(req.SKUPrefix)) > 32 {
+		return errors.New("sku_prefix cannot be longer than 32 characters")
+	}
+	if req.Stock < 0 {
+		return errors.New("stock cannot be negative")
+	}
+	if req.PriceOverride != nil && *req.PriceOverride <= 0 {
+		return errors.New("price_override must be greater than 0")
+	}
+	return nil
+}
+
+func validateImages(images []ImageInput) error {
+	primary := 0
+	for i, image := range images {
+		parsed, err := url.Parse(strings.TrimSpace(image.ImageURL))
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return fmt.Errorf("images[%d].image_url must be an http(s) URL", i)
+		}
+		if image.IsPrimary {
+			primary++
+		}
+	}
+	if primary > 1 {
+		return errors.New("only one image can be primary")
+	}
+	return nil
+}
+
+// ValidateTags checks tag names; they are created on first use.
+func ValidateTags(tags []string) error {
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if tag == "" {
+			return errors.New("tags cannot be empty")
+		}
+		if len(tag) > 50 {
+			return errors.New("tags cannot be longer than 50 characters")
+		}
+	}
+	return nil
+}
+
+// ValidateProductFilter validates the query of a product listing.
+func ValidateProductFilter(filter ProductFilter) error {
+	if filter.MinPrice != nil && *filter.MinPrice < 0 {
+		return errors.New("min_price cannot be negative")
+	}
+	if filter.MaxPrice != nil && *filter.MaxPrice < 0 {
+		return errors.New("max_price cannot be negative")
+	}
+	if filter.MinPrice != nil && filter.MaxPrice != nil && *filter.MinPrice > *filter.MaxPrice {
+		return errors.New("min_price cannot be greater than max_price")
+	}
+	if filter.CategoryID != nil {
+		if _, err := uuid.Parse(*filter.CategoryID); err != nil {
+			return errors.New("category_id must be a valid UUID")
+		}
+	}
+	for _, id := range filter.SubCatIDs {
+		if _, err := uuid.Parse(id); err != nil {
+			return fmt.Errorf("subcategory id %q must be a valid UUID", id)
+		}
+	}
+	if filter.MinRating != nil && (*filter.MinRating < 0 || *filter.MinRating > 5) {
+		return errors.New("min_rating must be between 0 and 5")
+	}
+	if filter.Sort != "" && !slices.Contains(ProductSorts, filter.Sort) {
+		return fmt.Errorf("sort must be one of %s", strings.Join(ProductSorts, ", "))
+	}
+	if filter.Page < 0 {
+		return errors.New("page cannot be negative")
+	}
+	if filter.PageSize < 0 || filter.PageSize > MaxPageSize {
+		return fmt.Errorf("page_size must be between 1 and %d", MaxPageSize)
+	}
+	return nil
+}
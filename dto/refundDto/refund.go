@@ -0,0 +1,52 @@
+package refundDto
+
+import "time"
+
+type RefundItemRequest struct {
+	OrderItemID string `json:"order_item_id"`
+	Quantity    int    `json:"quantity"` // 0 refunds whatever is left of the item
+}
+
+type CreateRefundRequest struct {
+	OrderID string              `json:"-"`
+	Reason  string              `json:"reason"`
+	Items   []RefundItemRequest `json:"items"`
+}
+
+type GetOrderRefundsRequest struct {
+	OrderID string `json:"order_id"`
+}
+
+type ListStoreRefundsRequest struct {
+	StoreID string `json:"-"`
+	Status  string `query:"status"`
+}
+
+type ReviewRefundRequest struct {
+	StoreID  string `json:"-"`
+	RefundID string `json:"-"`
+	Approve  bool   `json:"approve"`
+	Restock  bool   `json:"restock"` // put the returned units back on the shelf
+	Note     string `json:"note"`
+}
+
+type RefundItemResponse struct {
+	OrderItemID string  `json:"order_item_id"`
+	Quantity    int     `json:"quantity"`
+	Amount      float64 `json:"amount"`
+}
+
+type RefundResponse struct {
+	ID          string               `json:"id"`
+	OrderID     string               `json:"order_id"`
+	StoreID     string               `json:"store_id"`
+	PaymentID   string               `json:"payment_id"`
+	Amount      float64              `json:"amount"`
+	Reason      string               `json:"reason,omitempty"`
+	Status      string               `json:"status"`
+	ReviewNote  string               `json:"review_note,omitempty"`
+	Restocked   bool                 `json:"restocked"`
+	CreatedAt   time.Time            `json:"created_at"`
+	ProcessedAt *time.Time           `json:"processed_at,omitempty"`
+	Items       []RefundItemResponse `json:"items"`
+}
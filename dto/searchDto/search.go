@@ -0,0 +1,48 @@
+package searchDto
+
+import (
+	"time"
+
+	"github.com/abdulmalikraji/e-commerce/dto/productDto"
+)
+
+// MaxQueryLength bounds the search query, in characters.
+const MaxQueryLength = 200
+
+type SearchProductsRequest struct {
+	Query    string `query:"q"`
+	Page     int    `query:"page"`
+	PageSize int    `query:"page_size"`
+}
+
+// SearchResult is a matched product with its name and description fragments as HTML:
+// the text is escaped and matched words are wrapped in <mark> tags.
+type SearchResult struct {
+	productDto.ProductResponse
+	Highlight Highlight `json:"highlight"`
+}
+
+type Highlight struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+// SearchProductsResponse is one page of results; the handler turns the counts into
+// the response's page metadata.
+type SearchProductsResponse struct {
+	Results  []SearchResult
+	Total    int64
+	Page     int
+	PageSize int
+}
+
+type ZeroResultSearchesRequest struct {
+	Days  int `query:"days"`
+	Limit int `query:"limit"`
+}
+
+type ZeroResultSearch struct {
+	Query          string    `json:"query"`
+	Searches       int64     `json:"searches"`
+	LastSearchedAt time.Time `json:"last_searched_at"`
+}
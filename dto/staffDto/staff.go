@@ -0,0 +1,63 @@
+package staffDto
+
+import "time"
+
+type ListStaffRequest struct {
+	StoreID string `json:"-"`
+}
+
+type StaffMember struct {
+	UserID    string `json:"user_id"`
+	Email     string `json:"email"`
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+	RoleID    string `json:"role_id,omitempty"` // empty for the owner
+	Role      string `json:"role"`              // owner or the name of a store role
+	// Permissions are the actions the member may perform, from their role.
+	Permissions []string  `json:"permissions"`
+	JoinedAt    time.Time `json:"joined_at"`
+}
+
+type InviteStaffRequest struct {
+	StoreID string `json:"-"`
+	Email   string `json:"email"`
+	Role    string `json:"role"` // name of a store role, defaults to worker
+}
+
+// UpdateStaffRequest moves a member to another role.
+type UpdateStaffRequest struct {
+	StoreID string `json:"-"`
+	UserID  string `json:"-"`
+	Role    string `json:"role"` // name of a store role
+}
+
+type RemoveStaffRequest struct {
+	StoreID string `json:"-"`
+	UserID  string `json:"-"`
+}
+
+type ListInvitationsRequest struct {
+	StoreID string `json:"-"`
+	Status  string `query:"status"`
+}
+
+type RevokeInvitationRequest struct {
+	StoreID      string `json:"-"`
+	InvitationID string `json:"-"`
+}
+
+type AcceptInvitationRequest struct {
+	Token string `json:"token"`
+}
+
+type InvitationResponse struct {
+	ID         string     `json:"id"`
+	StoreID    string     `json:"store_id"`
+	Email      string     `json:"email"`
+	Role       string     `json:"role"`
+	Status     string     `json:"status"`
+	InvitedBy  string     `json:"invited_by"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	AcceptedAt *time.Time `json:"accepted_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
@@ -1,34 +1,120 @@
 package storeDto
 
+import "github.com/abdulmalikraji/e-commerce/utils"
+
+// CreateStoreRequest creates a store owned by the caller. Settings left out get their
+// defaults; currency_id and language_id are required.
 type CreateStoreRequest struct {
-	Name        string        `json:"name"`
-	OwnerID     string        `json:"owner_id"`
-	Description string        `json:"description"`
-	Settings    StoreSettings `json:"settings"`
+	Name        string             `json:"name"`
+	Description string             `json:"description"`
+	Settings    StoreSettingsPatch `json:"settings"`
+}
+
+// UpdateStoreRequest changes a store's details; nil fields are left as they are.
+type UpdateStoreRequest struct {
+	StoreID     string  `json:"-"`
+	Name        *string `json:"name"`
+	Description *string `json:"description"`
+}
+
+type GetStoreSettingsRequest struct {
+	StoreID string `json:"-"`
+}
+
+type UpdateStoreSettingsRequest struct {
+	StoreID string `json:"-"`
+	StoreSettingsPatch
+}
+
+type UpdateStoreImageRequest struct {
+	StoreID string `json:"-"`
+	Image   string `json:"image"` // URL of the logo, empty to remove it
+}
+
+// UploadStoreImageRequest replaces the store logo with an uploaded image.
+type UploadStoreImageRequest struct {
+	StoreID string     `json:"-"`
+	File    utils.File `json:"-"`
+}
+
+type DeleteStoreRequest struct {
+	StoreID string `json:"-"`
+}
+
+type RestoreStoreRequest struct {
+	StoreID string `json:"-"`
+}
+
+// StoreSettingsPatch changes store settings; nil fields are left as they are.
+type StoreSettingsPatch struct {
+	CurrencyID              *string           `json:"currency_id"`
+	LanguageID              *string           `json:"language_id"`
+	InventoryAlert          *bool             `json:"inventory_alert"`
+	InventoryAlertThreshold *int              `json:"inventory_alert_threshold"`
+	Tax                     *TaxSettingsPatch `json:"tax"`
+	ShippingOrigin          *ShippingOrigin   `json:"shipping_origin"` // replaces the whole address
+	ReturnPolicyDays        *int              `json:"return_policy_days"`
+	AutoAcceptOrders        *bool             `json:"auto_accept_orders"`
+}
+
+type TaxSettingsPatch struct {
+	Enabled          *bool    `json:"enabled"`
+	Rate             *float64 `json:"rate"`
+	IncludedInPrices *bool    `json:"included_in_prices"`
+	TaxID            *string  `json:"tax_id"`
+}
+
+type ShippingOrigin struct {
+	AddressLine1 string `json:"address_line1"`
+	AddressLine2 string `json:"address_line2,omitempty"`
+	City         string `json:"city"`
+	State        string `json:"state,omitempty"`
+	PostalCode   string `json:"postal_code"`
+	Country      string `json:"country"`
 }
 
 type StoreSettings struct {
-	CurrencyID     string `json:"currency_id"`
-	LanguageID     string `json:"language_id"`
-	InventoryAlert bool   `json:"inventory_alert"`
+	Version                 int            `json:"version"`
+	CurrencyID              string         `json:"currency_id"`
+	LanguageID              string         `json:"language_id"`
+	InventoryAlert          bool           `json:"inventory_alert"`
+	InventoryAlertThreshold int            `json:"inventory_alert_threshold"`
+	Tax                     TaxSettings    `json:"tax"`
+	ShippingOrigin          ShippingOrigin `json:"shipping_origin"`
+	ReturnPolicyDays        int            `json:"return_policy_days"`
+	AutoAcceptOrders        bool           `json:"auto_accept_orders"`
+}
+
+type TaxSettings struct {
+	Enabled          bool    `json:"enabled"`
+	Rate             float64 `json:"rate"`
+	IncludedInPrices bool    `json:"included_in_prices"`
+	TaxID            string  `json:"tax_id,omitempty"`
+}
+
+// PublicStoreSettings are the settings shown to anyone viewing the store; the rest are
+// only returned by the settings endpoint.
+type PublicStoreSettings struct {
+	CurrencyID string `json:"currency_id"`
+	LanguageID string `json:"language_id"`
 }
 
 type GetStoreByIDRequest struct {
-	StoreID string `json:"store_id"`
+	StoreID string `json:"-"`
 }
 
 type GetStoreByIDResponse struct {
-	ID          string `json:"id"`
-	Name        string `json:"name"`
-	Description string `json:"description"`
-	OwnerID     string `json:"owner_id"`
-	Image       string `json:"image,omitempty"`
-	Rating      string `json:"rating,omitempty"`
-	Settings    string `json:"settings"`
+	ID          string              `json:"id"`
+	Name        string              `json:"name"`
+	Description string              `json:"description"`
+	OwnerID     string              `json:"owner_id"`
+	Image       string              `json:"image,omitempty"`
+	Rating      string              `json:"rating,omitempty"`
+	Settings    PublicStoreSettings `json:"settings"`
 }
 
 type FindStoreRequest struct {
-	Name string `json:"name"`
+	Name string `query:"name"`
 }
 
 type FindStoreResponse struct {
@@ -45,7 +131,7 @@ type StoreSummary struct {
 }
 
 type GetStoreProductsRequest struct {
-	StoreID string `json:"store_id"`
+	StoreID string `json:"-"`
 }
 
 type GetStoreProductsResponse struct {
@@ -68,7 +154,7 @@ type StoreProducts struct {
 }
 
 type GetStoreByOwnerIDRequest struct {
-	OwnerID string `json:"owner_id"`
+	OwnerID string `json:"-"`
 }
 
 type GetStoreByOwnerIDResponse struct {
@@ -13,22 +13,62 @@ func ValidateCreateStore(req CreateStoreRequest) error {
 		return errors.New("name is required")
 	}
 
-	if _, err := uuid.Parse(req.OwnerID); err != nil {
-		return errors.New("owner_id must be a valid UUID")
+	if req.Settings.CurrencyID == nil || strings.TrimSpace(*req.Settings.CurrencyID) == "" {
+		return errors.New("settings.currency_id is required")
+	}
+	if req.Settings.LanguageID == nil || strings.TrimSpace(*req.Settings.LanguageID) == "" {
+		return errors.New("settings.language_id is required")
 	}
 
-	if strings.TrimSpace(req.Settings.CurrencyID) == "" {
-		return errors.New("settings.currency_id is required")
+	return ValidateSettings(req.Settings)
+}
+
+// ValidateUpdateStore validates the fields present in the UpdateStoreRequest.
+func ValidateUpdateStore(req UpdateStoreRequest) error {
+	if req.Name != nil && strings.TrimSpace(*req.Name) == "" {
+		return errors.New("name cannot be empty")
 	}
-	if _, err := uuid.Parse(req.Settings.CurrencyID); err != nil {
-		return errors.New("settings.currency_id must be a valid UUID")
+
+	return nil
+}
+
+// ValidateSettings checks the format and range of the settings present in the patch.
+// Whether the currency and language exist is checked by the service.
+func ValidateSettings(settings StoreSettingsPatch) error {
+	if settings.CurrencyID != nil {
+		if _, err := uuid.Parse(*settings.CurrencyID); err != nil {
+			return errors.New("settings.currency_id must be a valid UUID")
+		}
+	}
+	if settings.LanguageID != nil {
+		if _, err := uuid.Parse(*settings.LanguageID); err != nil {
+			return errors.New("settings.language_id must be a valid UUID")
+		}
 	}
 
-	if strings.TrimSpace(req.Settings.LanguageID) == "" {
-		return errors.New("settings.language_id is required")
+	if settings.InventoryAlertThreshold != nil && *settings.InventoryAlertThreshold < 0 {
+		return errors.New("settings.inventory_alert_threshold cannot be negative")
 	}
-	if _, err := uuid.Parse(req.Settings.LanguageID); err != nil {
-		return errors.New("settings.language_id must be a valid UUID")
+	if settings.ReturnPolicyDays != nil && (*settings.ReturnPolicyDays < 0 || *settings.ReturnPolicyDays > 365) {
+		return errors.New("settings.return_policy_days must be between 0 and 365")
+	}
+	if settings.Tax != nil && settings.Tax.Rate != nil && (*settings.Tax.Rate < 0 || *settings.Tax.Rate > 100) {
+		return errors.New("settings.tax.rate must be between 0 and 100")
+	}
+
+	if origin := settings.ShippingOrigin; origin != nil {
+		if strings.TrimSpace(origin.AddressLine1) == "" {
+			return errors.New("settings.shipping_origin.address_line1 is required")
+		}
+		if strings.TrimSpace(origin.City) == "" {
+			return errors.New("settings.shipping_origin.city is required")
+		}
+		if strings.TrimSpace(origin.PostalCode) == "" {
+			return errors.New("settings.shipping_origin.postal_code is required")
+		}
+		if len(origin.Country) != 2 {
+			return errors.New("settings.shipping_origin.country must be an ISO 3166-1 alpha-2 code")
+		}
 	}
 
 	return nil
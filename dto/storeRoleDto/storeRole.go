@@ -0,0 +1,37 @@
+package storeRoleDto
+
+import "time"
+
+type ListRolesRequest struct {
+	StoreID string `json:"-"`
+}
+
+type CreateRoleRequest struct {
+	StoreID     string   `json:"-"`
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions"` // models.StoreActions
+}
+
+// UpdateRoleRequest renames a role and/or replaces its permissions; nil fields are left as they are.
+type UpdateRoleRequest struct {
+	StoreID     string    `json:"-"`
+	RoleID      string    `json:"-"`
+	Name        *string   `json:"name"`
+	Description *string   `json:"description"`
+	Permissions *[]string `json:"permissions"`
+}
+
+type DeleteRoleRequest struct {
+	StoreID string `json:"-"`
+	RoleID  string `json:"-"`
+}
+
+type RoleResponse struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	System      bool      `json:"system"`
+	Permissions []string  `json:"permissions"`
+	CreatedAt   time.Time `json:"created_at"`
+}
@@ -0,0 +1,77 @@
+package taxonomyDto
+
+// CategoryNode is a category with its subtree.
+type CategoryNode struct {
+	ID          string         `json:"id"`
+	Name        string         `json:"name"`
+	Description string         `json:"description,omitempty"`
+	ParentID    string         `json:"parent_id,omitempty"`
+	Depth       int            `json:"depth"`
+	Children    []CategoryNode `json:"children"`
+}
+
+type GetSubtreeRequest struct {
+	CategoryID string `json:"-"`
+}
+
+type GetBreadcrumbsRequest struct {
+	ProductID string `json:"-"`
+}
+
+type Breadcrumb struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// BreadcrumbsResponse lists the product's main category and its ancestors, root first.
+type BreadcrumbsResponse struct {
+	ProductID   string       `json:"product_id"`
+	ProductName string       `json:"product_name"`
+	Categories  []Breadcrumb `json:"categories"`
+}
+
+// MoveCategoryRequest moves a category under ParentID, or to the root when it is nil.
+type MoveCategoryRequest struct {
+	CategoryID string  `json:"-"`
+	ParentID   *string `json:"parent_id"`
+}
+
+// CreateCategoryRequest adds a category under ParentID, or a root category when it is nil.
+type CreateCategoryRequest struct {
+	Name        string  `json:"name"`
+	Description string  `json:"description"`
+	ParentID    *string `json:"parent_id"`
+}
+
+// UpdateCategoryRequest renames or describes a category; nil fields are left as they
+// are. Use MoveCategoryRequest to change its parent.
+type UpdateCategoryRequest struct {
+	CategoryID  string  `json:"-"`
+	Name        *string `json:"name"`
+	Description *string `json:"description"`
+}
+
+type DeleteCategoryRequest struct {
+	CategoryID string `json:"-"`
+}
+
+type TagResponse struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// MaxRecategorizeProducts bounds the products of one RecategorizeRequest.
+const MaxRecategorizeProducts = 500
+
+// RecategorizeRequest sets the main category and subcategories of every listed product.
+// Tags, when given, replace the products' tags as well.
+type RecategorizeRequest struct {
+	ProductIDs     []string  `json:"product_ids"`
+	CategoryID     string    `json:"category_id"`
+	SubCategoryIDs []string  `json:"subcategory_ids"`
+	Tags           *[]string `json:"tags"`
+}
+
+type RecategorizeResponse struct {
+	Updated int `json:"updated"`
+}
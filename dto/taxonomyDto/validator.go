@@ -0,0 +1,75 @@
+package taxonomyDto
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/abdulmalikraji/e-commerce/dto/productDto"
+	"github.com/google/uuid"
+)
+
+// ValidateCreateCategory validates the CreateCategoryRequest fields. Whether the parent
+// exists is checked by the service.
+func ValidateCreateCategory(req CreateCategoryRequest) error {
+	if err := validateCategoryName(req.Name); err != nil {
+		return err
+	}
+	if req.ParentID != nil {
+		if _, err := uuid.Parse(*req.ParentID); err != nil {
+			return errors.New("parent_id must be a valid UUID")
+		}
+	}
+	return nil
+}
+
+func ValidateUpdateCategory(req UpdateCategoryRequest) error {
+	if req.Name != nil {
+		return validateCategoryName(*req.Name)
+	}
+	return nil
+}
+
+// ValidateRecategorize validates the RecategorizeRequest fields. Whether the products
+// and categories exist is checked by the service.
+func ValidateRecategorize(req RecategorizeRequest) error {
+	if len(req.ProductIDs) == 0 {
+		return errors.New("product_ids is required")
+	}
+	if len(req.ProductIDs) > MaxRecategorizeProducts {
+		return fmt.Errorf("at most %d products can be recategorized at once", MaxRecategorizeProducts)
+	}
+	seen := map[string]bool{}
+	for _, id := range req.ProductIDs {
+		if _, err := uuid.Parse(id); err != nil {
+			return fmt.Errorf("product id %q must be a valid UUID", id)
+		}
+		if seen[id] {
+			return fmt.Errorf("product %s is listed twice", id)
+		}
+		seen[id] = true
+	}
+	if _, err := uuid.Parse(req.CategoryID); err != nil {
+		return errors.New("category_id must be a valid UUID")
+	}
+	for _, id := range req.SubCategoryIDs {
+		if _, err := uuid.Parse(id); err != nil {
+			return fmt.Errorf("subcategory id %q must be a valid UUID", id)
+		}
+	}
+	if req.Tags != nil {
+		return productDto.ValidateTags(*req.Tags)
+	}
+	return nil
+}
+
+func validateCategoryName(name string) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return errors.New("name is required")
+	}
+	if len(name) > 100 {
+		return errors.New("name cannot be longer than 100 characters")
+	}
+	return nil
+}
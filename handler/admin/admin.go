@@ -0,0 +1,37 @@
+package admin
+
+import (
+	"github.com/abdulmalikraji/e-commerce/dto/adminDto"
+	"github.com/abdulmalikraji/e-commerce/services"
+	"github.com/abdulmalikraji/e-commerce/utils/genericResponse"
+	"github.com/gofiber/fiber/v2"
+)
+
+type AdminHandler interface {
+	UpdateUserRole(ctx *fiber.Ctx) error
+}
+
+type adminHandler struct {
+	service services.AdminService
+}
+
+func New(service services.AdminService) AdminHandler {
+	return adminHandler{
+		service: service,
+	}
+}
+
+func (c adminHandler) UpdateUserRole(ctx *fiber.Ctx) error {
+	var request adminDto.UpdateUserRoleRequest
+	if err := ctx.BodyParser(&request); err != nil {
+		return genericResponse.ErrorResponse(ctx, fiber.StatusBadRequest, err.Error())
+	}
+	request.UserID = ctx.Params("id")
+
+	response, status, err := c.service.UpdateUserRole(ctx, request)
+	if err != nil {
+		return genericResponse.ErrorResponse(ctx, status, err.Error())
+	}
+
+	return genericResponse.SuccessResponse(ctx, status, response, "User role updated successfully")
+}
@@ -0,0 +1,86 @@
+package cart
+
+import (
+	"github.com/abdulmalikraji/e-commerce/dto/cartDto"
+	"github.com/abdulmalikraji/e-commerce/services"
+	"github.com/abdulmalikraji/e-commerce/utils/genericResponse"
+	"github.com/gofiber/fiber/v2"
+)
+
+type CartHandler interface {
+	GetCart(ctx *fiber.Ctx) error
+	AddItem(ctx *fiber.Ctx) error
+	UpdateItem(ctx *fiber.Ctx) error
+	RemoveItem(ctx *fiber.Ctx) error
+	ClearCart(ctx *fiber.Ctx) error
+}
+
+type cartHandler struct {
+	service services.CartService
+}
+
+func New(service services.CartService) CartHandler {
+	return cartHandler{
+		service: service,
+	}
+}
+
+func (c cartHandler) GetCart(ctx *fiber.Ctx) error {
+	response, status, err := c.service.GetCart(ctx)
+	if err != nil {
+		return genericResponse.ErrorResponse(ctx, status, err.Error())
+	}
+
+	return genericResponse.SuccessResponse(ctx, status, response, "Cart retrieved successfully")
+}
+
+func (c cartHandler) AddItem(ctx *fiber.Ctx) error {
+	var request cartDto.AddCartItemRequest
+	if err := ctx.BodyParser(&request); err != nil {
+		return genericResponse.ErrorResponse(ctx, fiber.StatusBadRequest, err.Error())
+	}
+
+	response, status, err := c.service.AddItem(ctx, request)
+	if err != nil {
+		return genericResponse.ErrorResponse(ctx, status, err.Error())
+	}
+
+	return genericResponse.SuccessResponse(ctx, status, response, "Item added to cart successfully")
+}
+
+func (c cartHandler) UpdateItem(ctx *fiber.Ctx) error {
+	var request cartDto.UpdateCartItemRequest
+	if err := ctx.BodyParser(&request); err != nil {
+		return genericResponse.ErrorResponse(ctx, fiber.StatusBadRequest, err.Error())
+	}
+	request.ItemID = ctx.Params("item_id")
+
+	response, status, err := c.service.UpdateItem(ctx, request)
+	if err != nil {
+		return genericResponse.ErrorResponse(ctx, status, err.Error())
+	}
+
+	return genericResponse.SuccessResponse(ctx, status, response, "Cart item updated successfully")
+}
+
+func (c cartHandler) RemoveItem(ctx *fiber.Ctx) error {
+	request := cartDto.RemoveCartItemRequest{
+		ItemID: ctx.Params("item_id"),
+	}
+
+	response, status, err := c.service.RemoveItem(ctx, request)
+	if err != nil {
+		return genericResponse.ErrorResponse(ctx, status, err.Error())
+	}
+
+	return genericResponse.SuccessResponse(ctx, status, response, "Cart item removed successfully")
+}
+
+func (c cartHandler) ClearCart(ctx *fiber.Ctx) error {
+	response, status, err := c.service.ClearCart(ctx)
+	if err != nil {
+		return genericResponse.ErrorResponse(ctx, status, err.Error())
+	}
+
+	return genericResponse.SuccessResponse(ctx, status, response, "Cart cleared successfully")
+}
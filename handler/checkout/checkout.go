@@ -0,0 +1,62 @@
+package checkout
+
+import (
+	"errors"
+
+	"github.com/abdulmalikraji/e-commerce/dto/checkoutDto"
+	"github.com/abdulmalikraji/e-commerce/services"
+	"github.com/abdulmalikraji/e-commerce/utils/genericResponse"
+	"github.com/gofiber/fiber/v2"
+)
+
+type CheckoutHandler interface {
+	PreviewCheckout(ctx *fiber.Ctx) error
+	Checkout(ctx *fiber.Ctx) error
+}
+
+type checkoutHandler struct {
+	service services.CheckoutService
+}
+
+func New(service services.CheckoutService) CheckoutHandler {
+	return checkoutHandler{
+		service: service,
+	}
+}
+
+func (c checkoutHandler) PreviewCheckout(ctx *fiber.Ctx) error {
+	var request checkoutDto.CheckoutPreviewRequest
+	if err := ctx.QueryParser(&request); err != nil {
+		return genericResponse.ErrorResponse(ctx, fiber.StatusBadRequest, err.Error())
+	}
+
+	response, status, err := c.service.PreviewCheckout(ctx, request)
+	if err != nil {
+		return checkoutError(ctx, status, err)
+	}
+
+	return genericResponse.SuccessResponse(ctx, status, response, "Checkout preview retrieved successfully")
+}
+
+func (c checkoutHandler) Checkout(ctx *fiber.Ctx) error {
+	var request checkoutDto.CheckoutRequest
+	if err := ctx.BodyParser(&request); err != nil {
+		return genericResponse.ErrorResponse(ctx, fiber.StatusBadRequest, err.Error())
+	}
+
+	response, status, err := c.service.Checkout(ctx, request)
+	if err != nil {
+		return checkoutError(ctx, status, err)
+	}
+
+	return genericResponse.SuccessResponse(ctx, status, response, "Order placed successfully")
+}
+
+// checkoutError reports a failed checkout, attaching the reason when a coupon was rejected.
+func checkoutError(ctx *fiber.Ctx, status int, err error) error {
+	var rejection services.CouponRejection
+	if errors.As(err, &rejection) {
+		return genericResponse.ErrorResponse(ctx, status, rejection.Message, rejection)
+	}
+	return genericResponse.ErrorResponse(ctx, status, err.Error())
+}
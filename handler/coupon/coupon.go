@@ -0,0 +1,35 @@
+package coupon
+
+import (
+	"github.com/abdulmalikraji/e-commerce/dto/couponDto"
+	"github.com/abdulmalikraji/e-commerce/services"
+	"github.com/abdulmalikraji/e-commerce/utils/genericResponse"
+	"github.com/gofiber/fiber/v2"
+)
+
+type CouponHandler interface {
+	ValidateCoupon(ctx *fiber.Ctx) error
+}
+
+type couponHandler struct {
+	service services.CouponService
+}
+
+func New(service services.CouponService) CouponHandler {
+	return couponHandler{
+		service: service,
+	}
+}
+
+func (c couponHandler) ValidateCoupon(ctx *fiber.Ctx) error {
+	request := couponDto.ValidateCouponRequest{
+		Code: ctx.Params("code"),
+	}
+
+	response, status, err := c.service.ValidateCoupon(ctx, request)
+	if err != nil {
+		return genericResponse.ErrorResponse(ctx, status, err.Error())
+	}
+
+	return genericResponse.SuccessResponse(ctx, status, response, "Coupon checked successfully")
+}
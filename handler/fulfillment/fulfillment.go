@@ -0,0 +1,69 @@
+package fulfillment
+
+import (
+	"github.com/abdulmalikraji/e-commerce/dto/fulfillmentDto"
+	"github.com/abdulmalikraji/e-commerce/services"
+	"github.com/abdulmalikraji/e-commerce/utils/genericResponse"
+	"github.com/gofiber/fiber/v2"
+)
+
+type FulfillmentHandler interface {
+	ListStoreFulfillments(ctx *fiber.Ctx) error
+	GetFulfillment(ctx *fiber.Ctx) error
+	UpdateFulfillment(ctx *fiber.Ctx) error
+}
+
+type fulfillmentHandler struct {
+	service services.FulfillmentService
+}
+
+func New(service services.FulfillmentService) FulfillmentHandler {
+	return fulfillmentHandler{
+		service: service,
+	}
+}
+
+func (c fulfillmentHandler) ListStoreFulfillments(ctx *fiber.Ctx) error {
+	var request fulfillmentDto.ListFulfillmentsRequest
+	if err := ctx.QueryParser(&request); err != nil {
+		return genericResponse.ErrorResponse(ctx, fiber.StatusBadRequest, err.Error())
+	}
+	request.StoreID = ctx.Params("store_id")
+
+	response, status, err := c.service.ListStoreFulfillments(ctx, request)
+	if err != nil {
+		return genericResponse.ErrorResponse(ctx, status, err.Error())
+	}
+
+	return genericResponse.SuccessResponse(ctx, status, response, "Fulfillments retrieved successfully")
+}
+
+func (c fulfillmentHandler) GetFulfillment(ctx *fiber.Ctx) error {
+	request := fulfillmentDto.GetFulfillmentRequest{
+		StoreID:       ctx.Params("store_id"),
+		FulfillmentID: ctx.Params("id"),
+	}
+
+	response, status, err := c.service.GetFulfillment(ctx, request)
+	if err != nil {
+		return genericResponse.ErrorResponse(ctx, status, err.Error())
+	}
+
+	return genericResponse.SuccessResponse(ctx, status, response, "Fulfillment retrieved successfully")
+}
+
+func (c fulfillmentHandler) UpdateFulfillment(ctx *fiber.Ctx) error {
+	var request fulfillmentDto.UpdateFulfillmentRequest
+	if err := ctx.BodyParser(&request); err != nil {
+		return genericResponse.ErrorResponse(ctx, fiber.StatusBadRequest, err.Error())
+	}
+	request.StoreID = ctx.Params("store_id")
+	request.FulfillmentID = ctx.Params("id")
+
+	response, status, err := c.service.UpdateFulfillment(ctx, request)
+	if err != nil {
+		return genericResponse.ErrorResponse(ctx, status, err.Error())
+	}
+
+	return genericResponse.SuccessResponse(ctx, status, response, "Fulfillment updated successfully")
+}
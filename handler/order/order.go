@@ -0,0 +1,51 @@
+package order
+
+import (
+	"github.com/abdulmalikraji/e-commerce/dto/orderDto"
+	"github.com/abdulmalikraji/e-commerce/services"
+	"github.com/abdulmalikraji/e-commerce/utils/genericResponse"
+	"github.com/gofiber/fiber/v2"
+)
+
+type OrderHandler interface {
+	UpdateOrderStatus(ctx *fiber.Ctx) error
+	GetOrderHistory(ctx *fiber.Ctx) error
+}
+
+type orderHandler struct {
+	service services.OrderService
+}
+
+func New(service services.OrderService) OrderHandler {
+	return orderHandler{
+		service: service,
+	}
+}
+
+func (c orderHandler) UpdateOrderStatus(ctx *fiber.Ctx) error {
+	var request orderDto.UpdateOrderStatusRequest
+	if err := ctx.BodyParser(&request); err != nil {
+		return genericResponse.ErrorResponse(ctx, fiber.StatusBadRequest, err.Error())
+	}
+	request.OrderID = ctx.Params("id")
+
+	response, status, err := c.service.UpdateOrderStatus(ctx, request)
+	if err != nil {
+		return genericResponse.ErrorResponse(ctx, status, err.Error())
+	}
+
+	return genericResponse.SuccessResponse(ctx, status, response, "Order status updated successfully")
+}
+
+func (c orderHandler) GetOrderHistory(ctx *fiber.Ctx) error {
+	request := orderDto.GetOrderHistoryRequest{
+		OrderID: ctx.Params("id"),
+	}
+
+	response, status, err := c.service.GetOrderHistory(ctx, request)
+	if err != nil {
+		return genericResponse.ErrorResponse(ctx, status, err.Error())
+	}
+
+	return genericResponse.SuccessResponse(ctx, status, response, "Order history retrieved successfully")
+}
@@ -0,0 +1,97 @@
+package payment
+
+import (
+	"github.com/abdulmalikraji/e-commerce/dto/paymentDto"
+	"github.com/abdulmalikraji/e-commerce/payments"
+	"github.com/abdulmalikraji/e-commerce/services"
+	"github.com/abdulmalikraji/e-commerce/utils/genericResponse"
+	"github.com/gofiber/fiber/v2"
+)
+
+type PaymentHandler interface {
+	CreatePayment(ctx *fiber.Ctx) error
+	CapturePayment(ctx *fiber.Ctx) error
+	CancelPayment(ctx *fiber.Ctx) error
+	GetOrderPayments(ctx *fiber.Ctx) error
+	HandleWebhook(ctx *fiber.Ctx) error
+}
+
+type paymentHandler struct {
+	service services.PaymentService
+}
+
+func New(service services.PaymentService) PaymentHandler {
+	return paymentHandler{
+		service: service,
+	}
+}
+
+func (c paymentHandler) CreatePayment(ctx *fiber.Ctx) error {
+	var request paymentDto.CreatePaymentRequest
+	if err := ctx.BodyParser(&request); err != nil {
+		return genericResponse.ErrorResponse(ctx, fiber.StatusBadRequest, err.Error())
+	}
+	request.OrderID = ctx.Params("id")
+
+	response, status, err := c.service.CreatePayment(ctx, request)
+	if err != nil {
+		return genericResponse.ErrorResponse(ctx, status, err.Error())
+	}
+
+	return genericResponse.SuccessResponse(ctx, status, response, "Payment created successfully")
+}
+
+func (c paymentHandler) CapturePayment(ctx *fiber.Ctx) error {
+	request := paymentDto.PaymentActionRequest{
+		PaymentID: ctx.Params("id"),
+	}
+
+	response, status, err := c.service.CapturePayment(ctx, request)
+	if err != nil {
+		return genericResponse.ErrorResponse(ctx, status, err.Error())
+	}
+
+	return genericResponse.SuccessResponse(ctx, status, response, "Payment captured successfully")
+}
+
+func (c paymentHandler) CancelPayment(ctx *fiber.Ctx) error {
+	request := paymentDto.PaymentActionRequest{
+		PaymentID: ctx.Params("id"),
+	}
+
+	response, status, err := c.service.CancelPayment(ctx, request)
+	if err != nil {
+		return genericResponse.ErrorResponse(ctx, status, err.Error())
+	}
+
+	return genericResponse.SuccessResponse(ctx, status, response, "Payment cancelled successfully")
+}
+
+func (c paymentHandler) GetOrderPayments(ctx *fiber.Ctx) error {
+	request := paymentDto.GetOrderPaymentsRequest{
+		OrderID: ctx.Params("id"),
+	}
+
+	response, status, err := c.service.GetOrderPayments(ctx, request)
+	if err != nil {
+		return genericResponse.ErrorResponse(ctx, status, err.Error())
+	}
+
+	return genericResponse.SuccessResponse(ctx, status, response, "Payments retrieved successfully")
+}
+
+func (c paymentHandler) HandleWebhook(ctx *fiber.Ctx) error {
+	request := paymentDto.WebhookRequest{
+		Provider:  ctx.Params("provider"),
+		Signature: ctx.Get(payments.SignatureHeader),
+		// The body buffer is reused by fiber once the handler returns.
+		Payload: append([]byte(nil), ctx.Body()...),
+	}
+
+	response, status, err := c.service.HandleWebhook(ctx, request)
+	if err != nil {
+		return genericResponse.ErrorResponse(ctx, status, err.Error())
+	}
+
+	return genericResponse.SuccessResponse(ctx, status, response, "Webhook processed successfully")
+}
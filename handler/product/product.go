@@ -0,0 +1,119 @@
+package product
+
+import (
+	"github.com/abdulmalikraji/e-commerce/dto/productDto"
+	"github.com/abdulmalikraji/e-commerce/services"
+	"github.com/abdulmalikraji/e-commerce/utils/genericResponse"
+	"github.com/gofiber/fiber/v2"
+)
+
+type ProductHandler interface {
+	ListProducts(ctx *fiber.Ctx) error
+	CreateProduct(ctx *fiber.Ctx) error
+	GetStoreProduct(ctx *fiber.Ctx) error
+	UpdateProduct(ctx *fiber.Ctx) error
+	DeleteProduct(ctx *fiber.Ctx) error
+	GenerateVariants(ctx *fiber.Ctx) error
+}
+
+type productHandler struct {
+	service services.ProductService
+}
+
+func New(service services.ProductService) ProductHandler {
+	return productHandler{
+		service: service,
+	}
+}
+
+func (c productHandler) ListProducts(ctx *fiber.Ctx) error {
+	var filter productDto.ProductFilter
+	if err := ctx.QueryParser(&filter); err != nil {
+		return genericResponse.ErrorResponse(ctx, fiber.StatusBadRequest, err.Error())
+	}
+
+	response, status, err := c.service.ListProducts(ctx, filter)
+	if err != nil {
+		return genericResponse.ErrorResponse(ctx, status, err.Error())
+	}
+
+	meta := genericResponse.NewPageMeta(response.Page, response.PageSize, response.Total)
+	return genericResponse.PaginatedResponse(ctx, status, response.Products, meta, "Products retrieved successfully")
+}
+
+func (c productHandler) CreateProduct(ctx *fiber.Ctx) error {
+	var request productDto.CreateProductRequest
+	if err := ctx.BodyParser(&request); err != nil {
+		return genericResponse.ErrorResponse(ctx, fiber.StatusBadRequest, err.Error())
+	}
+	request.StoreID = ctx.Params("store_id")
+
+	response, status, err := c.service.CreateProduct(ctx, request)
+	if err != nil {
+		return genericResponse.ErrorResponse(ctx, status, err.Error())
+	}
+
+	return genericResponse.SuccessResponse(ctx, status, response, "Product created successfully")
+}
+
+func (c productHandler) GetStoreProduct(ctx *fiber.Ctx) error {
+	request := productDto.GetStoreProductRequest{
+		StoreID:   ctx.Params("store_id"),
+		ProductID: ctx.Params("id"),
+	}
+
+	response, status, err := c.service.GetStoreProduct(ctx, request)
+	if err != nil {
+		return genericResponse.ErrorResponse(ctx, status, err.Error())
+	}
+
+	return genericResponse.SuccessResponse(ctx, status, response, "Product retrieved successfully")
+}
+
+func (c productHandler) UpdateProduct(ctx *fiber.Ctx) error {
+	var request productDto.UpdateProductRequest
+	if err := ctx.BodyParser(&request); err != nil {
+		return genericResponse.ErrorResponse(ctx, fiber.StatusBadRequest, err.Error())
+	}
+	request.StoreID = ctx.Params("store_id")
+	request.ProductID = ctx.Params("id")
+
+	response, status, err := c.service.UpdateProduct(ctx, request)
+	if err != nil {
+		return genericResponse.ErrorResponse(ctx, status, err.Error())
+	}
+
+	return genericResponse.SuccessResponse(ctx, status, response, "Product updated successfully")
+}
+
+func (c productHandler) DeleteProduct(ctx *fiber.Ctx) error {
+	request := productDto.DeleteProductRequest{
+		StoreID:   ctx.Params("store_id"),
+		ProductID: ctx.Params("id"),
+	}
+
+	status, err := c.service.DeleteProduct(ctx, request)
+	if err != nil {
+		return genericResponse.ErrorResponse(ctx, status, err.Error())
+	}
+
+	return genericResponse.SuccessResponse(ctx, status, nil, "Product deleted successfully")
+}
+
+// GenerateVariants previews, or with "create" adds, the variants for every combination
+// of the product's option values.
+func (c productHandler) GenerateVariants(ctx *fiber.Ctx) error {
+	var request productDto.GenerateVariantsRequest
+	if err := ctx.BodyParser(&request); err != nil {
+		return genericResponse.ErrorResponse(ctx, fiber.StatusBadRequest, err.Error())
+	}
+	request.StoreID = ctx.Params("store_id")
+	request.ProductID = ctx.Params("id")
+
+	response, status, err := c.service.GenerateVariants(ctx, request)
+	if err != nil {
+		return genericResponse.ErrorResponse(ctx, status, err.Error())
+	}
+
+	return genericResponse.SuccessResponse(ctx, status, response, "Variant matrix generated successfully")
+}
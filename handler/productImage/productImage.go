@@ -0,0 +1,95 @@
+package productImage
+
+import (
+	"github.com/abdulmalikraji/e-commerce/dto/productDto"
+	"github.com/abdulmalikraji/e-commerce/services"
+	"github.com/abdulmalikraji/e-commerce/utils"
+	"github.com/abdulmalikraji/e-commerce/utils/genericResponse"
+	"github.com/gofiber/fiber/v2"
+)
+
+type ProductImageHandler interface {
+	UploadImage(ctx *fiber.Ctx) error
+	SetPrimaryImage(ctx *fiber.Ctx) error
+	ReorderImages(ctx *fiber.Ctx) error
+	DeleteImage(ctx *fiber.Ctx) error
+}
+
+type productImageHandler struct {
+	service services.ProductImageService
+}
+
+func New(service services.ProductImageService) ProductImageHandler {
+	return productImageHandler{
+		service: service,
+	}
+}
+
+// UploadImage reads a multipart form with the file in "image" and an optional
+// "is_primary" field.
+func (c productImageHandler) UploadImage(ctx *fiber.Ctx) error {
+	var request productDto.UploadImageRequest
+	if err := ctx.BodyParser(&request); err != nil {
+		return genericResponse.ErrorResponse(ctx, fiber.StatusBadRequest, err.Error())
+	}
+	file, err := utils.FormFile(ctx, "image", utils.MaxImageSize)
+	if err != nil {
+		return genericResponse.ErrorResponse(ctx, fiber.StatusBadRequest, err.Error())
+	}
+	request.File = file
+	request.StoreID = ctx.Params("store_id")
+	request.ProductID = ctx.Params("id")
+
+	response, status, err := c.service.UploadImage(ctx, request)
+	if err != nil {
+		return genericResponse.ErrorResponse(ctx, status, err.Error())
+	}
+
+	return genericResponse.SuccessResponse(ctx, status, response, "Image uploaded successfully")
+}
+
+func (c productImageHandler) SetPrimaryImage(ctx *fiber.Ctx) error {
+	request := productDto.ProductImageRequest{
+		StoreID:   ctx.Params("store_id"),
+		ProductID: ctx.Params("id"),
+		ImageID:   ctx.Params("image_id"),
+	}
+
+	response, status, err := c.service.SetPrimaryImage(ctx, request)
+	if err != nil {
+		return genericResponse.ErrorResponse(ctx, status, err.Error())
+	}
+
+	return genericResponse.SuccessResponse(ctx, status, response, "Primary image updated successfully")
+}
+
+func (c productImageHandler) ReorderImages(ctx *fiber.Ctx) error {
+	var request productDto.ReorderImagesRequest
+	if err := ctx.BodyParser(&request); err != nil {
+		return genericResponse.ErrorResponse(ctx, fiber.StatusBadRequest, err.Error())
+	}
+	request.StoreID = ctx.Params("store_id")
+	request.ProductID = ctx.Params("id")
+
+	response, status, err := c.service.ReorderImages(ctx, request)
+	if err != nil {
+		return genericResponse.ErrorResponse(ctx, status, err.Error())
+	}
+
+	return genericResponse.SuccessResponse(ctx, status, response, "Images reordered successfully")
+}
+
+func (c productImageHandler) DeleteImage(ctx *fiber.Ctx) error {
+	request := productDto.ProductImageRequest{
+		StoreID:   ctx.Params("store_id"),
+		ProductID: ctx.Params("id"),
+		ImageID:   ctx.Params("image_id"),
+	}
+
+	status, err := c.service.DeleteImage(ctx, request)
+	if err != nil {
+		return genericResponse.ErrorResponse(ctx, status, err.Error())
+	}
+
+	return genericResponse.SuccessResponse(ctx, status, nil, "Image deleted successfully")
+}
@@ -0,0 +1,90 @@
+package productImport
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/abdulmalikraji/e-commerce/dto/importDto"
+	"github.com/abdulmalikraji/e-commerce/services"
+	"github.com/abdulmalikraji/e-commerce/utils"
+	"github.com/abdulmalikraji/e-commerce/utils/genericResponse"
+	"github.com/gofiber/fiber/v2"
+)
+
+type ProductImportHandler interface {
+	StartImport(ctx *fiber.Ctx) error
+	GetImportJob(ctx *fiber.Ctx) error
+	ExportProducts(ctx *fiber.Ctx) error
+}
+
+type productImportHandler struct {
+	service services.ProductImportService
+}
+
+func New(service services.ProductImportService) ProductImportHandler {
+	return productImportHandler{
+		service: service,
+	}
+}
+
+// StartImport reads a multipart form with the file in "file"; ?format= and ?dry_run=
+// are read from the query string.
+func (c productImportHandler) StartImport(ctx *fiber.Ctx) error {
+	var request importDto.StartImportRequest
+	if err := ctx.QueryParser(&request); err != nil {
+		return genericResponse.ErrorResponse(ctx, fiber.StatusBadRequest, err.Error())
+	}
+	file, err := utils.FormFile(ctx, "file", importDto.MaxImportSize)
+	if err != nil {
+		return genericResponse.ErrorResponse(ctx, fiber.StatusBadRequest, err.Error())
+	}
+	request.File = file
+	request.StoreID = ctx.Params("store_id")
+
+	response, status, err := c.service.StartImport(ctx, request)
+	if err != nil {
+		return genericResponse.ErrorResponse(ctx, status, err.Error())
+	}
+
+	return genericResponse.SuccessResponse(ctx, status, response, "Import queued successfully")
+}
+
+func (c productImportHandler) GetImportJob(ctx *fiber.Ctx) error {
+	request := importDto.GetImportJobRequest{
+		StoreID: ctx.Params("store_id"),
+		JobID:   ctx.Params("job_id"),
+	}
+
+	response, status, err := c.service.GetImportJob(ctx, request)
+	if err != nil {
+		return genericResponse.ErrorResponse(ctx, status, err.Error())
+	}
+
+	return genericResponse.SuccessResponse(ctx, status, response, "Import job retrieved successfully")
+}
+
+// ExportProducts streams the catalog as a file download instead of a JSON response.
+func (c productImportHandler) ExportProducts(ctx *fiber.Ctx) error {
+	var request importDto.ExportRequest
+	if err := ctx.QueryParser(&request); err != nil {
+		return genericResponse.ErrorResponse(ctx, fiber.StatusBadRequest, err.Error())
+	}
+	request.StoreID = ctx.Params("store_id")
+
+	response, status, err := c.service.ExportProducts(ctx, request)
+	if err != nil {
+		return genericResponse.ErrorResponse(ctx, status, err.Error())
+	}
+
+	ctx.Set(fiber.HeaderContentType, response.ContentType)
+	ctx.Set(fiber.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", response.FileName))
+	// A failed export closes the pipe with its error, which makes fasthttp drop the
+	// connection instead of ending the chunked body, so a cut-off file is not taken for
+	// a complete one.
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(response.Write(pw))
+	}()
+	ctx.Context().SetBodyStream(pr, -1)
+	return nil
+}
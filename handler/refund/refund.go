@@ -0,0 +1,84 @@
+package refund
+
+import (
+	"github.com/abdulmalikraji/e-commerce/dto/refundDto"
+	"github.com/abdulmalikraji/e-commerce/services"
+	"github.com/abdulmalikraji/e-commerce/utils/genericResponse"
+	"github.com/gofiber/fiber/v2"
+)
+
+type RefundHandler interface {
+	RequestRefund(ctx *fiber.Ctx) error
+	GetOrderRefunds(ctx *fiber.Ctx) error
+	ListStoreRefunds(ctx *fiber.Ctx) error
+	ReviewRefund(ctx *fiber.Ctx) error
+}
+
+type refundHandler struct {
+	service services.RefundService
+}
+
+func New(service services.RefundService) RefundHandler {
+	return refundHandler{
+		service: service,
+	}
+}
+
+func (c refundHandler) RequestRefund(ctx *fiber.Ctx) error {
+	var request refundDto.CreateRefundRequest
+	if err := ctx.BodyParser(&request); err != nil {
+		return genericResponse.ErrorResponse(ctx, fiber.StatusBadRequest, err.Error())
+	}
+	request.OrderID = ctx.Params("id")
+
+	response, status, err := c.service.RequestRefund(ctx, request)
+	if err != nil {
+		return genericResponse.ErrorResponse(ctx, status, err.Error())
+	}
+
+	return genericResponse.SuccessResponse(ctx, status, response, "Refund requested successfully")
+}
+
+func (c refundHandler) GetOrderRefunds(ctx *fiber.Ctx) error {
+	request := refundDto.GetOrderRefundsRequest{
+		OrderID: ctx.Params("id"),
+	}
+
+	response, status, err := c.service.GetOrderRefunds(ctx, request)
+	if err != nil {
+		return genericResponse.ErrorResponse(ctx, status, err.Error())
+	}
+
+	return genericResponse.SuccessResponse(ctx, status, response, "Refunds retrieved successfully")
+}
+
+func (c refundHandler) ListStoreRefunds(ctx *fiber.Ctx) error {
+	var request refundDto.ListStoreRefundsRequest
+	if err := ctx.QueryParser(&request); err != nil {
+		return genericResponse.ErrorResponse(ctx, fiber.StatusBadRequest, err.Error())
+	}
+	request.StoreID = ctx.Params("store_id")
+
+	response, status, err := c.service.ListStoreRefunds(ctx, request)
+	if err != nil {
+		return genericResponse.ErrorResponse(ctx, status, err.Error())
+	}
+
+	return genericResponse.SuccessResponse(ctx, status, response, "Refunds retrieved successfully")
+}
+
+func (c refundHandler) ReviewRefund(ctx *fiber.Ctx) error {
+	var request refundDto.ReviewRefundRequest
+	if err := ctx.BodyParser(&request); err != nil {
+		return genericResponse.ErrorResponse(ctx, fiber.StatusBadRequest, err.Error())
+	}
+	request.StoreID = ctx.Params("store_id")
+	request.RefundID = ctx.Params("id")
+
+	response, status, err := c.service.ReviewRefund(ctx, request)
+	if err != nil {
+		return genericResponse.ErrorResponse(ctx, status, err.Error())
+	}
+
+	return genericResponse.SuccessResponse(ctx, status, response, "Refund reviewed successfully")
+}
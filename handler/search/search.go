@@ -0,0 +1,52 @@
+package search
+
+import (
+	"github.com/abdulmalikraji/e-commerce/dto/searchDto"
+	"github.com/abdulmalikraji/e-commerce/services"
+	"github.com/abdulmalikraji/e-commerce/utils/genericResponse"
+	"github.com/gofiber/fiber/v2"
+)
+
+type SearchHandler interface {
+	SearchProducts(ctx *fiber.Ctx) error
+	ZeroResultSearches(ctx *fiber.Ctx) error
+}
+
+type searchHandler struct {
+	service services.SearchService
+}
+
+func New(service services.SearchService) SearchHandler {
+	return searchHandler{
+		service: service,
+	}
+}
+
+func (c searchHandler) SearchProducts(ctx *fiber.Ctx) error {
+	var request searchDto.SearchProductsRequest
+	if err := ctx.QueryParser(&request); err != nil {
+		return genericResponse.ErrorResponse(ctx, fiber.StatusBadRequest, err.Error())
+	}
+
+	response, status, err := c.service.SearchProducts(ctx, request)
+	if err != nil {
+		return genericResponse.ErrorResponse(ctx, status, err.Error())
+	}
+
+	meta := genericResponse.NewPageMeta(response.Page, response.PageSize, response.Total)
+	return genericResponse.PaginatedResponse(ctx, status, response.Results, meta, "Products retrieved successfully")
+}
+
+func (c searchHandler) ZeroResultSearches(ctx *fiber.Ctx) error {
+	var request searchDto.ZeroResultSearchesRequest
+	if err := ctx.QueryParser(&request); err != nil {
+		return genericResponse.ErrorResponse(ctx, fiber.StatusBadRequest, err.Error())
+	}
+
+	response, status, err := c.service.ZeroResultSearches(ctx, request)
+	if err != nil {
+		return genericResponse.ErrorResponse(ctx, status, err.Error())
+	}
+
+	return genericResponse.SuccessResponse(ctx, status, response, "Searches retrieved successfully")
+}
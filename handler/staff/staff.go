@@ -0,0 +1,129 @@
+package staff
+
+import (
+	"github.com/abdulmalikraji/e-commerce/dto/staffDto"
+	"github.com/abdulmalikraji/e-commerce/services"
+	"github.com/abdulmalikraji/e-commerce/utils/genericResponse"
+	"github.com/gofiber/fiber/v2"
+)
+
+type StaffHandler interface {
+	ListStaff(ctx *fiber.Ctx) error
+	InviteStaff(ctx *fiber.Ctx) error
+	ListInvitations(ctx *fiber.Ctx) error
+	RevokeInvitation(ctx *fiber.Ctx) error
+	AcceptInvitation(ctx *fiber.Ctx) error
+	UpdateStaff(ctx *fiber.Ctx) error
+	RemoveStaff(ctx *fiber.Ctx) error
+}
+
+type staffHandler struct {
+	service services.StaffService
+}
+
+func New(service services.StaffService) StaffHandler {
+	return staffHandler{
+		service: service,
+	}
+}
+
+func (c staffHandler) ListStaff(ctx *fiber.Ctx) error {
+	request := staffDto.ListStaffRequest{
+		StoreID: ctx.Params("store_id"),
+	}
+
+	response, status, err := c.service.ListStaff(ctx, request)
+	if err != nil {
+		return genericResponse.ErrorResponse(ctx, status, err.Error())
+	}
+
+	return genericResponse.SuccessResponse(ctx, status, response, "Staff retrieved successfully")
+}
+
+func (c staffHandler) InviteStaff(ctx *fiber.Ctx) error {
+	var request staffDto.InviteStaffRequest
+	if err := ctx.BodyParser(&request); err != nil {
+		return genericResponse.ErrorResponse(ctx, fiber.StatusBadRequest, err.Error())
+	}
+	request.StoreID = ctx.Params("store_id")
+
+	response, status, err := c.service.InviteStaff(ctx, request)
+	if err != nil {
+		return genericResponse.ErrorResponse(ctx, status, err.Error())
+	}
+
+	return genericResponse.SuccessResponse(ctx, status, response, "Invitation sent successfully")
+}
+
+func (c staffHandler) ListInvitations(ctx *fiber.Ctx) error {
+	var request staffDto.ListInvitationsRequest
+	if err := ctx.QueryParser(&request); err != nil {
+		return genericResponse.ErrorResponse(ctx, fiber.StatusBadRequest, err.Error())
+	}
+	request.StoreID = ctx.Params("store_id")
+
+	response, status, err := c.service.ListInvitations(ctx, request)
+	if err != nil {
+		return genericResponse.ErrorResponse(ctx, status, err.Error())
+	}
+
+	return genericResponse.SuccessResponse(ctx, status, response, "Invitations retrieved successfully")
+}
+
+func (c staffHandler) RevokeInvitation(ctx *fiber.Ctx) error {
+	request := staffDto.RevokeInvitationRequest{
+		StoreID:      ctx.Params("store_id"),
+		InvitationID: ctx.Params("id"),
+	}
+
+	response, status, err := c.service.RevokeInvitation(ctx, request)
+	if err != nil {
+		return genericResponse.ErrorResponse(ctx, status, err.Error())
+	}
+
+	return genericResponse.SuccessResponse(ctx, status, response, "Invitation revoked successfully")
+}
+
+func (c staffHandler) AcceptInvitation(ctx *fiber.Ctx) error {
+	var request staffDto.AcceptInvitationRequest
+	if err := ctx.BodyParser(&request); err != nil {
+		return genericResponse.ErrorResponse(ctx, fiber.StatusBadRequest, err.Error())
+	}
+
+	response, status, err := c.service.AcceptInvitation(ctx, request)
+	if err != nil {
+		return genericResponse.ErrorResponse(ctx, status, err.Error())
+	}
+
+	return genericResponse.SuccessResponse(ctx, status, response, "Invitation accepted successfully")
+}
+
+func (c staffHandler) UpdateStaff(ctx *fiber.Ctx) error {
+	var request staffDto.UpdateStaffRequest
+	if err := ctx.BodyParser(&request); err != nil {
+		return genericResponse.ErrorResponse(ctx, fiber.StatusBadRequest, err.Error())
+	}
+	request.StoreID = ctx.Params("store_id")
+	request.UserID = ctx.Params("user_id")
+
+	response, status, err := c.service.UpdateStaff(ctx, request)
+	if err != nil {
+		return genericResponse.ErrorResponse(ctx, status, err.Error())
+	}
+
+	return genericResponse.SuccessResponse(ctx, status, response, "Staff member updated successfully")
+}
+
+func (c staffHandler) RemoveStaff(ctx *fiber.Ctx) error {
+	request := staffDto.RemoveStaffRequest{
+		StoreID: ctx.Params("store_id"),
+		UserID:  ctx.Params("user_id"),
+	}
+
+	status, err := c.service.RemoveStaff(ctx, request)
+	if err != nil {
+		return genericResponse.ErrorResponse(ctx, status, err.Error())
+	}
+
+	return genericResponse.SuccessResponse(ctx, status, nil, "Staff member removed successfully")
+}
@@ -3,15 +3,24 @@ package store
 import (
 	"github.com/abdulmalikraji/e-commerce/dto/storeDto"
 	"github.com/abdulmalikraji/e-commerce/services"
+	"github.com/abdulmalikraji/e-commerce/utils"
 	"github.com/abdulmalikraji/e-commerce/utils/genericResponse"
 	"github.com/gofiber/fiber/v2"
 )
 
 type StoreHandler interface {
 	CreateStore(ctx *fiber.Ctx) error
+	FindStores(ctx *fiber.Ctx) error
 	GetStoreByID(ctx *fiber.Ctx) error
 	GetStoreProducts(ctx *fiber.Ctx) error
-	GetStoreByOwnerID(ctx *fiber.Ctx) error
+	GetMyStores(ctx *fiber.Ctx) error
+	UpdateStore(ctx *fiber.Ctx) error
+	UpdateStoreImage(ctx *fiber.Ctx) error
+	UploadStoreImage(ctx *fiber.Ctx) error
+	DeleteStore(ctx *fiber.Ctx) error
+	RestoreStore(ctx *fiber.Ctx) error
+	GetStoreSettings(ctx *fiber.Ctx) error
+	UpdateStoreSettings(ctx *fiber.Ctx) error
 }
 
 type storeHandler struct {
@@ -27,54 +36,172 @@ func New(service services.StoreService) StoreHandler {
 func (c storeHandler) CreateStore(ctx *fiber.Ctx) error {
 	var request storeDto.CreateStoreRequest
 	if err := ctx.BodyParser(&request); err != nil {
-		return err
+		return genericResponse.ErrorResponse(ctx, fiber.StatusBadRequest, err.Error())
 	}
 
-	statusCode, err := c.service.CreateStore(ctx, request)
+	response, statusCode, err := c.service.CreateStore(ctx, request)
 	if err != nil {
+		return genericResponse.ErrorResponse(ctx, statusCode, err.Error())
+	}
+
+	return genericResponse.SuccessResponse(ctx, statusCode, response, "Store created successfully")
+}
+
+func (c storeHandler) FindStores(ctx *fiber.Ctx) error {
+	var request storeDto.FindStoreRequest
+	if err := ctx.QueryParser(&request); err != nil {
 		return genericResponse.ErrorResponse(ctx, fiber.StatusBadRequest, err.Error())
 	}
 
-	return genericResponse.SuccessResponse(ctx, statusCode, nil, "Store created successfully")
+	response, statusCode, err := c.service.FindStore(ctx, request)
+	if err != nil {
+		return genericResponse.ErrorResponse(ctx, statusCode, err.Error())
+	}
+
+	return genericResponse.SuccessResponse(ctx, statusCode, response, "Stores retrieved successfully")
 }
 
 func (c storeHandler) GetStoreByID(ctx *fiber.Ctx) error {
-	var request storeDto.GetStoreByIDRequest
-	if err := ctx.QueryParser(&request); err != nil {
-		return err
+	request := storeDto.GetStoreByIDRequest{
+		StoreID: ctx.Params("store_id"),
 	}
 
 	response, statusCode, err := c.service.GetStoreByID(ctx, request)
 	if err != nil {
-		return genericResponse.ErrorResponse(ctx, fiber.StatusBadRequest, err.Error())
-
+		return genericResponse.ErrorResponse(ctx, statusCode, err.Error())
 	}
 
 	return genericResponse.SuccessResponse(ctx, statusCode, response, "Store retrieved successfully")
 }
 
-func (c storeHandler) GetStoreByOwnerID(ctx *fiber.Ctx) error {
-	var request storeDto.GetStoreByOwnerIDRequest
-	if err := ctx.QueryParser(&request); err != nil {
-		return err
+// GetMyStores lists the stores owned by the caller.
+func (c storeHandler) GetMyStores(ctx *fiber.Ctx) error {
+	userID, err := utils.GetUserID(ctx)
+	if err != nil {
+		return genericResponse.ErrorResponse(ctx, fiber.StatusUnauthorized, err.Error())
+	}
+	request := storeDto.GetStoreByOwnerIDRequest{
+		OwnerID: userID.String(),
 	}
 
 	response, statusCode, err := c.service.GetStoreByOwnerID(ctx, request)
 	if err != nil {
-		return genericResponse.ErrorResponse(ctx, fiber.StatusBadRequest, err.Error())
+		return genericResponse.ErrorResponse(ctx, statusCode, err.Error())
 	}
 
 	return genericResponse.SuccessResponse(ctx, statusCode, response, "Stores retrieved successfully")
 }
 
 func (c storeHandler) GetStoreProducts(ctx *fiber.Ctx) error {
-	var request storeDto.GetStoreProductsRequest
-	if err := ctx.QueryParser(&request); err != nil {
-		return err
+	request := storeDto.GetStoreProductsRequest{
+		StoreID: ctx.Params("store_id"),
 	}
 	response, statusCode, err := c.service.GetStoreProducts(ctx, request)
 	if err != nil {
-		return genericResponse.ErrorResponse(ctx, fiber.StatusBadRequest, err.Error())
+		return genericResponse.ErrorResponse(ctx, statusCode, err.Error())
 	}
 	return genericResponse.SuccessResponse(ctx, statusCode, response, "Store products retrieved successfully")
-}
\ No newline at end of file
+}
+
+func (c storeHandler) UpdateStore(ctx *fiber.Ctx) error {
+	var request storeDto.UpdateStoreRequest
+	if err := ctx.BodyParser(&request); err != nil {
+		return genericResponse.ErrorResponse(ctx, fiber.StatusBadRequest, err.Error())
+	}
+	request.StoreID = ctx.Params("store_id")
+
+	response, statusCode, err := c.service.UpdateStore(ctx, request)
+	if err != nil {
+		return genericResponse.ErrorResponse(ctx, statusCode, err.Error())
+	}
+
+	return genericResponse.SuccessResponse(ctx, statusCode, response, "Store updated successfully")
+}
+
+func (c storeHandler) UpdateStoreImage(ctx *fiber.Ctx) error {
+	var request storeDto.UpdateStoreImageRequest
+	if err := ctx.BodyParser(&request); err != nil {
+		return genericResponse.ErrorResponse(ctx, fiber.StatusBadRequest, err.Error())
+	}
+	request.StoreID = ctx.Params("store_id")
+
+	response, statusCode, err := c.service.UpdateStoreImage(ctx, request)
+	if err != nil {
+		return genericResponse.ErrorResponse(ctx, statusCode, err.Error())
+	}
+
+	return genericResponse.SuccessResponse(ctx, statusCode, response, "Store image updated successfully")
+}
+
+// UploadStoreImage reads a multipart form with the logo file in "image".
+func (c storeHandler) UploadStoreImage(ctx *fiber.Ctx) error {
+	file, err := utils.FormFile(ctx, "image", utils.MaxImageSize)
+	if err != nil {
+		return genericResponse.ErrorResponse(ctx, fiber.StatusBadRequest, err.Error())
+	}
+	request := storeDto.UploadStoreImageRequest{
+		StoreID: ctx.Params("store_id"),
+		File:    file,
+	}
+
+	response, statusCode, err := c.service.UploadStoreImage(ctx, request)
+	if err != nil {
+		return genericResponse.ErrorResponse(ctx, statusCode, err.Error())
+	}
+
+	return genericResponse.SuccessResponse(ctx, statusCode, response, "Store image uploaded successfully")
+}
+
+func (c storeHandler) DeleteStore(ctx *fiber.Ctx) error {
+	request := storeDto.DeleteStoreRequest{
+		StoreID: ctx.Params("store_id"),
+	}
+
+	statusCode, err := c.service.DeleteStore(ctx, request)
+	if err != nil {
+		return genericResponse.ErrorResponse(ctx, statusCode, err.Error())
+	}
+
+	return genericResponse.SuccessResponse(ctx, statusCode, nil, "Store deleted successfully")
+}
+
+func (c storeHandler) RestoreStore(ctx *fiber.Ctx) error {
+	request := storeDto.RestoreStoreRequest{
+		StoreID: ctx.Params("store_id"),
+	}
+
+	response, statusCode, err := c.service.RestoreStore(ctx, request)
+	if err != nil {
+		return genericResponse.ErrorResponse(ctx, statusCode, err.Error())
+	}
+
+	return genericResponse.SuccessResponse(ctx, statusCode, response, "Store restored successfully")
+}
+
+func (c storeHandler) GetStoreSettings(ctx *fiber.Ctx) error {
+	request := storeDto.GetStoreSettingsRequest{
+		StoreID: ctx.Params("store_id"),
+	}
+
+	response, statusCode, err := c.service.GetStoreSettings(ctx, request)
+	if err != nil {
+		return genericResponse.ErrorResponse(ctx, statusCode, err.Error())
+	}
+
+	return genericResponse.SuccessResponse(ctx, statusCode, response, "Store settings retrieved successfully")
+}
+
+func (c storeHandler) UpdateStoreSettings(ctx *fiber.Ctx) error {
+	var request storeDto.UpdateStoreSettingsRequest
+	if err := ctx.BodyParser(&request); err != nil {
+		return genericResponse.ErrorResponse(ctx, fiber.StatusBadRequest, err.Error())
+	}
+	request.StoreID = ctx.Params("store_id")
+
+	response, statusCode, err := c.service.UpdateStoreSettings(ctx, request)
+	if err != nil {
+		return genericResponse.ErrorResponse(ctx, statusCode, err.Error())
+	}
+
+	return genericResponse.SuccessResponse(ctx, statusCode, response, "Store settings updated successfully")
+}
@@ -0,0 +1,93 @@
+package storeRole
+
+import (
+	"github.com/abdulmalikraji/e-commerce/dto/storeRoleDto"
+	"github.com/abdulmalikraji/e-commerce/services"
+	"github.com/abdulmalikraji/e-commerce/utils/genericResponse"
+	"github.com/gofiber/fiber/v2"
+)
+
+type StoreRoleHandler interface {
+	ListRoles(ctx *fiber.Ctx) error
+	ListActions(ctx *fiber.Ctx) error
+	CreateRole(ctx *fiber.Ctx) error
+	UpdateRole(ctx *fiber.Ctx) error
+	DeleteRole(ctx *fiber.Ctx) error
+}
+
+type storeRoleHandler struct {
+	service services.StoreRoleService
+}
+
+func New(service services.StoreRoleService) StoreRoleHandler {
+	return storeRoleHandler{
+		service: service,
+	}
+}
+
+func (c storeRoleHandler) ListRoles(ctx *fiber.Ctx) error {
+	request := storeRoleDto.ListRolesRequest{
+		StoreID: ctx.Params("store_id"),
+	}
+
+	response, status, err := c.service.ListRoles(ctx, request)
+	if err != nil {
+		return genericResponse.ErrorResponse(ctx, status, err.Error())
+	}
+
+	return genericResponse.SuccessResponse(ctx, status, response, "Roles retrieved successfully")
+}
+
+func (c storeRoleHandler) ListActions(ctx *fiber.Ctx) error {
+	response, status, err := c.service.ListActions(ctx)
+	if err != nil {
+		return genericResponse.ErrorResponse(ctx, status, err.Error())
+	}
+
+	return genericResponse.SuccessResponse(ctx, status, response, "Actions retrieved successfully")
+}
+
+func (c storeRoleHandler) CreateRole(ctx *fiber.Ctx) error {
+	var request storeRoleDto.CreateRoleRequest
+	if err := ctx.BodyParser(&request); err != nil {
+		return genericResponse.ErrorResponse(ctx, fiber.StatusBadRequest, err.Error())
+	}
+	request.StoreID = ctx.Params("store_id")
+
+	response, status, err := c.service.CreateRole(ctx, request)
+	if err != nil {
+		return genericResponse.ErrorResponse(ctx, status, err.Error())
+	}
+
+	return genericResponse.SuccessResponse(ctx, status, response, "Role created successfully")
+}
+
+func (c storeRoleHandler) UpdateRole(ctx *fiber.Ctx) error {
+	var request storeRoleDto.UpdateRoleRequest
+	if err := ctx.BodyParser(&request); err != nil {
+		return genericResponse.ErrorResponse(ctx, fiber.StatusBadRequest, err.Error())
+	}
+	request.StoreID = ctx.Params("store_id")
+	request.RoleID = ctx.Params("id")
+
+	response, status, err := c.service.UpdateRole(ctx, request)
+	if err != nil {
+		return genericResponse.ErrorResponse(ctx, status, err.Error())
+	}
+
+	return genericResponse.SuccessResponse(ctx, status, response, "Role updated successfully")
+}
+
+func (c storeRoleHandler) DeleteRole(ctx *fiber.Ctx) error {
+	request := storeRoleDto.DeleteRoleRequest{
+		StoreID: ctx.Params("store_id"),
+		RoleID:  ctx.Params("id"),
+	}
+
+	status, err := c.service.DeleteRole(ctx, request)
+	if err != nil {
+		return genericResponse.ErrorResponse(ctx, status, err.Error())
+	}
+
+	return genericResponse.SuccessResponse(ctx, status, nil, "Role deleted successfully")
+}
@@ -0,0 +1,145 @@
+package taxonomy
+
+import (
+	"github.com/abdulmalikraji/e-commerce/dto/taxonomyDto"
+	"github.com/abdulmalikraji/e-commerce/services"
+	"github.com/abdulmalikraji/e-commerce/utils/genericResponse"
+	"github.com/gofiber/fiber/v2"
+)
+
+type TaxonomyHandler interface {
+	GetTree(ctx *fiber.Ctx) error
+	GetSubtree(ctx *fiber.Ctx) error
+	GetBreadcrumbs(ctx *fiber.Ctx) error
+	CreateCategory(ctx *fiber.Ctx) error
+	UpdateCategory(ctx *fiber.Ctx) error
+	MoveCategory(ctx *fiber.Ctx) error
+	DeleteCategory(ctx *fiber.Ctx) error
+	ListTags(ctx *fiber.Ctx) error
+	RecategorizeProducts(ctx *fiber.Ctx) error
+}
+
+type taxonomyHandler struct {
+	service services.TaxonomyService
+}
+
+func New(service services.TaxonomyService) TaxonomyHandler {
+	return taxonomyHandler{
+		service: service,
+	}
+}
+
+func (c taxonomyHandler) GetTree(ctx *fiber.Ctx) error {
+	response, status, err := c.service.GetTree(ctx)
+	if err != nil {
+		return genericResponse.ErrorResponse(ctx, status, err.Error())
+	}
+
+	return genericResponse.SuccessResponse(ctx, status, response, "Categories retrieved successfully")
+}
+
+func (c taxonomyHandler) GetSubtree(ctx *fiber.Ctx) error {
+	request := taxonomyDto.GetSubtreeRequest{
+		CategoryID: ctx.Params("id"),
+	}
+
+	response, status, err := c.service.GetSubtree(ctx, request)
+	if err != nil {
+		return genericResponse.ErrorResponse(ctx, status, err.Error())
+	}
+
+	return genericResponse.SuccessResponse(ctx, status, response, "Category retrieved successfully")
+}
+
+func (c taxonomyHandler) GetBreadcrumbs(ctx *fiber.Ctx) error {
+	request := taxonomyDto.GetBreadcrumbsRequest{
+		ProductID: ctx.Params("id"),
+	}
+
+	response, status, err := c.service.GetBreadcrumbs(ctx, request)
+	if err != nil {
+		return genericResponse.ErrorResponse(ctx, status, err.Error())
+	}
+
+	return genericResponse.SuccessResponse(ctx, status, response, "Breadcrumbs retrieved successfully")
+}
+
+func (c taxonomyHandler) MoveCategory(ctx *fiber.Ctx) error {
+	var request taxonomyDto.MoveCategoryRequest
+	if err := ctx.BodyParser(&request); err != nil {
+		return genericResponse.ErrorResponse(ctx, fiber.StatusBadRequest, err.Error())
+	}
+	request.CategoryID = ctx.Params("id")
+
+	response, status, err := c.service.MoveCategory(ctx, request)
+	if err != nil {
+		return genericResponse.ErrorResponse(ctx, status, err.Error())
+	}
+
+	return genericResponse.SuccessResponse(ctx, status, response, "Category moved successfully")
+}
+
+func (c taxonomyHandler) CreateCategory(ctx *fiber.Ctx) error {
+	var request taxonomyDto.CreateCategoryRequest
+	if err := ctx.BodyParser(&request); err != nil {
+		return genericResponse.ErrorResponse(ctx, fiber.StatusBadRequest, err.Error())
+	}
+
+	response, status, err := c.service.CreateCategory(ctx, request)
+	if err != nil {
+		return genericResponse.ErrorResponse(ctx, status, err.Error())
+	}
+
+	return genericResponse.SuccessResponse(ctx, status, response, "Category created successfully")
+}
+
+func (c taxonomyHandler) UpdateCategory(ctx *fiber.Ctx) error {
+	var request taxonomyDto.UpdateCategoryRequest
+	if err := ctx.BodyParser(&request); err != nil {
+		return genericResponse.ErrorResponse(ctx, fiber.StatusBadRequest, err.Error())
+	}
+	request.CategoryID = ctx.Params("id")
+
+	response, status, err := c.service.UpdateCategory(ctx, request)
+	if err != nil {
+		return genericResponse.ErrorResponse(ctx, status, err.Error())
+	}
+
+	return genericResponse.SuccessResponse(ctx, status, response, "Category updated successfully")
+}
+
+func (c taxonomyHandler) DeleteCategory(ctx *fiber.Ctx) error {
+	request := taxonomyDto.DeleteCategoryRequest{
+		CategoryID: ctx.Params("id"),
+	}
+
+	status, err := c.service.DeleteCategory(ctx, request)
+	if err != nil {
+		return genericResponse.ErrorResponse(ctx, status, err.Error())
+	}
+
+	return genericResponse.SuccessResponse(ctx, status, nil, "Category deleted successfully")
+}
+
+func (c taxonomyHandler) ListTags(ctx *fiber.Ctx) error {
+	response, status, err := c.service.ListTags(ctx)
+	if err != nil {
+		return genericResponse.ErrorResponse(ctx, status, err.Error())
+	}
+
+	return genericResponse.SuccessResponse(ctx, status, response, "Tags retrieved successfully")
+}
+
+func (c taxonomyHandler) RecategorizeProducts(ctx *fiber.Ctx) error {
+	var request taxonomyDto.RecategorizeRequest
+	if err := ctx.BodyParser(&request); err != nil {
+		return genericResponse.ErrorResponse(ctx, fiber.StatusBadRequest, err.Error())
+	}
+
+	response, status, err := c.service.RecategorizeProducts(ctx, request)
+	if err != nil {
+		return genericResponse.ErrorResponse(ctx, status, err.Error())
+	}
+
+	return genericResponse.SuccessResponse(ctx, status, response, "Products recategorized successfully")
+}
@@ -0,0 +1,80 @@
+package mailer
+
+import (
+	"fmt"
+	"net"
+	"net/smtp"
+	"os"
+	"strings"
+
+	"github.com/gofiber/fiber/v2/log"
+)
+
+// Message is a plain-text email.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer delivers transactional email.
+type Mailer interface {
+	Send(message Message) error
+}
+
+// New returns an SMTP mailer when SMTP_HOST is set and a LogMailer otherwise.
+//
+//	SMTP_HOST, SMTP_PORT (default 587), SMTP_USERNAME, SMTP_PASSWORD, MAIL_FROM
+func New() Mailer {
+	host := os.Getenv("SMTP_HOST")
+	if host == "" {
+		log.Warn("SMTP_HOST is not set, emails are only logged")
+		return LogMailer{}
+	}
+	port := os.Getenv("SMTP_PORT")
+	if port == "" {
+		port = "587"
+	}
+	return smtpMailer{
+		address:  net.JoinHostPort(host, port),
+		host:     host,
+		username: os.Getenv("SMTP_USERNAME"),
+		password: os.Getenv("SMTP_PASSWORD"),
+		from:     os.Getenv("MAIL_FROM"),
+	}
+}
+
+// LogMailer writes messages to the log instead of sending them. It is meant for local
+// development.
+type LogMailer struct{}
+
+func (LogMailer) Send(message Message) error {
+	log.Infof("email to=%s subject=%q\n%s", message.To, message.Subject, message.Body)
+	return nil
+}
+
+type smtpMailer struct {
+	address  string
+	host     string
+	username string
+	password string
+	from     string
+}
+
+func (m smtpMailer) Send(message Message) error {
+	if strings.ContainsAny(message.To+message.Subject, "\r\n") {
+		return fmt.Errorf("mailer: header values must not contain line breaks")
+	}
+
+	var auth smtp.Auth
+	if m.username != "" {
+		auth = smtp.PlainAuth("", m.username, m.password, m.host)
+	}
+	body := "From: " + m.from + "\r\n" +
+		"To: " + message.To + "\r\n" +
+		"Subject: " + message.Subject + "\r\n" +
+		"MIME-Version: 1.0\r\n" +
+		"Content-Type: text/plain; charset=UTF-8\r\n" +
+		"\r\n" + message.Body
+	return smtp.SendMail(m.address, auth, m.from, []string{message.To}, []byte(body))
+}
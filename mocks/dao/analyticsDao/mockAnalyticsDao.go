@@ -0,0 +1,182 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: analyticsDao.go
+
+// Package analyticsDao is a generated GoMock package.
+package analyticsDao
+
+import (
+	reflect "reflect"
+	time "time"
+
+	analyticsDao "github.com/abdulmalikraji/e-commerce/db/dao/analyticsDao"
+	models "github.com/abdulmalikraji/e-commerce/db/models"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockDataAccess is a mock of DataAccess interface.
+type MockDataAccess struct {
+	ctrl     *gomock.Controller
+	recorder *MockDataAccessMockRecorder
+}
+
+// MockDataAccessMockRecorder is the mock recorder for MockDataAccess.
+type MockDataAccessMockRecorder struct {
+	mock *MockDataAccess
+}
+
+// NewMockDataAccess creates a new mock instance.
+func NewMockDataAccess(ctrl *gomock.Controller) *MockDataAccess {
+	mock := &MockDataAccess{ctrl: ctrl}
+	mock.recorder = &MockDataAccessMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDataAccess) EXPECT() *MockDataAccessMockRecorder {
+	return m.recorder
+}
+
+// FindAbandonedCarts mocks base method.
+func (m *MockDataAccess) FindAbandonedCarts(userId, sessionId *string) ([]models.AbandonedCart, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindAbandonedCarts", userId, sessionId)
+	ret0, _ := ret[0].([]models.AbandonedCart)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindAbandonedCarts indicates an expected call of FindAbandonedCarts.
+func (mr *MockDataAccessMockRecorder) FindAbandonedCarts(userId, sessionId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindAbandonedCarts", reflect.TypeOf((*MockDataAccess)(nil).FindAbandonedCarts), userId, sessionId)
+}
+
+// FindAddToCartEvents mocks base method.
+func (m *MockDataAccess) FindAddToCartEvents(productId string, sessionId, userId *string) ([]models.AddToCartEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindAddToCartEvents", productId, sessionId, userId)
+	ret0, _ := ret[0].([]models.AddToCartEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindAddToCartEvents indicates an expected call of FindAddToCartEvents.
+func (mr *MockDataAccessMockRecorder) FindAddToCartEvents(productId, sessionId, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindAddToCartEvents", reflect.TypeOf((*MockDataAccess)(nil).FindAddToCartEvents), productId, sessionId, userId)
+}
+
+// FindProductViews mocks base method.
+func (m *MockDataAccess) FindProductViews(productId string, sessionId, userId *string) ([]models.ProductView, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindProductViews", productId, sessionId, userId)
+	ret0, _ := ret[0].([]models.ProductView)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindProductViews indicates an expected call of FindProductViews.
+func (mr *MockDataAccessMockRecorder) FindProductViews(productId, sessionId, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindProductViews", reflect.TypeOf((*MockDataAccess)(nil).FindProductViews), productId, sessionId, userId)
+}
+
+// FindSalesStats mocks base method.
+func (m *MockDataAccess) FindSalesStats(productId string, sessionId, userId *string) ([]models.SalesStat, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindSalesStats", productId, sessionId, userId)
+	ret0, _ := ret[0].([]models.SalesStat)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindSalesStats indicates an expected call of FindSalesStats.
+func (mr *MockDataAccessMockRecorder) FindSalesStats(productId, sessionId, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindSalesStats", reflect.TypeOf((*MockDataAccess)(nil).FindSalesStats), productId, sessionId, userId)
+}
+
+// FindZeroResultSearches mocks base method.
+func (m *MockDataAccess) FindZeroResultSearches(since time.Time, limit int) ([]analyticsDao.ZeroResultSearch, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindZeroResultSearches", since, limit)
+	ret0, _ := ret[0].([]analyticsDao.ZeroResultSearch)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindZeroResultSearches indicates an expected call of FindZeroResultSearches.
+func (mr *MockDataAccessMockRecorder) FindZeroResultSearches(since, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindZeroResultSearches", reflect.TypeOf((*MockDataAccess)(nil).FindZeroResultSearches), since, limit)
+}
+
+// LogAbandonedCart mocks base method.
+func (m *MockDataAccess) LogAbandonedCart(event models.AbandonedCart) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LogAbandonedCart", event)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// LogAbandonedCart indicates an expected call of LogAbandonedCart.
+func (mr *MockDataAccessMockRecorder) LogAbandonedCart(event interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LogAbandonedCart", reflect.TypeOf((*MockDataAccess)(nil).LogAbandonedCart), event)
+}
+
+// LogAddToCart mocks base method.
+func (m *MockDataAccess) LogAddToCart(event models.AddToCartEvent) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LogAddToCart", event)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// LogAddToCart indicates an expected call of LogAddToCart.
+func (mr *MockDataAccessMockRecorder) LogAddToCart(event interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LogAddToCart", reflect.TypeOf((*MockDataAccess)(nil).LogAddToCart), event)
+}
+
+// LogProductView mocks base method.
+func (m *MockDataAccess) LogProductView(view models.ProductView) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LogProductView", view)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// LogProductView indicates an expected call of LogProductView.
+func (mr *MockDataAccessMockRecorder) LogProductView(view interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LogProductView", reflect.TypeOf((*MockDataAccess)(nil).LogProductView), view)
+}
+
+// LogSalesStat mocks base method.
+func (m *MockDataAccess) LogSalesStat(stat models.SalesStat) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LogSalesStat", stat)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// LogSalesStat indicates an expected call of LogSalesStat.
+func (mr *MockDataAccessMockRecorder) LogSalesStat(stat interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LogSalesStat", reflect.TypeOf((*MockDataAccess)(nil).LogSalesStat), stat)
+}
+
+// LogSearch mocks base method.
+func (m *MockDataAccess) LogSearch(search models.SearchAnalytics) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LogSearch", search)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// LogSearch indicates an expected call of LogSearch.
+func (mr *MockDataAccessMockRecorder) LogSearch(search interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LogSearch", reflect.TypeOf((*MockDataAccess)(nil).LogSearch), search)
+}
@@ -0,0 +1,284 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: cartDao.go
+
+// Package cartDao is a generated GoMock package.
+package cartDao
+
+import (
+	reflect "reflect"
+
+	cartdao "github.com/abdulmalikraji/e-commerce/db/dao/cartDao"
+	models "github.com/abdulmalikraji/e-commerce/db/models"
+	gomock "github.com/golang/mock/gomock"
+	gorm "gorm.io/gorm"
+)
+
+// MockDataAccess is a mock of DataAccess interface.
+type MockDataAccess struct {
+	ctrl     *gomock.Controller
+	recorder *MockDataAccessMockRecorder
+}
+
+// MockDataAccessMockRecorder is the mock recorder for MockDataAccess.
+type MockDataAccessMockRecorder struct {
+	mock *MockDataAccess
+}
+
+// NewMockDataAccess creates a new mock instance.
+func NewMockDataAccess(ctrl *gomock.Controller) *MockDataAccess {
+	mock := &MockDataAccess{ctrl: ctrl}
+	mock.recorder = &MockDataAccessMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDataAccess) EXPECT() *MockDataAccessMockRecorder {
+	return m.recorder
+}
+
+// AddItem mocks base method.
+func (m *MockDataAccess) AddItem(item models.CartItem) (models.CartItem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddItem", item)
+	ret0, _ := ret[0].(models.CartItem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddItem indicates an expected call of AddItem.
+func (mr *MockDataAccessMockRecorder) AddItem(item interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddItem", reflect.TypeOf((*MockDataAccess)(nil).AddItem), item)
+}
+
+// ClearCart mocks base method.
+func (m *MockDataAccess) ClearCart(cartId string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClearCart", cartId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ClearCart indicates an expected call of ClearCart.
+func (mr *MockDataAccessMockRecorder) ClearCart(cartId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClearCart", reflect.TypeOf((*MockDataAccess)(nil).ClearCart), cartId)
+}
+
+// Delete mocks base method.
+func (m *MockDataAccess) Delete(id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockDataAccessMockRecorder) Delete(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockDataAccess)(nil).Delete), id)
+}
+
+// FindAll mocks base method.
+func (m *MockDataAccess) FindAll() ([]models.Cart, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindAll")
+	ret0, _ := ret[0].([]models.Cart)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindAll indicates an expected call of FindAll.
+func (mr *MockDataAccessMockRecorder) FindAll() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindAll", reflect.TypeOf((*MockDataAccess)(nil).FindAll))
+}
+
+// FindById mocks base method.
+func (m *MockDataAccess) FindById(id string) (models.Cart, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindById", id)
+	ret0, _ := ret[0].(models.Cart)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindById indicates an expected call of FindById.
+func (mr *MockDataAccessMockRecorder) FindById(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindById", reflect.TypeOf((*MockDataAccess)(nil).FindById), id)
+}
+
+// FindBySessionId mocks base method.
+func (m *MockDataAccess) FindBySessionId(sessionId string) (models.Cart, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindBySessionId", sessionId)
+	ret0, _ := ret[0].(models.Cart)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindBySessionId indicates an expected call of FindBySessionId.
+func (mr *MockDataAccessMockRecorder) FindBySessionId(sessionId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindBySessionId", reflect.TypeOf((*MockDataAccess)(nil).FindBySessionId), sessionId)
+}
+
+// FindByUserId mocks base method.
+func (m *MockDataAccess) FindByUserId(userId string) (models.Cart, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByUserId", userId)
+	ret0, _ := ret[0].(models.Cart)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByUserId indicates an expected call of FindByUserId.
+func (mr *MockDataAccessMockRecorder) FindByUserId(userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByUserId", reflect.TypeOf((*MockDataAccess)(nil).FindByUserId), userId)
+}
+
+// FindItem mocks base method.
+func (m *MockDataAccess) FindItem(cartId, itemId string) (models.CartItem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindItem", cartId, itemId)
+	ret0, _ := ret[0].(models.CartItem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindItem indicates an expected call of FindItem.
+func (mr *MockDataAccessMockRecorder) FindItem(cartId, itemId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindItem", reflect.TypeOf((*MockDataAccess)(nil).FindItem), cartId, itemId)
+}
+
+// FindItems mocks base method.
+func (m *MockDataAccess) FindItems(cartId string) ([]models.CartItem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindItems", cartId)
+	ret0, _ := ret[0].([]models.CartItem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindItems indicates an expected call of FindItems.
+func (mr *MockDataAccessMockRecorder) FindItems(cartId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindItems", reflect.TypeOf((*MockDataAccess)(nil).FindItems), cartId)
+}
+
+// FindProductItem mocks base method.
+func (m *MockDataAccess) FindProductItem(cartId, productId string, variantId *string) (models.CartItem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindProductItem", cartId, productId, variantId)
+	ret0, _ := ret[0].(models.CartItem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindProductItem indicates an expected call of FindProductItem.
+func (mr *MockDataAccessMockRecorder) FindProductItem(cartId, productId, variantId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindProductItem", reflect.TypeOf((*MockDataAccess)(nil).FindProductItem), cartId, productId, variantId)
+}
+
+// Insert mocks base method.
+func (m *MockDataAccess) Insert(item models.Cart) (models.Cart, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Insert", item)
+	ret0, _ := ret[0].(models.Cart)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Insert indicates an expected call of Insert.
+func (mr *MockDataAccessMockRecorder) Insert(item interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Insert", reflect.TypeOf((*MockDataAccess)(nil).Insert), item)
+}
+
+// RemoveItem mocks base method.
+func (m *MockDataAccess) RemoveItem(itemId string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveItem", itemId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveItem indicates an expected call of RemoveItem.
+func (mr *MockDataAccessMockRecorder) RemoveItem(itemId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveItem", reflect.TypeOf((*MockDataAccess)(nil).RemoveItem), itemId)
+}
+
+// SoftDelete mocks base method.
+func (m *MockDataAccess) SoftDelete(id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SoftDelete", id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SoftDelete indicates an expected call of SoftDelete.
+func (mr *MockDataAccessMockRecorder) SoftDelete(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SoftDelete", reflect.TypeOf((*MockDataAccess)(nil).SoftDelete), id)
+}
+
+// Transaction mocks base method.
+func (m *MockDataAccess) Transaction(fn func(*gorm.DB) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Transaction", fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Transaction indicates an expected call of Transaction.
+func (mr *MockDataAccessMockRecorder) Transaction(fn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Transaction", reflect.TypeOf((*MockDataAccess)(nil).Transaction), fn)
+}
+
+// Update mocks base method.
+func (m *MockDataAccess) Update(item models.Cart) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", item)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockDataAccessMockRecorder) Update(item interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockDataAccess)(nil).Update), item)
+}
+
+// UpdateItem mocks base method.
+func (m *MockDataAccess) UpdateItem(item models.CartItem) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateItem", item)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateItem indicates an expected call of UpdateItem.
+func (mr *MockDataAccessMockRecorder) UpdateItem(item interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateItem", reflect.TypeOf((*MockDataAccess)(nil).UpdateItem), item)
+}
+
+// WithTx mocks base method.
+func (m *MockDataAccess) WithTx(tx *gorm.DB) cartdao.DataAccess {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WithTx", tx)
+	ret0, _ := ret[0].(cartdao.DataAccess)
+	return ret0
+}
+
+// WithTx indicates an expected call of WithTx.
+func (mr *MockDataAccessMockRecorder) WithTx(tx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithTx", reflect.TypeOf((*MockDataAccess)(nil).WithTx), tx)
+}
@@ -0,0 +1,241 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: categoryDao.go
+
+// Package categoryDao is a generated GoMock package.
+package categoryDao
+
+import (
+	reflect "reflect"
+
+	models "github.com/abdulmalikraji/e-commerce/db/models"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockDataAccess is a mock of DataAccess interface.
+type MockDataAccess struct {
+	ctrl     *gomock.Controller
+	recorder *MockDataAccessMockRecorder
+}
+
+// MockDataAccessMockRecorder is the mock recorder for MockDataAccess.
+type MockDataAccessMockRecorder struct {
+	mock *MockDataAccess
+}
+
+// NewMockDataAccess creates a new mock instance.
+func NewMockDataAccess(ctrl *gomock.Controller) *MockDataAccess {
+	mock := &MockDataAccess{ctrl: ctrl}
+	mock.recorder = &MockDataAccessMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDataAccess) EXPECT() *MockDataAccessMockRecorder {
+	return m.recorder
+}
+
+// CountProducts mocks base method.
+func (m *MockDataAccess) CountProducts(id string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountProducts", id)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountProducts indicates an expected call of CountProducts.
+func (mr *MockDataAccessMockRecorder) CountProducts(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountProducts", reflect.TypeOf((*MockDataAccess)(nil).CountProducts), id)
+}
+
+// Delete mocks base method.
+func (m *MockDataAccess) Delete(id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockDataAccessMockRecorder) Delete(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockDataAccess)(nil).Delete), id)
+}
+
+// FindAll mocks base method.
+func (m *MockDataAccess) FindAll() ([]models.Category, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindAll")
+	ret0, _ := ret[0].([]models.Category)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindAll indicates an expected call of FindAll.
+func (mr *MockDataAccessMockRecorder) FindAll() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindAll", reflect.TypeOf((*MockDataAccess)(nil).FindAll))
+}
+
+// FindAncestors mocks base method.
+func (m *MockDataAccess) FindAncestors(id string) ([]models.Category, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindAncestors", id)
+	ret0, _ := ret[0].([]models.Category)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindAncestors indicates an expected call of FindAncestors.
+func (mr *MockDataAccessMockRecorder) FindAncestors(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindAncestors", reflect.TypeOf((*MockDataAccess)(nil).FindAncestors), id)
+}
+
+// FindById mocks base method.
+func (m *MockDataAccess) FindById(id string) (models.Category, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindById", id)
+	ret0, _ := ret[0].(models.Category)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindById indicates an expected call of FindById.
+func (mr *MockDataAccessMockRecorder) FindById(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindById", reflect.TypeOf((*MockDataAccess)(nil).FindById), id)
+}
+
+// FindByName mocks base method.
+func (m *MockDataAccess) FindByName(name string) (models.Category, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByName", name)
+	ret0, _ := ret[0].(models.Category)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByName indicates an expected call of FindByName.
+func (mr *MockDataAccessMockRecorder) FindByName(name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByName", reflect.TypeOf((*MockDataAccess)(nil).FindByName), name)
+}
+
+// FindChildren mocks base method.
+func (m *MockDataAccess) FindChildren(parentId string) ([]models.Category, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindChildren", parentId)
+	ret0, _ := ret[0].([]models.Category)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindChildren indicates an expected call of FindChildren.
+func (mr *MockDataAccessMockRecorder) FindChildren(parentId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindChildren", reflect.TypeOf((*MockDataAccess)(nil).FindChildren), parentId)
+}
+
+// FindParent mocks base method.
+func (m *MockDataAccess) FindParent(id string) (models.Category, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindParent", id)
+	ret0, _ := ret[0].(models.Category)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindParent indicates an expected call of FindParent.
+func (mr *MockDataAccessMockRecorder) FindParent(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindParent", reflect.TypeOf((*MockDataAccess)(nil).FindParent), id)
+}
+
+// FindSubtree mocks base method.
+func (m *MockDataAccess) FindSubtree(id string) ([]models.Category, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindSubtree", id)
+	ret0, _ := ret[0].([]models.Category)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindSubtree indicates an expected call of FindSubtree.
+func (mr *MockDataAccessMockRecorder) FindSubtree(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindSubtree", reflect.TypeOf((*MockDataAccess)(nil).FindSubtree), id)
+}
+
+// Insert mocks base method.
+func (m *MockDataAccess) Insert(item models.Category) (models.Category, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Insert", item)
+	ret0, _ := ret[0].(models.Category)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Insert indicates an expected call of Insert.
+func (mr *MockDataAccessMockRecorder) Insert(item interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Insert", reflect.TypeOf((*MockDataAccess)(nil).Insert), item)
+}
+
+// Move mocks base method.
+func (m *MockDataAccess) Move(item models.Category, parent *models.Category) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Move", item, parent)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Move indicates an expected call of Move.
+func (mr *MockDataAccessMockRecorder) Move(item, parent interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Move", reflect.TypeOf((*MockDataAccess)(nil).Move), item, parent)
+}
+
+// NameTaken mocks base method.
+func (m *MockDataAccess) NameTaken(name, exceptId string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NameTaken", name, exceptId)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// NameTaken indicates an expected call of NameTaken.
+func (mr *MockDataAccessMockRecorder) NameTaken(name, exceptId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NameTaken", reflect.TypeOf((*MockDataAccess)(nil).NameTaken), name, exceptId)
+}
+
+// SoftDelete mocks base method.
+func (m *MockDataAccess) SoftDelete(id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SoftDelete", id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SoftDelete indicates an expected call of SoftDelete.
+func (mr *MockDataAccessMockRecorder) SoftDelete(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SoftDelete", reflect.TypeOf((*MockDataAccess)(nil).SoftDelete), id)
+}
+
+// Update mocks base method.
+func (m *MockDataAccess) Update(item models.Category) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", item)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockDataAccessMockRecorder) Update(item interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockDataAccess)(nil).Update), item)
+}
@@ -0,0 +1,183 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: couponDao.go
+
+// Package couponDao is a generated GoMock package.
+package couponDao
+
+import (
+	reflect "reflect"
+
+	couponDao "github.com/abdulmalikraji/e-commerce/db/dao/couponDao"
+	models "github.com/abdulmalikraji/e-commerce/db/models"
+	gomock "github.com/golang/mock/gomock"
+	uuid "github.com/google/uuid"
+	gorm "gorm.io/gorm"
+)
+
+// MockDataAccess is a mock of DataAccess interface.
+type MockDataAccess struct {
+	ctrl     *gomock.Controller
+	recorder *MockDataAccessMockRecorder
+}
+
+// MockDataAccessMockRecorder is the mock recorder for MockDataAccess.
+type MockDataAccessMockRecorder struct {
+	mock *MockDataAccess
+}
+
+// NewMockDataAccess creates a new mock instance.
+func NewMockDataAccess(ctrl *gomock.Controller) *MockDataAccess {
+	mock := &MockDataAccess{ctrl: ctrl}
+	mock.recorder = &MockDataAccessMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDataAccess) EXPECT() *MockDataAccessMockRecorder {
+	return m.recorder
+}
+
+// CountUserRedemptions mocks base method.
+func (m *MockDataAccess) CountUserRedemptions(couponId, userId string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountUserRedemptions", couponId, userId)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountUserRedemptions indicates an expected call of CountUserRedemptions.
+func (mr *MockDataAccessMockRecorder) CountUserRedemptions(couponId, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountUserRedemptions", reflect.TypeOf((*MockDataAccess)(nil).CountUserRedemptions), couponId, userId)
+}
+
+// Delete mocks base method.
+func (m *MockDataAccess) Delete(id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockDataAccessMockRecorder) Delete(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockDataAccess)(nil).Delete), id)
+}
+
+// FindAll mocks base method.
+func (m *MockDataAccess) FindAll() ([]models.Coupon, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindAll")
+	ret0, _ := ret[0].([]models.Coupon)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindAll indicates an expected call of FindAll.
+func (mr *MockDataAccessMockRecorder) FindAll() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindAll", reflect.TypeOf((*MockDataAccess)(nil).FindAll))
+}
+
+// FindByCode mocks base method.
+func (m *MockDataAccess) FindByCode(code string) (models.Coupon, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByCode", code)
+	ret0, _ := ret[0].(models.Coupon)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByCode indicates an expected call of FindByCode.
+func (mr *MockDataAccessMockRecorder) FindByCode(code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByCode", reflect.TypeOf((*MockDataAccess)(nil).FindByCode), code)
+}
+
+// FindById mocks base method.
+func (m *MockDataAccess) FindById(id string) (models.Coupon, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindById", id)
+	ret0, _ := ret[0].(models.Coupon)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindById indicates an expected call of FindById.
+func (mr *MockDataAccessMockRecorder) FindById(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindById", reflect.TypeOf((*MockDataAccess)(nil).FindById), id)
+}
+
+// Insert mocks base method.
+func (m *MockDataAccess) Insert(item models.Coupon) (models.Coupon, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Insert", item)
+	ret0, _ := ret[0].(models.Coupon)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Insert indicates an expected call of Insert.
+func (mr *MockDataAccessMockRecorder) Insert(item interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Insert", reflect.TypeOf((*MockDataAccess)(nil).Insert), item)
+}
+
+// Redeem mocks base method.
+func (m *MockDataAccess) Redeem(coupon models.Coupon, userId, orderId uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Redeem", coupon, userId, orderId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Redeem indicates an expected call of Redeem.
+func (mr *MockDataAccessMockRecorder) Redeem(coupon, userId, orderId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Redeem", reflect.TypeOf((*MockDataAccess)(nil).Redeem), coupon, userId, orderId)
+}
+
+// SoftDelete mocks base method.
+func (m *MockDataAccess) SoftDelete(id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SoftDelete", id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SoftDelete indicates an expected call of SoftDelete.
+func (mr *MockDataAccessMockRecorder) SoftDelete(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SoftDelete", reflect.TypeOf((*MockDataAccess)(nil).SoftDelete), id)
+}
+
+// Update mocks base method.
+func (m *MockDataAccess) Update(item models.Coupon) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", item)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockDataAccessMockRecorder) Update(item interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockDataAccess)(nil).Update), item)
+}
+
+// WithTx mocks base method.
+func (m *MockDataAccess) WithTx(tx *gorm.DB) couponDao.DataAccess {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WithTx", tx)
+	ret0, _ := ret[0].(couponDao.DataAccess)
+	return ret0
+}
+
+// WithTx indicates an expected call of WithTx.
+func (mr *MockDataAccessMockRecorder) WithTx(tx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithTx", reflect.TypeOf((*MockDataAccess)(nil).WithTx), tx)
+}
@@ -0,0 +1,65 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: currencyDao.go
+
+// Package currencyDao is a generated GoMock package.
+package currencyDao
+
+import (
+	reflect "reflect"
+
+	models "github.com/abdulmalikraji/e-commerce/db/models"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockDataAccess is a mock of DataAccess interface.
+type MockDataAccess struct {
+	ctrl     *gomock.Controller
+	recorder *MockDataAccessMockRecorder
+}
+
+// MockDataAccessMockRecorder is the mock recorder for MockDataAccess.
+type MockDataAccessMockRecorder struct {
+	mock *MockDataAccess
+}
+
+// NewMockDataAccess creates a new mock instance.
+func NewMockDataAccess(ctrl *gomock.Controller) *MockDataAccess {
+	mock := &MockDataAccess{ctrl: ctrl}
+	mock.recorder = &MockDataAccessMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDataAccess) EXPECT() *MockDataAccessMockRecorder {
+	return m.recorder
+}
+
+// FindAll mocks base method.
+func (m *MockDataAccess) FindAll() ([]models.Currency, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindAll")
+	ret0, _ := ret[0].([]models.Currency)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindAll indicates an expected call of FindAll.
+func (mr *MockDataAccessMockRecorder) FindAll() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindAll", reflect.TypeOf((*MockDataAccess)(nil).FindAll))
+}
+
+// FindById mocks base method.
+func (m *MockDataAccess) FindById(id string) (models.Currency, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindById", id)
+	ret0, _ := ret[0].(models.Currency)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindById indicates an expected call of FindById.
+func (mr *MockDataAccessMockRecorder) FindById(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindById", reflect.TypeOf((*MockDataAccess)(nil).FindById), id)
+}
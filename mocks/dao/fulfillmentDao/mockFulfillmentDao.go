@@ -0,0 +1,138 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: fulfillmentDao.go
+
+// Package fulfillmentDao is a generated GoMock package.
+package fulfillmentDao
+
+import (
+	reflect "reflect"
+
+	models "github.com/abdulmalikraji/e-commerce/db/models"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockDataAccess is a mock of DataAccess interface.
+type MockDataAccess struct {
+	ctrl     *gomock.Controller
+	recorder *MockDataAccessMockRecorder
+}
+
+// MockDataAccessMockRecorder is the mock recorder for MockDataAccess.
+type MockDataAccessMockRecorder struct {
+	mock *MockDataAccess
+}
+
+// NewMockDataAccess creates a new mock instance.
+func NewMockDataAccess(ctrl *gomock.Controller) *MockDataAccess {
+	mock := &MockDataAccess{ctrl: ctrl}
+	mock.recorder = &MockDataAccessMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDataAccess) EXPECT() *MockDataAccessMockRecorder {
+	return m.recorder
+}
+
+// FindById mocks base method.
+func (m *MockDataAccess) FindById(id string) (models.Fulfillment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindById", id)
+	ret0, _ := ret[0].(models.Fulfillment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindById indicates an expected call of FindById.
+func (mr *MockDataAccessMockRecorder) FindById(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindById", reflect.TypeOf((*MockDataAccess)(nil).FindById), id)
+}
+
+// FindByIdAndStore mocks base method.
+func (m *MockDataAccess) FindByIdAndStore(id, storeId string) (models.Fulfillment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByIdAndStore", id, storeId)
+	ret0, _ := ret[0].(models.Fulfillment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByIdAndStore indicates an expected call of FindByIdAndStore.
+func (mr *MockDataAccessMockRecorder) FindByIdAndStore(id, storeId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByIdAndStore", reflect.TypeOf((*MockDataAccess)(nil).FindByIdAndStore), id, storeId)
+}
+
+// FindByOrderId mocks base method.
+func (m *MockDataAccess) FindByOrderId(orderId string) ([]models.Fulfillment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByOrderId", orderId)
+	ret0, _ := ret[0].([]models.Fulfillment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByOrderId indicates an expected call of FindByOrderId.
+func (mr *MockDataAccessMockRecorder) FindByOrderId(orderId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByOrderId", reflect.TypeOf((*MockDataAccess)(nil).FindByOrderId), orderId)
+}
+
+// FindByStoreId mocks base method.
+func (m *MockDataAccess) FindByStoreId(storeId string, status *string) ([]models.Fulfillment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByStoreId", storeId, status)
+	ret0, _ := ret[0].([]models.Fulfillment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByStoreId indicates an expected call of FindByStoreId.
+func (mr *MockDataAccessMockRecorder) FindByStoreId(storeId, status interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByStoreId", reflect.TypeOf((*MockDataAccess)(nil).FindByStoreId), storeId, status)
+}
+
+// Insert mocks base method.
+func (m *MockDataAccess) Insert(item models.Fulfillment) (models.Fulfillment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Insert", item)
+	ret0, _ := ret[0].(models.Fulfillment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Insert indicates an expected call of Insert.
+func (mr *MockDataAccessMockRecorder) Insert(item interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Insert", reflect.TypeOf((*MockDataAccess)(nil).Insert), item)
+}
+
+// SoftDelete mocks base method.
+func (m *MockDataAccess) SoftDelete(id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SoftDelete", id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SoftDelete indicates an expected call of SoftDelete.
+func (mr *MockDataAccessMockRecorder) SoftDelete(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SoftDelete", reflect.TypeOf((*MockDataAccess)(nil).SoftDelete), id)
+}
+
+// Update mocks base method.
+func (m *MockDataAccess) Update(item models.Fulfillment) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", item)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockDataAccessMockRecorder) Update(item interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockDataAccess)(nil).Update), item)
+}
@@ -0,0 +1,124 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: importJobDao.go
+
+// Package importJobDao is a generated GoMock package.
+package importJobDao
+
+import (
+	reflect "reflect"
+	time "time"
+
+	models "github.com/abdulmalikraji/e-commerce/db/models"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockDataAccess is a mock of DataAccess interface.
+type MockDataAccess struct {
+	ctrl     *gomock.Controller
+	recorder *MockDataAccessMockRecorder
+}
+
+// MockDataAccessMockRecorder is the mock recorder for MockDataAccess.
+type MockDataAccessMockRecorder struct {
+	mock *MockDataAccess
+}
+
+// NewMockDataAccess creates a new mock instance.
+func NewMockDataAccess(ctrl *gomock.Controller) *MockDataAccess {
+	mock := &MockDataAccess{ctrl: ctrl}
+	mock.recorder = &MockDataAccessMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDataAccess) EXPECT() *MockDataAccessMockRecorder {
+	return m.recorder
+}
+
+// ClaimNext mocks base method.
+func (m *MockDataAccess) ClaimNext(staleAfter time.Time) (models.ImportJob, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimNext", staleAfter)
+	ret0, _ := ret[0].(models.ImportJob)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ClaimNext indicates an expected call of ClaimNext.
+func (mr *MockDataAccessMockRecorder) ClaimNext(staleAfter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimNext", reflect.TypeOf((*MockDataAccess)(nil).ClaimNext), staleAfter)
+}
+
+// FindByIdAndStore mocks base method.
+func (m *MockDataAccess) FindByIdAndStore(id, storeId string) (models.ImportJob, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByIdAndStore", id, storeId)
+	ret0, _ := ret[0].(models.ImportJob)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByIdAndStore indicates an expected call of FindByIdAndStore.
+func (mr *MockDataAccessMockRecorder) FindByIdAndStore(id, storeId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByIdAndStore", reflect.TypeOf((*MockDataAccess)(nil).FindByIdAndStore), id, storeId)
+}
+
+// Finish mocks base method.
+func (m *MockDataAccess) Finish(item models.ImportJob) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Finish", item)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Finish indicates an expected call of Finish.
+func (mr *MockDataAccessMockRecorder) Finish(item interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Finish", reflect.TypeOf((*MockDataAccess)(nil).Finish), item)
+}
+
+// Insert mocks base method.
+func (m *MockDataAccess) Insert(item models.ImportJob) (models.ImportJob, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Insert", item)
+	ret0, _ := ret[0].(models.ImportJob)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Insert indicates an expected call of Insert.
+func (mr *MockDataAccessMockRecorder) Insert(item interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Insert", reflect.TypeOf((*MockDataAccess)(nil).Insert), item)
+}
+
+// Requeue mocks base method.
+func (m *MockDataAccess) Requeue(item models.ImportJob) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Requeue", item)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Requeue indicates an expected call of Requeue.
+func (mr *MockDataAccessMockRecorder) Requeue(item interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Requeue", reflect.TypeOf((*MockDataAccess)(nil).Requeue), item)
+}
+
+// UpdateProgress mocks base method.
+func (m *MockDataAccess) UpdateProgress(item models.ImportJob) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateProgress", item)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateProgress indicates an expected call of UpdateProgress.
+func (mr *MockDataAccessMockRecorder) UpdateProgress(item interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateProgress", reflect.TypeOf((*MockDataAccess)(nil).UpdateProgress), item)
+}
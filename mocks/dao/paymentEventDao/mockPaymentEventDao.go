@@ -0,0 +1,81 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: paymentEventDao.go
+
+// Package paymentEventDao is a generated GoMock package.
+package paymentEventDao
+
+import (
+	reflect "reflect"
+
+	paymentEventDao "github.com/abdulmalikraji/e-commerce/db/dao/paymentEventDao"
+	models "github.com/abdulmalikraji/e-commerce/db/models"
+	gomock "github.com/golang/mock/gomock"
+	gorm "gorm.io/gorm"
+)
+
+// MockDataAccess is a mock of DataAccess interface.
+type MockDataAccess struct {
+	ctrl     *gomock.Controller
+	recorder *MockDataAccessMockRecorder
+}
+
+// MockDataAccessMockRecorder is the mock recorder for MockDataAccess.
+type MockDataAccessMockRecorder struct {
+	mock *MockDataAccess
+}
+
+// NewMockDataAccess creates a new mock instance.
+func NewMockDataAccess(ctrl *gomock.Controller) *MockDataAccess {
+	mock := &MockDataAccess{ctrl: ctrl}
+	mock.recorder = &MockDataAccessMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDataAccess) EXPECT() *MockDataAccessMockRecorder {
+	return m.recorder
+}
+
+// FindByEventId mocks base method.
+func (m *MockDataAccess) FindByEventId(provider, eventId string) (models.PaymentEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByEventId", provider, eventId)
+	ret0, _ := ret[0].(models.PaymentEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByEventId indicates an expected call of FindByEventId.
+func (mr *MockDataAccessMockRecorder) FindByEventId(provider, eventId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByEventId", reflect.TypeOf((*MockDataAccess)(nil).FindByEventId), provider, eventId)
+}
+
+// Record mocks base method.
+func (m *MockDataAccess) Record(item models.PaymentEvent) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Record", item)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Record indicates an expected call of Record.
+func (mr *MockDataAccessMockRecorder) Record(item interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Record", reflect.TypeOf((*MockDataAccess)(nil).Record), item)
+}
+
+// WithTx mocks base method.
+func (m *MockDataAccess) WithTx(tx *gorm.DB) paymentEventDao.DataAccess {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WithTx", tx)
+	ret0, _ := ret[0].(paymentEventDao.DataAccess)
+	return ret0
+}
+
+// WithTx indicates an expected call of WithTx.
+func (mr *MockDataAccessMockRecorder) WithTx(tx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithTx", reflect.TypeOf((*MockDataAccess)(nil).WithTx), tx)
+}
@@ -0,0 +1,436 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: productDao.go
+
+// Package productDao is a generated GoMock package.
+package productDao
+
+import (
+	reflect "reflect"
+
+	productDao "github.com/abdulmalikraji/e-commerce/db/dao/productDao"
+	models "github.com/abdulmalikraji/e-commerce/db/models"
+	productDto "github.com/abdulmalikraji/e-commerce/dto/productDto"
+	gomock "github.com/golang/mock/gomock"
+	uuid "github.com/google/uuid"
+	gorm "gorm.io/gorm"
+)
+
+// MockDataAccess is a mock of DataAccess interface.
+type MockDataAccess struct {
+	ctrl     *gomock.Controller
+	recorder *MockDataAccessMockRecorder
+}
+
+// MockDataAccessMockRecorder is the mock recorder for MockDataAccess.
+type MockDataAccessMockRecorder struct {
+	mock *MockDataAccess
+}
+
+// NewMockDataAccess creates a new mock instance.
+func NewMockDataAccess(ctrl *gomock.Controller) *MockDataAccess {
+	mock := &MockDataAccess{ctrl: ctrl}
+	mock.recorder = &MockDataAccessMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDataAccess) EXPECT() *MockDataAccessMockRecorder {
+	return m.recorder
+}
+
+// BarcodeTaken mocks base method.
+func (m *MockDataAccess) BarcodeTaken(barcode, exceptId string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BarcodeTaken", barcode, exceptId)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BarcodeTaken indicates an expected call of BarcodeTaken.
+func (mr *MockDataAccessMockRecorder) BarcodeTaken(barcode, exceptId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BarcodeTaken", reflect.TypeOf((*MockDataAccess)(nil).BarcodeTaken), barcode, exceptId)
+}
+
+// Delete mocks base method.
+func (m *MockDataAccess) Delete(id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockDataAccessMockRecorder) Delete(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockDataAccess)(nil).Delete), id)
+}
+
+// FindAll mocks base method.
+func (m *MockDataAccess) FindAll() ([]models.Product, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindAll")
+	ret0, _ := ret[0].([]models.Product)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindAll indicates an expected call of FindAll.
+func (mr *MockDataAccessMockRecorder) FindAll() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindAll", reflect.TypeOf((*MockDataAccess)(nil).FindAll))
+}
+
+// FindBatchByStore mocks base method.
+func (m *MockDataAccess) FindBatchByStore(storeId string, afterId uuid.UUID, limit int) ([]models.Product, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindBatchByStore", storeId, afterId, limit)
+	ret0, _ := ret[0].([]models.Product)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindBatchByStore indicates an expected call of FindBatchByStore.
+func (mr *MockDataAccessMockRecorder) FindBatchByStore(storeId, afterId, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindBatchByStore", reflect.TypeOf((*MockDataAccess)(nil).FindBatchByStore), storeId, afterId, limit)
+}
+
+// FindByBarcodeAndStore mocks base method.
+func (m *MockDataAccess) FindByBarcodeAndStore(barcode, storeId string) (models.Product, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByBarcodeAndStore", barcode, storeId)
+	ret0, _ := ret[0].(models.Product)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByBarcodeAndStore indicates an expected call of FindByBarcodeAndStore.
+func (mr *MockDataAccessMockRecorder) FindByBarcodeAndStore(barcode, storeId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByBarcodeAndStore", reflect.TypeOf((*MockDataAccess)(nil).FindByBarcodeAndStore), barcode, storeId)
+}
+
+// FindByCategoryId mocks base method.
+func (m *MockDataAccess) FindByCategoryId(categoryId string) ([]models.Product, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByCategoryId", categoryId)
+	ret0, _ := ret[0].([]models.Product)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByCategoryId indicates an expected call of FindByCategoryId.
+func (mr *MockDataAccessMockRecorder) FindByCategoryId(categoryId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByCategoryId", reflect.TypeOf((*MockDataAccess)(nil).FindByCategoryId), categoryId)
+}
+
+// FindByFilter mocks base method.
+func (m *MockDataAccess) FindByFilter(filter productDto.ProductFilter) ([]models.Product, int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByFilter", filter)
+	ret0, _ := ret[0].([]models.Product)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// FindByFilter indicates an expected call of FindByFilter.
+func (mr *MockDataAccessMockRecorder) FindByFilter(filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByFilter", reflect.TypeOf((*MockDataAccess)(nil).FindByFilter), filter)
+}
+
+// FindById mocks base method.
+func (m *MockDataAccess) FindById(id string) (models.Product, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindById", id)
+	ret0, _ := ret[0].(models.Product)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindById indicates an expected call of FindById.
+func (mr *MockDataAccessMockRecorder) FindById(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindById", reflect.TypeOf((*MockDataAccess)(nil).FindById), id)
+}
+
+// FindByIdAndStore mocks base method.
+func (m *MockDataAccess) FindByIdAndStore(id, storeId string) (models.Product, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByIdAndStore", id, storeId)
+	ret0, _ := ret[0].(models.Product)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByIdAndStore indicates an expected call of FindByIdAndStore.
+func (mr *MockDataAccessMockRecorder) FindByIdAndStore(id, storeId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByIdAndStore", reflect.TypeOf((*MockDataAccess)(nil).FindByIdAndStore), id, storeId)
+}
+
+// FindByIds mocks base method.
+func (m *MockDataAccess) FindByIds(ids []string) ([]models.Product, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByIds", ids)
+	ret0, _ := ret[0].([]models.Product)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByIds indicates an expected call of FindByIds.
+func (mr *MockDataAccessMockRecorder) FindByIds(ids interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByIds", reflect.TypeOf((*MockDataAccess)(nil).FindByIds), ids)
+}
+
+// FindByName mocks base method.
+func (m *MockDataAccess) FindByName(name string) (models.Product, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByName", name)
+	ret0, _ := ret[0].(models.Product)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByName indicates an expected call of FindByName.
+func (mr *MockDataAccessMockRecorder) FindByName(name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByName", reflect.TypeOf((*MockDataAccess)(nil).FindByName), name)
+}
+
+// FindByStoreId mocks base method.
+func (m *MockDataAccess) FindByStoreId(storeId string) ([]models.Product, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByStoreId", storeId)
+	ret0, _ := ret[0].([]models.Product)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByStoreId indicates an expected call of FindByStoreId.
+func (mr *MockDataAccessMockRecorder) FindByStoreId(storeId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByStoreId", reflect.TypeOf((*MockDataAccess)(nil).FindByStoreId), storeId)
+}
+
+// FindPopular mocks base method.
+func (m *MockDataAccess) FindPopular() ([]models.Product, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindPopular")
+	ret0, _ := ret[0].([]models.Product)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindPopular indicates an expected call of FindPopular.
+func (mr *MockDataAccessMockRecorder) FindPopular() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindPopular", reflect.TypeOf((*MockDataAccess)(nil).FindPopular))
+}
+
+// FindPopularByStoreId mocks base method.
+func (m *MockDataAccess) FindPopularByStoreId(storeId string) ([]models.Product, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindPopularByStoreId", storeId)
+	ret0, _ := ret[0].([]models.Product)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindPopularByStoreId indicates an expected call of FindPopularByStoreId.
+func (mr *MockDataAccessMockRecorder) FindPopularByStoreId(storeId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindPopularByStoreId", reflect.TypeOf((*MockDataAccess)(nil).FindPopularByStoreId), storeId)
+}
+
+// FindProductReviews mocks base method.
+func (m *MockDataAccess) FindProductReviews(id string) (models.Product, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindProductReviews", id)
+	ret0, _ := ret[0].(models.Product)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindProductReviews indicates an expected call of FindProductReviews.
+func (mr *MockDataAccessMockRecorder) FindProductReviews(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindProductReviews", reflect.TypeOf((*MockDataAccess)(nil).FindProductReviews), id)
+}
+
+// Insert mocks base method.
+func (m *MockDataAccess) Insert(item models.Product) (models.Product, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Insert", item)
+	ret0, _ := ret[0].(models.Product)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Insert indicates an expected call of Insert.
+func (mr *MockDataAccessMockRecorder) Insert(item interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Insert", reflect.TypeOf((*MockDataAccess)(nil).Insert), item)
+}
+
+// RefreshCategorySearchVectors mocks base method.
+func (m *MockDataAccess) RefreshCategorySearchVectors(categoryId string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RefreshCategorySearchVectors", categoryId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RefreshCategorySearchVectors indicates an expected call of RefreshCategorySearchVectors.
+func (mr *MockDataAccessMockRecorder) RefreshCategorySearchVectors(categoryId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RefreshCategorySearchVectors", reflect.TypeOf((*MockDataAccess)(nil).RefreshCategorySearchVectors), categoryId)
+}
+
+// RefreshSearchVector mocks base method.
+func (m *MockDataAccess) RefreshSearchVector(id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RefreshSearchVector", id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RefreshSearchVector indicates an expected call of RefreshSearchVector.
+func (mr *MockDataAccessMockRecorder) RefreshSearchVector(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RefreshSearchVector", reflect.TypeOf((*MockDataAccess)(nil).RefreshSearchVector), id)
+}
+
+// ReplaceImages mocks base method.
+func (m *MockDataAccess) ReplaceImages(productId uuid.UUID, images []models.ProductImage) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReplaceImages", productId, images)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReplaceImages indicates an expected call of ReplaceImages.
+func (mr *MockDataAccessMockRecorder) ReplaceImages(productId, images interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReplaceImages", reflect.TypeOf((*MockDataAccess)(nil).ReplaceImages), productId, images)
+}
+
+// SaveOptions mocks base method.
+func (m *MockDataAccess) SaveOptions(productId uuid.UUID, options []models.ProductOption) ([]models.ProductOption, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveOptions", productId, options)
+	ret0, _ := ret[0].([]models.ProductOption)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SaveOptions indicates an expected call of SaveOptions.
+func (mr *MockDataAccessMockRecorder) SaveOptions(productId, options interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveOptions", reflect.TypeOf((*MockDataAccess)(nil).SaveOptions), productId, options)
+}
+
+// Search mocks base method.
+func (m *MockDataAccess) Search(query string, page, pageSize int) ([]productDao.SearchResult, int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Search", query, page, pageSize)
+	ret0, _ := ret[0].([]productDao.SearchResult)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Search indicates an expected call of Search.
+func (mr *MockDataAccessMockRecorder) Search(query, page, pageSize interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Search", reflect.TypeOf((*MockDataAccess)(nil).Search), query, page, pageSize)
+}
+
+// SetClassification mocks base method.
+func (m *MockDataAccess) SetClassification(item models.Product) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetClassification", item)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetClassification indicates an expected call of SetClassification.
+func (mr *MockDataAccessMockRecorder) SetClassification(item interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetClassification", reflect.TypeOf((*MockDataAccess)(nil).SetClassification), item)
+}
+
+// SoftDelete mocks base method.
+func (m *MockDataAccess) SoftDelete(id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SoftDelete", id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SoftDelete indicates an expected call of SoftDelete.
+func (mr *MockDataAccessMockRecorder) SoftDelete(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SoftDelete", reflect.TypeOf((*MockDataAccess)(nil).SoftDelete), id)
+}
+
+// Transaction mocks base method.
+func (m *MockDataAccess) Transaction(fn func(*gorm.DB) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Transaction", fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Transaction indicates an expected call of Transaction.
+func (mr *MockDataAccessMockRecorder) Transaction(fn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Transaction", reflect.TypeOf((*MockDataAccess)(nil).Transaction), fn)
+}
+
+// Update mocks base method.
+func (m *MockDataAccess) Update(item models.Product) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", item)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockDataAccessMockRecorder) Update(item interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockDataAccess)(nil).Update), item)
+}
+
+// UpdateDetails mocks base method.
+func (m *MockDataAccess) UpdateDetails(item models.Product) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateDetails", item)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateDetails indicates an expected call of UpdateDetails.
+func (mr *MockDataAccessMockRecorder) UpdateDetails(item interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateDetails", reflect.TypeOf((*MockDataAccess)(nil).UpdateDetails), item)
+}
+
+// WithTx mocks base method.
+func (m *MockDataAccess) WithTx(tx *gorm.DB) productDao.DataAccess {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WithTx", tx)
+	ret0, _ := ret[0].(productDao.DataAccess)
+	return ret0
+}
+
+// WithTx indicates an expected call of WithTx.
+func (mr *MockDataAccessMockRecorder) WithTx(tx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithTx", reflect.TypeOf((*MockDataAccess)(nil).WithTx), tx)
+}
@@ -0,0 +1,137 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: productImageDao.go
+
+// Package productImageDao is a generated GoMock package.
+package productImageDao
+
+import (
+	reflect "reflect"
+
+	models "github.com/abdulmalikraji/e-commerce/db/models"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockDataAccess is a mock of DataAccess interface.
+type MockDataAccess struct {
+	ctrl     *gomock.Controller
+	recorder *MockDataAccessMockRecorder
+}
+
+// MockDataAccessMockRecorder is the mock recorder for MockDataAccess.
+type MockDataAccessMockRecorder struct {
+	mock *MockDataAccess
+}
+
+// NewMockDataAccess creates a new mock instance.
+func NewMockDataAccess(ctrl *gomock.Controller) *MockDataAccess {
+	mock := &MockDataAccess{ctrl: ctrl}
+	mock.recorder = &MockDataAccessMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDataAccess) EXPECT() *MockDataAccessMockRecorder {
+	return m.recorder
+}
+
+// Append mocks base method.
+func (m *MockDataAccess) Append(item models.ProductImage, limit int) (models.ProductImage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Append", item, limit)
+	ret0, _ := ret[0].(models.ProductImage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Append indicates an expected call of Append.
+func (mr *MockDataAccessMockRecorder) Append(item, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Append", reflect.TypeOf((*MockDataAccess)(nil).Append), item, limit)
+}
+
+// Delete mocks base method.
+func (m *MockDataAccess) Delete(item models.ProductImage) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", item)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockDataAccessMockRecorder) Delete(item interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockDataAccess)(nil).Delete), item)
+}
+
+// FindById mocks base method.
+func (m *MockDataAccess) FindById(id, productId string) (models.ProductImage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindById", id, productId)
+	ret0, _ := ret[0].(models.ProductImage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindById indicates an expected call of FindById.
+func (mr *MockDataAccessMockRecorder) FindById(id, productId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindById", reflect.TypeOf((*MockDataAccess)(nil).FindById), id, productId)
+}
+
+// FindByProductId mocks base method.
+func (m *MockDataAccess) FindByProductId(productId string) ([]models.ProductImage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByProductId", productId)
+	ret0, _ := ret[0].([]models.ProductImage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByProductId indicates an expected call of FindByProductId.
+func (mr *MockDataAccessMockRecorder) FindByProductId(productId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByProductId", reflect.TypeOf((*MockDataAccess)(nil).FindByProductId), productId)
+}
+
+// Insert mocks base method.
+func (m *MockDataAccess) Insert(item models.ProductImage) (models.ProductImage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Insert", item)
+	ret0, _ := ret[0].(models.ProductImage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Insert indicates an expected call of Insert.
+func (mr *MockDataAccessMockRecorder) Insert(item interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Insert", reflect.TypeOf((*MockDataAccess)(nil).Insert), item)
+}
+
+// Reorder mocks base method.
+func (m *MockDataAccess) Reorder(productId string, ids []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Reorder", productId, ids)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Reorder indicates an expected call of Reorder.
+func (mr *MockDataAccessMockRecorder) Reorder(productId, ids interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reorder", reflect.TypeOf((*MockDataAccess)(nil).Reorder), productId, ids)
+}
+
+// SetPrimary mocks base method.
+func (m *MockDataAccess) SetPrimary(productId, id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetPrimary", productId, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetPrimary indicates an expected call of SetPrimary.
+func (mr *MockDataAccessMockRecorder) SetPrimary(productId, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetPrimary", reflect.TypeOf((*MockDataAccess)(nil).SetPrimary), productId, id)
+}
@@ -0,0 +1,225 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: productVariantDao.go
+
+// Package productVariantDao is a generated GoMock package.
+package productVariantDao
+
+import (
+	reflect "reflect"
+
+	productvariantdao "github.com/abdulmalikraji/e-commerce/db/dao/productVariantDao"
+	models "github.com/abdulmalikraji/e-commerce/db/models"
+	gomock "github.com/golang/mock/gomock"
+	gorm "gorm.io/gorm"
+)
+
+// MockDataAccess is a mock of DataAccess interface.
+type MockDataAccess struct {
+	ctrl     *gomock.Controller
+	recorder *MockDataAccessMockRecorder
+}
+
+// MockDataAccessMockRecorder is the mock recorder for MockDataAccess.
+type MockDataAccessMockRecorder struct {
+	mock *MockDataAccess
+}
+
+// NewMockDataAccess creates a new mock instance.
+func NewMockDataAccess(ctrl *gomock.Controller) *MockDataAccess {
+	mock := &MockDataAccess{ctrl: ctrl}
+	mock.recorder = &MockDataAccessMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDataAccess) EXPECT() *MockDataAccessMockRecorder {
+	return m.recorder
+}
+
+// Delete mocks base method.
+func (m *MockDataAccess) Delete(id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockDataAccessMockRecorder) Delete(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockDataAccess)(nil).Delete), id)
+}
+
+// FindAll mocks base method.
+func (m *MockDataAccess) FindAll() ([]models.ProductVariant, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindAll")
+	ret0, _ := ret[0].([]models.ProductVariant)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindAll indicates an expected call of FindAll.
+func (mr *MockDataAccessMockRecorder) FindAll() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindAll", reflect.TypeOf((*MockDataAccess)(nil).FindAll))
+}
+
+// FindByBarcodes mocks base method.
+func (m *MockDataAccess) FindByBarcodes(barcodes []string) ([]models.ProductVariant, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByBarcodes", barcodes)
+	ret0, _ := ret[0].([]models.ProductVariant)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByBarcodes indicates an expected call of FindByBarcodes.
+func (mr *MockDataAccessMockRecorder) FindByBarcodes(barcodes interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByBarcodes", reflect.TypeOf((*MockDataAccess)(nil).FindByBarcodes), barcodes)
+}
+
+// FindById mocks base method.
+func (m *MockDataAccess) FindById(id string) (models.ProductVariant, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindById", id)
+	ret0, _ := ret[0].(models.ProductVariant)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindById indicates an expected call of FindById.
+func (mr *MockDataAccessMockRecorder) FindById(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindById", reflect.TypeOf((*MockDataAccess)(nil).FindById), id)
+}
+
+// FindByProductId mocks base method.
+func (m *MockDataAccess) FindByProductId(productId string) ([]models.ProductVariant, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByProductId", productId)
+	ret0, _ := ret[0].([]models.ProductVariant)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByProductId indicates an expected call of FindByProductId.
+func (mr *MockDataAccessMockRecorder) FindByProductId(productId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByProductId", reflect.TypeOf((*MockDataAccess)(nil).FindByProductId), productId)
+}
+
+// FindBySKUs mocks base method.
+func (m *MockDataAccess) FindBySKUs(skus []string) ([]models.ProductVariant, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindBySKUs", skus)
+	ret0, _ := ret[0].([]models.ProductVariant)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindBySKUs indicates an expected call of FindBySKUs.
+func (mr *MockDataAccessMockRecorder) FindBySKUs(skus interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindBySKUs", reflect.TypeOf((*MockDataAccess)(nil).FindBySKUs), skus)
+}
+
+// Insert mocks base method.
+func (m *MockDataAccess) Insert(item models.ProductVariant) (models.ProductVariant, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Insert", item)
+	ret0, _ := ret[0].(models.ProductVariant)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Insert indicates an expected call of Insert.
+func (mr *MockDataAccessMockRecorder) Insert(item interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Insert", reflect.TypeOf((*MockDataAccess)(nil).Insert), item)
+}
+
+// ReplaceImages mocks base method.
+func (m *MockDataAccess) ReplaceImages(item models.ProductVariant) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReplaceImages", item)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReplaceImages indicates an expected call of ReplaceImages.
+func (mr *MockDataAccessMockRecorder) ReplaceImages(item interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReplaceImages", reflect.TypeOf((*MockDataAccess)(nil).ReplaceImages), item)
+}
+
+// ReplaceOptionValues mocks base method.
+func (m *MockDataAccess) ReplaceOptionValues(item models.ProductVariant) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReplaceOptionValues", item)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReplaceOptionValues indicates an expected call of ReplaceOptionValues.
+func (mr *MockDataAccessMockRecorder) ReplaceOptionValues(item interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReplaceOptionValues", reflect.TypeOf((*MockDataAccess)(nil).ReplaceOptionValues), item)
+}
+
+// SoftDelete mocks base method.
+func (m *MockDataAccess) SoftDelete(id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SoftDelete", id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SoftDelete indicates an expected call of SoftDelete.
+func (mr *MockDataAccessMockRecorder) SoftDelete(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SoftDelete", reflect.TypeOf((*MockDataAccess)(nil).SoftDelete), id)
+}
+
+// Update mocks base method.
+func (m *MockDataAccess) Update(item models.ProductVariant) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", item)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockDataAccessMockRecorder) Update(item interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockDataAccess)(nil).Update), item)
+}
+
+// UpdateDetails mocks base method.
+func (m *MockDataAccess) UpdateDetails(item models.ProductVariant) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateDetails", item)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateDetails indicates an expected call of UpdateDetails.
+func (mr *MockDataAccessMockRecorder) UpdateDetails(item interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateDetails", reflect.TypeOf((*MockDataAccess)(nil).UpdateDetails), item)
+}
+
+// WithTx mocks base method.
+func (m *MockDataAccess) WithTx(tx *gorm.DB) productvariantdao.DataAccess {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WithTx", tx)
+	ret0, _ := ret[0].(productvariantdao.DataAccess)
+	return ret0
+}
+
+// WithTx indicates an expected call of WithTx.
+func (mr *MockDataAccessMockRecorder) WithTx(tx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithTx", reflect.TypeOf((*MockDataAccess)(nil).WithTx), tx)
+}
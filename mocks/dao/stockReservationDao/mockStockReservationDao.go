@@ -0,0 +1,155 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: stockReservationDao.go
+
+// Package stockReservationDao is a generated GoMock package.
+package stockReservationDao
+
+import (
+	reflect "reflect"
+	time "time"
+
+	stockReservationDao "github.com/abdulmalikraji/e-commerce/db/dao/stockReservationDao"
+	models "github.com/abdulmalikraji/e-commerce/db/models"
+	gomock "github.com/golang/mock/gomock"
+	uuid "github.com/google/uuid"
+	gorm "gorm.io/gorm"
+)
+
+// MockDataAccess is a mock of DataAccess interface.
+type MockDataAccess struct {
+	ctrl     *gomock.Controller
+	recorder *MockDataAccessMockRecorder
+}
+
+// MockDataAccessMockRecorder is the mock recorder for MockDataAccess.
+type MockDataAccessMockRecorder struct {
+	mock *MockDataAccess
+}
+
+// NewMockDataAccess creates a new mock instance.
+func NewMockDataAccess(ctrl *gomock.Controller) *MockDataAccess {
+	mock := &MockDataAccess{ctrl: ctrl}
+	mock.recorder = &MockDataAccessMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDataAccess) EXPECT() *MockDataAccessMockRecorder {
+	return m.recorder
+}
+
+// Commit mocks base method.
+func (m *MockDataAccess) Commit(orderID uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Commit", orderID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Commit indicates an expected call of Commit.
+func (mr *MockDataAccessMockRecorder) Commit(orderID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Commit", reflect.TypeOf((*MockDataAccess)(nil).Commit), orderID)
+}
+
+// FindById mocks base method.
+func (m *MockDataAccess) FindById(id string) (models.StockReservation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindById", id)
+	ret0, _ := ret[0].(models.StockReservation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindById indicates an expected call of FindById.
+func (mr *MockDataAccessMockRecorder) FindById(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindById", reflect.TypeOf((*MockDataAccess)(nil).FindById), id)
+}
+
+// FindByOrderId mocks base method.
+func (m *MockDataAccess) FindByOrderId(orderId string) ([]models.StockReservation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByOrderId", orderId)
+	ret0, _ := ret[0].([]models.StockReservation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByOrderId indicates an expected call of FindByOrderId.
+func (mr *MockDataAccessMockRecorder) FindByOrderId(orderId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByOrderId", reflect.TypeOf((*MockDataAccess)(nil).FindByOrderId), orderId)
+}
+
+// Release mocks base method.
+func (m *MockDataAccess) Release(orderID uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Release", orderID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Release indicates an expected call of Release.
+func (mr *MockDataAccessMockRecorder) Release(orderID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Release", reflect.TypeOf((*MockDataAccess)(nil).Release), orderID)
+}
+
+// ReleaseExpired mocks base method.
+func (m *MockDataAccess) ReleaseExpired(now time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReleaseExpired", now)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReleaseExpired indicates an expected call of ReleaseExpired.
+func (mr *MockDataAccessMockRecorder) ReleaseExpired(now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReleaseExpired", reflect.TypeOf((*MockDataAccess)(nil).ReleaseExpired), now)
+}
+
+// ReleaseUnits mocks base method.
+func (m *MockDataAccess) ReleaseUnits(orderID, productID uuid.UUID, quantity int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReleaseUnits", orderID, productID, quantity)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReleaseUnits indicates an expected call of ReleaseUnits.
+func (mr *MockDataAccessMockRecorder) ReleaseUnits(orderID, productID, quantity interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReleaseUnits", reflect.TypeOf((*MockDataAccess)(nil).ReleaseUnits), orderID, productID, quantity)
+}
+
+// Reserve mocks base method.
+func (m *MockDataAccess) Reserve(productID uuid.UUID, orderID *uuid.UUID, quantity int, ttl time.Duration) ([]models.StockReservation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Reserve", productID, orderID, quantity, ttl)
+	ret0, _ := ret[0].([]models.StockReservation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Reserve indicates an expected call of Reserve.
+func (mr *MockDataAccessMockRecorder) Reserve(productID, orderID, quantity, ttl interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reserve", reflect.TypeOf((*MockDataAccess)(nil).Reserve), productID, orderID, quantity, ttl)
+}
+
+// WithTx mocks base method.
+func (m *MockDataAccess) WithTx(tx *gorm.DB) stockReservationDao.DataAccess {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WithTx", tx)
+	ret0, _ := ret[0].(stockReservationDao.DataAccess)
+	return ret0
+}
+
+// WithTx indicates an expected call of WithTx.
+func (mr *MockDataAccessMockRecorder) WithTx(tx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithTx", reflect.TypeOf((*MockDataAccess)(nil).WithTx), tx)
+}
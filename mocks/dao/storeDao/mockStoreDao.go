@@ -108,6 +108,21 @@ func (mr *MockDataAccessMockRecorder) FindByOwnerID(ownerId interface{}) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByOwnerID", reflect.TypeOf((*MockDataAccess)(nil).FindByOwnerID), ownerId)
 }
 
+// FindDeletedById mocks base method.
+func (m *MockDataAccess) FindDeletedById(id string) (models.Store, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindDeletedById", id)
+	ret0, _ := ret[0].(models.Store)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindDeletedById indicates an expected call of FindDeletedById.
+func (mr *MockDataAccessMockRecorder) FindDeletedById(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindDeletedById", reflect.TypeOf((*MockDataAccess)(nil).FindDeletedById), id)
+}
+
 // FindStoreProducts mocks base method.
 func (m *MockDataAccess) FindStoreProducts(storeId string) (models.Store, error) {
 	m.ctrl.T.Helper()
@@ -138,6 +153,35 @@ func (mr *MockDataAccessMockRecorder) Insert(item interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Insert", reflect.TypeOf((*MockDataAccess)(nil).Insert), item)
 }
 
+// NameTaken mocks base method.
+func (m *MockDataAccess) NameTaken(name, exceptId string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NameTaken", name, exceptId)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// NameTaken indicates an expected call of NameTaken.
+func (mr *MockDataAccessMockRecorder) NameTaken(name, exceptId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NameTaken", reflect.TypeOf((*MockDataAccess)(nil).NameTaken), name, exceptId)
+}
+
+// Restore mocks base method.
+func (m *MockDataAccess) Restore(id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Restore", id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Restore indicates an expected call of Restore.
+func (mr *MockDataAccessMockRecorder) Restore(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Restore", reflect.TypeOf((*MockDataAccess)(nil).Restore), id)
+}
+
 // SoftDelete mocks base method.
 func (m *MockDataAccess) SoftDelete(id string) error {
 	m.ctrl.T.Helper()
@@ -165,3 +209,45 @@ func (mr *MockDataAccessMockRecorder) Update(item interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockDataAccess)(nil).Update), item)
 }
+
+// UpdateDetails mocks base method.
+func (m *MockDataAccess) UpdateDetails(item models.Store) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateDetails", item)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateDetails indicates an expected call of UpdateDetails.
+func (mr *MockDataAccessMockRecorder) UpdateDetails(item interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateDetails", reflect.TypeOf((*MockDataAccess)(nil).UpdateDetails), item)
+}
+
+// UpdateImage mocks base method.
+func (m *MockDataAccess) UpdateImage(id string, image *string, key string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateImage", id, image, key)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateImage indicates an expected call of UpdateImage.
+func (mr *MockDataAccessMockRecorder) UpdateImage(id, image, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateImage", reflect.TypeOf((*MockDataAccess)(nil).UpdateImage), id, image, key)
+}
+
+// UpdateSettings mocks base method.
+func (m *MockDataAccess) UpdateSettings(id string, settings models.StoreSettings) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateSettings", id, settings)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateSettings indicates an expected call of UpdateSettings.
+func (mr *MockDataAccessMockRecorder) UpdateSettings(id, settings interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateSettings", reflect.TypeOf((*MockDataAccess)(nil).UpdateSettings), id, settings)
+}
@@ -0,0 +1,182 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: storeInvitationDao.go
+
+// Package storeInvitationDao is a generated GoMock package.
+package storeInvitationDao
+
+import (
+	reflect "reflect"
+
+	models "github.com/abdulmalikraji/e-commerce/db/models"
+	gomock "github.com/golang/mock/gomock"
+	gorm "gorm.io/gorm"
+)
+
+// MockDataAccess is a mock of DataAccess interface.
+type MockDataAccess struct {
+	ctrl     *gomock.Controller
+	recorder *MockDataAccessMockRecorder
+}
+
+// MockDataAccessMockRecorder is the mock recorder for MockDataAccess.
+type MockDataAccessMockRecorder struct {
+	mock *MockDataAccess
+}
+
+// NewMockDataAccess creates a new mock instance.
+func NewMockDataAccess(ctrl *gomock.Controller) *MockDataAccess {
+	mock := &MockDataAccess{ctrl: ctrl}
+	mock.recorder = &MockDataAccessMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDataAccess) EXPECT() *MockDataAccessMockRecorder {
+	return m.recorder
+}
+
+// FindById mocks base method.
+func (m *MockDataAccess) FindById(id string) (models.StoreInvitation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindById", id)
+	ret0, _ := ret[0].(models.StoreInvitation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindById indicates an expected call of FindById.
+func (mr *MockDataAccessMockRecorder) FindById(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindById", reflect.TypeOf((*MockDataAccess)(nil).FindById), id)
+}
+
+// FindByIdAndStore mocks base method.
+func (m *MockDataAccess) FindByIdAndStore(id, storeId string) (models.StoreInvitation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByIdAndStore", id, storeId)
+	ret0, _ := ret[0].(models.StoreInvitation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByIdAndStore indicates an expected call of FindByIdAndStore.
+func (mr *MockDataAccessMockRecorder) FindByIdAndStore(id, storeId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByIdAndStore", reflect.TypeOf((*MockDataAccess)(nil).FindByIdAndStore), id, storeId)
+}
+
+// FindByStoreId mocks base method.
+func (m *MockDataAccess) FindByStoreId(storeId string, status *string) ([]models.StoreInvitation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByStoreId", storeId, status)
+	ret0, _ := ret[0].([]models.StoreInvitation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByStoreId indicates an expected call of FindByStoreId.
+func (mr *MockDataAccessMockRecorder) FindByStoreId(storeId, status interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByStoreId", reflect.TypeOf((*MockDataAccess)(nil).FindByStoreId), storeId, status)
+}
+
+// FindByTokenHash mocks base method.
+func (m *MockDataAccess) FindByTokenHash(tokenHash string) (models.StoreInvitation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByTokenHash", tokenHash)
+	ret0, _ := ret[0].(models.StoreInvitation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByTokenHash indicates an expected call of FindByTokenHash.
+func (mr *MockDataAccessMockRecorder) FindByTokenHash(tokenHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByTokenHash", reflect.TypeOf((*MockDataAccess)(nil).FindByTokenHash), tokenHash)
+}
+
+// FindPendingByEmail mocks base method.
+func (m *MockDataAccess) FindPendingByEmail(storeId, email string) (models.StoreInvitation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindPendingByEmail", storeId, email)
+	ret0, _ := ret[0].(models.StoreInvitation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindPendingByEmail indicates an expected call of FindPendingByEmail.
+func (mr *MockDataAccessMockRecorder) FindPendingByEmail(storeId, email interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindPendingByEmail", reflect.TypeOf((*MockDataAccess)(nil).FindPendingByEmail), storeId, email)
+}
+
+// Insert mocks base method.
+func (m *MockDataAccess) Insert(item models.StoreInvitation) (models.StoreInvitation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Insert", item)
+	ret0, _ := ret[0].(models.StoreInvitation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Insert indicates an expected call of Insert.
+func (mr *MockDataAccessMockRecorder) Insert(item interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Insert", reflect.TypeOf((*MockDataAccess)(nil).Insert), item)
+}
+
+// SoftDelete mocks base method.
+func (m *MockDataAccess) SoftDelete(id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SoftDelete", id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SoftDelete indicates an expected call of SoftDelete.
+func (mr *MockDataAccessMockRecorder) SoftDelete(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SoftDelete", reflect.TypeOf((*MockDataAccess)(nil).SoftDelete), id)
+}
+
+// Transaction mocks base method.
+func (m *MockDataAccess) Transaction(fn func(*gorm.DB) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Transaction", fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Transaction indicates an expected call of Transaction.
+func (mr *MockDataAccessMockRecorder) Transaction(fn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Transaction", reflect.TypeOf((*MockDataAccess)(nil).Transaction), fn)
+}
+
+// Update mocks base method.
+func (m *MockDataAccess) Update(item models.StoreInvitation) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", item)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockDataAccessMockRecorder) Update(item interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockDataAccess)(nil).Update), item)
+}
+
+// UpdateStatus mocks base method.
+func (m *MockDataAccess) UpdateStatus(id, status string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateStatus", id, status)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateStatus indicates an expected call of UpdateStatus.
+func (mr *MockDataAccessMockRecorder) UpdateStatus(id, status interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateStatus", reflect.TypeOf((*MockDataAccess)(nil).UpdateStatus), id, status)
+}
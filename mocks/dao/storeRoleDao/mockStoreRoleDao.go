@@ -0,0 +1,183 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: storeRoleDao.go
+
+// Package storeRoleDao is a generated GoMock package.
+package storeRoleDao
+
+import (
+	reflect "reflect"
+
+	storeRoleDao "github.com/abdulmalikraji/e-commerce/db/dao/storeRoleDao"
+	models "github.com/abdulmalikraji/e-commerce/db/models"
+	gomock "github.com/golang/mock/gomock"
+	uuid "github.com/google/uuid"
+	gorm "gorm.io/gorm"
+)
+
+// MockDataAccess is a mock of DataAccess interface.
+type MockDataAccess struct {
+	ctrl     *gomock.Controller
+	recorder *MockDataAccessMockRecorder
+}
+
+// MockDataAccessMockRecorder is the mock recorder for MockDataAccess.
+type MockDataAccessMockRecorder struct {
+	mock *MockDataAccess
+}
+
+// NewMockDataAccess creates a new mock instance.
+func NewMockDataAccess(ctrl *gomock.Controller) *MockDataAccess {
+	mock := &MockDataAccess{ctrl: ctrl}
+	mock.recorder = &MockDataAccessMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDataAccess) EXPECT() *MockDataAccessMockRecorder {
+	return m.recorder
+}
+
+// Delete mocks base method.
+func (m *MockDataAccess) Delete(id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockDataAccessMockRecorder) Delete(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockDataAccess)(nil).Delete), id)
+}
+
+// FindActions mocks base method.
+func (m *MockDataAccess) FindActions() ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindActions")
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindActions indicates an expected call of FindActions.
+func (mr *MockDataAccessMockRecorder) FindActions() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindActions", reflect.TypeOf((*MockDataAccess)(nil).FindActions))
+}
+
+// FindByIdForStore mocks base method.
+func (m *MockDataAccess) FindByIdForStore(id, storeId string) (models.StoreRole, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByIdForStore", id, storeId)
+	ret0, _ := ret[0].(models.StoreRole)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByIdForStore indicates an expected call of FindByIdForStore.
+func (mr *MockDataAccessMockRecorder) FindByIdForStore(id, storeId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByIdForStore", reflect.TypeOf((*MockDataAccess)(nil).FindByIdForStore), id, storeId)
+}
+
+// FindByName mocks base method.
+func (m *MockDataAccess) FindByName(storeId, name string) (models.StoreRole, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByName", storeId, name)
+	ret0, _ := ret[0].(models.StoreRole)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByName indicates an expected call of FindByName.
+func (mr *MockDataAccessMockRecorder) FindByName(storeId, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByName", reflect.TypeOf((*MockDataAccess)(nil).FindByName), storeId, name)
+}
+
+// FindForStore mocks base method.
+func (m *MockDataAccess) FindForStore(storeId string) ([]models.StoreRole, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindForStore", storeId)
+	ret0, _ := ret[0].([]models.StoreRole)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindForStore indicates an expected call of FindForStore.
+func (mr *MockDataAccessMockRecorder) FindForStore(storeId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindForStore", reflect.TypeOf((*MockDataAccess)(nil).FindForStore), storeId)
+}
+
+// Insert mocks base method.
+func (m *MockDataAccess) Insert(item models.StoreRole) (models.StoreRole, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Insert", item)
+	ret0, _ := ret[0].(models.StoreRole)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Insert indicates an expected call of Insert.
+func (mr *MockDataAccessMockRecorder) Insert(item interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Insert", reflect.TypeOf((*MockDataAccess)(nil).Insert), item)
+}
+
+// ReplacePermissions mocks base method.
+func (m *MockDataAccess) ReplacePermissions(roleId uuid.UUID, actions []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReplacePermissions", roleId, actions)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReplacePermissions indicates an expected call of ReplacePermissions.
+func (mr *MockDataAccessMockRecorder) ReplacePermissions(roleId, actions interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReplacePermissions", reflect.TypeOf((*MockDataAccess)(nil).ReplacePermissions), roleId, actions)
+}
+
+// Transaction mocks base method.
+func (m *MockDataAccess) Transaction(fn func(*gorm.DB) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Transaction", fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Transaction indicates an expected call of Transaction.
+func (mr *MockDataAccessMockRecorder) Transaction(fn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Transaction", reflect.TypeOf((*MockDataAccess)(nil).Transaction), fn)
+}
+
+// Update mocks base method.
+func (m *MockDataAccess) Update(item models.StoreRole) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", item)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockDataAccessMockRecorder) Update(item interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockDataAccess)(nil).Update), item)
+}
+
+// WithTx mocks base method.
+func (m *MockDataAccess) WithTx(tx *gorm.DB) storeRoleDao.DataAccess {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WithTx", tx)
+	ret0, _ := ret[0].(storeRoleDao.DataAccess)
+	return ret0
+}
+
+// WithTx indicates an expected call of WithTx.
+func (mr *MockDataAccessMockRecorder) WithTx(tx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithTx", reflect.TypeOf((*MockDataAccess)(nil).WithTx), tx)
+}
@@ -0,0 +1,226 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: storeUserDao.go
+
+// Package storeUserDao is a generated GoMock package.
+package storeUserDao
+
+import (
+	reflect "reflect"
+
+	models "github.com/abdulmalikraji/e-commerce/db/models"
+	gomock "github.com/golang/mock/gomock"
+	uuid "github.com/google/uuid"
+)
+
+// MockDataAccess is a mock of DataAccess interface.
+type MockDataAccess struct {
+	ctrl     *gomock.Controller
+	recorder *MockDataAccessMockRecorder
+}
+
+// MockDataAccessMockRecorder is the mock recorder for MockDataAccess.
+type MockDataAccessMockRecorder struct {
+	mock *MockDataAccess
+}
+
+// NewMockDataAccess creates a new mock instance.
+func NewMockDataAccess(ctrl *gomock.Controller) *MockDataAccess {
+	mock := &MockDataAccess{ctrl: ctrl}
+	mock.recorder = &MockDataAccessMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDataAccess) EXPECT() *MockDataAccessMockRecorder {
+	return m.recorder
+}
+
+// CountByRole mocks base method.
+func (m *MockDataAccess) CountByRole(roleId string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountByRole", roleId)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountByRole indicates an expected call of CountByRole.
+func (mr *MockDataAccessMockRecorder) CountByRole(roleId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountByRole", reflect.TypeOf((*MockDataAccess)(nil).CountByRole), roleId)
+}
+
+// Delete mocks base method.
+func (m *MockDataAccess) Delete(id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockDataAccessMockRecorder) Delete(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockDataAccess)(nil).Delete), id)
+}
+
+// FindAll mocks base method.
+func (m *MockDataAccess) FindAll() ([]models.StoreUser, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindAll")
+	ret0, _ := ret[0].([]models.StoreUser)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindAll indicates an expected call of FindAll.
+func (mr *MockDataAccessMockRecorder) FindAll() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindAll", reflect.TypeOf((*MockDataAccess)(nil).FindAll))
+}
+
+// FindById mocks base method.
+func (m *MockDataAccess) FindById(id string) (models.StoreUser, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindById", id)
+	ret0, _ := ret[0].(models.StoreUser)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindById indicates an expected call of FindById.
+func (mr *MockDataAccessMockRecorder) FindById(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindById", reflect.TypeOf((*MockDataAccess)(nil).FindById), id)
+}
+
+// FindByStoreAndUser mocks base method.
+func (m *MockDataAccess) FindByStoreAndUser(storeID, userID uuid.UUID) (models.StoreUser, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByStoreAndUser", storeID, userID)
+	ret0, _ := ret[0].(models.StoreUser)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByStoreAndUser indicates an expected call of FindByStoreAndUser.
+func (mr *MockDataAccessMockRecorder) FindByStoreAndUser(storeID, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByStoreAndUser", reflect.TypeOf((*MockDataAccess)(nil).FindByStoreAndUser), storeID, userID)
+}
+
+// FindByStoreID mocks base method.
+func (m *MockDataAccess) FindByStoreID(storeId string) ([]models.StoreUser, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByStoreID", storeId)
+	ret0, _ := ret[0].([]models.StoreUser)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByStoreID indicates an expected call of FindByStoreID.
+func (mr *MockDataAccessMockRecorder) FindByStoreID(storeId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByStoreID", reflect.TypeOf((*MockDataAccess)(nil).FindByStoreID), storeId)
+}
+
+// FindByUserId mocks base method.
+func (m *MockDataAccess) FindByUserId(userId string) ([]models.StoreUser, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByUserId", userId)
+	ret0, _ := ret[0].([]models.StoreUser)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByUserId indicates an expected call of FindByUserId.
+func (mr *MockDataAccessMockRecorder) FindByUserId(userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByUserId", reflect.TypeOf((*MockDataAccess)(nil).FindByUserId), userId)
+}
+
+// FindMemberships mocks base method.
+func (m *MockDataAccess) FindMemberships(userID uuid.UUID) ([]models.StoreUser, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindMemberships", userID)
+	ret0, _ := ret[0].([]models.StoreUser)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindMemberships indicates an expected call of FindMemberships.
+func (mr *MockDataAccessMockRecorder) FindMemberships(userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindMemberships", reflect.TypeOf((*MockDataAccess)(nil).FindMemberships), userID)
+}
+
+// HasPermission mocks base method.
+func (m *MockDataAccess) HasPermission(storeID, userID uuid.UUID, action string) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HasPermission", storeID, userID, action)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// HasPermission indicates an expected call of HasPermission.
+func (mr *MockDataAccessMockRecorder) HasPermission(storeID, userID, action interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HasPermission", reflect.TypeOf((*MockDataAccess)(nil).HasPermission), storeID, userID, action)
+}
+
+// Insert mocks base method.
+func (m *MockDataAccess) Insert(item models.StoreUser) (models.StoreUser, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Insert", item)
+	ret0, _ := ret[0].(models.StoreUser)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Insert indicates an expected call of Insert.
+func (mr *MockDataAccessMockRecorder) Insert(item interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Insert", reflect.TypeOf((*MockDataAccess)(nil).Insert), item)
+}
+
+// SoftDelete mocks base method.
+func (m *MockDataAccess) SoftDelete(id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SoftDelete", id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SoftDelete indicates an expected call of SoftDelete.
+func (mr *MockDataAccessMockRecorder) SoftDelete(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SoftDelete", reflect.TypeOf((*MockDataAccess)(nil).SoftDelete), id)
+}
+
+// Update mocks base method.
+func (m *MockDataAccess) Update(item models.StoreUser) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", item)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockDataAccessMockRecorder) Update(item interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockDataAccess)(nil).Update), item)
+}
+
+// UpdateAccess mocks base method.
+func (m *MockDataAccess) UpdateAccess(item models.StoreUser) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateAccess", item)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateAccess indicates an expected call of UpdateAccess.
+func (mr *MockDataAccessMockRecorder) UpdateAccess(item interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateAccess", reflect.TypeOf((*MockDataAccess)(nil).UpdateAccess), item)
+}
@@ -0,0 +1,138 @@
+package payments
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+)
+
+const (
+	// FakeProviderName is the name the fake provider registers under.
+	FakeProviderName = "fake"
+	// FakeDeclinedMethod makes the fake provider decline the capture.
+	FakeDeclinedMethod = "card_declined"
+)
+
+// FakeProvider is a deterministic in-process PaymentProvider for local development and
+// tests. Transaction references are numbered in creation order, every capture succeeds
+// unless the intent was created with FakeDeclinedMethod, and no network is involved.
+type FakeProvider struct {
+	mu           sync.Mutex
+	seq          int
+	transactions map[string]*fakeTransaction
+}
+
+type fakeTransaction struct {
+	amount   float64
+	captured float64
+	refunded float64
+	method   string
+	status   string
+}
+
+func NewFakeProvider() *FakeProvider {
+	return &FakeProvider{
+		transactions: map[string]*fakeTransaction{},
+	}
+}
+
+func (p *FakeProvider) Name() string {
+	return FakeProviderName
+}
+
+func (p *FakeProvider) CreateIntent(request IntentRequest) (Intent, error) {
+	if request.Amount <= 0 {
+		return Intent{}, ErrInvalidAmount
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.seq++
+	ref := fmt.Sprintf("fake_txn_%06d", p.seq)
+	p.transactions[ref] = &fakeTransaction{
+		amount: request.Amount,
+		method: request.Method,
+		status: StatusRequiresCapture,
+	}
+
+	return Intent{
+		TransactionRef: ref,
+		ClientSecret:   ref + "_secret",
+		Status:         StatusRequiresCapture,
+	}, nil
+}
+
+func (p *FakeProvider) Capture(transactionRef string, amount float64) (Result, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	txn, ok := p.transactions[transactionRef]
+	if !ok {
+		return Result{}, ErrUnknownTransaction
+	}
+	if txn.status != StatusRequiresCapture {
+		return Result{}, ErrInvalidState
+	}
+	if amount <= 0 || amount > txn.amount {
+		return Result{}, ErrInvalidAmount
+	}
+	if txn.method == FakeDeclinedMethod {
+		txn.status = StatusFailed
+		return Result{TransactionRef: transactionRef, Status: StatusFailed}, ErrDeclined
+	}
+
+	txn.captured = amount
+	txn.status = StatusSucceeded
+	return Result{TransactionRef: transactionRef, Status: StatusSucceeded, Amount: amount}, nil
+}
+
+func (p *FakeProvider) Cancel(transactionRef string) (Result, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	txn, ok := p.transactions[transactionRef]
+	if !ok {
+		return Result{}, ErrUnknownTransaction
+	}
+	if txn.status != StatusRequiresCapture {
+		return Result{}, ErrInvalidState
+	}
+
+	txn.status = StatusCancelled
+	return Result{TransactionRef: transactionRef, Status: StatusCancelled}, nil
+}
+
+func (p *FakeProvider) Refund(transactionRef string, amount float64) (Result, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	txn, ok := p.transactions[transactionRef]
+	if !ok {
+		return Result{}, ErrUnknownTransaction
+	}
+	if txn.status != StatusSucceeded && txn.status != StatusRefunded {
+		return Result{}, ErrInvalidState
+	}
+	if amount <= 0 || txn.refunded+amount > txn.captured+0.005 {
+		return Result{}, ErrInvalidAmount
+	}
+
+	txn.refunded += amount
+	if txn.refunded+0.005 >= txn.captured {
+		txn.status = StatusRefunded
+	}
+	return Result{TransactionRef: transactionRef, Status: StatusRefunded, Amount: amount}, nil
+}
+
+// ParseWebhook decodes the JSON encoding of a WebhookEvent.
+func (p *FakeProvider) ParseWebhook(payload []byte) (WebhookEvent, error) {
+	var event WebhookEvent
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return WebhookEvent{}, err
+	}
+	if event.ID == "" || event.Type == "" || event.TransactionRef == "" {
+		return WebhookEvent{}, fmt.Errorf("fake webhook: id, type and transaction_ref are required")
+	}
+	return event, nil
+}
@@ -0,0 +1,94 @@
+package payments
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFakeProvider_Capture_Then_Partial_Refunds(t *testing.T) {
+	provider := NewFakeProvider()
+
+	intent, err := provider.CreateIntent(IntentRequest{OrderID: "order", Amount: 100, Method: "card"})
+	assert.NoError(t, err)
+	assert.Equal(t, "fake_txn_000001", intent.TransactionRef)
+	assert.Equal(t, StatusRequiresCapture, intent.Status)
+
+	result, err := provider.Capture(intent.TransactionRef, 100)
+	assert.NoError(t, err)
+	assert.Equal(t, StatusSucceeded, result.Status)
+
+	_, err = provider.Refund(intent.TransactionRef, 60)
+	assert.NoError(t, err)
+	_, err = provider.Refund(intent.TransactionRef, 50)
+	assert.ErrorIs(t, err, ErrInvalidAmount)
+	_, err = provider.Refund(intent.TransactionRef, 40)
+	assert.NoError(t, err)
+}
+
+func TestFakeProvider_Declined_Method(t *testing.T) {
+	provider := NewFakeProvider()
+
+	intent, err := provider.CreateIntent(IntentRequest{OrderID: "order", Amount: 25, Method: FakeDeclinedMethod})
+	assert.NoError(t, err)
+
+	result, err := provider.Capture(intent.TransactionRef, 25)
+	assert.ErrorIs(t, err, ErrDeclined)
+	assert.Equal(t, StatusFailed, result.Status)
+
+	_, err = provider.Refund(intent.TransactionRef, 25)
+	assert.ErrorIs(t, err, ErrInvalidState)
+}
+
+func TestFakeProvider_Cancel_Only_Before_Capture(t *testing.T) {
+	provider := NewFakeProvider()
+
+	first, _ := provider.CreateIntent(IntentRequest{Amount: 10})
+	second, _ := provider.CreateIntent(IntentRequest{Amount: 10})
+	assert.Equal(t, "fake_txn_000002", second.TransactionRef)
+
+	result, err := provider.Cancel(first.TransactionRef)
+	assert.NoError(t, err)
+	assert.Equal(t, StatusCancelled, result.Status)
+	_, err = provider.Capture(first.TransactionRef, 10)
+	assert.ErrorIs(t, err, ErrInvalidState)
+
+	_, err = provider.Capture(second.TransactionRef, 10)
+	assert.NoError(t, err)
+	_, err = provider.Cancel(second.TransactionRef)
+	assert.ErrorIs(t, err, ErrInvalidState)
+}
+
+func TestFakeProvider_ParseWebhook(t *testing.T) {
+	provider := NewFakeProvider()
+
+	event, err := provider.ParseWebhook([]byte(`{"id":"evt_1","type":"payment.succeeded","transaction_ref":"fake_txn_000001","amount":12.5}`))
+	assert.NoError(t, err)
+	assert.Equal(t, WebhookEvent{ID: "evt_1", Type: EventPaymentSucceeded, TransactionRef: "fake_txn_000001", Amount: 12.5}, event)
+
+	_, err = provider.ParseWebhook([]byte(`{"type":"payment.succeeded"}`))
+	assert.Error(t, err)
+}
+
+func TestRegistry_Get_Is_Case_Insensitive(t *testing.T) {
+	registry := NewRegistry(NewFakeProvider())
+
+	provider, err := registry.Get("FAKE")
+	assert.NoError(t, err)
+	assert.Equal(t, FakeProviderName, provider.Name())
+
+	_, err = registry.Get("stripe")
+	assert.ErrorIs(t, err, ErrUnknownProvider)
+}
+
+func TestVerifySignature(t *testing.T) {
+	payload := []byte(`{"id":"evt_1"}`)
+	signature := Sign("secret", payload)
+
+	assert.True(t, VerifySignature("secret", payload, signature))
+	assert.True(t, VerifySignature("secret", payload, "sha256="+signature))
+	assert.False(t, VerifySignature("other", payload, signature))
+	assert.False(t, VerifySignature("secret", []byte(`{"id":"evt_2"}`), signature))
+	assert.False(t, VerifySignature("", payload, Sign("", payload)))
+	assert.False(t, VerifySignature("secret", payload, "not-hex"))
+}
@@ -0,0 +1,119 @@
+package payments
+
+import (
+	"errors"
+	"os"
+	"strings"
+)
+
+var (
+	// ErrUnknownProvider is returned when no provider is registered under the requested name.
+	ErrUnknownProvider = errors.New("unknown payment provider")
+	// ErrDeclined is returned when the provider refuses to move the money.
+	ErrDeclined = errors.New("payment declined")
+	// ErrUnknownTransaction is returned for a transaction reference the provider has never issued.
+	ErrUnknownTransaction = errors.New("unknown transaction")
+	// ErrInvalidState is returned when the operation does not fit the transaction's current state.
+	ErrInvalidState = errors.New("operation not allowed in current transaction state")
+	// ErrInvalidAmount is returned for non-positive amounts or amounts above what is available.
+	ErrInvalidAmount = errors.New("invalid amount")
+)
+
+// Statuses reported by providers for a transaction.
+const (
+	StatusRequiresCapture = "requires_capture"
+	StatusSucceeded       = "succeeded"
+	StatusFailed          = "failed"
+	StatusCancelled       = "cancelled"
+	StatusRefunded        = "refunded"
+)
+
+// Webhook event types understood by the payment layer.
+const (
+	EventPaymentSucceeded = "payment.succeeded"
+	EventPaymentFailed    = "payment.failed"
+	EventPaymentCancelled = "payment.cancelled"
+	EventPaymentRefunded  = "payment.refunded"
+)
+
+// PaymentProvider is implemented by every payment gateway the shop can charge through.
+// Implementations talk to the gateway only; recording payments and driving orders is
+// left to the payment service.
+type PaymentProvider interface {
+	// Name is the identifier stored in Payment.Provider and used in webhook routes.
+	Name() string
+	// CreateIntent authorizes an amount for an order and returns the transaction reference.
+	CreateIntent(request IntentRequest) (Intent, error)
+	// Capture collects a previously authorized amount.
+	Capture(transactionRef string, amount float64) (Result, error)
+	// Cancel voids an authorization that has not been captured.
+	Cancel(transactionRef string) (Result, error)
+	// Refund returns all or part of a captured amount.
+	Refund(transactionRef string, amount float64) (Result, error)
+	// ParseWebhook decodes an (already authenticated) webhook payload into an event.
+	ParseWebhook(payload []byte) (WebhookEvent, error)
+}
+
+type IntentRequest struct {
+	OrderID  string
+	Amount   float64
+	Currency string
+	Method   string
+}
+
+type Intent struct {
+	TransactionRef string
+	ClientSecret   string
+	Status         string
+}
+
+type Result struct {
+	TransactionRef string
+	Status         string
+	Amount         float64
+}
+
+type WebhookEvent struct {
+	ID             string  `json:"id"`
+	Type           string  `json:"type"`
+	TransactionRef string  `json:"transaction_ref"`
+	Amount         float64 `json:"amount"`
+}
+
+// New builds the registry of providers enabled in the environment. The fake provider is
+// only registered when PAYMENT_FAKE_ENABLED is "true" so it never reaches production.
+func New() Registry {
+	var providers []PaymentProvider
+	if os.Getenv("PAYMENT_FAKE_ENABLED") == "true" {
+		providers = append(providers, NewFakeProvider())
+	}
+	return NewRegistry(providers...)
+}
+
+// Currency is the ISO currency code intents are created in (PAYMENT_CURRENCY, default USD).
+func Currency() string {
+	if currency := os.Getenv("PAYMENT_CURRENCY"); currency != "" {
+		return strings.ToUpper(currency)
+	}
+	return "USD"
+}
+
+// Registry holds the configured providers keyed by name.
+type Registry map[string]PaymentProvider
+
+func NewRegistry(providers ...PaymentProvider) Registry {
+	registry := Registry{}
+	for _, provider := range providers {
+		registry[strings.ToLower(provider.Name())] = provider
+	}
+	return registry
+}
+
+// Get returns the provider registered under name.
+func (r Registry) Get(name string) (PaymentProvider, error) {
+	provider, ok := r[strings.ToLower(name)]
+	if !ok {
+		return nil, ErrUnknownProvider
+	}
+	return provider, nil
+}
@@ -0,0 +1,43 @@
+package payments
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"strings"
+)
+
+// SignatureHeader carries the hex encoded HMAC-SHA256 of the raw webhook body.
+const SignatureHeader = "X-Signature"
+
+// Sign returns the hex encoded HMAC-SHA256 of payload under secret.
+func Sign(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifySignature reports whether signature (optionally prefixed with "sha256=") is the
+// HMAC-SHA256 of payload under secret. The comparison runs in constant time.
+func VerifySignature(secret string, payload []byte, signature string) bool {
+	if secret == "" || signature == "" {
+		return false
+	}
+	given, err := hex.DecodeString(strings.TrimPrefix(signature, "sha256="))
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return hmac.Equal(given, mac.Sum(nil))
+}
+
+// WebhookSecret returns the signing secret for a provider's webhooks, read from
+// PAYMENT_WEBHOOK_SECRET_<PROVIDER> and falling back to PAYMENT_WEBHOOK_SECRET.
+func WebhookSecret(provider string) string {
+	if secret := os.Getenv("PAYMENT_WEBHOOK_SECRET_" + strings.ToUpper(provider)); secret != "" {
+		return secret
+	}
+	return os.Getenv("PAYMENT_WEBHOOK_SECRET")
+}
@@ -0,0 +1,89 @@
+package services
+
+import (
+	"errors"
+	"os"
+
+	"github.com/abdulmalikraji/e-commerce/db/dao/userDao"
+	"github.com/abdulmalikraji/e-commerce/db/models"
+	"github.com/abdulmalikraji/e-commerce/dto/adminDto"
+	"github.com/abdulmalikraji/e-commerce/utils"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/log"
+	"github.com/supabase-community/auth-go"
+	"github.com/supabase-community/auth-go/types"
+	"gorm.io/gorm"
+)
+
+type AdminService interface {
+	UpdateUserRole(ctx *fiber.Ctx, request adminDto.UpdateUserRoleRequest) (adminDto.UserRoleResponse, int, error)
+}
+
+type adminService struct {
+	userDao    userDao.DataAccess
+	authClient auth.Client
+}
+
+func NewAdminService(userDao userDao.DataAccess, authClient auth.Client) AdminService {
+	return adminService{
+		userDao:    userDao,
+		authClient: authClient,
+	}
+}
+
+// UpdateUserRole promotes or demotes a user. The role is written to the database and
+// synced to the auth provider in one transaction, so a failed sync leaves both unchanged.
+func (s adminService) UpdateUserRole(ctx *fiber.Ctx, request adminDto.UpdateUserRoleRequest) (adminDto.UserRoleResponse, int, error) {
+	adminID, err := utils.GetUserID(ctx)
+	if err != nil {
+		return adminDto.UserRoleResponse{}, fiber.StatusUnauthorized, err
+	}
+	if !models.IsUserRole(request.Role) {
+		return adminDto.UserRoleResponse{}, fiber.StatusBadRequest, fiber.NewError(fiber.StatusBadRequest, "Unknown role "+request.Role)
+	}
+
+	user, err := s.userDao.FindById(request.UserID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return adminDto.UserRoleResponse{}, fiber.StatusNotFound, fiber.NewError(fiber.StatusNotFound, "User not found")
+		}
+		return adminDto.UserRoleResponse{}, fiber.StatusInternalServerError, err
+	}
+	// Admins cannot demote themselves and lock the platform out.
+	if user.ID == adminID && request.Role != models.UserRoleAdmin {
+		return adminDto.UserRoleResponse{}, fiber.StatusConflict, fiber.NewError(fiber.StatusConflict, "You cannot change your own role")
+	}
+
+	response := adminDto.UserRoleResponse{
+		UserID:       user.ID.String(),
+		Email:        user.Email,
+		PreviousRole: user.Role,
+		Role:         request.Role,
+	}
+	if user.Role == request.Role {
+		return response, fiber.StatusOK, nil
+	}
+
+	err = s.userDao.Transaction(func(tx *gorm.DB) error {
+		res := tx.Table(user.TableName()).
+			Where("id = ? AND del_flg = ?", user.ID, false).
+			Update("role", request.Role)
+		if res.Error != nil {
+			return res.Error
+		}
+
+		_, err := s.authClient.WithToken(os.Getenv("SERVICE_ROLE_KEY")).AdminUpdateUser(types.AdminUpdateUserRequest{
+			UserID:      user.ID,
+			Role:        request.Role,
+			AppMetadata: map[string]interface{}{"role": request.Role},
+		})
+		return err
+	})
+	if err != nil {
+		log.Errorf("failed to change role of user_id=%s to %s: %v", user.ID.String(), request.Role, err)
+		return adminDto.UserRoleResponse{}, fiber.StatusInternalServerError, err
+	}
+
+	log.Infof("user_id=%s changed role of user_id=%s from %s to %s", adminID.String(), user.ID.String(), user.Role, request.Role)
+	return response, fiber.StatusOK, nil
+}
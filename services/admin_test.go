@@ -0,0 +1,60 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/abdulmalikraji/e-commerce/db/models"
+	"github.com/abdulmalikraji/e-commerce/dto/adminDto"
+	"github.com/abdulmalikraji/e-commerce/dto/authDto"
+	"github.com/abdulmalikraji/e-commerce/utils"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuthService_Signup_Rejects_Admin_Role(t *testing.T) {
+	teardown := setup(t)
+	defer teardown()
+
+	status, err := s.SignupByEmail(fiberCtx, authDto.SignUpByEmailRequest{
+		Email: "jack.doe@company.com",
+		Role:  models.UserRoleAdmin,
+	})
+
+	assert.Error(t, err)
+	assert.Equal(t, fiber.StatusForbidden, status)
+}
+
+func TestAdminService_Admin_Cannot_Demote_Themselves(t *testing.T) {
+	teardown := setup(t)
+	defer teardown()
+	utils.SetPrincipal(fiberCtx, utils.Principal{UserID: user_id, Role: models.UserRoleAdmin})
+
+	userMockDao.EXPECT().FindById(user_id.String()).Return(models.User{ID: user_id, Role: models.UserRoleAdmin}, nil)
+
+	_, status, err := NewAdminService(userMockDao, mockAuthClient).UpdateUserRole(fiberCtx, adminDto.UpdateUserRoleRequest{
+		UserID: user_id.String(),
+		Role:   models.UserRoleBuyer,
+	})
+
+	assert.Error(t, err)
+	assert.Equal(t, fiber.StatusConflict, status)
+}
+
+func TestAdminService_Unchanged_Role_Skips_Sync(t *testing.T) {
+	teardown := setup(t)
+	defer teardown()
+	utils.SetPrincipal(fiberCtx, utils.Principal{UserID: user_id, Role: models.UserRoleAdmin})
+
+	seller := models.User{ID: uuid.New(), Email: "seller@company.com", Role: models.UserRoleSeller}
+	userMockDao.EXPECT().FindById(seller.ID.String()).Return(seller, nil)
+
+	response, status, err := NewAdminService(userMockDao, mockAuthClient).UpdateUserRole(fiberCtx, adminDto.UpdateUserRoleRequest{
+		UserID: seller.ID.String(),
+		Role:   models.UserRoleSeller,
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, status)
+	assert.Equal(t, models.UserRoleSeller, response.PreviousRole)
+}
@@ -6,13 +6,17 @@ import (
 	"strings"
 	"time"
 
+	"github.com/abdulmalikraji/e-commerce/authenticator"
+	cartdao "github.com/abdulmalikraji/e-commerce/db/dao/cartDao"
 	"github.com/abdulmalikraji/e-commerce/db/dao/userDao"
 	"github.com/abdulmalikraji/e-commerce/db/dao/userTokenDao"
 	"github.com/abdulmalikraji/e-commerce/db/models"
 	"github.com/abdulmalikraji/e-commerce/dto/authDto"
+	"github.com/abdulmalikraji/e-commerce/utils"
 	"github.com/abdulmalikraji/e-commerce/utils/messages"
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/log"
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
 	"github.com/supabase-community/auth-go"
 	"github.com/supabase-community/auth-go/types"
@@ -42,17 +46,23 @@ type authService struct {
 	userDao      userDao.DataAccess
 	authClient   auth.Client
 	userTokenDao userTokenDao.DataAccess
+	cartDao      cartdao.DataAccess
+	tokens       *authenticator.TokenVerifier
 }
 
 func NewAuthService(
 	userDao userDao.DataAccess,
 	authClient auth.Client,
 	userTokenDao userTokenDao.DataAccess,
+	cartDao cartdao.DataAccess,
+	tokens *authenticator.TokenVerifier,
 ) AuthService {
 	return authService{
 		userDao:      userDao,
 		authClient:   authClient,
 		userTokenDao: userTokenDao,
+		cartDao:      cartDao,
+		tokens:       tokens,
 	}
 }
 
@@ -95,6 +105,8 @@ func (s authService) LoginByEmail(ctx *fiber.Ctx, request authDto.LoginByEmailRe
 	ctx.Set("Authorization", "Bearer "+resp.AccessToken)
 	ctx.Set("X-User-ID", resp.User.ID.String())
 
+	s.adoptGuestCart(ctx, resp.User.ID)
+
 	// Return data for client storage (localStorage)
 	return authDto.LoginByEmailResponse{
 		AccessToken: resp.AccessToken,
@@ -104,6 +116,17 @@ func (s authService) LoginByEmail(ctx *fiber.Ctx, request authDto.LoginByEmailRe
 
 func (s authService) SignupByEmail(ctx *fiber.Ctx, request authDto.SignUpByEmailRequest) (int, error) {
 
+	// Admins are promoted by another admin, never self-assigned.
+	if request.Role == "" {
+		request.Role = models.UserRoleBuyer
+	}
+	if request.Role == models.UserRoleAdmin {
+		return fiber.StatusForbidden, fiber.NewError(fiber.StatusForbidden, "The admin role cannot be requested at signup")
+	}
+	if !models.IsUserRole(request.Role) {
+		return fiber.StatusBadRequest, fiber.NewError(fiber.StatusBadRequest, "Unknown role "+request.Role)
+	}
+
 	if s.userDao.IsEmailExists(request.Email) || s.userDao.IsPhoneNumberExists(request.PhoneNumber) {
 		return fiber.StatusConflict, fiber.NewError(fiber.StatusConflict, "Email or phone number already exists")
 	}
@@ -126,9 +149,10 @@ func (s authService) SignupByEmail(ctx *fiber.Ctx, request authDto.SignUpByEmail
 
 	// Update phone number in Auth0
 	resp, err := s.authClient.WithToken(os.Getenv("SERVICE_ROLE_KEY")).AdminUpdateUser(types.AdminUpdateUserRequest{
-		UserID: userResp.ID,
-		Phone:  request.PhoneNumber,
-		Role:   request.Role,
+		UserID:      userResp.ID,
+		Phone:       request.PhoneNumber,
+		Role:        request.Role,
+		AppMetadata: map[string]interface{}{"role": request.Role},
 	})
 	if err != nil || resp.ID == uuid.Nil {
 		// Attempt best-effort cleanup: delete the auth provider user we just created
@@ -172,9 +196,33 @@ func (s authService) SignupByEmail(ctx *fiber.Ctx, request authDto.SignUpByEmail
 	// Audit log for successful signup
 	log.Infof("signup success user_id=%s email=%s", userResp.ID.String(), request.Email)
 
+	s.adoptGuestCart(ctx, userResp.ID)
+
 	return fiber.StatusCreated, nil
 }
 
+// adoptGuestCart merges the caller's guest cart, if any, into the user's cart and
+// expires the session cookie. A failed merge is logged but does not fail the login.
+func (s authService) adoptGuestCart(ctx *fiber.Ctx, userID uuid.UUID) {
+	session := ctx.Cookies(GuestCartCookie)
+	if session == "" {
+		return
+	}
+	if err := mergeGuestCart(s.cartDao, userID, session); err != nil {
+		log.Warnf("failed to merge guest cart into cart of user_id=%s: %v", userID.String(), err)
+		return
+	}
+	ctx.Cookie(&fiber.Cookie{
+		Name:     GuestCartCookie,
+		Value:    "",
+		Path:     "/",
+		Expires:  time.Unix(0, 0),
+		Secure:   true,
+		HTTPOnly: true,
+		SameSite: "Lax",
+	})
+}
+
 func (s authService) GetUser(ctx *fiber.Ctx, request authDto.GetUserRequest) (authDto.GetUserResponse, int, error) {
 
 	// Fetch user from database
@@ -196,11 +244,48 @@ func (s authService) GetUser(ctx *fiber.Ctx, request authDto.GetUserRequest) (au
 	}, fiber.StatusOK, nil
 }
 
+// ValidateToken verifies the access token locally and stores the caller as the request's
+// principal. The auth server is only asked when the remote fallback is enabled.
 func (s authService) ValidateToken(ctx *fiber.Ctx, token string) (int, error) {
-	_, err := s.authClient.WithToken(token).GetUser()
+	claims, err := s.tokens.Verify(token)
+	if err != nil {
+		if !s.tokens.RemoteFallback() || errors.Is(err, jwt.ErrTokenExpired) {
+			return fiber.StatusUnauthorized, err
+		}
+		user, err := s.authClient.WithToken(token).GetUser()
+		if err != nil {
+			return fiber.StatusUnauthorized, err
+		}
+		claims = authenticator.Claims{
+			Email:        user.Email,
+			Role:         user.Role,
+			AppMetadata:  user.AppMetadata,
+			UserMetadata: user.UserMetadata,
+		}
+		claims.Subject = user.ID.String()
+	}
+
+	userID, err := claims.UserID()
 	if err != nil {
 		return fiber.StatusUnauthorized, err
 	}
+	// Accounts whose app metadata carries no role yet take the one on their user record.
+	role := claims.AppRole()
+	if role == "" {
+		user, err := s.userDao.FindById(userID.String())
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return fiber.StatusUnauthorized, fiber.NewError(fiber.StatusUnauthorized, "Unknown user")
+			}
+			return fiber.StatusInternalServerError, err
+		}
+		role = user.Role
+	}
+	utils.SetPrincipal(ctx, utils.Principal{
+		UserID: userID,
+		Email:  claims.Email,
+		Role:   role,
+	})
 	return fiber.StatusOK, nil
 }
 
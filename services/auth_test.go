@@ -4,10 +4,16 @@ import (
 	"testing"
 	"time"
 
+	tokenAuth "github.com/abdulmalikraji/e-commerce/authenticator"
+	"github.com/abdulmalikraji/e-commerce/db/models"
+	"github.com/abdulmalikraji/e-commerce/dto/authDto"
 	authenticator "github.com/abdulmalikraji/e-commerce/mocks/auth"
+	"github.com/abdulmalikraji/e-commerce/mocks/dao/cartDao"
 	"github.com/abdulmalikraji/e-commerce/mocks/dao/userDao"
 	"github.com/abdulmalikraji/e-commerce/mocks/dao/userTokenDao"
+	"github.com/abdulmalikraji/e-commerce/utils"
 	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/golang/mock/gomock"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
@@ -18,13 +24,15 @@ import (
 var userMockDao *userDao.MockDataAccess
 var userTokenMockDao *userTokenDao.MockDataAccess
 var mockAuthClient *authenticator.MockClient
+var cartMockDao *cartDao.MockDataAccess
 
 var fiberCtx *fiber.Ctx
 
 var s AuthService
 
-var user_id, _ = uuid.Parse("user_id")
-var refreshExpiry = time.Now().Add(7 * 24 * time.Hour)
+var user_id = uuid.New()
+
+const testJWTSecret = "test-secret"
 
 func setup(t *testing.T) func() {
 	ct := gomock.NewController(t)
@@ -39,8 +47,11 @@ func setup(t *testing.T) func() {
 	userMockDao = userDao.NewMockDataAccess(ct)
 	userTokenMockDao = userTokenDao.NewMockDataAccess(ct)
 	mockAuthClient = authenticator.NewMockClient(ct)
+	cartMockDao = cartDao.NewMockDataAccess(ct)
+
+	tokens, _ := tokenAuth.NewTokenVerifier(tokenAuth.TokenConfig{Secret: testJWTSecret, Audience: "authenticated"})
 
-	s = NewAuthService(userMockDao, mockAuthClient, userTokenMockDao)
+	s = NewAuthService(userMockDao, mockAuthClient, userTokenMockDao, cartMockDao, tokens)
 	return func() {
 		s = nil
 		defer ct.Finish()
@@ -55,23 +66,33 @@ func TestAuthService_Login_By_Email_Successfully(t *testing.T) {
 		GrantType: "password",
 		Email:     "jack.doe@company.com",
 		Password:  "password",
-	}).Return(types.TokenResponse{
-		types.Session{
+	}).Return(&types.TokenResponse{
+		Session: types.Session{
 			User: types.User{
 				ID: user_id,
 			},
 			RefreshToken: "refresh",
-			AccessToken: "access"
+			AccessToken:  "access",
+			ExpiresAt:    time.Now().Add(time.Hour).Unix(),
 		},
-	})
+	}, nil)
 
-	userTokenDao.EXPECT().Insert(models.UserToken{
-		UserID:       user_id
+	userTokenMockDao.EXPECT().Insert(models.UserToken{
+		UserID:       user_id,
 		RefreshToken: "refresh",
 		IsRevoked:    false,
 		ExpiresAt:    refreshExpiry,
+	}).Return(models.UserToken{}, nil)
+
+	response, status, err := s.LoginByEmail(fiberCtx, authDto.LoginByEmailRequest{
+		Email:    "jack.doe@company.com",
+		Password: "password",
 	})
 
+	assert.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, status)
+	assert.Equal(t, "access", response.AccessToken)
+
 	// userMockDao.EXPECT().FindByEmailOrPhoneNumber().Return(fakeUsers[2], nil)
 	// cryptoMock.EXPECT().CheckPasswordHash("hashedPassword", fakeUsers[2].Password).Return(true)
 
@@ -91,3 +112,62 @@ func TestAuthService_Login_By_Email_Successfully(t *testing.T) {
 	// assert.Equal(t, response.PhoneNumber, fakeUsers[2].PhoneNumber)
 	// assert.NotNil(t, response.Token)
 }
+
+func signedTestToken(t *testing.T, expiresAt time.Time, appMetadata map[string]any) string {
+	claims := tokenAuth.Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   user_id.String(),
+			Audience:  jwt.ClaimStrings{"authenticated"},
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+		Role:         "authenticated",
+		AppMetadata:  appMetadata,
+		UserMetadata: map[string]any{"role": "admin"},
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(testJWTSecret))
+	assert.NoError(t, err)
+	return token
+}
+
+func TestAuthService_Validate_Token_Locally(t *testing.T) {
+	teardown := setup(t)
+	defer teardown()
+
+	status, err := s.ValidateToken(fiberCtx, signedTestToken(t, time.Now().Add(time.Hour), map[string]any{"role": "seller"}))
+
+	assert.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, status)
+	principal, ok := utils.GetPrincipal(fiberCtx)
+	assert.True(t, ok)
+	assert.Equal(t, user_id, principal.UserID)
+	assert.Equal(t, "seller", principal.Role)
+}
+
+// The top-level role claim is "authenticated" for everyone, so a token without an app
+// role takes the role of the user record rather than that claim.
+func TestAuthService_Validate_Token_Without_App_Role_Uses_The_User_Record(t *testing.T) {
+	teardown := setup(t)
+	defer teardown()
+
+	userMockDao.EXPECT().FindById(user_id.String()).Return(models.User{ID: user_id, Role: models.UserRoleBuyer}, nil)
+
+	status, err := s.ValidateToken(fiberCtx, signedTestToken(t, time.Now().Add(time.Hour), nil))
+
+	assert.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, status)
+	principal, _ := utils.GetPrincipal(fiberCtx)
+	assert.Equal(t, models.UserRoleBuyer, principal.Role)
+}
+
+func TestAuthService_Validate_Token_Rejects_Expired_Token(t *testing.T) {
+	teardown := setup(t)
+	defer teardown()
+
+	// No expectations on the auth client: the remote fallback is disabled.
+	status, err := s.ValidateToken(fiberCtx, signedTestToken(t, time.Now().Add(-time.Hour), map[string]any{"role": "seller"}))
+
+	assert.ErrorIs(t, err, jwt.ErrTokenExpired)
+	assert.Equal(t, fiber.StatusUnauthorized, status)
+	_, ok := utils.GetPrincipal(fiberCtx)
+	assert.False(t, ok)
+}
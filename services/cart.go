@@ -0,0 +1,365 @@
+package services
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	cartdao "github.com/abdulmalikraji/e-commerce/db/dao/cartDao"
+	"github.com/abdulmalikraji/e-commerce/db/dao/productDao"
+	productvariantdao "github.com/abdulmalikraji/e-commerce/db/dao/productVariantDao"
+	"github.com/abdulmalikraji/e-commerce/db/models"
+	"github.com/abdulmalikraji/e-commerce/dto/cartDto"
+	"github.com/abdulmalikraji/e-commerce/utils"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// GuestCartCookie holds the session ID of a guest's cart.
+const GuestCartCookie = "cart_session"
+
+const guestCartLifetime = 30 * 24 * time.Hour
+
+type CartService interface {
+	GetCart(ctx *fiber.Ctx) (cartDto.CartResponse, int, error)
+	AddItem(ctx *fiber.Ctx, request cartDto.AddCartItemRequest) (cartDto.CartResponse, int, error)
+	UpdateItem(ctx *fiber.Ctx, request cartDto.UpdateCartItemRequest) (cartDto.CartResponse, int, error)
+	RemoveItem(ctx *fiber.Ctx, request cartDto.RemoveCartItemRequest) (cartDto.CartResponse, int, error)
+	ClearCart(ctx *fiber.Ctx) (cartDto.CartResponse, int, error)
+}
+
+type cartService struct {
+	cartDao    cartdao.DataAccess
+	productDao productDao.DataAccess
+	variantDao productvariantdao.DataAccess
+}
+
+func NewCartService(
+	cartDao cartdao.DataAccess,
+	productDao productDao.DataAccess,
+	variantDao productvariantdao.DataAccess,
+) CartService {
+	return cartService{
+		cartDao:    cartDao,
+		productDao: productDao,
+		variantDao: variantDao,
+	}
+}
+
+func (s cartService) GetCart(ctx *fiber.Ctx) (cartDto.CartResponse, int, error) {
+	cart, found, err := s.findCart(ctx)
+	if err != nil {
+		return cartDto.CartResponse{}, fiber.StatusInternalServerError, err
+	}
+	if !found {
+		return emptyCart(ctx), fiber.StatusOK, nil
+	}
+	return s.cartResponse(cart)
+}
+
+func (s cartService) AddItem(ctx *fiber.Ctx, request cartDto.AddCartItemRequest) (cartDto.CartResponse, int, error) {
+	if request.Quantity == 0 {
+		request.Quantity = 1
+	}
+	if request.Quantity < 0 {
+		return cartDto.CartResponse{}, fiber.StatusBadRequest, fiber.NewError(fiber.StatusBadRequest, "quantity must be positive")
+	}
+
+	product, err := s.productDao.FindById(request.ProductID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return cartDto.CartResponse{}, fiber.StatusNotFound, fiber.NewError(fiber.StatusNotFound, "Product not found")
+		}
+		return cartDto.CartResponse{}, fiber.StatusInternalServerError, err
+	}
+
+	var variant *models.ProductVariant
+	var variantID *string
+	if request.VariantID != "" {
+		found, err := s.variantDao.FindById(request.VariantID)
+		if err != nil || found.ProductID != product.ID {
+			return cartDto.CartResponse{}, fiber.StatusNotFound, fiber.NewError(fiber.StatusNotFound, "Variant not found for this product")
+		}
+		variant = &found
+		variantID = &request.VariantID
+	} else if product.HasVariants {
+		return cartDto.CartResponse{}, fiber.StatusBadRequest, fiber.NewError(fiber.StatusBadRequest, "variant_id is required for "+product.Name)
+	}
+
+	cart, err := s.findOrCreateCart(ctx)
+	if err != nil {
+		return cartDto.CartResponse{}, fiber.StatusInternalServerError, err
+	}
+
+	// Adding a product already in the cart tops up that line instead of adding another.
+	existing, err := s.cartDao.FindProductItem(cart.ID.String(), product.ID.String(), variantID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return cartDto.CartResponse{}, fiber.StatusInternalServerError, err
+	}
+	quantity := existing.Quantity + request.Quantity
+
+	item := models.CartItem{Product: product, Variant: variant}
+	if err := checkCartStock(item, quantity); err != nil {
+		return cartDto.CartResponse{}, fiber.StatusConflict, err
+	}
+
+	if existing.ID != uuid.Nil {
+		err = s.cartDao.UpdateItem(models.CartItem{ID: existing.ID, Quantity: quantity})
+	} else {
+		newItem := models.CartItem{
+			CartID:    cart.ID,
+			ProductID: product.ID,
+			Quantity:  quantity,
+		}
+		if variant != nil {
+			newItem.VariantID = &variant.ID
+		}
+		_, err = s.cartDao.AddItem(newItem)
+	}
+	if err != nil {
+		return cartDto.CartResponse{}, fiber.StatusInternalServerError, err
+	}
+
+	response, _, err := s.cartResponse(cart)
+	if err != nil {
+		return cartDto.CartResponse{}, fiber.StatusInternalServerError, err
+	}
+	return response, fiber.StatusCreated, nil
+}
+
+func (s cartService) UpdateItem(ctx *fiber.Ctx, request cartDto.UpdateCartItemRequest) (cartDto.CartResponse, int, error) {
+	if request.Quantity < 0 {
+		return cartDto.CartResponse{}, fiber.StatusBadRequest, fiber.NewError(fiber.StatusBadRequest, "quantity cannot be negative")
+	}
+	if request.Quantity == 0 {
+		return s.RemoveItem(ctx, cartDto.RemoveCartItemRequest{ItemID: request.ItemID})
+	}
+
+	cart, item, status, err := s.findCartItem(ctx, request.ItemID)
+	if err != nil {
+		return cartDto.CartResponse{}, status, err
+	}
+	if err := checkCartStock(item, request.Quantity); err != nil {
+		return cartDto.CartResponse{}, fiber.StatusConflict, err
+	}
+
+	if err := s.cartDao.UpdateItem(models.CartItem{ID: item.ID, Quantity: request.Quantity}); err != nil {
+		return cartDto.CartResponse{}, fiber.StatusInternalServerError, err
+	}
+	return s.cartResponse(cart)
+}
+
+func (s cartService) RemoveItem(ctx *fiber.Ctx, request cartDto.RemoveCartItemRequest) (cartDto.CartResponse, int, error) {
+	cart, item, status, err := s.findCartItem(ctx, request.ItemID)
+	if err != nil {
+		return cartDto.CartResponse{}, status, err
+	}
+
+	if err := s.cartDao.RemoveItem(item.ID.String()); err != nil {
+		return cartDto.CartResponse{}, fiber.StatusInternalServerError, err
+	}
+	return s.cartResponse(cart)
+}
+
+func (s cartService) ClearCart(ctx *fiber.Ctx) (cartDto.CartResponse, int, error) {
+	cart, found, err := s.findCart(ctx)
+	if err != nil {
+		return cartDto.CartResponse{}, fiber.StatusInternalServerError, err
+	}
+	if !found {
+		return emptyCart(ctx), fiber.StatusOK, nil
+	}
+
+	if err := s.cartDao.ClearCart(cart.ID.String()); err != nil {
+		return cartDto.CartResponse{}, fiber.StatusInternalServerError, err
+	}
+	return s.cartResponse(cart)
+}
+
+// findCart returns the cart of the logged-in user or, for guests, the cart of the
+// session cookie. found is false when there is no cart yet.
+func (s cartService) findCart(ctx *fiber.Ctx) (cart models.Cart, found bool, err error) {
+	userID, err := utils.GetUserID(ctx)
+	if err == nil {
+		cart, err = s.cartDao.FindByUserId(userID.String())
+	} else if session := ctx.Cookies(GuestCartCookie); session != "" {
+		cart, err = s.cartDao.FindBySessionId(session)
+	} else {
+		return models.Cart{}, false, nil
+	}
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.Cart{}, false, nil
+		}
+		return models.Cart{}, false, err
+	}
+	return cart, true, nil
+}
+
+// findOrCreateCart returns the caller's cart, creating it (and for guests the session
+// cookie) on first use.
+func (s cartService) findOrCreateCart(ctx *fiber.Ctx) (models.Cart, error) {
+	cart, found, err := s.findCart(ctx)
+	if err != nil || found {
+		return cart, err
+	}
+
+	cart = models.Cart{IsActive: true}
+	if userID, err := utils.GetUserID(ctx); err == nil {
+		cart.UserID = &userID
+	} else {
+		session := ctx.Cookies(GuestCartCookie)
+		if session == "" {
+			session = uuid.NewString()
+		}
+		cart.SessionID = &session
+		ctx.Cookie(&fiber.Cookie{
+			Name:     GuestCartCookie,
+			Value:    session,
+			Path:     "/",
+			Expires:  time.Now().Add(guestCartLifetime),
+			Secure:   true,
+			HTTPOnly: true,
+			SameSite: "Lax",
+		})
+	}
+	return s.cartDao.Insert(cart)
+}
+
+func (s cartService) findCartItem(ctx *fiber.Ctx, itemID string) (models.Cart, models.CartItem, int, error) {
+	cart, found, err := s.findCart(ctx)
+	if err != nil {
+		return models.Cart{}, models.CartItem{}, fiber.StatusInternalServerError, err
+	}
+	if !found {
+		return models.Cart{}, models.CartItem{}, fiber.StatusNotFound, fiber.NewError(fiber.StatusNotFound, "Cart item not found")
+	}
+
+	item, err := s.cartDao.FindItem(cart.ID.String(), itemID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.Cart{}, models.CartItem{}, fiber.StatusNotFound, fiber.NewError(fiber.StatusNotFound, "Cart item not found")
+		}
+		return models.Cart{}, models.CartItem{}, fiber.StatusInternalServerError, err
+	}
+	return cart, item, fiber.StatusOK, nil
+}
+
+func (s cartService) cartResponse(cart models.Cart) (cartDto.CartResponse, int, error) {
+	items, err := s.cartDao.FindItems(cart.ID.String())
+	if err != nil {
+		return cartDto.CartResponse{}, fiber.StatusInternalServerError, err
+	}
+
+	response := cartDto.CartResponse{
+		ID:    cart.ID.String(),
+		Guest: cart.UserID == nil,
+		Items: []cartDto.CartLine{},
+	}
+	for _, item := range items {
+		price := unitPrice(item)
+		lineTotal := utils.Round(price*float64(item.Quantity), 2)
+
+		var variantID string
+		if item.VariantID != nil {
+			variantID = item.VariantID.String()
+		}
+		response.Items = append(response.Items, cartDto.CartLine{
+			ItemID:    item.ID.String(),
+			ProductID: item.ProductID.String(),
+			VariantID: variantID,
+			StoreID:   item.Product.StoreID.String(),
+			Name:      item.Product.Name,
+			Quantity:  item.Quantity,
+			UnitPrice: price,
+			LineTotal: lineTotal,
+			Stock:     availableStock(item),
+			InStock:   availableStock(item) >= item.Quantity,
+		})
+		response.ItemCount += item.Quantity
+		response.Subtotal += lineTotal
+	}
+	response.Subtotal = utils.Round(response.Subtotal, 2)
+	return response, fiber.StatusOK, nil
+}
+
+func emptyCart(ctx *fiber.Ctx) cartDto.CartResponse {
+	_, err := utils.GetUserID(ctx)
+	return cartDto.CartResponse{
+		Guest: err != nil,
+		Items: []cartDto.CartLine{},
+	}
+}
+
+// checkCartStock verifies the variant (or product) has quantity units in stock.
+func checkCartStock(item models.CartItem, quantity int) error {
+	if stock := availableStock(item); quantity > stock {
+		return fiber.NewError(fiber.StatusConflict, "Only "+strconv.Itoa(stock)+" unit(s) of "+item.Product.Name+" in stock")
+	}
+	return nil
+}
+
+// mergeGuestCart moves the lines of the guest cart for session into the user's cart,
+// summing quantities of the same product/variant capped at available stock, and
+// deletes the guest cart. Everything happens in one transaction.
+func mergeGuestCart(cartDao cartdao.DataAccess, userID uuid.UUID, session string) error {
+	return cartDao.Transaction(func(tx *gorm.DB) error {
+		carts := cartDao.WithTx(tx)
+
+		guest, err := carts.FindBySessionId(session)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil
+			}
+			return err
+		}
+		items, err := carts.FindItems(guest.ID.String())
+		if err != nil {
+			return err
+		}
+
+		cart, err := carts.FindByUserId(userID.String())
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			cart, err = carts.Insert(models.Cart{UserID: &userID, IsActive: true})
+		}
+		if err != nil {
+			return err
+		}
+
+		for _, item := range items {
+			var variantID *string
+			if item.VariantID != nil {
+				id := item.VariantID.String()
+				variantID = &id
+			}
+			existing, err := carts.FindProductItem(cart.ID.String(), item.ProductID.String(), variantID)
+			if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+				return err
+			}
+
+			quantity := min(existing.Quantity+item.Quantity, availableStock(item))
+			switch {
+			case quantity <= 0 || quantity == existing.Quantity:
+				continue
+			case existing.ID != uuid.Nil:
+				err = carts.UpdateItem(models.CartItem{ID: existing.ID, Quantity: quantity})
+			default:
+				_, err = carts.AddItem(models.CartItem{
+					CartID:    cart.ID,
+					ProductID: item.ProductID,
+					VariantID: item.VariantID,
+					Quantity:  quantity,
+				})
+			}
+			if err != nil {
+				return err
+			}
+		}
+
+		if err := carts.ClearCart(guest.ID.String()); err != nil {
+			return err
+		}
+		return carts.Delete(guest.ID.String())
+	})
+}
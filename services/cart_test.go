@@ -0,0 +1,297 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/abdulmalikraji/e-commerce/db/models"
+	"github.com/abdulmalikraji/e-commerce/dto/cartDto"
+	"github.com/abdulmalikraji/e-commerce/mocks/dao/cartDao"
+	"github.com/abdulmalikraji/e-commerce/mocks/dao/productDao"
+	"github.com/abdulmalikraji/e-commerce/mocks/dao/productVariantDao"
+	"github.com/abdulmalikraji/e-commerce/utils"
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+	"gorm.io/gorm"
+)
+
+var carts CartService
+
+// setupCart signs the buyer in, or leaves the caller a guest when guest is set.
+func setupCart(t *testing.T, guest bool) func() {
+	ct := gomock.NewController(t)
+
+	app := fiber.New()
+	fiberCtx = app.AcquireCtx(&fasthttp.RequestCtx{})
+	if !guest {
+		utils.SetPrincipal(fiberCtx, utils.Principal{UserID: buyerID})
+	}
+
+	cartMockDao = cartDao.NewMockDataAccess(ct)
+	productMockDao = productDao.NewMockDataAccess(ct)
+	variantMockDao = productVariantDao.NewMockDataAccess(ct)
+
+	carts = NewCartService(cartMockDao, productMockDao, variantMockDao)
+	return func() {
+		carts = nil
+		app.ReleaseCtx(fiberCtx)
+		ct.Finish()
+	}
+}
+
+// cartMug is a product with five units in stock.
+var cartMug = models.Product{ID: uuid.New(), StoreID: uuid.New(), Name: "Mug", Price: 12, Stock: 5}
+
+func TestCartService_User_Adds_To_A_New_Cart(t *testing.T) {
+	teardown := setupCart(t, false)
+	defer teardown()
+
+	cart := models.Cart{ID: uuid.New(), UserID: &buyerID}
+	productMockDao.EXPECT().FindById(cartMug.ID.String()).Return(cartMug, nil)
+	cartMockDao.EXPECT().FindByUserId(buyerID.String()).Return(models.Cart{}, gorm.ErrRecordNotFound)
+	cartMockDao.EXPECT().Insert(gomock.Any()).DoAndReturn(func(created models.Cart) (models.Cart, error) {
+		assert.Equal(t, &buyerID, created.UserID)
+		assert.Nil(t, created.SessionID)
+		return cart, nil
+	})
+	cartMockDao.EXPECT().FindProductItem(cart.ID.String(), cartMug.ID.String(), nil).Return(models.CartItem{}, gorm.ErrRecordNotFound)
+	cartMockDao.EXPECT().AddItem(models.CartItem{CartID: cart.ID, ProductID: cartMug.ID, Quantity: 2}).Return(models.CartItem{}, nil)
+	cartMockDao.EXPECT().FindItems(cart.ID.String()).Return([]models.CartItem{
+		{ID: uuid.New(), CartID: cart.ID, ProductID: cartMug.ID, Product: cartMug, Quantity: 2},
+	}, nil)
+
+	response, status, err := carts.AddItem(fiberCtx, cartDto.AddCartItemRequest{ProductID: cartMug.ID.String(), Quantity: 2})
+
+	assert.NoError(t, err)
+	assert.Equal(t, fiber.StatusCreated, status)
+	assert.False(t, response.Guest)
+	assert.Equal(t, 2, response.ItemCount)
+	assert.Equal(t, 24.0, response.Subtotal)
+	assert.True(t, response.Items[0].InStock)
+}
+
+func TestCartService_Guest_Gets_A_Cart_Cookie(t *testing.T) {
+	teardown := setupCart(t, true)
+	defer teardown()
+
+	var session string
+	cart := models.Cart{ID: uuid.New()}
+	productMockDao.EXPECT().FindById(cartMug.ID.String()).Return(cartMug, nil)
+	cartMockDao.EXPECT().Insert(gomock.Any()).DoAndReturn(func(created models.Cart) (models.Cart, error) {
+		assert.Nil(t, created.UserID)
+		session = *created.SessionID
+		cart.SessionID = created.SessionID
+		return cart, nil
+	})
+	cartMockDao.EXPECT().FindProductItem(cart.ID.String(), cartMug.ID.String(), nil).Return(models.CartItem{}, gorm.ErrRecordNotFound)
+	cartMockDao.EXPECT().AddItem(gomock.Any()).Return(models.CartItem{}, nil)
+	cartMockDao.EXPECT().FindItems(cart.ID.String()).Return([]models.CartItem{}, nil)
+
+	response, status, err := carts.AddItem(fiberCtx, cartDto.AddCartItemRequest{ProductID: cartMug.ID.String()})
+
+	assert.NoError(t, err)
+	assert.Equal(t, fiber.StatusCreated, status)
+	assert.True(t, response.Guest)
+	assert.NotEmpty(t, session)
+	assert.Contains(t, string(fiberCtx.Response().Header.PeekCookie(GuestCartCookie)), GuestCartCookie+"="+session)
+}
+
+func TestCartService_Guest_Adding_Again_Tops_Up_The_Line(t *testing.T) {
+	teardown := setupCart(t, true)
+	defer teardown()
+
+	fiberCtx.Request().Header.SetCookie(GuestCartCookie, "session")
+	cart := models.Cart{ID: uuid.New()}
+	existing := models.CartItem{ID: uuid.New(), CartID: cart.ID, ProductID: cartMug.ID, Quantity: 2}
+	productMockDao.EXPECT().FindById(cartMug.ID.String()).Return(cartMug, nil)
+	cartMockDao.EXPECT().FindBySessionId("session").Return(cart, nil)
+	cartMockDao.EXPECT().FindProductItem(cart.ID.String(), cartMug.ID.String(), nil).Return(existing, nil)
+	cartMockDao.EXPECT().UpdateItem(models.CartItem{ID: existing.ID, Quantity: 3}).Return(nil)
+	cartMockDao.EXPECT().FindItems(cart.ID.String()).Return([]models.CartItem{}, nil)
+
+	_, status, err := carts.AddItem(fiberCtx, cartDto.AddCartItemRequest{ProductID: cartMug.ID.String()})
+
+	assert.NoError(t, err)
+	assert.Equal(t, fiber.StatusCreated, status)
+}
+
+func TestCartService_Adding_More_Than_In_Stock_Is_A_Conflict(t *testing.T) {
+	teardown := setupCart(t, false)
+	defer teardown()
+
+	cart := models.Cart{ID: uuid.New(), UserID: &buyerID}
+	existing := models.CartItem{ID: uuid.New(), CartID: cart.ID, ProductID: cartMug.ID, Quantity: 4}
+	productMockDao.EXPECT().FindById(cartMug.ID.String()).Return(cartMug, nil)
+	cartMockDao.EXPECT().FindByUserId(buyerID.String()).Return(cart, nil)
+	cartMockDao.EXPECT().FindProductItem(cart.ID.String(), cartMug.ID.String(), nil).Return(existing, nil)
+
+	_, status, err := carts.AddItem(fiberCtx, cartDto.AddCartItemRequest{ProductID: cartMug.ID.String(), Quantity: 2})
+
+	assert.EqualError(t, err, "Only 5 unit(s) of Mug in stock")
+	assert.Equal(t, fiber.StatusConflict, status)
+}
+
+func TestCartService_Add_Checks_The_Variant(t *testing.T) {
+	shirt := models.Product{ID: uuid.New(), Name: "Shirt", HasVariants: true}
+	tests := []struct {
+		name      string
+		variantID string
+		variant   *models.ProductVariant
+		status    int
+	}{
+		{name: "missing variant", status: fiber.StatusBadRequest},
+		{name: "variant of another product", variantID: uuid.NewString(), variant: &models.ProductVariant{ProductID: uuid.New()}, status: fiber.StatusNotFound},
+		{name: "sold out variant", variantID: uuid.NewString(), variant: &models.ProductVariant{ProductID: shirt.ID, Stock: 1}, status: fiber.StatusConflict},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			teardown := setupCart(t, false)
+			defer teardown()
+
+			productMockDao.EXPECT().FindById(shirt.ID.String()).Return(shirt, nil)
+			if test.variant != nil {
+				variantMockDao.EXPECT().FindById(test.variantID).Return(*test.variant, nil)
+			}
+			if test.status == fiber.StatusConflict {
+				cart := models.Cart{ID: uuid.New(), UserID: &buyerID}
+				cartMockDao.EXPECT().FindByUserId(buyerID.String()).Return(cart, nil)
+				cartMockDao.EXPECT().FindProductItem(cart.ID.String(), shirt.ID.String(), &test.variantID).Return(models.CartItem{}, gorm.ErrRecordNotFound)
+			}
+
+			_, status, err := carts.AddItem(fiberCtx, cartDto.AddCartItemRequest{ProductID: shirt.ID.String(), VariantID: test.variantID, Quantity: 2})
+
+			assert.Error(t, err)
+			assert.Equal(t, test.status, status)
+		})
+	}
+}
+
+func TestCartService_Update_Item(t *testing.T) {
+	tests := []struct {
+		name     string
+		quantity int
+		status   int
+	}{
+		{name: "within stock", quantity: 5, status: fiber.StatusOK},
+		{name: "over stock", quantity: 6, status: fiber.StatusConflict},
+		{name: "zero removes the line", quantity: 0, status: fiber.StatusOK},
+		{name: "negative quantity", quantity: -1, status: fiber.StatusBadRequest},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			teardown := setupCart(t, false)
+			defer teardown()
+
+			cart := models.Cart{ID: uuid.New(), UserID: &buyerID}
+			item := models.CartItem{ID: uuid.New(), CartID: cart.ID, ProductID: cartMug.ID, Product: cartMug, Quantity: 1}
+			if test.quantity >= 0 {
+				cartMockDao.EXPECT().FindByUserId(buyerID.String()).Return(cart, nil)
+				cartMockDao.EXPECT().FindItem(cart.ID.String(), item.ID.String()).Return(item, nil)
+			}
+			switch {
+			case test.quantity == 0:
+				cartMockDao.EXPECT().RemoveItem(item.ID.String()).Return(nil)
+				cartMockDao.EXPECT().FindItems(cart.ID.String()).Return([]models.CartItem{}, nil)
+			case test.status == fiber.StatusOK:
+				cartMockDao.EXPECT().UpdateItem(models.CartItem{ID: item.ID, Quantity: test.quantity}).Return(nil)
+				cartMockDao.EXPECT().FindItems(cart.ID.String()).Return([]models.CartItem{}, nil)
+			}
+
+			_, status, err := carts.UpdateItem(fiberCtx, cartDto.UpdateCartItemRequest{ItemID: item.ID.String(), Quantity: test.quantity})
+
+			assert.Equal(t, test.status, status)
+			if test.status == fiber.StatusOK {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestCartService_Remove_Item(t *testing.T) {
+	teardown := setupCart(t, false)
+	defer teardown()
+
+	cart := models.Cart{ID: uuid.New(), UserID: &buyerID}
+	item := models.CartItem{ID: uuid.New(), CartID: cart.ID, ProductID: cartMug.ID, Quantity: 1}
+	cartMockDao.EXPECT().FindByUserId(buyerID.String()).Return(cart, nil)
+	cartMockDao.EXPECT().FindItem(cart.ID.String(), item.ID.String()).Return(item, nil)
+	cartMockDao.EXPECT().RemoveItem(item.ID.String()).Return(nil)
+	cartMockDao.EXPECT().FindItems(cart.ID.String()).Return([]models.CartItem{}, nil)
+
+	response, status, err := carts.RemoveItem(fiberCtx, cartDto.RemoveCartItemRequest{ItemID: item.ID.String()})
+
+	assert.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, status)
+	assert.Empty(t, response.Items)
+}
+
+func TestCartService_Remove_Item_Of_Another_Cart_Is_Not_Found(t *testing.T) {
+	teardown := setupCart(t, true)
+	defer teardown()
+
+	fiberCtx.Request().Header.SetCookie(GuestCartCookie, "session")
+	cart := models.Cart{ID: uuid.New()}
+	itemID := uuid.NewString()
+	cartMockDao.EXPECT().FindBySessionId("session").Return(cart, nil)
+	cartMockDao.EXPECT().FindItem(cart.ID.String(), itemID).Return(models.CartItem{}, gorm.ErrRecordNotFound)
+
+	_, status, err := carts.RemoveItem(fiberCtx, cartDto.RemoveCartItemRequest{ItemID: itemID})
+
+	assert.Error(t, err)
+	assert.Equal(t, fiber.StatusNotFound, status)
+}
+
+func TestMergeGuestCart_Sums_Quantities_Capped_At_Stock(t *testing.T) {
+	ct := gomock.NewController(t)
+	defer ct.Finish()
+	carts := cartDao.NewMockDataAccess(ct)
+
+	userID := uuid.New()
+	guest := models.Cart{ID: uuid.New()}
+	userCart := models.Cart{ID: uuid.New(), UserID: &userID}
+	mug := models.Product{ID: uuid.New(), Name: "Mug", Stock: 5}
+	poster := models.Product{ID: uuid.New(), Name: "Poster", Stock: 10}
+	existingMug := models.CartItem{ID: uuid.New(), CartID: userCart.ID, ProductID: mug.ID, Quantity: 3}
+
+	carts.EXPECT().Transaction(gomock.Any()).DoAndReturn(func(fn func(tx *gorm.DB) error) error {
+		return fn(nil)
+	})
+	carts.EXPECT().WithTx(gomock.Any()).Return(carts)
+	carts.EXPECT().FindBySessionId("session").Return(guest, nil)
+	carts.EXPECT().FindItems(guest.ID.String()).Return([]models.CartItem{
+		{ID: uuid.New(), CartID: guest.ID, ProductID: mug.ID, Product: mug, Quantity: 4},
+		{ID: uuid.New(), CartID: guest.ID, ProductID: poster.ID, Product: poster, Quantity: 2},
+	}, nil)
+	carts.EXPECT().FindByUserId(userID.String()).Return(userCart, nil)
+	carts.EXPECT().FindProductItem(userCart.ID.String(), mug.ID.String(), nil).Return(existingMug, nil)
+	carts.EXPECT().UpdateItem(models.CartItem{ID: existingMug.ID, Quantity: 5}).Return(nil)
+	carts.EXPECT().FindProductItem(userCart.ID.String(), poster.ID.String(), nil).Return(models.CartItem{}, gorm.ErrRecordNotFound)
+	carts.EXPECT().AddItem(gomock.Any()).DoAndReturn(func(item models.CartItem) (models.CartItem, error) {
+		assert.Equal(t, userCart.ID, item.CartID)
+		assert.Equal(t, poster.ID, item.ProductID)
+		assert.Equal(t, 2, item.Quantity)
+		return item, nil
+	})
+	carts.EXPECT().ClearCart(guest.ID.String()).Return(nil)
+	carts.EXPECT().Delete(guest.ID.String()).Return(nil)
+
+	assert.NoError(t, mergeGuestCart(carts, userID, "session"))
+}
+
+func TestMergeGuestCart_Without_Guest_Cart_Is_A_No_Op(t *testing.T) {
+	ct := gomock.NewController(t)
+	defer ct.Finish()
+	carts := cartDao.NewMockDataAccess(ct)
+
+	carts.EXPECT().Transaction(gomock.Any()).DoAndReturn(func(fn func(tx *gorm.DB) error) error {
+		return fn(nil)
+	})
+	carts.EXPECT().WithTx(gomock.Any()).Return(carts)
+	carts.EXPECT().FindBySessionId("session").Return(models.Cart{}, gorm.ErrRecordNotFound)
+
+	assert.NoError(t, mergeGuestCart(carts, uuid.New(), "session"))
+}
@@ -0,0 +1,354 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	cartdao "github.com/abdulmalikraji/e-commerce/db/dao/cartDao"
+	"github.com/abdulmalikraji/e-commerce/db/dao/couponDao"
+	"github.com/abdulmalikraji/e-commerce/db/dao/orderDao"
+	"github.com/abdulmalikraji/e-commerce/db/dao/stockReservationDao"
+	"github.com/abdulmalikraji/e-commerce/db/models"
+	"github.com/abdulmalikraji/e-commerce/dto/checkoutDto"
+	"github.com/abdulmalikraji/e-commerce/utils"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/log"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type CheckoutService interface {
+	PreviewCheckout(ctx *fiber.Ctx, request checkoutDto.CheckoutPreviewRequest) (checkoutDto.CheckoutPreviewResponse, int, error)
+	Checkout(ctx *fiber.Ctx, request checkoutDto.CheckoutRequest) (checkoutDto.CheckoutResponse, int, error)
+}
+
+type checkoutService struct {
+	cartDao        cartdao.DataAccess
+	orderDao       orderDao.DataAccess
+	couponDao      couponDao.DataAccess
+	reservationDao stockReservationDao.DataAccess
+}
+
+func NewCheckoutService(
+	cartDao cartdao.DataAccess,
+	orderDao orderDao.DataAccess,
+	couponDao couponDao.DataAccess,
+	reservationDao stockReservationDao.DataAccess,
+) CheckoutService {
+	return checkoutService{
+		cartDao:        cartDao,
+		orderDao:       orderDao,
+		couponDao:      couponDao,
+		reservationDao: reservationDao,
+	}
+}
+
+func (s checkoutService) PreviewCheckout(ctx *fiber.Ctx, request checkoutDto.CheckoutPreviewRequest) (checkoutDto.CheckoutPreviewResponse, int, error) {
+	userID, err := utils.GetUserID(ctx)
+	if err != nil {
+		return checkoutDto.CheckoutPreviewResponse{}, fiber.StatusUnauthorized, err
+	}
+
+	cart, err := s.cartDao.FindByUserId(userID.String())
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return checkoutDto.CheckoutPreviewResponse{}, fiber.StatusBadRequest, fiber.NewError(fiber.StatusBadRequest, "Cart is empty")
+		}
+		return checkoutDto.CheckoutPreviewResponse{}, fiber.StatusInternalServerError, err
+	}
+
+	items, err := s.cartDao.FindItems(cart.ID.String())
+	if err != nil {
+		return checkoutDto.CheckoutPreviewResponse{}, fiber.StatusInternalServerError, err
+	}
+	if len(items) == 0 {
+		return checkoutDto.CheckoutPreviewResponse{}, fiber.StatusBadRequest, fiber.NewError(fiber.StatusBadRequest, "Cart is empty")
+	}
+
+	lines, subtotal := priceCartItems(items)
+
+	var discount float64
+	code := strings.TrimSpace(request.CouponCode)
+	if code != "" {
+		_, discount, err = quoteCoupon(s.couponDao, code, userID, couponLinesFromCart(items), time.Now())
+		if err != nil {
+			return checkoutDto.CheckoutPreviewResponse{}, errorStatus(err), err
+		}
+	}
+
+	return checkoutDto.CheckoutPreviewResponse{
+		Items:      lines,
+		Subtotal:   subtotal,
+		Discount:   discount,
+		Total:      utils.Round(subtotal-discount, 2),
+		CouponCode: code,
+	}, fiber.StatusOK, nil
+}
+
+func (s checkoutService) Checkout(ctx *fiber.Ctx, request checkoutDto.CheckoutRequest) (checkoutDto.CheckoutResponse, int, error) {
+	userID, err := utils.GetUserID(ctx)
+	if err != nil {
+		return checkoutDto.CheckoutResponse{}, fiber.StatusUnauthorized, err
+	}
+
+	var shippingAddressID *uuid.UUID
+	if request.ShippingAddressID != "" {
+		id, err := uuid.Parse(request.ShippingAddressID)
+		if err != nil {
+			return checkoutDto.CheckoutResponse{}, fiber.StatusBadRequest, fiber.NewError(fiber.StatusBadRequest, "shipping_address_id must be a valid UUID")
+		}
+		shippingAddressID = &id
+	}
+
+	var response checkoutDto.CheckoutResponse
+	status := fiber.StatusCreated
+
+	// Everything from reading the cart to clearing it happens in one transaction so a
+	// failure at any step (e.g. a product selling out) leaves no partial order behind.
+	err = s.orderDao.Transaction(func(tx *gorm.DB) error {
+		carts := s.cartDao.WithTx(tx)
+
+		cart, err := carts.FindByUserId(userID.String())
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				status = fiber.StatusBadRequest
+				return fiber.NewError(fiber.StatusBadRequest, "Cart is empty")
+			}
+			return err
+		}
+
+		items, err := carts.FindItems(cart.ID.String())
+		if err != nil {
+			return err
+		}
+		if len(items) == 0 {
+			status = fiber.StatusBadRequest
+			return fiber.NewError(fiber.StatusBadRequest, "Cart is empty")
+		}
+
+		if shippingAddressID != nil {
+			var address models.Address
+			res := tx.Table(address.TableName()).
+				Where("id = ? AND user_id = ? AND del_flg = ?", *shippingAddressID, userID, false).
+				First(&address)
+			if res.Error != nil {
+				status = fiber.StatusBadRequest
+				return fiber.NewError(fiber.StatusBadRequest, "Shipping address not found")
+			}
+		}
+
+		lines, subtotal := priceCartItems(items)
+
+		coupons := s.couponDao.WithTx(tx)
+		var discount float64
+		var coupon *models.Coupon
+		if code := strings.TrimSpace(request.CouponCode); code != "" {
+			quoted, amount, err := quoteCoupon(coupons, code, userID, couponLinesFromCart(items), time.Now())
+			if err != nil {
+				status = errorStatus(err)
+				return err
+			}
+			discount = amount
+			coupon = &quoted
+		}
+		var couponID *uuid.UUID
+		if coupon != nil {
+			couponID = &coupon.ID
+		}
+
+		order := models.Order{
+			BuyerID:           userID,
+			ShippingAddressID: shippingAddressID,
+			Status:            models.OrderStatusPending,
+			TotalAmount:       utils.Round(subtotal-discount, 2),
+			CouponID:          couponID,
+			Discount:          discount,
+		}
+		if res := tx.Table(order.TableName()).Create(&order); res.Error != nil {
+			return res.Error
+		}
+
+		// Redeeming re-checks the usage limits under a row lock, so a code that was
+		// valid when quoted can still be turned down by a concurrent checkout here.
+		if coupon != nil {
+			if err := redeemCoupon(coupons, *coupon, userID, order.ID); err != nil {
+				status = errorStatus(err)
+				return err
+			}
+		}
+
+		history := models.OrderStatusHistory{
+			OrderID:   order.ID,
+			ToStatus:  order.Status,
+			ChangedBy: &userID,
+			Note:      "order placed",
+		}
+		if res := tx.Table(history.TableName()).Create(&history); res.Error != nil {
+			return res.Error
+		}
+
+		reservations := s.reservationDao.WithTx(tx)
+		ttl := reservationTTL()
+		for _, item := range items {
+			orderItem := models.OrderItem{
+				OrderID:   order.ID,
+				StoreID:   item.Product.StoreID,
+				ProductID: item.ProductID,
+				VariantID: item.VariantID,
+				Quantity:  item.Quantity,
+				UnitPrice: unitPrice(item),
+			}
+			if res := tx.Table(orderItem.TableName()).Create(&orderItem); res.Error != nil {
+				return res.Error
+			}
+
+			if !purchasable(item) {
+				status = fiber.StatusConflict
+				return fiber.NewError(fiber.StatusConflict, item.Product.Name+" is no longer available")
+			}
+			if err := decrementStock(tx, item); err != nil {
+				status = fiber.StatusConflict
+				return err
+			}
+
+			// Hold the warehouse units until the order is paid; the hold lapses after ttl.
+			if _, err := reservations.Reserve(item.ProductID, &order.ID, item.Quantity, ttl); err != nil {
+				if errors.Is(err, stockReservationDao.ErrInsufficientStock) {
+					status = fiber.StatusConflict
+					return fiber.NewError(fiber.StatusConflict, "Insufficient stock for "+item.Product.Name)
+				}
+				return err
+			}
+		}
+
+		// Each store handles its own slice of the order through a fulfillment.
+		for _, storeID := range cartStoreIDs(items) {
+			fulfillment := models.Fulfillment{
+				OrderID: order.ID,
+				StoreID: storeID,
+				Status:  models.FulfillmentPending,
+			}
+			if res := tx.Table(fulfillment.TableName()).Create(&fulfillment); res.Error != nil {
+				return res.Error
+			}
+		}
+
+		if err := carts.ClearCart(cart.ID.String()); err != nil {
+			return err
+		}
+
+		response = checkoutDto.CheckoutResponse{
+			OrderID:  order.ID.String(),
+			Status:   order.Status,
+			Items:    lines,
+			Subtotal: subtotal,
+			Discount: discount,
+			Total:    order.TotalAmount,
+		}
+		return nil
+	})
+	if err != nil {
+		if status == fiber.StatusCreated {
+			status = fiber.StatusInternalServerError
+		}
+		log.Errorf("checkout failed for user_id=%s: %v", userID.String(), err)
+		return checkoutDto.CheckoutResponse{}, status, err
+	}
+
+	log.Infof("checkout success user_id=%s order_id=%s", userID.String(), response.OrderID)
+	return response, fiber.StatusCreated, nil
+}
+
+// unitPrice resolves the price of a single unit of a cart item: the variant's
+// PriceOverride when set, otherwise the product price, less the product discount.
+func unitPrice(item models.CartItem) float64 {
+	price := item.Product.Price
+	if item.Variant != nil && item.Variant.PriceOverride != nil {
+		price = *item.Variant.PriceOverride
+	}
+	if item.Product.IsDiscounted && item.Product.DiscountPct > 0 {
+		price = price * (1 - item.Product.DiscountPct/100)
+	}
+	return utils.Round(price, 2)
+}
+
+// purchasable reports whether the cart item can still be bought: its product, variant
+// and store have not been deleted since it was added.
+func purchasable(item models.CartItem) bool {
+	if item.Product.DelFlg || item.Product.Store.DelFlg {
+		return false
+	}
+	return item.Variant == nil || !item.Variant.DelFlg
+}
+
+// availableStock returns the stock the cart item draws from.
+func availableStock(item models.CartItem) int {
+	if item.Variant != nil {
+		return item.Variant.Stock
+	}
+	return item.Product.Stock
+}
+
+func priceCartItems(items []models.CartItem) ([]checkoutDto.CheckoutLine, float64) {
+	var lines []checkoutDto.CheckoutLine
+	var subtotal float64
+	for _, item := range items {
+		price := unitPrice(item)
+		lineTotal := utils.Round(price*float64(item.Quantity), 2)
+		subtotal += lineTotal
+
+		var variantID string
+		if item.VariantID != nil {
+			variantID = item.VariantID.String()
+		}
+		lines = append(lines, checkoutDto.CheckoutLine{
+			CartItemID: item.ID.String(),
+			ProductID:  item.ProductID.String(),
+			VariantID:  variantID,
+			StoreID:    item.Product.StoreID.String(),
+			Name:       item.Product.Name,
+			Quantity:   item.Quantity,
+			UnitPrice:  price,
+			LineTotal:  lineTotal,
+			InStock:    purchasable(item) && availableStock(item) >= item.Quantity,
+		})
+	}
+	return lines, utils.Round(subtotal, 2)
+}
+
+// cartStoreIDs returns the distinct stores selling the cart items, in cart order.
+func cartStoreIDs(items []models.CartItem) []uuid.UUID {
+	seen := map[uuid.UUID]bool{}
+	var ids []uuid.UUID
+	for _, item := range items {
+		if !seen[item.Product.StoreID] {
+			seen[item.Product.StoreID] = true
+			ids = append(ids, item.Product.StoreID)
+		}
+	}
+	return ids
+}
+
+// decrementStock removes the purchased quantity from the variant (or product) stock.
+// The update only matches rows with enough stock, so concurrent checkouts cannot
+// drive stock negative.
+func decrementStock(tx *gorm.DB, item models.CartItem) error {
+	var res *gorm.DB
+	if item.VariantID != nil {
+		res = tx.Table(models.ProductVariant{}.TableName()).
+			Where("id = ? AND stock >= ?", *item.VariantID, item.Quantity).
+			Update("stock", gorm.Expr("stock - ?", item.Quantity))
+	} else {
+		res = tx.Table(models.Product{}.TableName()).
+			Where("id = ? AND stock >= ?", item.ProductID, item.Quantity).
+			Update("stock", gorm.Expr("stock - ?", item.Quantity))
+	}
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return fiber.NewError(fiber.StatusConflict, "Insufficient stock for "+item.Product.Name)
+	}
+	return nil
+}
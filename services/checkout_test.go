@@ -0,0 +1,205 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/abdulmalikraji/e-commerce/db/models"
+	"github.com/abdulmalikraji/e-commerce/dto/checkoutDto"
+	"github.com/abdulmalikraji/e-commerce/mocks/dao/cartDao"
+	"github.com/abdulmalikraji/e-commerce/mocks/dao/couponDao"
+	"github.com/abdulmalikraji/e-commerce/mocks/dao/orderDao"
+	"github.com/abdulmalikraji/e-commerce/mocks/dao/stockReservationDao"
+	"github.com/abdulmalikraji/e-commerce/utils"
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+	"gorm.io/gorm"
+)
+
+var couponMockDao *couponDao.MockDataAccess
+var reservationMockDao *stockReservationDao.MockDataAccess
+
+var checkouts CheckoutService
+
+var buyerCart = models.Cart{ID: uuid.New()}
+
+func setupCheckout(t *testing.T) func() {
+	ct := gomock.NewController(t)
+
+	app := fiber.New()
+	fiberCtx = app.AcquireCtx(&fasthttp.RequestCtx{})
+	utils.SetPrincipal(fiberCtx, utils.Principal{UserID: buyerID})
+
+	cartMockDao = cartDao.NewMockDataAccess(ct)
+	orderMockDao = orderDao.NewMockDataAccess(ct)
+	couponMockDao = couponDao.NewMockDataAccess(ct)
+	reservationMockDao = stockReservationDao.NewMockDataAccess(ct)
+
+	checkouts = NewCheckoutService(cartMockDao, orderMockDao, couponMockDao, reservationMockDao)
+	return func() {
+		checkouts = nil
+		app.ReleaseCtx(fiberCtx)
+		ct.Finish()
+	}
+}
+
+// cartItems returns a mug bought through a variant with its own price and a poster on
+// a 25% discount, from two stores.
+func cartItems() []models.CartItem {
+	override := 15.0
+	mug := models.Product{ID: uuid.New(), StoreID: uuid.New(), Name: "Mug", Price: 12, Stock: 10}
+	red := models.ProductVariant{ID: uuid.New(), ProductID: mug.ID, SKU: "MUG-RED", PriceOverride: &override, Stock: 3}
+	poster := models.Product{ID: uuid.New(), StoreID: uuid.New(), Name: "Poster", Price: 20, Stock: 5, IsDiscounted: true, DiscountPct: 25}
+	return []models.CartItem{
+		{ID: uuid.New(), CartID: buyerCart.ID, ProductID: mug.ID, Product: mug, VariantID: &red.ID, Variant: &red, Quantity: 2},
+		{ID: uuid.New(), CartID: buyerCart.ID, ProductID: poster.ID, Product: poster, Quantity: 1},
+	}
+}
+
+// beginCheckout runs the checkout transaction on tx up to the order's first item.
+func beginCheckout(tx *gorm.DB, sqlMock sqlmock.Sqlmock, items []models.CartItem) {
+	orderMockDao.EXPECT().Transaction(gomock.Any()).DoAndReturn(func(fn func(tx *gorm.DB) error) error {
+		return fn(tx)
+	})
+	cartMockDao.EXPECT().WithTx(tx).Return(cartMockDao)
+	cartMockDao.EXPECT().FindByUserId(buyerID.String()).Return(buyerCart, nil)
+	cartMockDao.EXPECT().FindItems(buyerCart.ID.String()).Return(items, nil)
+	couponMockDao.EXPECT().WithTx(tx).Return(couponMockDao)
+	reservationMockDao.EXPECT().WithTx(tx).Return(reservationMockDao)
+
+	sqlMock.ExpectQuery(`INSERT INTO "ecom"."orders"`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(uuid.New()))
+	sqlMock.ExpectQuery(`INSERT INTO "ecom"."order_status_history"`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(uuid.New()))
+	sqlMock.ExpectQuery(`INSERT INTO "ecom"."order_items"`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(uuid.New()))
+}
+
+func TestCheckoutService_Preview_Prices_The_Cart(t *testing.T) {
+	teardown := setupCheckout(t)
+	defer teardown()
+
+	amount := 5.0
+	items := cartItems()
+	cartMockDao.EXPECT().FindByUserId(buyerID.String()).Return(buyerCart, nil)
+	cartMockDao.EXPECT().FindItems(buyerCart.ID.String()).Return(items, nil)
+	couponMockDao.EXPECT().FindByCode("SAVE5").Return(models.Coupon{ID: uuid.New(), Code: "SAVE5", DiscountAmount: &amount}, nil)
+
+	response, status, err := checkouts.PreviewCheckout(fiberCtx, checkoutDto.CheckoutPreviewRequest{CouponCode: " SAVE5 "})
+
+	assert.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, status)
+	assert.Equal(t, 15.0, response.Items[0].UnitPrice)
+	assert.Equal(t, 30.0, response.Items[0].LineTotal)
+	assert.Equal(t, 15.0, response.Items[1].UnitPrice)
+	assert.Equal(t, 45.0, response.Subtotal)
+	assert.Equal(t, 5.0, response.Discount)
+	assert.Equal(t, 40.0, response.Total)
+	assert.Equal(t, "SAVE5", response.CouponCode)
+}
+
+func TestCheckoutService_Preview_Flags_Unavailable_Items(t *testing.T) {
+	teardown := setupCheckout(t)
+	defer teardown()
+
+	items := cartItems()
+	items[0].Quantity = 4
+	items[1].Product.DelFlg = true
+	cartMockDao.EXPECT().FindByUserId(buyerID.String()).Return(buyerCart, nil)
+	cartMockDao.EXPECT().FindItems(buyerCart.ID.String()).Return(items, nil)
+
+	response, _, err := checkouts.PreviewCheckout(fiberCtx, checkoutDto.CheckoutPreviewRequest{})
+
+	assert.NoError(t, err)
+	assert.False(t, response.Items[0].InStock)
+	assert.False(t, response.Items[1].InStock)
+}
+
+func TestCheckoutService_Empty_Cart_Is_Rejected(t *testing.T) {
+	teardown := setupCheckout(t)
+	defer teardown()
+
+	tx, _ := mockTx(t)
+	orderMockDao.EXPECT().Transaction(gomock.Any()).DoAndReturn(func(fn func(tx *gorm.DB) error) error {
+		return fn(tx)
+	})
+	cartMockDao.EXPECT().WithTx(tx).Return(cartMockDao)
+	cartMockDao.EXPECT().FindByUserId(buyerID.String()).Return(buyerCart, nil)
+	cartMockDao.EXPECT().FindItems(buyerCart.ID.String()).Return([]models.CartItem{}, nil)
+
+	_, status, err := checkouts.Checkout(fiberCtx, checkoutDto.CheckoutRequest{})
+
+	assert.Error(t, err)
+	assert.Equal(t, fiber.StatusBadRequest, status)
+}
+
+func TestCheckoutService_Places_The_Order(t *testing.T) {
+	teardown := setupCheckout(t)
+	defer teardown()
+
+	items := cartItems()
+	tx, sqlMock := mockTx(t)
+	beginCheckout(tx, sqlMock, items)
+	sqlMock.ExpectExec(`UPDATE "ecom"."product_variants" SET "stock"=stock - \$1 WHERE id = \$2 AND stock >= \$3`).
+		WithArgs(2, *items[0].VariantID, 2).WillReturnResult(sqlmock.NewResult(0, 1))
+	reservationMockDao.EXPECT().Reserve(items[0].ProductID, gomock.Any(), 2, gomock.Any()).Return(nil, nil)
+	sqlMock.ExpectQuery(`INSERT INTO "ecom"."order_items"`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(uuid.New()))
+	sqlMock.ExpectExec(`UPDATE "ecom"."products" SET "stock"=stock - \$1 WHERE id = \$2 AND stock >= \$3`).
+		WithArgs(1, items[1].ProductID, 1).WillReturnResult(sqlmock.NewResult(0, 1))
+	reservationMockDao.EXPECT().Reserve(items[1].ProductID, gomock.Any(), 1, gomock.Any()).Return(nil, nil)
+	sqlMock.ExpectQuery(`INSERT INTO "ecom"."fulfillments"`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(uuid.New()))
+	sqlMock.ExpectQuery(`INSERT INTO "ecom"."fulfillments"`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(uuid.New()))
+	cartMockDao.EXPECT().ClearCart(buyerCart.ID.String()).Return(nil)
+
+	response, status, err := checkouts.Checkout(fiberCtx, checkoutDto.CheckoutRequest{})
+
+	assert.NoError(t, err)
+	assert.Equal(t, fiber.StatusCreated, status)
+	assert.Equal(t, models.OrderStatusPending, response.Status)
+	assert.Equal(t, 45.0, response.Total)
+	assert.NoError(t, sqlMock.ExpectationsWereMet())
+}
+
+func TestCheckoutService_Sold_Out_Item_Is_A_Conflict(t *testing.T) {
+	teardown := setupCheckout(t)
+	defer teardown()
+
+	items := cartItems()
+	tx, sqlMock := mockTx(t)
+	beginCheckout(tx, sqlMock, items)
+	sqlMock.ExpectExec(`UPDATE "ecom"."product_variants" SET "stock"=stock - \$1`).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	_, status, err := checkouts.Checkout(fiberCtx, checkoutDto.CheckoutRequest{})
+
+	assert.EqualError(t, err, "Insufficient stock for Mug")
+	assert.Equal(t, fiber.StatusConflict, status)
+	assert.NoError(t, sqlMock.ExpectationsWereMet())
+}
+
+func TestCheckoutService_Unavailable_Item_Is_A_Conflict(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(item *models.CartItem)
+	}{
+		{name: "deleted product", change: func(item *models.CartItem) { item.Product.DelFlg = true }},
+		{name: "deleted variant", change: func(item *models.CartItem) { item.Variant.DelFlg = true }},
+		{name: "deleted store", change: func(item *models.CartItem) { item.Product.Store.DelFlg = true }},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			teardown := setupCheckout(t)
+			defer teardown()
+
+			items := cartItems()
+			test.change(&items[0])
+			tx, sqlMock := mockTx(t)
+			beginCheckout(tx, sqlMock, items)
+
+			_, status, err := checkouts.Checkout(fiberCtx, checkoutDto.CheckoutRequest{})
+
+			assert.EqualError(t, err, "Mug is no longer available")
+			assert.Equal(t, fiber.StatusConflict, status)
+			assert.NoError(t, sqlMock.ExpectationsWereMet())
+		})
+	}
+}
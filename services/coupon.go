@@ -0,0 +1,241 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	cartdao "github.com/abdulmalikraji/e-commerce/db/dao/cartDao"
+	"github.com/abdulmalikraji/e-commerce/db/dao/couponDao"
+	"github.com/abdulmalikraji/e-commerce/db/models"
+	"github.com/abdulmalikraji/e-commerce/dto/couponDto"
+	"github.com/abdulmalikraji/e-commerce/utils"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Reasons a coupon code is rejected.
+const (
+	CouponReasonNotFound         = "not_found"
+	CouponReasonNotYetValid      = "not_yet_valid"
+	CouponReasonExpired          = "expired"
+	CouponReasonUsageLimit       = "usage_limit_reached"
+	CouponReasonUserLimit        = "user_limit_reached"
+	CouponReasonNotApplicable    = "not_applicable"
+	CouponReasonBelowMinimum     = "below_minimum_order_value"
+	CouponReasonNoDiscountConfig = "no_discount"
+)
+
+// CouponRejection explains why a coupon code cannot be applied.
+type CouponRejection struct {
+	Code    string `json:"code"`
+	Reason  string `json:"reason"`
+	Message string `json:"message"`
+}
+
+func (r CouponRejection) Error() string {
+	return r.Message
+}
+
+type CouponService interface {
+	ValidateCoupon(ctx *fiber.Ctx, request couponDto.ValidateCouponRequest) (couponDto.ValidateCouponResponse, int, error)
+}
+
+type couponService struct {
+	cartDao   cartdao.DataAccess
+	couponDao couponDao.DataAccess
+}
+
+func NewCouponService(cartDao cartdao.DataAccess, couponDao couponDao.DataAccess) CouponService {
+	return couponService{
+		cartDao:   cartDao,
+		couponDao: couponDao,
+	}
+}
+
+// ValidateCoupon checks a code against the user's current cart without redeeming it.
+// A rejected code is not an error: the response carries the reason instead.
+func (s couponService) ValidateCoupon(ctx *fiber.Ctx, request couponDto.ValidateCouponRequest) (couponDto.ValidateCouponResponse, int, error) {
+	userID, err := utils.GetUserID(ctx)
+	if err != nil {
+		return couponDto.ValidateCouponResponse{}, fiber.StatusUnauthorized, err
+	}
+
+	code := strings.TrimSpace(request.Code)
+	if code == "" {
+		return couponDto.ValidateCouponResponse{}, fiber.StatusBadRequest, fiber.NewError(fiber.StatusBadRequest, "code is required")
+	}
+
+	var items []models.CartItem
+	cart, err := s.cartDao.FindByUserId(userID.String())
+	if err == nil {
+		items, err = s.cartDao.FindItems(cart.ID.String())
+	}
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return couponDto.ValidateCouponResponse{}, fiber.StatusInternalServerError, err
+	}
+
+	lines := couponLinesFromCart(items)
+	response := couponDto.ValidateCouponResponse{Code: code}
+	coupon, discount, err := quoteCoupon(s.couponDao, code, userID, lines, time.Now())
+	if err != nil {
+		var rejection CouponRejection
+		if !errors.As(err, &rejection) {
+			return couponDto.ValidateCouponResponse{}, fiber.StatusInternalServerError, err
+		}
+		response.Reason = rejection.Reason
+		response.Message = rejection.Message
+		return response, fiber.StatusOK, nil
+	}
+
+	response.Valid = true
+	response.EligibleSubtotal = eligibleSubtotal(coupon, lines)
+	response.Discount = discount
+	return response, fiber.StatusOK, nil
+}
+
+// couponLine is the part of an order a coupon is evaluated against.
+type couponLine struct {
+	StoreID    uuid.UUID
+	CategoryID uuid.UUID
+	Amount     float64
+}
+
+func couponLinesFromCart(items []models.CartItem) []couponLine {
+	var lines []couponLine
+	for _, item := range items {
+		lines = append(lines, couponLine{
+			StoreID:    item.Product.StoreID,
+			CategoryID: item.Product.CategoryID,
+			Amount:     utils.Round(unitPrice(item)*float64(item.Quantity), 2),
+		})
+	}
+	return lines
+}
+
+// quoteCoupon looks up a code and works out the discount it grants on lines for the user.
+func quoteCoupon(coupons couponDao.DataAccess, code string, userID uuid.UUID, lines []couponLine, now time.Time) (models.Coupon, float64, error) {
+	coupon, err := coupons.FindByCode(code)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.Coupon{}, 0, CouponRejection{Code: code, Reason: CouponReasonNotFound, Message: "Invalid coupon code"}
+		}
+		return models.Coupon{}, 0, err
+	}
+
+	var used int64
+	if coupon.MaxUsesPerUser != nil {
+		used, err = coupons.CountUserRedemptions(coupon.ID.String(), userID.String())
+		if err != nil {
+			return models.Coupon{}, 0, err
+		}
+	}
+
+	discount, err := evaluateCoupon(coupon, lines, used, now)
+	if err != nil {
+		return models.Coupon{}, 0, err
+	}
+	return coupon, discount, nil
+}
+
+// evaluateCoupon applies the coupon rules in order: validity window, global and per-user
+// usage limits, store/category scope, minimum order value. Scope and minimum are judged
+// on the eligible subtotal, i.e. only the lines the coupon covers, and the discount is
+// never more than that subtotal.
+func evaluateCoupon(coupon models.Coupon, lines []couponLine, userRedemptions int64, now time.Time) (float64, error) {
+	reject := func(reason, message string) (float64, error) {
+		return 0, CouponRejection{Code: coupon.Code, Reason: reason, Message: message}
+	}
+
+	if coupon.ValidFrom != nil && now.Before(*coupon.ValidFrom) {
+		return reject(CouponReasonNotYetValid, "Coupon is valid from "+coupon.ValidFrom.Format(time.RFC3339))
+	}
+	if coupon.ValidUntil != nil && now.After(*coupon.ValidUntil) {
+		return reject(CouponReasonExpired, "Coupon expired on "+coupon.ValidUntil.Format(time.RFC3339))
+	}
+	if coupon.MaxUses != nil && coupon.UsedCount >= *coupon.MaxUses {
+		return reject(CouponReasonUsageLimit, "Coupon usage limit reached")
+	}
+	if coupon.MaxUsesPerUser != nil && userRedemptions >= int64(*coupon.MaxUsesPerUser) {
+		return reject(CouponReasonUserLimit, fmt.Sprintf("Coupon can only be used %d time(s) per customer", *coupon.MaxUsesPerUser))
+	}
+	if coupon.DiscountPct == nil && coupon.DiscountAmount == nil {
+		return reject(CouponReasonNoDiscountConfig, "Coupon does not grant a discount")
+	}
+
+	subtotal := eligibleSubtotal(coupon, lines)
+	if subtotal <= 0 {
+		switch {
+		case coupon.StoreID != nil && coupon.CategoryID != nil:
+			return reject(CouponReasonNotApplicable, "Coupon only applies to items of one store and category, none of which are in the cart")
+		case coupon.StoreID != nil:
+			return reject(CouponReasonNotApplicable, "Coupon only applies to items of another store")
+		case coupon.CategoryID != nil:
+			return reject(CouponReasonNotApplicable, "Coupon only applies to items of another category")
+		}
+		return reject(CouponReasonNotApplicable, "Cart is empty")
+	}
+	if coupon.MinOrderValue != nil && subtotal < *coupon.MinOrderValue {
+		return reject(CouponReasonBelowMinimum, fmt.Sprintf("Coupon requires a minimum order value of %.2f", *coupon.MinOrderValue))
+	}
+
+	var discount float64
+	if coupon.DiscountPct != nil {
+		discount = subtotal * (*coupon.DiscountPct / 100)
+	} else {
+		discount = *coupon.DiscountAmount
+	}
+	if discount > subtotal {
+		discount = subtotal
+	}
+	return utils.Round(discount, 2), nil
+}
+
+// eligibleSubtotal sums the lines inside the coupon's store and category scope.
+func eligibleSubtotal(coupon models.Coupon, lines []couponLine) float64 {
+	var subtotal float64
+	for _, line := range lines {
+		if coupon.StoreID != nil && line.StoreID != *coupon.StoreID {
+			continue
+		}
+		if coupon.CategoryID != nil && line.CategoryID != *coupon.CategoryID {
+			continue
+		}
+		subtotal += line.Amount
+	}
+	return utils.Round(subtotal, 2)
+}
+
+// redeemCoupon uses the coupon for the order inside the checkout transaction, turning the
+// DAO's limit errors into rejections.
+func redeemCoupon(coupons couponDao.DataAccess, coupon models.Coupon, userID uuid.UUID, orderID uuid.UUID) error {
+	err := coupons.Redeem(coupon, userID, orderID)
+	switch {
+	case errors.Is(err, couponDao.ErrUsageLimitReached):
+		return CouponRejection{Code: coupon.Code, Reason: CouponReasonUsageLimit, Message: "Coupon usage limit reached"}
+	case errors.Is(err, couponDao.ErrUserLimitReached):
+		return CouponRejection{Code: coupon.Code, Reason: CouponReasonUserLimit, Message: fmt.Sprintf("Coupon can only be used %d time(s) per customer", *coupon.MaxUsesPerUser)}
+	}
+	return err
+}
+
+// releaseCouponRedemption gives back the coupon use of a cancelled order, if it had one.
+func releaseCouponRedemption(tx *gorm.DB, orderID uuid.UUID) error {
+	var redemption models.CouponRedemption
+	res := tx.Table(redemption.TableName()).
+		Where("order_id = ?", orderID).
+		Find(&redemption)
+	if res.Error != nil || res.RowsAffected == 0 {
+		return res.Error
+	}
+
+	if res := tx.Table(redemption.TableName()).Where("id = ?", redemption.ID).Delete(&redemption); res.Error != nil {
+		return res.Error
+	}
+	res = tx.Table(models.Coupon{}.TableName()).
+		Where("id = ? AND used_count > 0", redemption.CouponID).
+		Update("used_count", gorm.Expr("used_count - 1"))
+	return res.Error
+}
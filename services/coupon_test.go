@@ -0,0 +1,59 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/abdulmalikraji/e-commerce/db/models"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEvaluateCoupon(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	past, future := now.Add(-time.Hour), now.Add(time.Hour)
+	storeA, storeB := uuid.New(), uuid.New()
+	shoes := uuid.New()
+	pct := func(v float64) *float64 { return &v }
+	count := func(v int) *int { return &v }
+
+	lines := []couponLine{
+		{StoreID: storeA, CategoryID: shoes, Amount: 80},
+		{StoreID: storeB, CategoryID: uuid.New(), Amount: 20},
+	}
+
+	tests := []struct {
+		name        string
+		coupon      models.Coupon
+		redemptions int64
+		discount    float64
+		reason      string
+	}{
+		{name: "percent off whole cart", coupon: models.Coupon{DiscountPct: pct(10)}, discount: 10},
+		{name: "amount capped at subtotal", coupon: models.Coupon{DiscountAmount: pct(500)}, discount: 100},
+		{name: "not yet valid", coupon: models.Coupon{DiscountPct: pct(10), ValidFrom: &future}, reason: CouponReasonNotYetValid},
+		{name: "expired", coupon: models.Coupon{DiscountPct: pct(10), ValidUntil: &past}, reason: CouponReasonExpired},
+		{name: "used up", coupon: models.Coupon{DiscountPct: pct(10), MaxUses: count(5), UsedCount: 5}, reason: CouponReasonUsageLimit},
+		{name: "per user limit", coupon: models.Coupon{DiscountPct: pct(10), MaxUsesPerUser: count(1)}, redemptions: 1, reason: CouponReasonUserLimit},
+		{name: "store scoped", coupon: models.Coupon{DiscountPct: pct(50), StoreID: &storeB}, discount: 10},
+		{name: "category scoped", coupon: models.Coupon{DiscountPct: pct(50), CategoryID: &shoes}, discount: 40},
+		{name: "scope matches nothing", coupon: models.Coupon{DiscountPct: pct(50), StoreID: &storeB, CategoryID: &shoes}, reason: CouponReasonNotApplicable},
+		{name: "minimum judged on eligible lines", coupon: models.Coupon{DiscountPct: pct(10), StoreID: &storeB, MinOrderValue: pct(50)}, reason: CouponReasonBelowMinimum},
+		{name: "no discount configured", coupon: models.Coupon{}, reason: CouponReasonNoDiscountConfig},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			discount, err := evaluateCoupon(tt.coupon, lines, tt.redemptions, now)
+			if tt.reason == "" {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.discount, discount)
+				return
+			}
+			var rejection CouponRejection
+			assert.True(t, errors.As(err, &rejection))
+			assert.Equal(t, tt.reason, rejection.Reason)
+		})
+	}
+}
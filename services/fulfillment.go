@@ -0,0 +1,364 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"github.com/abdulmalikraji/e-commerce/db/dao/fulfillmentDao"
+	"github.com/abdulmalikraji/e-commerce/db/dao/orderDao"
+	"github.com/abdulmalikraji/e-commerce/db/dao/orderItemDao"
+	"github.com/abdulmalikraji/e-commerce/db/dao/paymentDao"
+	"github.com/abdulmalikraji/e-commerce/db/dao/refundDao"
+	"github.com/abdulmalikraji/e-commerce/db/dao/stockReservationDao"
+	"github.com/abdulmalikraji/e-commerce/db/models"
+	"github.com/abdulmalikraji/e-commerce/dto/fulfillmentDto"
+	"github.com/abdulmalikraji/e-commerce/dto/refundDto"
+	"github.com/abdulmalikraji/e-commerce/payments"
+	"github.com/abdulmalikraji/e-commerce/utils"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/log"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type FulfillmentService interface {
+	ListStoreFulfillments(ctx *fiber.Ctx, request fulfillmentDto.ListFulfillmentsRequest) ([]fulfillmentDto.FulfillmentResponse, int, error)
+	GetFulfillment(ctx *fiber.Ctx, request fulfillmentDto.GetFulfillmentRequest) (fulfillmentDto.FulfillmentResponse, int, error)
+	UpdateFulfillment(ctx *fiber.Ctx, request fulfillmentDto.UpdateFulfillmentRequest) (fulfillmentDto.FulfillmentResponse, int, error)
+}
+
+type fulfillmentService struct {
+	fulfillmentDao fulfillmentDao.DataAccess
+	orderDao       orderDao.DataAccess
+	orderItemDao   orderItemDao.DataAccess
+	reservationDao stockReservationDao.DataAccess
+	refunds        refundService
+}
+
+func NewFulfillmentService(
+	fulfillmentDao fulfillmentDao.DataAccess,
+	orderDao orderDao.DataAccess,
+	orderItemDao orderItemDao.DataAccess,
+	refundDao refundDao.DataAccess,
+	paymentDao paymentDao.DataAccess,
+	reservationDao stockReservationDao.DataAccess,
+	providers payments.Registry,
+) FulfillmentService {
+	return fulfillmentService{
+		fulfillmentDao: fulfillmentDao,
+		orderDao:       orderDao,
+		orderItemDao:   orderItemDao,
+		reservationDao: reservationDao,
+		refunds: refundService{
+			orderDao:       orderDao,
+			refundDao:      refundDao,
+			paymentDao:     paymentDao,
+			reservationDao: reservationDao,
+			providers:      providers,
+		},
+	}
+}
+
+func (s fulfillmentService) ListStoreFulfillments(ctx *fiber.Ctx, request fulfillmentDto.ListFulfillmentsRequest) ([]fulfillmentDto.FulfillmentResponse, int, error) {
+	var status *string
+	if request.Status != "" {
+		if _, ok := models.FulfillmentStatusTransitions[request.Status]; !ok {
+			return nil, fiber.StatusBadRequest, fiber.NewError(fiber.StatusBadRequest, "Unknown fulfillment status "+request.Status)
+		}
+		status = &request.Status
+	}
+
+	fulfillments, err := s.fulfillmentDao.FindByStoreId(request.StoreID, status)
+	if err != nil {
+		return nil, fiber.StatusInternalServerError, err
+	}
+
+	response := []fulfillmentDto.FulfillmentResponse{}
+	for _, fulfillment := range fulfillments {
+		response = append(response, toFulfillmentResponse(fulfillment, nil))
+	}
+	return response, fiber.StatusOK, nil
+}
+
+func (s fulfillmentService) GetFulfillment(ctx *fiber.Ctx, request fulfillmentDto.GetFulfillmentRequest) (fulfillmentDto.FulfillmentResponse, int, error) {
+	fulfillment, err := s.fulfillmentDao.FindByIdAndStore(request.FulfillmentID, request.StoreID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fulfillmentDto.FulfillmentResponse{}, fiber.StatusNotFound, fiber.NewError(fiber.StatusNotFound, "Fulfillment not found")
+		}
+		return fulfillmentDto.FulfillmentResponse{}, fiber.StatusInternalServerError, err
+	}
+
+	items, err := s.orderItemDao.FindByOrderAndStore(fulfillment.OrderID.String(), fulfillment.StoreID.String())
+	if err != nil {
+		return fulfillmentDto.FulfillmentResponse{}, fiber.StatusInternalServerError, err
+	}
+
+	return toFulfillmentResponse(fulfillment, items), fiber.StatusOK, nil
+}
+
+// UpdateFulfillment edits a store's fulfillment and moves it through its lifecycle. A store
+// that cancels its fulfillment on a paid order refunds the buyer for its items, which go
+// back on the shelf.
+func (s fulfillmentService) UpdateFulfillment(ctx *fiber.Ctx, request fulfillmentDto.UpdateFulfillmentRequest) (fulfillmentDto.FulfillmentResponse, int, error) {
+	userID, err := utils.GetUserID(ctx)
+	if err != nil {
+		return fulfillmentDto.FulfillmentResponse{}, fiber.StatusUnauthorized, err
+	}
+
+	if request.ShippingCost != nil && *request.ShippingCost < 0 {
+		return fulfillmentDto.FulfillmentResponse{}, fiber.StatusBadRequest, fiber.NewError(fiber.StatusBadRequest, "shipping_cost cannot be negative")
+	}
+	// Unknown statuses are bad input; only known ones are checked against the lifecycle.
+	if request.Status != nil {
+		if _, ok := models.FulfillmentStatusTransitions[*request.Status]; !ok {
+			return fulfillmentDto.FulfillmentResponse{}, fiber.StatusBadRequest, fiber.NewError(fiber.StatusBadRequest, "Unknown fulfillment status "+*request.Status)
+		}
+	}
+
+	var refund models.Refund
+	err = s.orderDao.Transaction(func(tx *gorm.DB) error {
+		var fulfillment models.Fulfillment
+		res := tx.Table(fulfillment.TableName()).
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND store_id = ? AND del_flg = ?", request.FulfillmentID, request.StoreID, false).
+			First(&fulfillment)
+		if res.Error != nil {
+			if errors.Is(res.Error, gorm.ErrRecordNotFound) {
+				return fiber.NewError(fiber.StatusNotFound, "Fulfillment not found")
+			}
+			return res.Error
+		}
+
+		updates := map[string]interface{}{"updated_by": userID}
+		if request.Carrier != nil {
+			updates["carrier"] = *request.Carrier
+		}
+		if request.TrackingNumber != nil {
+			updates["tracking_number"] = *request.TrackingNumber
+		}
+		if request.ShippingCost != nil {
+			updates["shipping_cost"] = *request.ShippingCost
+		}
+
+		statusChanged := request.Status != nil && *request.Status != fulfillment.Status
+		if statusChanged {
+			to := *request.Status
+			if !models.CanTransitionFulfillment(fulfillment.Status, to) {
+				return fiber.NewError(fiber.StatusConflict, "Fulfillment cannot move from "+fulfillment.Status+" to "+to)
+			}
+
+			var order models.Order
+			if res := tx.Table(order.TableName()).Where("id = ?", fulfillment.OrderID).First(&order); res.Error != nil {
+				return res.Error
+			}
+			// Nothing leaves the warehouse before the buyer has paid.
+			if to != models.FulfillmentCancelled && !orderIsPaid(order.Status) {
+				return fiber.NewError(fiber.StatusConflict, "Order "+order.ID.String()+" is "+order.Status+" and cannot be fulfilled")
+			}
+			if to == models.FulfillmentCancelled && orderIsPaid(order.Status) {
+				var err error
+				if refund, err = refundFulfillment(tx, order, fulfillment.StoreID, userID); err != nil {
+					return err
+				}
+			}
+
+			now := time.Now()
+			updates["status"] = to
+			switch to {
+			case models.FulfillmentShipped:
+				updates["shipped_at"] = now
+			case models.FulfillmentDelivered:
+				updates["delivered_at"] = now
+			}
+		}
+
+		res = tx.Table(fulfillment.TableName()).
+			Where("id = ?", fulfillment.ID).
+			Updates(updates)
+		if res.Error != nil {
+			return res.Error
+		}
+
+		if statusChanged {
+			return syncOrderWithFulfillments(tx, s.reservationDao, fulfillment.OrderID, &userID)
+		}
+		return nil
+	})
+	if err != nil {
+		return fulfillmentDto.FulfillmentResponse{}, errorStatus(err), err
+	}
+
+	// The fulfillment stays cancelled when the payout fails; the refund is then left
+	// pending for the store to approve again.
+	if refund.ID != uuid.Nil {
+		if status, err := s.refunds.executeRefund(refund, true, userID); err != nil {
+			return fulfillmentDto.FulfillmentResponse{}, status, err
+		}
+		log.Infof("refund %s of %.2f paid out for cancelled fulfillment %s", refund.ID.String(), refund.Amount, request.FulfillmentID)
+	}
+
+	log.Infof("fulfillment %s updated by user_id=%s", request.FulfillmentID, userID.String())
+	return s.GetFulfillment(ctx, fulfillmentDto.GetFulfillmentRequest{
+		StoreID:       request.StoreID,
+		FulfillmentID: request.FulfillmentID,
+	})
+}
+
+// refundFulfillment records an approved refund of whatever the buyer has not had refunded
+// yet of a store's items on a paid order, for the fulfillment the store is cancelling. It
+// returns an empty refund when nothing is left to refund.
+func refundFulfillment(tx *gorm.DB, order models.Order, storeID, userID uuid.UUID) (models.Refund, error) {
+	res := tx.Table(models.OrderItem{}.TableName()).
+		Where("order_id = ? AND del_flg = ?", order.ID, false).
+		Find(&order.Items)
+	if res.Error != nil {
+		return models.Refund{}, res.Error
+	}
+
+	payment, err := lockCapturedPayment(tx, order.ID)
+	if err != nil {
+		return models.Refund{}, err
+	}
+	refundedQty, err := refundedQuantities(tx, order.ID)
+	if err != nil {
+		return models.Refund{}, err
+	}
+
+	var lines []refundDto.RefundItemRequest
+	for _, item := range order.Items {
+		if left := item.Quantity - refundedQty[item.ID]; item.StoreID == storeID && left > 0 {
+			lines = append(lines, refundDto.RefundItemRequest{OrderItemID: item.ID.String(), Quantity: left})
+		}
+	}
+	if len(lines) == 0 {
+		return models.Refund{}, nil
+	}
+
+	refund, err := priceRefund(tx, order, payment, refundedQty, lines)
+	if err != nil {
+		return models.Refund{}, err
+	}
+	refund.Reason = "Fulfillment cancelled by the store"
+	refund.Status = models.RefundApproved
+	refund.RequestedBy = userID
+	refund.ReviewedBy = &userID
+
+	return refund, tx.Table(refund.TableName()).Create(&refund).Error
+}
+
+// orderIsPaid reports whether the order has been paid for and not cancelled or refunded since.
+func orderIsPaid(status string) bool {
+	switch status {
+	case models.OrderStatusPaid, models.OrderStatusFulfilled, models.OrderStatusShipped, models.OrderStatusDelivered:
+		return true
+	}
+	return false
+}
+
+// syncOrderWithFulfillments rolls the per-store fulfillments up into the order status:
+// the order is shipped once every store has shipped and delivered once every store has
+// delivered. Cancelled fulfillments drop out of the roll-up once the buyer has their money
+// back for them; until then the order waits.
+func syncOrderWithFulfillments(tx *gorm.DB, reservationDao stockReservationDao.DataAccess, orderID uuid.UUID, changedBy *uuid.UUID) error {
+	var fulfillments []models.Fulfillment
+	res := tx.Table(models.Fulfillment{}.TableName()).
+		Where("order_id = ? AND del_flg = ?", orderID, false).
+		Find(&fulfillments)
+	if res.Error != nil {
+		return res.Error
+	}
+
+	var active int
+	var cancelledStores []uuid.UUID
+	allShipped, allDelivered := true, true
+	for _, fulfillment := range fulfillments {
+		switch fulfillment.Status {
+		case models.FulfillmentCancelled:
+			cancelledStores = append(cancelledStores, fulfillment.StoreID)
+			continue
+		case models.FulfillmentDelivered:
+		case models.FulfillmentShipped:
+			allDelivered = false
+		default:
+			allShipped, allDelivered = false, false
+		}
+		active++
+	}
+	if active == 0 || !allShipped {
+		return nil
+	}
+
+	if len(cancelledStores) > 0 {
+		var unpaid int64
+		res := tx.Table(models.Refund{}.TableName()).
+			Where("order_id = ? AND store_id IN ? AND status IN ? AND del_flg = ?",
+				orderID, cancelledStores, []string{models.RefundPending, models.RefundApproved}, false).
+			Count(&unpaid)
+		if res.Error != nil {
+			return res.Error
+		}
+		if unpaid > 0 {
+			return nil
+		}
+	}
+
+	var steps []string
+	if allShipped {
+		steps = append(steps, models.OrderStatusShipped)
+	}
+	if allDelivered {
+		steps = append(steps, models.OrderStatusDelivered)
+	}
+
+	for _, step := range steps {
+		var order models.Order
+		if res := tx.Table(order.TableName()).Where("id = ?", orderID).First(&order); res.Error != nil {
+			return res.Error
+		}
+		if !models.CanTransitionOrder(order.Status, step) {
+			continue
+		}
+		if _, err := transitionOrderStatus(tx, reservationDao, orderStatusChange{
+			OrderID:   orderID,
+			To:        step,
+			ChangedBy: changedBy,
+			Note:      "all store fulfillments " + step,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func toFulfillmentResponse(fulfillment models.Fulfillment, items []models.OrderItem) fulfillmentDto.FulfillmentResponse {
+	response := fulfillmentDto.FulfillmentResponse{
+		ID:             fulfillment.ID.String(),
+		OrderID:        fulfillment.OrderID.String(),
+		StoreID:        fulfillment.StoreID.String(),
+		OrderStatus:    fulfillment.Order.Status,
+		Status:         fulfillment.Status,
+		Carrier:        fulfillment.Carrier,
+		TrackingNumber: fulfillment.TrackingNumber,
+		ShippingCost:   fulfillment.ShippingCost,
+		ShippedAt:      fulfillment.ShippedAt,
+		DeliveredAt:    fulfillment.DeliveredAt,
+		CreatedAt:      fulfillment.CreatedAt,
+	}
+	for _, item := range items {
+		var variantID string
+		if item.VariantID != nil {
+			variantID = item.VariantID.String()
+		}
+		response.Items = append(response.Items, fulfillmentDto.FulfillmentItem{
+			OrderItemID: item.ID.String(),
+			ProductID:   item.ProductID.String(),
+			VariantID:   variantID,
+			Name:        item.Product.Name,
+			Quantity:    item.Quantity,
+			UnitPrice:   item.UnitPrice,
+		})
+	}
+	return response
+}
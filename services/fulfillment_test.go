@@ -0,0 +1,213 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/abdulmalikraji/e-commerce/db/models"
+	"github.com/abdulmalikraji/e-commerce/dto/fulfillmentDto"
+	"github.com/abdulmalikraji/e-commerce/mocks/dao/fulfillmentDao"
+	"github.com/abdulmalikraji/e-commerce/mocks/dao/orderDao"
+	"github.com/abdulmalikraji/e-commerce/mocks/dao/orderItemDao"
+	"github.com/abdulmalikraji/e-commerce/mocks/dao/paymentDao"
+	"github.com/abdulmalikraji/e-commerce/mocks/dao/stockReservationDao"
+	"github.com/abdulmalikraji/e-commerce/payments"
+	"github.com/abdulmalikraji/e-commerce/utils"
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+	"gorm.io/gorm"
+)
+
+var fulfillmentMockDao *fulfillmentDao.MockDataAccess
+var orderItemMockDao *orderItemDao.MockDataAccess
+
+var fulfillments FulfillmentService
+
+func setupFulfillments(t *testing.T) func() {
+	ct := gomock.NewController(t)
+
+	app := fiber.New()
+	fiberCtx = app.AcquireCtx(&fasthttp.RequestCtx{})
+	utils.SetPrincipal(fiberCtx, staffOwner)
+
+	fulfillmentMockDao = fulfillmentDao.NewMockDataAccess(ct)
+	orderMockDao = orderDao.NewMockDataAccess(ct)
+	orderItemMockDao = orderItemDao.NewMockDataAccess(ct)
+	paymentMockDao = paymentDao.NewMockDataAccess(ct)
+	reservationMockDao = stockReservationDao.NewMockDataAccess(ct)
+	fakeProvider = payments.NewFakeProvider()
+
+	fulfillments = NewFulfillmentService(fulfillmentMockDao, orderMockDao, orderItemMockDao, nil, paymentMockDao,
+		reservationMockDao, payments.NewRegistry(fakeProvider))
+	return func() {
+		fulfillments = nil
+		app.ReleaseCtx(fiberCtx)
+		ct.Finish()
+	}
+}
+
+// expectFulfillments expects the roll-up to load the order's fulfillments.
+func expectFulfillments(sqlMock sqlmock.Sqlmock, orderID uuid.UUID, fulfillments ...models.Fulfillment) {
+	rows := sqlmock.NewRows([]string{"id", "order_id", "store_id", "status"})
+	for _, fulfillment := range fulfillments {
+		rows.AddRow(fulfillment.ID, orderID, fulfillment.StoreID, fulfillment.Status)
+	}
+	sqlMock.ExpectQuery(`FROM "ecom"."fulfillments" WHERE order_id = \$1`).WillReturnRows(rows)
+}
+
+func TestFulfillmentService_Update_Rejects_Unknown_Status(t *testing.T) {
+	app := fiber.New()
+	ctx := app.AcquireCtx(&fasthttp.RequestCtx{})
+	defer app.ReleaseCtx(ctx)
+	utils.SetPrincipal(ctx, staffOwner)
+	fs := NewFulfillmentService(nil, nil, nil, nil, nil, nil, nil)
+
+	status := "teleported"
+	_, code, err := fs.UpdateFulfillment(ctx, fulfillmentDto.UpdateFulfillmentRequest{
+		StoreID:       staffStore.ID.String(),
+		FulfillmentID: uuid.NewString(),
+		Status:        &status,
+	})
+
+	assert.Error(t, err)
+	assert.Equal(t, fiber.StatusBadRequest, code)
+}
+
+// A paid order of two units from staffStore and one from another store: cancelling
+// staffStore's fulfillment pays its 20 back and puts both units back on the shelf.
+func TestFulfillmentService_Cancelling_On_A_Paid_Order_Refunds_And_Restocks(t *testing.T) {
+	teardown := setupFulfillments(t)
+	defer teardown()
+
+	order, item := storeOrder(models.OrderStatusPaid)
+	item.VariantID, item.Quantity, item.UnitPrice = nil, 2, 10
+	other := models.OrderItem{ID: uuid.New(), OrderID: order.ID, StoreID: uuid.New(), ProductID: uuid.New(), Quantity: 1, UnitPrice: 10}
+	order.TotalAmount = 30
+	payment := initiatedPayment(t, order, "card")
+	_, err := fakeProvider.Capture(payment.TransactionRef, 30)
+	assert.NoError(t, err)
+	payment.Status = models.PaymentSuccessful
+	fulfillment := models.Fulfillment{ID: uuid.New(), OrderID: order.ID, StoreID: staffStore.ID, Status: models.FulfillmentProcessing}
+	shipping := models.Fulfillment{ID: uuid.New(), OrderID: order.ID, StoreID: other.StoreID, Status: models.FulfillmentProcessing}
+
+	tx, sqlMock := mockTx(t)
+	orderMockDao.EXPECT().Transaction(gomock.Any()).DoAndReturn(func(fn func(tx *gorm.DB) error) error {
+		return fn(tx)
+	}).Times(2)
+	sqlMock.ExpectQuery(`FROM "ecom"."fulfillments" .* FOR UPDATE`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "order_id", "store_id", "status"}).
+			AddRow(fulfillment.ID, order.ID, fulfillment.StoreID, fulfillment.Status))
+	sqlMock.ExpectQuery(`FROM "ecom"."orders"`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "buyer_id", "status"}).AddRow(order.ID, order.BuyerID, order.Status))
+	sqlMock.ExpectQuery(`FROM "ecom"."order_items"`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "order_id", "store_id", "product_id", "quantity", "unit_price"}).
+			AddRow(item.ID, order.ID, item.StoreID, item.ProductID, item.Quantity, item.UnitPrice).
+			AddRow(other.ID, order.ID, other.StoreID, other.ProductID, other.Quantity, other.UnitPrice))
+	sqlMock.ExpectQuery(`FROM "ecom"."payments" .* FOR UPDATE`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "order_id", "amount", "status"}).
+			AddRow(payment.ID, order.ID, payment.Amount, payment.Status))
+	sqlMock.ExpectQuery(`FROM ecom.refund_items AS ri`).WillReturnRows(sqlmock.NewRows([]string{"order_item_id", "quantity"}))
+	sqlMock.ExpectQuery(`SELECT COALESCE\(SUM\(amount\), 0\) FROM "ecom"."refunds"`).
+		WillReturnRows(sqlmock.NewRows([]string{"coalesce"}).AddRow(0.0))
+	refundID := uuid.New()
+	sqlMock.ExpectQuery(`INSERT INTO "ecom"."refunds"`).
+		WithArgs(payment.ID, order.ID, staffStore.ID, 20.0, sqlmock.AnyArg(), models.RefundApproved,
+			staffOwner.UserID, staffOwner.UserID, sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(refundID))
+	sqlMock.ExpectQuery(`INSERT INTO "ecom"."refund_items"`).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(uuid.New()))
+	sqlMock.ExpectExec(`UPDATE "ecom"."fulfillments" SET "status"=\$1,"updated_by"=\$2 WHERE id = \$3`).
+		WithArgs(models.FulfillmentCancelled, staffOwner.UserID, fulfillment.ID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	fulfillment.Status = models.FulfillmentCancelled
+	expectFulfillments(sqlMock, order.ID, fulfillment, shipping)
+
+	// The payout, then the refund is recorded and the units restocked.
+	paymentMockDao.EXPECT().FindById(payment.ID.String()).Return(payment, nil)
+	sqlMock.ExpectExec(`UPDATE "ecom"."refunds" SET`).WillReturnResult(sqlmock.NewResult(0, 1))
+	sqlMock.ExpectQuery(`FROM "ecom"."refund_items"`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "refund_id", "order_item_id", "quantity", "amount"}).
+			AddRow(uuid.New(), refundID, item.ID, 2, 20.0))
+	sqlMock.ExpectQuery(`FROM "ecom"."order_items"`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "order_id", "product_id", "quantity"}).
+			AddRow(item.ID, order.ID, item.ProductID, item.Quantity))
+	sqlMock.ExpectExec(`UPDATE "ecom"."products" SET "stock"=stock \+ \$1`).
+		WithArgs(2, item.ProductID).WillReturnResult(sqlmock.NewResult(0, 1))
+	reservationMockDao.EXPECT().WithTx(gomock.Any()).Return(reservationMockDao)
+	reservationMockDao.EXPECT().ReleaseUnits(order.ID, item.ProductID, 2).Return(nil)
+	sqlMock.ExpectQuery(`SELECT COALESCE\(SUM\(amount\), 0\) FROM "ecom"."refunds"`).
+		WillReturnRows(sqlmock.NewRows([]string{"coalesce"}).AddRow(20.0))
+	expectFulfillments(sqlMock, order.ID, fulfillment, shipping)
+
+	fulfillmentMockDao.EXPECT().FindByIdAndStore(fulfillment.ID.String(), staffStore.ID.String()).Return(fulfillment, nil)
+	orderItemMockDao.EXPECT().FindByOrderAndStore(order.ID.String(), staffStore.ID.String()).Return([]models.OrderItem{item}, nil)
+
+	cancelled := models.FulfillmentCancelled
+	response, status, err := fulfillments.UpdateFulfillment(fiberCtx, fulfillmentDto.UpdateFulfillmentRequest{
+		StoreID:       staffStore.ID.String(),
+		FulfillmentID: fulfillment.ID.String(),
+		Status:        &cancelled,
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, status)
+	assert.Equal(t, models.FulfillmentCancelled, response.Status)
+	assert.NoError(t, sqlMock.ExpectationsWereMet())
+
+	// Only staffStore's share went back to the buyer.
+	_, err = fakeProvider.Refund(payment.TransactionRef, 10.01)
+	assert.ErrorIs(t, err, payments.ErrInvalidAmount)
+}
+
+func TestFulfillmentService_Roll_Up(t *testing.T) {
+	cancelledStore, shippingStore := uuid.New(), uuid.New()
+	cancelled := models.Fulfillment{ID: uuid.New(), StoreID: cancelledStore, Status: models.FulfillmentCancelled}
+	shipped := models.Fulfillment{ID: uuid.New(), StoreID: shippingStore, Status: models.FulfillmentShipped}
+	processing := models.Fulfillment{ID: uuid.New(), StoreID: shippingStore, Status: models.FulfillmentProcessing}
+	one := 1
+
+	tests := []struct {
+		name         string
+		fulfillments []models.Fulfillment
+		unpaid       *int // refunds of cancelled stores not paid out yet, when they are counted
+		ships        bool
+	}{
+		{name: "every store shipped", fulfillments: []models.Fulfillment{shipped}, ships: true},
+		{name: "a store is still packing", fulfillments: []models.Fulfillment{cancelled, processing}},
+		{name: "a cancelled store is refunded", fulfillments: []models.Fulfillment{cancelled, shipped}, unpaid: new(int), ships: true},
+		{name: "a cancelled store still owes its refund", fulfillments: []models.Fulfillment{cancelled, shipped}, unpaid: &one},
+		{name: "every store cancelled", fulfillments: []models.Fulfillment{cancelled}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ct := gomock.NewController(t)
+			defer ct.Finish()
+			reservations := stockReservationDao.NewMockDataAccess(ct)
+
+			order, _ := storeOrder(models.OrderStatusPaid)
+			tx, sqlMock := mockTx(t)
+			expectFulfillments(sqlMock, order.ID, test.fulfillments...)
+			if test.unpaid != nil {
+				sqlMock.ExpectQuery(`SELECT count\(\*\) FROM "ecom"."refunds" WHERE order_id = \$1 AND store_id IN \(\$2\) AND status IN \(\$3,\$4\)`).
+					WithArgs(order.ID, cancelledStore, models.RefundPending, models.RefundApproved, false).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(*test.unpaid))
+			}
+			if test.ships {
+				sqlMock.ExpectQuery(`FROM "ecom"."orders"`).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "buyer_id", "status"}).AddRow(order.ID, order.BuyerID, order.Status))
+				expectOrderLock(sqlMock, order, models.OrderStatusPaid)
+				sqlMock.ExpectExec(`UPDATE "ecom"."orders" SET "status"=\$1`).
+					WithArgs(models.OrderStatusShipped, order.ID).WillReturnResult(sqlmock.NewResult(0, 1))
+				expectHistory(sqlMock, order, models.OrderStatusPaid, models.OrderStatusShipped, staffOwner.UserID, "all store fulfillments shipped")
+			}
+
+			err := syncOrderWithFulfillments(tx, reservations, order.ID, &staffOwner.UserID)
+
+			assert.NoError(t, err)
+			assert.NoError(t, sqlMock.ExpectationsWereMet())
+		})
+	}
+}
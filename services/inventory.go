@@ -0,0 +1,143 @@
+package services
+
+import (
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/abdulmalikraji/e-commerce/db/dao/orderDao"
+	"github.com/abdulmalikraji/e-commerce/db/dao/stockReservationDao"
+	"github.com/abdulmalikraji/e-commerce/db/models"
+	"github.com/gofiber/fiber/v2/log"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultReservationTTL = 15 * time.Minute
+	// expiredOrderBatch is how many abandoned orders a sweep cancels at most.
+	expiredOrderBatch = 100
+)
+
+// InventoryService manages the stock holds placed at checkout.
+type InventoryService interface {
+	// CommitOrder marks the order's holds as sold (payment succeeded).
+	CommitOrder(orderID uuid.UUID) error
+	// ReleaseOrder gives the order's held and sold warehouse units back (order cancelled
+	// or payment failed).
+	ReleaseOrder(orderID uuid.UUID) error
+	// ReleaseExpired expires every hold whose TTL has passed.
+	ReleaseExpired() (int64, error)
+	// CancelExpiredOrders cancels the pending orders whose checkout holds have lapsed,
+	// which puts their stock back on sale and frees their coupon uses.
+	CancelExpiredOrders() (int, error)
+}
+
+type inventoryService struct {
+	reservationDao stockReservationDao.DataAccess
+	orderDao       orderDao.DataAccess
+}
+
+func NewInventoryService(reservationDao stockReservationDao.DataAccess, orderDao orderDao.DataAccess) InventoryService {
+	return inventoryService{
+		reservationDao: reservationDao,
+		orderDao:       orderDao,
+	}
+}
+
+func (s inventoryService) CommitOrder(orderID uuid.UUID) error {
+	if err := s.reservationDao.Commit(orderID); err != nil {
+		log.Errorf("failed to commit stock reservations for order_id=%s: %v", orderID.String(), err)
+		return err
+	}
+	return nil
+}
+
+func (s inventoryService) ReleaseOrder(orderID uuid.UUID) error {
+	if err := s.reservationDao.Release(orderID); err != nil {
+		log.Errorf("failed to release stock reservations for order_id=%s: %v", orderID.String(), err)
+		return err
+	}
+	return nil
+}
+
+func (s inventoryService) ReleaseExpired() (int64, error) {
+	return s.reservationDao.ReleaseExpired(time.Now())
+}
+
+// CancelExpiredOrders goes by the order's age: checkout holds every item for
+// reservationTTL, so a pending order older than that has lost its holds, including
+// one whose products have no warehouse stock and got none. An order paid meanwhile is
+// left alone.
+func (s inventoryService) CancelExpiredOrders() (int, error) {
+	orders, err := s.orderDao.FindStalePending(time.Now().Add(-reservationTTL()), expiredOrderBatch)
+	if err != nil {
+		return 0, err
+	}
+
+	cancelled := 0
+	for _, order := range orders {
+		err := s.orderDao.Transaction(func(tx *gorm.DB) error {
+			_, err := transitionOrderStatus(tx, s.reservationDao, orderStatusChange{
+				OrderID: order.ID,
+				From:    models.OrderStatusPending,
+				To:      models.OrderStatusCancelled,
+				Note:    "checkout hold expired",
+			})
+			return err
+		})
+		if err != nil {
+			log.Errorf("failed to cancel expired order_id=%s: %v", order.ID.String(), err)
+			continue
+		}
+		cancelled++
+	}
+	return cancelled, nil
+}
+
+// StartReservationSweeper cancels the orders whose holds lapsed and expires the
+// remaining lapsed holds every interval until the returned stop function is called.
+func StartReservationSweeper(service InventoryService, interval time.Duration) (stop func()) {
+	ticker := time.NewTicker(interval)
+	done := make(chan struct{})
+
+	go func() {
+		for {
+			select {
+			case <-ticker.C:
+				orders, err := service.CancelExpiredOrders()
+				if err != nil {
+					log.Errorf("reservation sweeper failed: %v", err)
+				}
+				if orders > 0 {
+					log.Infof("reservation sweeper cancelled %d expired orders", orders)
+				}
+
+				count, err := service.ReleaseExpired()
+				if err != nil {
+					log.Errorf("reservation sweeper failed: %v", err)
+					continue
+				}
+				if count > 0 {
+					log.Infof("reservation sweeper expired %d holds", count)
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		ticker.Stop()
+		close(done)
+	}
+}
+
+// reservationTTL returns how long checkout holds stock for, configurable through
+// the RESERVATION_TTL_MINUTES environment variable.
+func reservationTTL() time.Duration {
+	if minutes, err := strconv.Atoi(os.Getenv("RESERVATION_TTL_MINUTES")); err == nil && minutes > 0 {
+		return time.Duration(minutes) * time.Minute
+	}
+	return defaultReservationTTL
+}
@@ -0,0 +1,96 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/abdulmalikraji/e-commerce/db/models"
+	"github.com/abdulmalikraji/e-commerce/mocks/dao/orderDao"
+	"github.com/abdulmalikraji/e-commerce/mocks/dao/stockReservationDao"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// expectOrderLock expects transitionOrderStatus to lock the order and find it in status.
+func expectOrderLock(sqlMock sqlmock.Sqlmock, order models.Order, status string) {
+	sqlMock.ExpectQuery(`FROM "ecom"."orders" .* FOR UPDATE`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "buyer_id", "status", "total_amount"}).
+			AddRow(order.ID, order.BuyerID, status, order.TotalAmount))
+}
+
+// expectCancellation expects a locked order to be cancelled: its warehouse holds
+// released, item back in stock, its coupon use given back and its open fulfillments
+// cancelled.
+func expectCancellation(sqlMock sqlmock.Sqlmock, reservations *stockReservationDao.MockDataAccess, order models.Order, item models.OrderItem) {
+	reservations.EXPECT().WithTx(gomock.Any()).Return(reservations)
+	reservations.EXPECT().Release(order.ID).Return(nil)
+	sqlMock.ExpectQuery(`FROM "ecom"."order_items"`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "order_id", "product_id", "variant_id", "quantity"}).
+			AddRow(item.ID, order.ID, item.ProductID, item.VariantID, item.Quantity))
+	sqlMock.ExpectExec(`UPDATE "ecom"."product_variants" SET "stock"=stock \+ \$1 WHERE id = \$2`).
+		WithArgs(item.Quantity, *item.VariantID).WillReturnResult(sqlmock.NewResult(0, 1))
+	sqlMock.ExpectQuery(`FROM "ecom"."coupon_redemptions"`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "coupon_id", "order_id"}).AddRow(uuid.New(), uuid.New(), order.ID))
+	sqlMock.ExpectExec(`DELETE FROM "ecom"."coupon_redemptions"`).WillReturnResult(sqlmock.NewResult(0, 1))
+	sqlMock.ExpectExec(`UPDATE "ecom"."coupons" SET "used_count"=used_count - 1`).WillReturnResult(sqlmock.NewResult(0, 1))
+	sqlMock.ExpectExec(`UPDATE "ecom"."fulfillments" SET "status"=\$1`).
+		WithArgs(models.FulfillmentCancelled, order.ID, models.FulfillmentPending, models.FulfillmentProcessing).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	sqlMock.ExpectExec(`UPDATE "ecom"."orders" SET "status"=\$1`).
+		WithArgs(models.OrderStatusCancelled, order.ID).WillReturnResult(sqlmock.NewResult(0, 1))
+	sqlMock.ExpectQuery(`INSERT INTO "ecom"."order_status_history"`).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(uuid.New()))
+}
+
+func setupInventory(t *testing.T) (InventoryService, *orderDao.MockDataAccess, *stockReservationDao.MockDataAccess) {
+	ct := gomock.NewController(t)
+	t.Cleanup(ct.Finish)
+	orders := orderDao.NewMockDataAccess(ct)
+	reservations := stockReservationDao.NewMockDataAccess(ct)
+	return NewInventoryService(reservations, orders), orders, reservations
+}
+
+func TestInventoryService_Cancels_Orders_Whose_Holds_Expired(t *testing.T) {
+	inventory, orders, reservations := setupInventory(t)
+
+	order := pendingOrder()
+	variantID := uuid.New()
+	item := models.OrderItem{ID: uuid.New(), OrderID: order.ID, ProductID: uuid.New(), VariantID: &variantID, Quantity: 2}
+	tx, sqlMock := mockTx(t)
+	orders.EXPECT().FindStalePending(gomock.Any(), expiredOrderBatch).DoAndReturn(func(placedBefore time.Time, limit int) ([]models.Order, error) {
+		assert.WithinDuration(t, time.Now().Add(-defaultReservationTTL), placedBefore, time.Second)
+		return []models.Order{order}, nil
+	})
+	orders.EXPECT().Transaction(gomock.Any()).DoAndReturn(func(fn func(tx *gorm.DB) error) error {
+		return fn(tx)
+	})
+	expectOrderLock(sqlMock, order, models.OrderStatusPending)
+	expectCancellation(sqlMock, reservations, order, item)
+
+	cancelled, err := inventory.CancelExpiredOrders()
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, cancelled)
+	assert.NoError(t, sqlMock.ExpectationsWereMet())
+}
+
+func TestInventoryService_Leaves_An_Order_Paid_Since_It_Was_Found(t *testing.T) {
+	inventory, orders, _ := setupInventory(t)
+
+	order := pendingOrder()
+	tx, sqlMock := mockTx(t)
+	orders.EXPECT().FindStalePending(gomock.Any(), expiredOrderBatch).Return([]models.Order{order}, nil)
+	orders.EXPECT().Transaction(gomock.Any()).DoAndReturn(func(fn func(tx *gorm.DB) error) error {
+		return fn(tx)
+	})
+	expectOrderLock(sqlMock, order, models.OrderStatusPaid)
+
+	cancelled, err := inventory.CancelExpiredOrders()
+
+	assert.NoError(t, err)
+	assert.Equal(t, 0, cancelled)
+	assert.NoError(t, sqlMock.ExpectationsWereMet())
+}
@@ -0,0 +1,303 @@
+package services
+
+import (
+	"errors"
+
+	"github.com/abdulmalikraji/e-commerce/db/dao/orderDao"
+	"github.com/abdulmalikraji/e-commerce/db/dao/orderStatusHistoryDao"
+	"github.com/abdulmalikraji/e-commerce/db/dao/stockReservationDao"
+	"github.com/abdulmalikraji/e-commerce/db/models"
+	"github.com/abdulmalikraji/e-commerce/dto/orderDto"
+	"github.com/abdulmalikraji/e-commerce/utils"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/log"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type OrderService interface {
+	UpdateOrderStatus(ctx *fiber.Ctx, request orderDto.UpdateOrderStatusRequest) (orderDto.UpdateOrderStatusResponse, int, error)
+	GetOrderHistory(ctx *fiber.Ctx, request orderDto.GetOrderHistoryRequest) ([]orderDto.OrderStatusHistoryResponse, int, error)
+}
+
+type orderService struct {
+	orderDao       orderDao.DataAccess
+	historyDao     orderStatusHistoryDao.DataAccess
+	reservationDao stockReservationDao.DataAccess
+}
+
+func NewOrderService(
+	orderDao orderDao.DataAccess,
+	historyDao orderStatusHistoryDao.DataAccess,
+	reservationDao stockReservationDao.DataAccess,
+) OrderService {
+	return orderService{
+		orderDao:       orderDao,
+		historyDao:     historyDao,
+		reservationDao: reservationDao,
+	}
+}
+
+func (s orderService) UpdateOrderStatus(ctx *fiber.Ctx, request orderDto.UpdateOrderStatusRequest) (orderDto.UpdateOrderStatusResponse, int, error) {
+	principal, ok := utils.GetPrincipal(ctx)
+	if !ok {
+		return orderDto.UpdateOrderStatusResponse{}, fiber.StatusUnauthorized, fiber.NewError(fiber.StatusUnauthorized, "missing user id")
+	}
+	userID := principal.UserID
+
+	if _, ok := models.OrderStatusTransitions[request.Status]; !ok {
+		return orderDto.UpdateOrderStatusResponse{}, fiber.StatusBadRequest, fiber.NewError(fiber.StatusBadRequest, "Unknown order status "+request.Status)
+	}
+	// Payment and refund outcomes are driven by the payment layer, never set by hand.
+	if request.Status == models.OrderStatusPaid || request.Status == models.OrderStatusRefunded {
+		return orderDto.UpdateOrderStatusResponse{}, fiber.StatusBadRequest, fiber.NewError(fiber.StatusBadRequest, "Order status "+request.Status+" is set by the payment flow")
+	}
+
+	order, err := s.orderDao.FindById(request.OrderID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return orderDto.UpdateOrderStatusResponse{}, fiber.StatusNotFound, fiber.NewError(fiber.StatusNotFound, "Order not found")
+		}
+		return orderDto.UpdateOrderStatusResponse{}, fiber.StatusInternalServerError, err
+	}
+
+	// Buyers may only cancel an order they have not paid for yet; any other change is
+	// made by staff allowed to manage orders on every store the order touches.
+	buyerCancel := order.BuyerID == userID &&
+		order.Status == models.OrderStatusPending &&
+		request.Status == models.OrderStatusCancelled
+	if !buyerCancel && !canManageAll(order, principal) {
+		return orderDto.UpdateOrderStatusResponse{}, fiber.StatusForbidden, fiber.NewError(fiber.StatusForbidden, "You are not allowed to change this order")
+	}
+	// Cancelling would keep the buyer's money; cancelling each store's fulfillment refunds it.
+	if request.Status == models.OrderStatusCancelled && orderIsPaid(order.Status) {
+		return orderDto.UpdateOrderStatusResponse{}, fiber.StatusConflict, fiber.NewError(fiber.StatusConflict, "A paid order is cancelled by cancelling its store fulfillments, which refunds the buyer")
+	}
+
+	var updated models.Order
+	err = s.orderDao.Transaction(func(tx *gorm.DB) error {
+		updated, err = transitionOrderStatus(tx, s.reservationDao, orderStatusChange{
+			OrderID:   order.ID,
+			To:        request.Status,
+			ChangedBy: &userID,
+			Note:      request.Note,
+		})
+		return err
+	})
+	if err != nil {
+		return orderDto.UpdateOrderStatusResponse{}, errorStatus(err), err
+	}
+
+	log.Infof("order %s moved to %s by user_id=%s", order.ID.String(), updated.Status, userID.String())
+	return orderDto.UpdateOrderStatusResponse{
+		OrderID: updated.ID.String(),
+		Status:  updated.Status,
+	}, fiber.StatusOK, nil
+}
+
+func (s orderService) GetOrderHistory(ctx *fiber.Ctx, request orderDto.GetOrderHistoryRequest) ([]orderDto.OrderStatusHistoryResponse, int, error) {
+	principal, ok := utils.GetPrincipal(ctx)
+	if !ok {
+		return nil, fiber.StatusUnauthorized, fiber.NewError(fiber.StatusUnauthorized, "missing user id")
+	}
+	userID := principal.UserID
+
+	order, err := s.orderDao.FindById(request.OrderID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fiber.StatusNotFound, fiber.NewError(fiber.StatusNotFound, "Order not found")
+		}
+		return nil, fiber.StatusInternalServerError, err
+	}
+
+	if order.BuyerID != userID && !canManageAny(order, principal) {
+		return nil, fiber.StatusForbidden, fiber.NewError(fiber.StatusForbidden, "You are not allowed to view this order")
+	}
+
+	history, err := s.historyDao.FindByOrderId(order.ID.String())
+	if err != nil {
+		return nil, fiber.StatusInternalServerError, err
+	}
+
+	response := []orderDto.OrderStatusHistoryResponse{}
+	for _, entry := range history {
+		var changedBy string
+		if entry.ChangedBy != nil {
+			changedBy = entry.ChangedBy.String()
+		}
+		response = append(response, orderDto.OrderStatusHistoryResponse{
+			FromStatus: entry.FromStatus,
+			ToStatus:   entry.ToStatus,
+			ChangedBy:  changedBy,
+			Note:       entry.Note,
+			ChangedAt:  entry.CreatedAt,
+		})
+	}
+
+	return response, fiber.StatusOK, nil
+}
+
+// canManageAll reports whether the user may manage orders on every store in the order.
+func canManageAll(order models.Order, principal utils.Principal) bool {
+	stores := orderStoreIDs(order)
+	if len(stores) == 0 {
+		return false
+	}
+	for _, storeID := range stores {
+		if !principal.Can(storeID, models.ActionManageOrders) {
+			return false
+		}
+	}
+	return true
+}
+
+// canManageAny reports whether the user may manage orders on at least one store in the order.
+func canManageAny(order models.Order, principal utils.Principal) bool {
+	for _, storeID := range orderStoreIDs(order) {
+		if principal.Can(storeID, models.ActionManageOrders) {
+			return true
+		}
+	}
+	return false
+}
+
+func orderStoreIDs(order models.Order) []uuid.UUID {
+	seen := map[uuid.UUID]bool{}
+	var ids []uuid.UUID
+	for _, item := range order.Items {
+		if !seen[item.StoreID] {
+			seen[item.StoreID] = true
+			ids = append(ids, item.StoreID)
+		}
+	}
+	return ids
+}
+
+type orderStatusChange struct {
+	OrderID   uuid.UUID
+	From      string // when set, the order must still be in this status
+	To        string
+	ChangedBy *uuid.UUID // nil for system changes
+	Note      string
+}
+
+// transitionOrderStatus moves an order to a new status inside tx, rejecting moves the
+// lifecycle does not allow, and records the change in the status history. Stock
+// follows the order: paying commits the checkout holds, cancelling releases them and
+// puts the units back on the shelf (and gives back the coupon use).
+func transitionOrderStatus(tx *gorm.DB, reservationDao stockReservationDao.DataAccess, change orderStatusChange) (models.Order, error) {
+	var order models.Order
+	res := tx.Table(order.TableName()).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ? AND del_flg = ?", change.OrderID, false).
+		First(&order)
+	if res.Error != nil {
+		if errors.Is(res.Error, gorm.ErrRecordNotFound) {
+			return models.Order{}, fiber.NewError(fiber.StatusNotFound, "Order not found")
+		}
+		return models.Order{}, res.Error
+	}
+
+	if change.From != "" && order.Status != change.From {
+		return models.Order{}, fiber.NewError(fiber.StatusConflict, "Order is no longer "+change.From)
+	}
+	if !models.CanTransitionOrder(order.Status, change.To) {
+		return models.Order{}, fiber.NewError(fiber.StatusConflict, "Order cannot move from "+order.Status+" to "+change.To)
+	}
+
+	switch change.To {
+	case models.OrderStatusPaid:
+		if err := reservationDao.WithTx(tx).Commit(order.ID); err != nil {
+			return models.Order{}, err
+		}
+	case models.OrderStatusCancelled:
+		if err := reservationDao.WithTx(tx).Release(order.ID); err != nil {
+			return models.Order{}, err
+		}
+		if err := restockOrderItems(tx, order.ID); err != nil {
+			return models.Order{}, err
+		}
+		if err := releaseCouponRedemption(tx, order.ID); err != nil {
+			return models.Order{}, err
+		}
+		res := tx.Table(models.Fulfillment{}.TableName()).
+			Where("order_id = ? AND status IN ?", order.ID, []string{models.FulfillmentPending, models.FulfillmentProcessing}).
+			Update("status", models.FulfillmentCancelled)
+		if res.Error != nil {
+			return models.Order{}, res.Error
+		}
+	}
+
+	res = tx.Table(order.TableName()).
+		Where("id = ?", order.ID).
+		Update("status", change.To)
+	if res.Error != nil {
+		return models.Order{}, res.Error
+	}
+
+	history := models.OrderStatusHistory{
+		OrderID:    order.ID,
+		FromStatus: order.Status,
+		ToStatus:   change.To,
+		ChangedBy:  change.ChangedBy,
+		Note:       change.Note,
+	}
+	if res := tx.Table(history.TableName()).Create(&history); res.Error != nil {
+		return models.Order{}, res.Error
+	}
+
+	order.Status = change.To
+	return order, nil
+}
+
+// restockOrderItems returns the quantities of an order's items to variant (or product) stock.
+func restockOrderItems(tx *gorm.DB, orderID uuid.UUID) error {
+	var items []models.OrderItem
+	res := tx.Table(models.OrderItem{}.TableName()).
+		Where("order_id = ? AND del_flg = ?", orderID, false).
+		Find(&items)
+	if res.Error != nil {
+		return res.Error
+	}
+
+	for _, item := range items {
+		if err := restockItem(tx, item.ProductID, item.VariantID, item.Quantity); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func restockItem(tx *gorm.DB, productID uuid.UUID, variantID *uuid.UUID, quantity int) error {
+	var res *gorm.DB
+	if variantID != nil {
+		res = tx.Table(models.ProductVariant{}.TableName()).
+			Where("id = ?", *variantID).
+			Update("stock", gorm.Expr("stock + ?", quantity))
+	} else {
+		res = tx.Table(models.Product{}.TableName()).
+			Where("id = ?", productID).
+			Update("stock", gorm.Expr("stock + ?", quantity))
+	}
+	return res.Error
+}
+
+// errorStatus maps an error to the HTTP status it should be reported with.
+func errorStatus(err error) int {
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) {
+		return fiberErr.Code
+	}
+	var rejection CouponRejection
+	if errors.As(err, &rejection) {
+		return fiber.StatusBadRequest
+	}
+	switch {
+	case errors.Is(err, stockReservationDao.ErrInsufficientStock),
+		errors.Is(err, stockReservationDao.ErrReservationExpired),
+		errors.Is(err, errOrderNotPayable):
+		return fiber.StatusConflict
+	}
+	return fiber.StatusInternalServerError
+}
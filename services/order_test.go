@@ -0,0 +1,209 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/abdulmalikraji/e-commerce/db/models"
+	"github.com/abdulmalikraji/e-commerce/dto/orderDto"
+	"github.com/abdulmalikraji/e-commerce/mocks/dao/orderDao"
+	"github.com/abdulmalikraji/e-commerce/mocks/dao/orderStatusHistoryDao"
+	"github.com/abdulmalikraji/e-commerce/mocks/dao/stockReservationDao"
+	"github.com/abdulmalikraji/e-commerce/utils"
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+	"gorm.io/gorm"
+)
+
+var historyMockDao *orderStatusHistoryDao.MockDataAccess
+
+var orders OrderService
+
+func setupOrders(t *testing.T, principal utils.Principal) func() {
+	ct := gomock.NewController(t)
+
+	app := fiber.New()
+	fiberCtx = app.AcquireCtx(&fasthttp.RequestCtx{})
+	utils.SetPrincipal(fiberCtx, principal)
+
+	orderMockDao = orderDao.NewMockDataAccess(ct)
+	historyMockDao = orderStatusHistoryDao.NewMockDataAccess(ct)
+	reservationMockDao = stockReservationDao.NewMockDataAccess(ct)
+
+	orders = NewOrderService(orderMockDao, historyMockDao, reservationMockDao)
+	return func() {
+		orders = nil
+		app.ReleaseCtx(fiberCtx)
+		ct.Finish()
+	}
+}
+
+// storeOrder is an order of one of staffStore's items in status.
+func storeOrder(status string) (models.Order, models.OrderItem) {
+	order := pendingOrder()
+	order.Status = status
+	variantID := uuid.New()
+	item := models.OrderItem{ID: uuid.New(), OrderID: order.ID, StoreID: staffStore.ID, ProductID: uuid.New(), VariantID: &variantID, Quantity: 1}
+	order.Items = []models.OrderItem{item}
+	return order, item
+}
+
+func expectHistory(sqlMock sqlmock.Sqlmock, order models.Order, from, to string, changedBy uuid.UUID, note string) {
+	sqlMock.ExpectQuery(`INSERT INTO "ecom"."order_status_history"`).
+		WithArgs(order.ID, from, to, changedBy, note, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(uuid.New()))
+}
+
+func TestOrderService_Update_Status_Records_History(t *testing.T) {
+	tests := []struct {
+		name string
+		from string
+		to   string
+	}{
+		{name: "paid to shipped", from: models.OrderStatusPaid, to: models.OrderStatusShipped},
+		{name: "paid to fulfilled", from: models.OrderStatusPaid, to: models.OrderStatusFulfilled},
+		{name: "shipped to delivered", from: models.OrderStatusShipped, to: models.OrderStatusDelivered},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			teardown := setupOrders(t, staffOwner)
+			defer teardown()
+
+			order, _ := storeOrder(test.from)
+			tx, sqlMock := mockTx(t)
+			orderMockDao.EXPECT().FindById(order.ID.String()).Return(order, nil)
+			orderMockDao.EXPECT().Transaction(gomock.Any()).DoAndReturn(func(fn func(tx *gorm.DB) error) error {
+				return fn(tx)
+			})
+			expectOrderLock(sqlMock, order, test.from)
+			sqlMock.ExpectExec(`UPDATE "ecom"."orders" SET "status"=\$1`).
+				WithArgs(test.to, order.ID).WillReturnResult(sqlmock.NewResult(0, 1))
+			expectHistory(sqlMock, order, test.from, test.to, staffOwner.UserID, "on its way")
+
+			response, status, err := orders.UpdateOrderStatus(fiberCtx, orderDto.UpdateOrderStatusRequest{
+				OrderID: order.ID.String(),
+				Status:  test.to,
+				Note:    "on its way",
+			})
+
+			assert.NoError(t, err)
+			assert.Equal(t, fiber.StatusOK, status)
+			assert.Equal(t, test.to, response.Status)
+			assert.NoError(t, sqlMock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestOrderService_Update_Status_Blocks_Disallowed_Moves(t *testing.T) {
+	tests := []struct {
+		name   string
+		from   string
+		to     string
+		status int
+		locked bool // whether the move reaches the transition, which turns it down
+	}{
+		{name: "unknown status", from: models.OrderStatusPaid, to: "teleported", status: fiber.StatusBadRequest},
+		{name: "paid by hand", from: models.OrderStatusPending, to: models.OrderStatusPaid, status: fiber.StatusBadRequest},
+		{name: "refunded by hand", from: models.OrderStatusPaid, to: models.OrderStatusRefunded, status: fiber.StatusBadRequest},
+		{name: "paid order cancelled", from: models.OrderStatusPaid, to: models.OrderStatusCancelled, status: fiber.StatusConflict},
+		{name: "shipped order cancelled", from: models.OrderStatusShipped, to: models.OrderStatusCancelled, status: fiber.StatusConflict},
+		{name: "delivered back to shipped", from: models.OrderStatusDelivered, to: models.OrderStatusShipped, status: fiber.StatusConflict, locked: true},
+		{name: "pending shipped", from: models.OrderStatusPending, to: models.OrderStatusShipped, status: fiber.StatusConflict, locked: true},
+		{name: "cancelled is final", from: models.OrderStatusCancelled, to: models.OrderStatusCancelled, status: fiber.StatusConflict, locked: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			teardown := setupOrders(t, staffOwner)
+			defer teardown()
+
+			order, _ := storeOrder(test.from)
+			orderMockDao.EXPECT().FindById(order.ID.String()).Return(order, nil).MaxTimes(1)
+			var sqlMock sqlmock.Sqlmock
+			if test.locked {
+				var tx *gorm.DB
+				tx, sqlMock = mockTx(t)
+				orderMockDao.EXPECT().Transaction(gomock.Any()).DoAndReturn(func(fn func(tx *gorm.DB) error) error {
+					return fn(tx)
+				})
+				expectOrderLock(sqlMock, order, test.from)
+			}
+
+			_, status, err := orders.UpdateOrderStatus(fiberCtx, orderDto.UpdateOrderStatusRequest{
+				OrderID: order.ID.String(),
+				Status:  test.to,
+			})
+
+			assert.Error(t, err)
+			assert.Equal(t, test.status, status)
+			if sqlMock != nil {
+				assert.NoError(t, sqlMock.ExpectationsWereMet())
+			}
+		})
+	}
+}
+
+func TestOrderService_Buyer_Cancels_Unpaid_Order(t *testing.T) {
+	order, item := storeOrder(models.OrderStatusPending)
+	teardown := setupOrders(t, utils.Principal{UserID: order.BuyerID})
+	defer teardown()
+
+	tx, sqlMock := mockTx(t)
+	orderMockDao.EXPECT().FindById(order.ID.String()).Return(order, nil)
+	orderMockDao.EXPECT().Transaction(gomock.Any()).DoAndReturn(func(fn func(tx *gorm.DB) error) error {
+		return fn(tx)
+	})
+	expectOrderLock(sqlMock, order, models.OrderStatusPending)
+	expectCancellation(sqlMock, reservationMockDao, order, item)
+
+	response, status, err := orders.UpdateOrderStatus(fiberCtx, orderDto.UpdateOrderStatusRequest{
+		OrderID: order.ID.String(),
+		Status:  models.OrderStatusCancelled,
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, status)
+	assert.Equal(t, models.OrderStatusCancelled, response.Status)
+	assert.NoError(t, sqlMock.ExpectationsWereMet())
+}
+
+func TestOrderService_Stranger_Cannot_Change_Order(t *testing.T) {
+	teardown := setupOrders(t, utils.Principal{UserID: uuid.New()})
+	defer teardown()
+
+	order, _ := storeOrder(models.OrderStatusPaid)
+	orderMockDao.EXPECT().FindById(order.ID.String()).Return(order, nil)
+
+	_, status, err := orders.UpdateOrderStatus(fiberCtx, orderDto.UpdateOrderStatusRequest{
+		OrderID: order.ID.String(),
+		Status:  models.OrderStatusShipped,
+	})
+
+	assert.Error(t, err)
+	assert.Equal(t, fiber.StatusForbidden, status)
+}
+
+func TestOrderService_History_Lists_Status_Changes(t *testing.T) {
+	order, _ := storeOrder(models.OrderStatusPaid)
+	teardown := setupOrders(t, utils.Principal{UserID: order.BuyerID})
+	defer teardown()
+
+	placed := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	orderMockDao.EXPECT().FindById(order.ID.String()).Return(order, nil)
+	historyMockDao.EXPECT().FindByOrderId(order.ID.String()).Return([]models.OrderStatusHistory{
+		{OrderID: order.ID, ToStatus: models.OrderStatusPending, ChangedBy: &order.BuyerID, Note: "order placed", CreatedAt: placed},
+		{OrderID: order.ID, FromStatus: models.OrderStatusPending, ToStatus: models.OrderStatusPaid, CreatedAt: placed.Add(time.Minute)},
+	}, nil)
+
+	history, status, err := orders.GetOrderHistory(fiberCtx, orderDto.GetOrderHistoryRequest{OrderID: order.ID.String()})
+
+	assert.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, status)
+	assert.Equal(t, []orderDto.OrderStatusHistoryResponse{
+		{ToStatus: models.OrderStatusPending, ChangedBy: order.BuyerID.String(), Note: "order placed", ChangedAt: placed},
+		{FromStatus: models.OrderStatusPending, ToStatus: models.OrderStatusPaid, ChangedAt: placed.Add(time.Minute)},
+	}, history)
+}
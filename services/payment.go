@@ -0,0 +1,449 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/abdulmalikraji/e-commerce/db/dao/orderDao"
+	"github.com/abdulmalikraji/e-commerce/db/dao/paymentDao"
+	"github.com/abdulmalikraji/e-commerce/db/dao/paymentEventDao"
+	"github.com/abdulmalikraji/e-commerce/db/dao/stockReservationDao"
+	"github.com/abdulmalikraji/e-commerce/db/models"
+	"github.com/abdulmalikraji/e-commerce/dto/paymentDto"
+	"github.com/abdulmalikraji/e-commerce/payments"
+	"github.com/abdulmalikraji/e-commerce/utils"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/log"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type PaymentService interface {
+	CreatePayment(ctx *fiber.Ctx, request paymentDto.CreatePaymentRequest) (paymentDto.PaymentResponse, int, error)
+	CapturePayment(ctx *fiber.Ctx, request paymentDto.PaymentActionRequest) (paymentDto.PaymentResponse, int, error)
+	CancelPayment(ctx *fiber.Ctx, request paymentDto.PaymentActionRequest) (paymentDto.PaymentResponse, int, error)
+	GetOrderPayments(ctx *fiber.Ctx, request paymentDto.GetOrderPaymentsRequest) ([]paymentDto.PaymentResponse, int, error)
+	HandleWebhook(ctx *fiber.Ctx, request paymentDto.WebhookRequest) (paymentDto.WebhookResponse, int, error)
+}
+
+type paymentService struct {
+	orderDao       orderDao.DataAccess
+	paymentDao     paymentDao.DataAccess
+	eventDao       paymentEventDao.DataAccess
+	reservationDao stockReservationDao.DataAccess
+	providers      payments.Registry
+}
+
+func NewPaymentService(
+	orderDao orderDao.DataAccess,
+	paymentDao paymentDao.DataAccess,
+	eventDao paymentEventDao.DataAccess,
+	reservationDao stockReservationDao.DataAccess,
+	providers payments.Registry,
+) PaymentService {
+	return paymentService{
+		orderDao:       orderDao,
+		paymentDao:     paymentDao,
+		eventDao:       eventDao,
+		reservationDao: reservationDao,
+		providers:      providers,
+	}
+}
+
+func (s paymentService) CreatePayment(ctx *fiber.Ctx, request paymentDto.CreatePaymentRequest) (paymentDto.PaymentResponse, int, error) {
+	userID, err := utils.GetUserID(ctx)
+	if err != nil {
+		return paymentDto.PaymentResponse{}, fiber.StatusUnauthorized, err
+	}
+
+	if request.Method == "" {
+		return paymentDto.PaymentResponse{}, fiber.StatusBadRequest, fiber.NewError(fiber.StatusBadRequest, "method is required")
+	}
+	provider, err := s.providers.Get(request.Provider)
+	if err != nil {
+		return paymentDto.PaymentResponse{}, fiber.StatusBadRequest, fiber.NewError(fiber.StatusBadRequest, "Unsupported payment provider "+request.Provider)
+	}
+
+	order, err := s.orderDao.FindById(request.OrderID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return paymentDto.PaymentResponse{}, fiber.StatusNotFound, fiber.NewError(fiber.StatusNotFound, "Order not found")
+		}
+		return paymentDto.PaymentResponse{}, fiber.StatusInternalServerError, err
+	}
+	if order.BuyerID != userID {
+		return paymentDto.PaymentResponse{}, fiber.StatusForbidden, fiber.NewError(fiber.StatusForbidden, "You are not allowed to pay for this order")
+	}
+	if order.Status != models.OrderStatusPending {
+		return paymentDto.PaymentResponse{}, fiber.StatusConflict, fiber.NewError(fiber.StatusConflict, "Order is "+order.Status+" and cannot be paid")
+	}
+
+	intent, err := provider.CreateIntent(payments.IntentRequest{
+		OrderID:  order.ID.String(),
+		Amount:   order.TotalAmount,
+		Currency: payments.Currency(),
+		Method:   request.Method,
+	})
+	if err != nil {
+		log.Errorf("create intent failed for order_id=%s provider=%s: %v", order.ID.String(), provider.Name(), err)
+		return paymentDto.PaymentResponse{}, fiber.StatusBadGateway, fiber.NewError(fiber.StatusBadGateway, "Payment provider error: "+err.Error())
+	}
+
+	payment, err := s.paymentDao.Insert(models.Payment{
+		OrderID:        order.ID,
+		Provider:       provider.Name(),
+		Method:         request.Method,
+		Amount:         order.TotalAmount,
+		Status:         models.PaymentInitiated,
+		TransactionRef: intent.TransactionRef,
+	})
+	if err != nil {
+		return paymentDto.PaymentResponse{}, fiber.StatusInternalServerError, err
+	}
+
+	response := toPaymentResponse(payment)
+	response.OrderStatus = order.Status
+	response.ClientSecret = intent.ClientSecret
+	return response, fiber.StatusCreated, nil
+}
+
+func (s paymentService) CapturePayment(ctx *fiber.Ctx, request paymentDto.PaymentActionRequest) (paymentDto.PaymentResponse, int, error) {
+	userID, err := utils.GetUserID(ctx)
+	if err != nil {
+		return paymentDto.PaymentResponse{}, fiber.StatusUnauthorized, err
+	}
+
+	payment, provider, status, err := s.buyerPayment(request.PaymentID, userID)
+	if err != nil {
+		return paymentDto.PaymentResponse{}, status, err
+	}
+
+	result, err := provider.Capture(payment.TransactionRef, payment.Amount)
+	if err != nil {
+		if errors.Is(err, payments.ErrDeclined) {
+			if err := s.paymentDao.UpdateStatus(payment.ID.String(), models.PaymentFailed); err != nil {
+				return paymentDto.PaymentResponse{}, fiber.StatusInternalServerError, err
+			}
+			log.Infof("payment %s declined by %s", payment.ID.String(), provider.Name())
+			return paymentDto.PaymentResponse{}, fiber.StatusPaymentRequired, fiber.NewError(fiber.StatusPaymentRequired, "Payment was declined")
+		}
+		log.Errorf("capture failed for payment_id=%s: %v", payment.ID.String(), err)
+		return paymentDto.PaymentResponse{}, fiber.StatusBadGateway, fiber.NewError(fiber.StatusBadGateway, "Payment provider error: "+err.Error())
+	}
+
+	var order models.Order
+	err = s.orderDao.Transaction(func(tx *gorm.DB) error {
+		order, err = applyCapturedPayment(tx, s.reservationDao, payment.ID, &userID, "payment captured via "+provider.Name())
+		return err
+	})
+	if err != nil {
+		// The money has moved but the order could not take it (e.g. it was cancelled or
+		// its stock holds lapsed meanwhile), so hand it straight back.
+		status, err := s.compensateCapture(payment, provider, result.Amount, err)
+		return paymentDto.PaymentResponse{}, status, err
+	}
+
+	log.Infof("payment %s captured for order %s by user_id=%s", payment.ID.String(), order.ID.String(), userID.String())
+	payment.Status = models.PaymentSuccessful
+	response := toPaymentResponse(payment)
+	response.OrderStatus = order.Status
+	return response, fiber.StatusOK, nil
+}
+
+func (s paymentService) CancelPayment(ctx *fiber.Ctx, request paymentDto.PaymentActionRequest) (paymentDto.PaymentResponse, int, error) {
+	userID, err := utils.GetUserID(ctx)
+	if err != nil {
+		return paymentDto.PaymentResponse{}, fiber.StatusUnauthorized, err
+	}
+
+	payment, provider, status, err := s.buyerPayment(request.PaymentID, userID)
+	if err != nil {
+		return paymentDto.PaymentResponse{}, status, err
+	}
+
+	if _, err := provider.Cancel(payment.TransactionRef); err != nil {
+		log.Errorf("cancel failed for payment_id=%s: %v", payment.ID.String(), err)
+		return paymentDto.PaymentResponse{}, fiber.StatusBadGateway, fiber.NewError(fiber.StatusBadGateway, "Payment provider error: "+err.Error())
+	}
+	if err := s.paymentDao.UpdateStatus(payment.ID.String(), models.PaymentCancelled); err != nil {
+		return paymentDto.PaymentResponse{}, fiber.StatusInternalServerError, err
+	}
+
+	payment.Status = models.PaymentCancelled
+	return toPaymentResponse(payment), fiber.StatusOK, nil
+}
+
+func (s paymentService) GetOrderPayments(ctx *fiber.Ctx, request paymentDto.GetOrderPaymentsRequest) ([]paymentDto.PaymentResponse, int, error) {
+	userID, err := utils.GetUserID(ctx)
+	if err != nil {
+		return nil, fiber.StatusUnauthorized, err
+	}
+
+	order, err := s.orderDao.FindById(request.OrderID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fiber.StatusNotFound, fiber.NewError(fiber.StatusNotFound, "Order not found")
+		}
+		return nil, fiber.StatusInternalServerError, err
+	}
+	if order.BuyerID != userID {
+		return nil, fiber.StatusForbidden, fiber.NewError(fiber.StatusForbidden, "You are not allowed to view this order")
+	}
+
+	found, err := s.paymentDao.FindByOrderId(order.ID.String())
+	if err != nil {
+		return nil, fiber.StatusInternalServerError, err
+	}
+
+	response := []paymentDto.PaymentResponse{}
+	for _, payment := range found {
+		response = append(response, toPaymentResponse(payment))
+	}
+	return response, fiber.StatusOK, nil
+}
+
+// HandleWebhook applies an asynchronous provider notification. The signature is checked
+// against the raw body before anything is parsed, and the event is recorded in the same
+// transaction as the changes it causes, so a redelivered event is acknowledged without
+// being applied twice.
+func (s paymentService) HandleWebhook(ctx *fiber.Ctx, request paymentDto.WebhookRequest) (paymentDto.WebhookResponse, int, error) {
+	provider, err := s.providers.Get(request.Provider)
+	if err != nil {
+		return paymentDto.WebhookResponse{}, fiber.StatusNotFound, fiber.NewError(fiber.StatusNotFound, "Unknown payment provider "+request.Provider)
+	}
+
+	secret := payments.WebhookSecret(provider.Name())
+	if secret == "" {
+		log.Errorf("webhook secret not configured for provider=%s", provider.Name())
+		return paymentDto.WebhookResponse{}, fiber.StatusInternalServerError, fiber.NewError(fiber.StatusInternalServerError, "Webhook is not configured")
+	}
+	if !payments.VerifySignature(secret, request.Payload, request.Signature) {
+		log.Warnf("rejected webhook with invalid signature for provider=%s", provider.Name())
+		return paymentDto.WebhookResponse{}, fiber.StatusUnauthorized, fiber.NewError(fiber.StatusUnauthorized, "Invalid webhook signature")
+	}
+
+	event, err := provider.ParseWebhook(request.Payload)
+	if err != nil {
+		return paymentDto.WebhookResponse{}, fiber.StatusBadRequest, fiber.NewError(fiber.StatusBadRequest, "Invalid webhook payload: "+err.Error())
+	}
+	response := paymentDto.WebhookResponse{EventID: event.ID, Type: event.Type}
+
+	payment, err := s.paymentDao.FindByTransactionRef(provider.Name(), event.TransactionRef)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			// Not recorded, so the provider retries once the payment row exists.
+			return paymentDto.WebhookResponse{}, fiber.StatusNotFound, fiber.NewError(fiber.StatusNotFound, "Payment not found")
+		}
+		return paymentDto.WebhookResponse{}, fiber.StatusInternalServerError, err
+	}
+
+	var refundDue bool
+	err = s.orderDao.Transaction(func(tx *gorm.DB) error {
+		isNew, err := s.eventDao.WithTx(tx).Record(models.PaymentEvent{
+			Provider:       provider.Name(),
+			EventID:        event.ID,
+			Type:           event.Type,
+			TransactionRef: event.TransactionRef,
+			PaymentID:      &payment.ID,
+			Payload:        string(request.Payload),
+		})
+		if err != nil {
+			return err
+		}
+		if !isNew {
+			response.Duplicate = true
+			return nil
+		}
+
+		refundDue, err = applyWebhookEvent(tx, s.reservationDao, payment, event)
+		return err
+	})
+	if err != nil {
+		log.Errorf("webhook event %s for payment_id=%s failed: %v", event.ID, payment.ID.String(), err)
+		return paymentDto.WebhookResponse{}, errorStatus(err), err
+	}
+
+	if refundDue {
+		if status, err := s.compensateCapture(payment, provider, payment.Amount, fiber.NewError(fiber.StatusConflict, "order cannot be paid")); status == fiber.StatusInternalServerError {
+			return paymentDto.WebhookResponse{}, status, err
+		}
+		response.Refunded = true
+	}
+
+	log.Infof("webhook event %s (%s) for payment_id=%s processed, duplicate=%t", event.ID, event.Type, payment.ID.String(), response.Duplicate)
+	return response, fiber.StatusOK, nil
+}
+
+// buyerPayment loads an initiated payment owned by the user together with its provider.
+func (s paymentService) buyerPayment(paymentID string, userID uuid.UUID) (models.Payment, payments.PaymentProvider, int, error) {
+	payment, err := s.paymentDao.FindById(paymentID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.Payment{}, nil, fiber.StatusNotFound, fiber.NewError(fiber.StatusNotFound, "Payment not found")
+		}
+		return models.Payment{}, nil, fiber.StatusInternalServerError, err
+	}
+	if payment.Order.BuyerID != userID {
+		return models.Payment{}, nil, fiber.StatusForbidden, fiber.NewError(fiber.StatusForbidden, "You are not allowed to change this payment")
+	}
+	if payment.Status != models.PaymentInitiated {
+		return models.Payment{}, nil, fiber.StatusConflict, fiber.NewError(fiber.StatusConflict, "Payment is already "+payment.Status)
+	}
+
+	provider, err := s.providers.Get(payment.Provider)
+	if err != nil {
+		return models.Payment{}, nil, fiber.StatusInternalServerError, fiber.NewError(fiber.StatusInternalServerError, "Payment provider "+payment.Provider+" is not configured")
+	}
+	return payment, provider, fiber.StatusOK, nil
+}
+
+// compensateCapture refunds a captured amount that could not be applied to its order and
+// returns the status and error to report for the original failure.
+func (s paymentService) compensateCapture(payment models.Payment, provider payments.PaymentProvider, amount float64, cause error) (int, error) {
+	log.Errorf("payment %s captured but order update failed, refunding: %v", payment.ID.String(), cause)
+
+	if _, err := provider.Refund(payment.TransactionRef, amount); err != nil {
+		log.Errorf("compensating refund failed for payment_id=%s: %v", payment.ID.String(), err)
+		return fiber.StatusInternalServerError, fiber.NewError(fiber.StatusInternalServerError, "Payment was captured but could not be applied to the order")
+	}
+	if err := s.paymentDao.UpdateStatus(payment.ID.String(), models.PaymentRefunded); err != nil {
+		log.Errorf("failed to mark payment_id=%s refunded: %v", payment.ID.String(), err)
+		return fiber.StatusInternalServerError, err
+	}
+	return fiber.StatusConflict, fiber.NewError(fiber.StatusConflict, "Payment could not be applied to the order and was refunded: "+cause.Error())
+}
+
+// errOrderNotPayable is returned by applyCapturedPayment when the order refuses to move to
+// paid, either because it already left pending or because its stock holds lapsed. Only
+// then does captured money have to go back to the buyer.
+var errOrderNotPayable = errors.New("order cannot be paid")
+
+// applyCapturedPayment marks an initiated payment successful and moves its order to paid
+// inside tx, which also commits the order's stock holds. A payment that a racing capture
+// or webhook already marked successful is left as it is.
+func applyCapturedPayment(tx *gorm.DB, reservationDao stockReservationDao.DataAccess, paymentID uuid.UUID, changedBy *uuid.UUID, note string) (models.Order, error) {
+	var payment models.Payment
+	res := tx.Table(payment.TableName()).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ? AND del_flg = ?", paymentID, false).
+		First(&payment)
+	if res.Error != nil {
+		return models.Order{}, res.Error
+	}
+
+	var order models.Order
+	res = tx.Table(order.TableName()).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ? AND del_flg = ?", payment.OrderID, false).
+		First(&order)
+	if res.Error != nil {
+		return models.Order{}, res.Error
+	}
+
+	if payment.Status == models.PaymentSuccessful {
+		return order, nil
+	}
+	if payment.Status != models.PaymentInitiated {
+		return models.Order{}, fiber.NewError(fiber.StatusConflict, "Payment is already "+payment.Status)
+	}
+	if !models.CanTransitionOrder(order.Status, models.OrderStatusPaid) {
+		return models.Order{}, fmt.Errorf("%w: order is %s", errOrderNotPayable, order.Status)
+	}
+
+	res = tx.Table(payment.TableName()).
+		Where("id = ?", payment.ID).
+		Update("status", models.PaymentSuccessful)
+	if res.Error != nil {
+		return models.Order{}, res.Error
+	}
+
+	order, err := transitionOrderStatus(tx, reservationDao, orderStatusChange{
+		OrderID:   payment.OrderID,
+		To:        models.OrderStatusPaid,
+		ChangedBy: changedBy,
+		Note:      note,
+	})
+	if errors.Is(err, stockReservationDao.ErrReservationExpired) || errors.Is(err, stockReservationDao.ErrInsufficientStock) {
+		return models.Order{}, fmt.Errorf("%w: %w", errOrderNotPayable, err)
+	}
+	return order, err
+}
+
+// applyWebhookEvent moves the payment (and its order) to the state the event reports.
+// Events that arrive after the payment has already left that state are ignored. It
+// reports whether money was captured for an order that can no longer take it and so has
+// to be refunded once the transaction commits.
+func applyWebhookEvent(tx *gorm.DB, reservationDao stockReservationDao.DataAccess, payment models.Payment, event payments.WebhookEvent) (bool, error) {
+	note := event.Type + " webhook " + event.ID
+
+	switch event.Type {
+	case payments.EventPaymentSucceeded:
+		if payment.Status != models.PaymentInitiated {
+			return false, nil
+		}
+		// A savepoint keeps the event recorded even when the order rejects the payment.
+		err := tx.Transaction(func(inner *gorm.DB) error {
+			_, err := applyCapturedPayment(inner, reservationDao, payment.ID, nil, note)
+			return err
+		})
+		if err != nil {
+			if errors.Is(err, errOrderNotPayable) {
+				log.Warnf("payment %s succeeded but order %s cannot be paid: %v", payment.ID.String(), payment.OrderID.String(), err)
+				return true, nil
+			}
+			return false, err
+		}
+	case payments.EventPaymentFailed:
+		return false, setPaymentStatus(tx, payment.ID, models.PaymentInitiated, models.PaymentFailed)
+	case payments.EventPaymentCancelled:
+		return false, setPaymentStatus(tx, payment.ID, models.PaymentInitiated, models.PaymentCancelled)
+	case payments.EventPaymentRefunded:
+		// Partial refunds are only recorded; a full refund closes out the payment and order.
+		if event.Amount > 0 && event.Amount+0.005 < payment.Amount {
+			return false, nil
+		}
+		if err := setPaymentStatus(tx, payment.ID, models.PaymentSuccessful, models.PaymentRefunded); err != nil {
+			return false, err
+		}
+		var order models.Order
+		if res := tx.Table(order.TableName()).Where("id = ?", payment.OrderID).First(&order); res.Error != nil {
+			return false, res.Error
+		}
+		if models.CanTransitionOrder(order.Status, models.OrderStatusRefunded) {
+			if _, err := transitionOrderStatus(tx, reservationDao, orderStatusChange{
+				OrderID: order.ID,
+				To:      models.OrderStatusRefunded,
+				Note:    note,
+			}); err != nil {
+				return false, err
+			}
+		}
+	default:
+		log.Infof("ignoring webhook event %s of type %s", event.ID, event.Type)
+	}
+	return false, nil
+}
+
+// setPaymentStatus moves a payment from one status to another; payments in any other
+// status are left as they are.
+func setPaymentStatus(tx *gorm.DB, paymentID uuid.UUID, from, to string) error {
+	res := tx.Table(models.Payment{}.TableName()).
+		Where("id = ? AND status = ?", paymentID, from).
+		Update("status", to)
+	return res.Error
+}
+
+func toPaymentResponse(payment models.Payment) paymentDto.PaymentResponse {
+	return paymentDto.PaymentResponse{
+		ID:             payment.ID.String(),
+		OrderID:        payment.OrderID.String(),
+		OrderStatus:    payment.Order.Status,
+		Provider:       payment.Provider,
+		Method:         payment.Method,
+		Amount:         payment.Amount,
+		Status:         payment.Status,
+		TransactionRef: payment.TransactionRef,
+		CreatedAt:      payment.CreatedAt,
+	}
+}
@@ -0,0 +1,319 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/abdulmalikraji/e-commerce/db/models"
+	"github.com/abdulmalikraji/e-commerce/dto/paymentDto"
+	"github.com/abdulmalikraji/e-commerce/mocks/dao/orderDao"
+	"github.com/abdulmalikraji/e-commerce/mocks/dao/paymentDao"
+	"github.com/abdulmalikraji/e-commerce/mocks/dao/paymentEventDao"
+	"github.com/abdulmalikraji/e-commerce/payments"
+	"github.com/abdulmalikraji/e-commerce/utils"
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+var orderMockDao *orderDao.MockDataAccess
+var paymentMockDao *paymentDao.MockDataAccess
+var paymentEventMockDao *paymentEventDao.MockDataAccess
+var fakeProvider *payments.FakeProvider
+
+var ps PaymentService
+
+var buyerID = uuid.New()
+
+func setupPayment(t *testing.T) func() {
+	ct := gomock.NewController(t)
+
+	app := fiber.New()
+	fiberCtx = app.AcquireCtx(&fasthttp.RequestCtx{})
+	utils.SetPrincipal(fiberCtx, utils.Principal{UserID: buyerID})
+
+	orderMockDao = orderDao.NewMockDataAccess(ct)
+	paymentMockDao = paymentDao.NewMockDataAccess(ct)
+	paymentEventMockDao = paymentEventDao.NewMockDataAccess(ct)
+	fakeProvider = payments.NewFakeProvider()
+
+	ps = NewPaymentService(orderMockDao, paymentMockDao, paymentEventMockDao, nil, payments.NewRegistry(fakeProvider))
+	return func() {
+		ps = nil
+		app.ReleaseCtx(fiberCtx)
+		ct.Finish()
+	}
+}
+
+// mockTx opens a transaction on a sqlmock-backed connection for code that queries tx directly.
+func mockTx(t *testing.T) (*gorm.DB, sqlmock.Sqlmock) {
+	conn, sqlMock, err := sqlmock.New()
+	assert.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: conn}), &gorm.Config{SkipDefaultTransaction: true})
+	assert.NoError(t, err)
+
+	sqlMock.ExpectBegin()
+	return db.Begin(), sqlMock
+}
+
+func pendingOrder() models.Order {
+	return models.Order{
+		ID:          uuid.New(),
+		BuyerID:     buyerID,
+		Status:      models.OrderStatusPending,
+		TotalAmount: 42.5,
+	}
+}
+
+// initiatedPayment creates an intent on the fake provider and the matching payment row.
+func initiatedPayment(t *testing.T, order models.Order, method string) models.Payment {
+	intent, err := fakeProvider.CreateIntent(payments.IntentRequest{OrderID: order.ID.String(), Amount: order.TotalAmount, Method: method})
+	assert.NoError(t, err)
+	return models.Payment{
+		ID:             uuid.New(),
+		OrderID:        order.ID,
+		Provider:       payments.FakeProviderName,
+		Method:         method,
+		Amount:         order.TotalAmount,
+		Status:         models.PaymentInitiated,
+		TransactionRef: intent.TransactionRef,
+		Order:          order,
+	}
+}
+
+func TestPaymentService_Create_Payment_Successfully(t *testing.T) {
+	teardown := setupPayment(t)
+	defer teardown()
+
+	order := pendingOrder()
+	orderMockDao.EXPECT().FindById(order.ID.String()).Return(order, nil)
+	paymentMockDao.EXPECT().Insert(gomock.Any()).DoAndReturn(func(payment models.Payment) (models.Payment, error) {
+		assert.Equal(t, order.ID, payment.OrderID)
+		assert.Equal(t, models.PaymentInitiated, payment.Status)
+		assert.Equal(t, 42.5, payment.Amount)
+		assert.Equal(t, "fake_txn_000001", payment.TransactionRef)
+		payment.ID = uuid.New()
+		return payment, nil
+	})
+
+	response, status, err := ps.CreatePayment(fiberCtx, paymentDto.CreatePaymentRequest{
+		OrderID:  order.ID.String(),
+		Provider: "fake",
+		Method:   "card",
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, fiber.StatusCreated, status)
+	assert.Equal(t, models.PaymentInitiated, response.Status)
+	assert.Equal(t, "fake_txn_000001_secret", response.ClientSecret)
+}
+
+func TestPaymentService_Create_Payment_Rejects_Non_Pending_Order(t *testing.T) {
+	teardown := setupPayment(t)
+	defer teardown()
+
+	order := pendingOrder()
+	order.Status = models.OrderStatusCancelled
+	orderMockDao.EXPECT().FindById(order.ID.String()).Return(order, nil)
+
+	_, status, err := ps.CreatePayment(fiberCtx, paymentDto.CreatePaymentRequest{
+		OrderID:  order.ID.String(),
+		Provider: "fake",
+		Method:   "card",
+	})
+
+	assert.Error(t, err)
+	assert.Equal(t, fiber.StatusConflict, status)
+}
+
+func TestPaymentService_Create_Payment_Unknown_Provider(t *testing.T) {
+	teardown := setupPayment(t)
+	defer teardown()
+
+	_, status, err := ps.CreatePayment(fiberCtx, paymentDto.CreatePaymentRequest{
+		OrderID:  uuid.NewString(),
+		Provider: "stripe",
+		Method:   "card",
+	})
+
+	assert.Error(t, err)
+	assert.Equal(t, fiber.StatusBadRequest, status)
+}
+
+func TestPaymentService_Capture_Declined_Marks_Payment_Failed(t *testing.T) {
+	teardown := setupPayment(t)
+	defer teardown()
+
+	payment := initiatedPayment(t, pendingOrder(), payments.FakeDeclinedMethod)
+	paymentMockDao.EXPECT().FindById(payment.ID.String()).Return(payment, nil)
+	paymentMockDao.EXPECT().UpdateStatus(payment.ID.String(), models.PaymentFailed).Return(nil)
+
+	_, status, err := ps.CapturePayment(fiberCtx, paymentDto.PaymentActionRequest{PaymentID: payment.ID.String()})
+
+	assert.Error(t, err)
+	assert.Equal(t, fiber.StatusPaymentRequired, status)
+}
+
+func TestPaymentService_Capture_Refunds_When_Order_Cannot_Be_Paid(t *testing.T) {
+	teardown := setupPayment(t)
+	defer teardown()
+
+	payment := initiatedPayment(t, pendingOrder(), "card")
+	paymentMockDao.EXPECT().FindById(payment.ID.String()).Return(payment, nil)
+	orderMockDao.EXPECT().Transaction(gomock.Any()).Return(errors.New("order was cancelled"))
+	paymentMockDao.EXPECT().UpdateStatus(payment.ID.String(), models.PaymentRefunded).Return(nil)
+
+	_, status, err := ps.CapturePayment(fiberCtx, paymentDto.PaymentActionRequest{PaymentID: payment.ID.String()})
+
+	assert.Error(t, err)
+	assert.Equal(t, fiber.StatusConflict, status)
+
+	// The captured amount went back in full, so nothing is left to refund.
+	_, err = fakeProvider.Refund(payment.TransactionRef, 0.01)
+	assert.ErrorIs(t, err, payments.ErrInvalidAmount)
+}
+
+func TestPaymentService_Capture_Successfully(t *testing.T) {
+	teardown := setupPayment(t)
+	defer teardown()
+
+	payment := initiatedPayment(t, pendingOrder(), "card")
+	paymentMockDao.EXPECT().FindById(payment.ID.String()).Return(payment, nil)
+	orderMockDao.EXPECT().Transaction(gomock.Any()).Return(nil)
+
+	response, status, err := ps.CapturePayment(fiberCtx, paymentDto.PaymentActionRequest{PaymentID: payment.ID.String()})
+
+	assert.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, status)
+	assert.Equal(t, models.PaymentSuccessful, response.Status)
+}
+
+func TestPaymentService_Cancel_Rejects_Other_Buyers(t *testing.T) {
+	teardown := setupPayment(t)
+	defer teardown()
+
+	order := pendingOrder()
+	order.BuyerID = uuid.New()
+	payment := initiatedPayment(t, order, "card")
+	paymentMockDao.EXPECT().FindById(payment.ID.String()).Return(payment, nil)
+
+	_, status, err := ps.CancelPayment(fiberCtx, paymentDto.PaymentActionRequest{PaymentID: payment.ID.String()})
+
+	assert.Error(t, err)
+	assert.Equal(t, fiber.StatusForbidden, status)
+}
+
+func TestPaymentService_Webhook_Rejects_Invalid_Signature(t *testing.T) {
+	teardown := setupPayment(t)
+	defer teardown()
+	t.Setenv("PAYMENT_WEBHOOK_SECRET", "whsec")
+
+	payload := []byte(`{"id":"evt_1","type":"payment.succeeded","transaction_ref":"fake_txn_000001"}`)
+	_, status, err := ps.HandleWebhook(fiberCtx, paymentDto.WebhookRequest{
+		Provider:  "fake",
+		Signature: payments.Sign("wrong", payload),
+		Payload:   payload,
+	})
+
+	assert.Error(t, err)
+	assert.Equal(t, fiber.StatusUnauthorized, status)
+}
+
+func TestPaymentService_Webhook_Redelivered_Event_Is_A_No_Op(t *testing.T) {
+	teardown := setupPayment(t)
+	defer teardown()
+	t.Setenv("PAYMENT_WEBHOOK_SECRET_FAKE", "whsec")
+
+	payment := initiatedPayment(t, pendingOrder(), "card")
+	payload := []byte(`{"id":"evt_1","type":"payment.succeeded","transaction_ref":"` + payment.TransactionRef + `"}`)
+
+	paymentMockDao.EXPECT().FindByTransactionRef(payments.FakeProviderName, payment.TransactionRef).Return(payment, nil)
+	orderMockDao.EXPECT().Transaction(gomock.Any()).DoAndReturn(func(fn func(tx *gorm.DB) error) error {
+		return fn(nil)
+	})
+	paymentEventMockDao.EXPECT().WithTx(gomock.Any()).Return(paymentEventMockDao)
+	paymentEventMockDao.EXPECT().Record(gomock.Any()).DoAndReturn(func(event models.PaymentEvent) (bool, error) {
+		assert.Equal(t, "evt_1", event.EventID)
+		assert.Equal(t, &payment.ID, event.PaymentID)
+		return false, nil
+	})
+
+	response, status, err := ps.HandleWebhook(fiberCtx, paymentDto.WebhookRequest{
+		Provider:  "fake",
+		Signature: payments.Sign("whsec", payload),
+		Payload:   payload,
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, status)
+	assert.True(t, response.Duplicate)
+}
+
+func TestPaymentService_Webhook_Unknown_Payment_Is_Not_Recorded(t *testing.T) {
+	teardown := setupPayment(t)
+	defer teardown()
+	t.Setenv("PAYMENT_WEBHOOK_SECRET", "whsec")
+
+	payload := []byte(`{"id":"evt_2","type":"payment.failed","transaction_ref":"fake_txn_999999"}`)
+	paymentMockDao.EXPECT().FindByTransactionRef(payments.FakeProviderName, "fake_txn_999999").Return(models.Payment{}, gorm.ErrRecordNotFound)
+
+	_, status, err := ps.HandleWebhook(fiberCtx, paymentDto.WebhookRequest{
+		Provider:  "fake",
+		Signature: "sha256=" + payments.Sign("whsec", payload),
+		Payload:   payload,
+	})
+
+	assert.Error(t, err)
+	assert.Equal(t, fiber.StatusNotFound, status)
+}
+
+func TestPaymentService_Webhook_Racing_Capture_Is_Not_Refunded(t *testing.T) {
+	teardown := setupPayment(t)
+	defer teardown()
+	t.Setenv("PAYMENT_WEBHOOK_SECRET_FAKE", "whsec")
+
+	order := pendingOrder()
+	payment := initiatedPayment(t, order, "card")
+	_, err := fakeProvider.Capture(payment.TransactionRef, payment.Amount)
+	assert.NoError(t, err)
+	payload := []byte(`{"id":"evt_3","type":"payment.succeeded","transaction_ref":"` + payment.TransactionRef + `"}`)
+
+	// The webhook loaded the payment while it was still initiated, but CapturePayment
+	// committed before the webhook took the row lock.
+	tx, sqlMock := mockTx(t)
+	sqlMock.ExpectExec("SAVEPOINT").WillReturnResult(sqlmock.NewResult(0, 0))
+	sqlMock.ExpectQuery(`FROM "ecom"."payments" .* FOR UPDATE`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "order_id", "amount", "status"}).
+			AddRow(payment.ID, order.ID, payment.Amount, models.PaymentSuccessful))
+	sqlMock.ExpectQuery(`FROM "ecom"."orders" .* FOR UPDATE`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "status"}).AddRow(order.ID, models.OrderStatusPaid))
+
+	paymentMockDao.EXPECT().FindByTransactionRef(payments.FakeProviderName, payment.TransactionRef).Return(payment, nil)
+	orderMockDao.EXPECT().Transaction(gomock.Any()).DoAndReturn(func(fn func(tx *gorm.DB) error) error {
+		return fn(tx)
+	})
+	paymentEventMockDao.EXPECT().WithTx(gomock.Any()).Return(paymentEventMockDao)
+	paymentEventMockDao.EXPECT().Record(gomock.Any()).Return(true, nil)
+
+	response, status, err := ps.HandleWebhook(fiberCtx, paymentDto.WebhookRequest{
+		Provider:  "fake",
+		Signature: payments.Sign("whsec", payload),
+		Payload:   payload,
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, status)
+	assert.False(t, response.Refunded)
+	assert.NoError(t, sqlMock.ExpectationsWereMet())
+
+	// Nothing was handed back, so the full captured amount is still refundable.
+	_, err = fakeProvider.Refund(payment.TransactionRef, payment.Amount)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,867 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/abdulmalikraji/e-commerce/db/dao/categoryDao"
+	"github.com/abdulmalikraji/e-commerce/db/dao/productDao"
+	productvariantdao "github.com/abdulmalikraji/e-commerce/db/dao/productVariantDao"
+	"github.com/abdulmalikraji/e-commerce/db/dao/tagDao"
+	"github.com/abdulmalikraji/e-commerce/db/models"
+	"github.com/abdulmalikraji/e-commerce/dto/productDto"
+	"github.com/abdulmalikraji/e-commerce/utils"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/log"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type ProductService interface {
+	ListProducts(ctx *fiber.Ctx, filter productDto.ProductFilter) (productDto.ListProductsResponse, int, error)
+	CreateProduct(ctx *fiber.Ctx, request productDto.CreateProductRequest) (productDto.ProductResponse, int, error)
+	GetStoreProduct(ctx *fiber.Ctx, request productDto.GetStoreProductRequest) (productDto.ProductResponse, int, error)
+	UpdateProduct(ctx *fiber.Ctx, request productDto.UpdateProductRequest) (productDto.ProductResponse, int, error)
+	DeleteProduct(ctx *fiber.Ctx, request productDto.DeleteProductRequest) (int, error)
+	GenerateVariants(ctx *fiber.Ctx, request productDto.GenerateVariantsRequest) (productDto.VariantMatrixResponse, int, error)
+}
+
+type productService struct {
+	productDao productDao.DataAccess
+	variantDao productvariantdao.DataAccess
+	classifier classifier
+}
+
+func NewProductService(
+	productDao productDao.DataAccess,
+	variantDao productvariantdao.DataAccess,
+	categoryDao categoryDao.DataAccess,
+	tagDao tagDao.DataAccess,
+) ProductService {
+	return productService{
+		productDao: productDao,
+		variantDao: variantDao,
+		classifier: classifier{categoryDao: categoryDao, tagDao: tagDao},
+	}
+}
+
+// ListProducts returns one page of the public catalog.
+func (s productService) ListProducts(ctx *fiber.Ctx, filter productDto.ProductFilter) (productDto.ListProductsResponse, int, error) {
+	// Accept ?subcategory_ids=a,b as well as repeated parameters.
+	subCatIDs := []string{}
+	for _, ids := range filter.SubCatIDs {
+		for _, id := range strings.Split(ids, ",") {
+			if id = strings.TrimSpace(id); id != "" {
+				subCatIDs = append(subCatIDs, id)
+			}
+		}
+	}
+	filter.SubCatIDs = subCatIDs
+	if err := productDto.ValidateProductFilter(filter); err != nil {
+		return productDto.ListProductsResponse{}, fiber.StatusBadRequest, fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	if filter.Page < 1 {
+		filter.Page = 1
+	}
+	if filter.PageSize == 0 {
+		filter.PageSize = productDto.DefaultPageSize
+	}
+	if filter.Sort == "" {
+		filter.Sort = productDto.SortNewest
+	}
+
+	found, total, err := s.productDao.FindByFilter(filter)
+	if err != nil {
+		return productDto.ListProductsResponse{}, fiber.StatusInternalServerError, err
+	}
+
+	response := productDto.ListProductsResponse{
+		Products: []productDto.ProductResponse{},
+		Total:    total,
+		Page:     filter.Page,
+		PageSize: filter.PageSize,
+	}
+	for _, product := range found {
+		response.Products = append(response.Products, toProductResponse(product))
+	}
+	return response, fiber.StatusOK, nil
+}
+
+// CreateProduct adds a product to the store, together with its options, variants, images
+// and tags.
+func (s productService) CreateProduct(ctx *fiber.Ctx, request productDto.CreateProductRequest) (productDto.ProductResponse, int, error) {
+	userID, err := utils.GetUserID(ctx)
+	if err != nil {
+		return productDto.ProductResponse{}, fiber.StatusUnauthorized, err
+	}
+	product, status, err := s.createProduct(request, userID)
+	if err != nil {
+		return productDto.ProductResponse{}, status, err
+	}
+	return toProductResponse(product), status, nil
+}
+
+// createProduct is CreateProduct for a known user, shared with the product import.
+func (s productService) createProduct(request productDto.CreateProductRequest, userID uuid.UUID) (models.Product, int, error) {
+	storeID, err := uuid.Parse(request.StoreID)
+	if err != nil {
+		return models.Product{}, fiber.StatusBadRequest, fiber.NewError(fiber.StatusBadRequest, "invalid store id format")
+	}
+	if err := productDto.ValidateCreateProduct(request); err != nil {
+		return models.Product{}, fiber.StatusBadRequest, fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	category, subCategories, status, err := s.classifier.categories(request.CategoryID, request.SubCategoryIDs)
+	if err != nil {
+		return models.Product{}, status, err
+	}
+	barcode, status, err := s.checkBarcode(request.Barcode, "")
+	if err != nil {
+		return models.Product{}, status, err
+	}
+	options := productOptions(request.Options)
+	if status, err := checkVariantOptions(options, request.Variants); err != nil {
+		return models.Product{}, status, err
+	}
+	if status, err := s.checkSKUs(request.Variants, uuid.Nil); err != nil {
+		return models.Product{}, status, err
+	}
+	if status, err := s.checkVariantBarcodes(request.Variants, uuid.Nil); err != nil {
+		return models.Product{}, status, err
+	}
+	tags, status, err := s.classifier.tags(request.Tags, userID)
+	if err != nil {
+		return models.Product{}, status, err
+	}
+
+	product := models.Product{
+		StoreID:       storeID,
+		CategoryID:    category.ID,
+		Name:          strings.TrimSpace(request.Name),
+		Description:   request.Description,
+		Price:         request.Price,
+		Stock:         request.Stock,
+		HasVariants:   len(request.Variants) > 0,
+		IsDiscounted:  request.IsDiscounted,
+		DiscountPct:   request.DiscountPct,
+		Barcode:       barcode,
+		CreatedBy:     &userID,
+		UpdatedBy:     &userID,
+		Images:        productImages(request.Images),
+		Tags:          tags,
+		SubCategories: subCategories,
+	}
+
+	err = s.productDao.Transaction(func(tx *gorm.DB) error {
+		products := s.productDao.WithTx(tx)
+		product, err = products.Insert(product)
+		if err != nil {
+			return err
+		}
+		product.Tags, product.SubCategories = tags, subCategories
+		if err := products.SetClassification(product); err != nil {
+			return err
+		}
+		// Variants link to the option values, so the options are saved first.
+		if len(options) > 0 {
+			if product.Options, err = products.SaveOptions(product.ID, options); err != nil {
+				return err
+			}
+		}
+		variants := s.variantDao.WithTx(tx)
+		for _, input := range request.Variants {
+			variant, err := newVariant(input, product, userID)
+			if err != nil {
+				return err
+			}
+			if variant, err = variants.Insert(variant); err != nil {
+				return err
+			}
+			product.Variants = append(product.Variants, variant)
+		}
+		return nil
+	})
+	if err != nil {
+		return models.Product{}, fiber.StatusInternalServerError, err
+	}
+
+	log.Infof("user_id=%s created product %s in store %s", userID.String(), product.ID.String(), storeID.String())
+	return product, fiber.StatusCreated, nil
+}
+
+func (s productService) GetStoreProduct(ctx *fiber.Ctx, request productDto.GetStoreProductRequest) (productDto.ProductResponse, int, error) {
+	product, status, err := s.findProduct(request.ProductID, request.StoreID)
+	if err != nil {
+		return productDto.ProductResponse{}, status, err
+	}
+	return toProductResponse(product), fiber.StatusOK, nil
+}
+
+// UpdateProduct changes a product. Options, variants, images, tags and subcategories in
+// the request replace the current ones in the same transaction as the product itself.
+func (s productService) UpdateProduct(ctx *fiber.Ctx, request productDto.UpdateProductRequest) (productDto.ProductResponse, int, error) {
+	userID, err := utils.GetUserID(ctx)
+	if err != nil {
+		return productDto.ProductResponse{}, fiber.StatusUnauthorized, err
+	}
+	product, status, err := s.updateProduct(request, userID)
+	if err != nil {
+		return productDto.ProductResponse{}, status, err
+	}
+	return toProductResponse(product), status, nil
+}
+
+// updateProduct is UpdateProduct for a known user, shared with the product import.
+func (s productService) updateProduct(request productDto.UpdateProductRequest, userID uuid.UUID) (models.Product, int, error) {
+	if err := productDto.ValidateUpdateProduct(request); err != nil {
+		return models.Product{}, fiber.StatusBadRequest, fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	product, status, err := s.findProduct(request.ProductID, request.StoreID)
+	if err != nil {
+		return models.Product{}, status, err
+	}
+
+	if request.Name != nil {
+		product.Name = strings.TrimSpace(*request.Name)
+	}
+	if request.Description != nil {
+		product.Description = *request.Description
+	}
+	if request.Price != nil {
+		product.Price = *request.Price
+	}
+	if request.Stock != nil {
+		product.Stock = *request.Stock
+	}
+	if request.IsDiscounted != nil {
+		product.IsDiscounted = *request.IsDiscounted
+	}
+	if request.DiscountPct != nil {
+		product.DiscountPct = *request.DiscountPct
+	}
+	if request.Barcode != nil {
+		product.Barcode, status, err = s.checkBarcode(*request.Barcode, product.ID.String())
+		if err != nil {
+			return models.Product{}, status, err
+		}
+	}
+
+	// Subcategories must sit under the main category, so both are checked together.
+	categoryID := product.CategoryID.String()
+	if request.CategoryID != nil {
+		categoryID = *request.CategoryID
+	}
+	if request.CategoryID != nil || request.SubCategoryIDs != nil {
+		subCategoryIDs := make([]string, 0, len(product.SubCategories))
+		for _, subCategory := range product.SubCategories {
+			subCategoryIDs = append(subCategoryIDs, subCategory.ID.String())
+		}
+		if request.SubCategoryIDs != nil {
+			subCategoryIDs = *request.SubCategoryIDs
+		}
+		category, subCategories, status, err := s.classifier.categories(categoryID, subCategoryIDs)
+		if err != nil {
+			return models.Product{}, status, err
+		}
+		product.CategoryID, product.SubCategories = category.ID, subCategories
+	}
+	if request.Tags != nil {
+		product.Tags, status, err = s.classifier.tags(*request.Tags, userID)
+		if err != nil {
+			return models.Product{}, status, err
+		}
+	}
+
+	existing := map[uuid.UUID]models.ProductVariant{}
+	for _, variant := range product.Variants {
+		existing[variant.ID] = variant
+	}
+
+	// Changed options must still fit every variant: the ones sent, or else the current ones.
+	options := product.Options
+	if request.Options != nil {
+		options = productOptions(*request.Options)
+	}
+	if request.Options != nil || request.Variants != nil {
+		var inputs []productDto.VariantInput
+		if request.Variants != nil {
+			inputs = *request.Variants
+		} else {
+			for _, variant := range product.Variants {
+				inputs = append(inputs, productDto.VariantInput{SKU: variant.SKU, Options: variantOptions(product.Options, variant)})
+			}
+		}
+		if status, err := checkVariantOptions(options, inputs); err != nil {
+			return models.Product{}, status, err
+		}
+	}
+	if request.Variants != nil {
+		for _, input := range *request.Variants {
+			if input.ID == "" {
+				continue
+			}
+			if _, ok := existing[uuid.MustParse(input.ID)]; !ok {
+				return models.Product{}, fiber.StatusBadRequest, fiber.NewError(fiber.StatusBadRequest, "variant "+input.ID+" does not belong to this product")
+			}
+		}
+		if status, err := s.checkSKUs(*request.Variants, product.ID); err != nil {
+			return models.Product{}, status, err
+		}
+		if status, err := s.checkVariantBarcodes(*request.Variants, product.ID); err != nil {
+			return models.Product{}, status, err
+		}
+		product.HasVariants = len(*request.Variants) > 0
+	}
+	product.UpdatedBy = &userID
+
+	err = s.productDao.Transaction(func(tx *gorm.DB) error {
+		products := s.productDao.WithTx(tx)
+		if err := products.UpdateDetails(product); err != nil {
+			return err
+		}
+		if request.Images != nil {
+			if err := products.ReplaceImages(product.ID, productImages(*request.Images)); err != nil {
+				return err
+			}
+		}
+		if request.Options != nil {
+			if product.Options, err = products.SaveOptions(product.ID, options); err != nil {
+				return err
+			}
+		}
+		if request.Variants != nil {
+			if err := saveVariants(s.variantDao.WithTx(tx), product, existing, *request.Variants, userID); err != nil {
+				return err
+			}
+		}
+		// Also refreshes the search document for the new name and description.
+		return products.SetClassification(product)
+	})
+	if err != nil {
+		return models.Product{}, fiber.StatusInternalServerError, err
+	}
+
+	return s.findProduct(request.ProductID, request.StoreID)
+}
+
+// DeleteProduct soft-deletes a product; it disappears from the catalog and carts.
+func (s productService) DeleteProduct(ctx *fiber.Ctx, request productDto.DeleteProductRequest) (int, error) {
+	userID, err := utils.GetUserID(ctx)
+	if err != nil {
+		return fiber.StatusUnauthorized, err
+	}
+	product, status, err := s.findProduct(request.ProductID, request.StoreID)
+	if err != nil {
+		return status, err
+	}
+
+	if err := s.productDao.SoftDelete(product.ID.String()); err != nil {
+		return fiber.StatusInternalServerError, err
+	}
+
+	log.Infof("user_id=%s deleted product %s", userID.String(), product.ID.String())
+	return fiber.StatusOK, nil
+}
+
+// GenerateVariants builds the product's variant matrix: every combination of its option
+// values, with the variant that already covers it or a suggested SKU checked against
+// the SKUs in use. With Create, the missing combinations are added as variants; a
+// single SKU conflict fails the whole request.
+func (s productService) GenerateVariants(ctx *fiber.Ctx, request productDto.GenerateVariantsRequest) (productDto.VariantMatrixResponse, int, error) {
+	userID, err := utils.GetUserID(ctx)
+	if err != nil {
+		return productDto.VariantMatrixResponse{}, fiber.StatusUnauthorized, err
+	}
+	if err := productDto.ValidateGenerateVariants(request); err != nil {
+		return productDto.VariantMatrixResponse{}, fiber.StatusBadRequest, fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	product, status, err := s.findProduct(request.ProductID, request.StoreID)
+	if err != nil {
+		return productDto.VariantMatrixResponse{}, status, err
+	}
+	if len(product.Options) == 0 {
+		return productDto.VariantMatrixResponse{}, fiber.StatusBadRequest, fiber.NewError(fiber.StatusBadRequest, "The product has no options")
+	}
+
+	combinations := optionCombinations(product.Options)
+	if len(combinations) > productDto.MaxVariants {
+		return productDto.VariantMatrixResponse{}, fiber.StatusBadRequest, fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("The options make %d combinations; a product can have at most %d variants", len(combinations), productDto.MaxVariants))
+	}
+
+	covered := map[string]models.ProductVariant{}
+	for _, variant := range product.Variants {
+		covered[combinationKey(product.Options, variantOptions(product.Options, variant))] = variant
+	}
+	prefix := strings.TrimSpace(request.SKUPrefix)
+	if prefix == "" {
+		prefix = skuPart(product.Name)
+		if len(prefix) > 16 {
+			prefix = strings.TrimRight(prefix[:16], "-")
+		}
+	}
+
+	response := productDto.VariantMatrixResponse{Combinations: []productDto.VariantCombination{}}
+	suggested := map[string]int{}
+	for _, values := range combinations {
+		combination := productDto.VariantCombination{Options: map[string]string{}}
+		parts := []string{prefix}
+		for i, value := range values {
+			combination.Options[product.Options[i].Name] = value.Value
+			parts = append(parts, skuPart(value.Value))
+		}
+		if variant, ok := covered[combinationKey(product.Options, combination.Options)]; ok {
+			combination.SKU, combination.VariantID = variant.SKU, variant.ID.String()
+		} else {
+			combination.SKU = strings.Join(parts, "-")
+			suggested[combination.SKU]++
+		}
+		response.Combinations = append(response.Combinations, combination)
+	}
+
+	skus := make([]string, 0, len(suggested))
+	for sku := range suggested {
+		skus = append(skus, sku)
+	}
+	used, err := s.variantDao.FindBySKUs(skus)
+	if err != nil {
+		return productDto.VariantMatrixResponse{}, fiber.StatusInternalServerError, err
+	}
+	inUse := map[string]bool{}
+	for _, variant := range used {
+		inUse[variant.SKU] = true
+	}
+	conflicts := 0
+	for i, combination := range response.Combinations {
+		switch {
+		case combination.VariantID != "":
+			continue
+		case len(combination.SKU) > 64:
+			response.Combinations[i].Conflict = "SKU is longer than 64 characters"
+		case inUse[combination.SKU]:
+			response.Combinations[i].Conflict = "SKU is already in use"
+		case suggested[combination.SKU] > 1:
+			response.Combinations[i].Conflict = "SKU is suggested for several combinations"
+		default:
+			continue
+		}
+		conflicts++
+	}
+
+	if !request.Create {
+		return response, fiber.StatusOK, nil
+	}
+	if conflicts > 0 {
+		return productDto.VariantMatrixResponse{}, fiber.StatusConflict, fiber.NewError(fiber.StatusConflict, fmt.Sprintf("%d suggested SKUs cannot be used; choose another sku_prefix or create those variants by hand", conflicts))
+	}
+
+	err = s.productDao.Transaction(func(tx *gorm.DB) error {
+		variants := s.variantDao.WithTx(tx)
+		for i, combination := range response.Combinations {
+			if combination.VariantID != "" {
+				continue
+			}
+			input := productDto.VariantInput{
+				SKU:           combination.SKU,
+				Options:       combination.Options,
+				PriceOverride: request.PriceOverride,
+				Stock:         request.Stock,
+			}
+			variant, err := newVariant(input, product, userID)
+			if err != nil {
+				return err
+			}
+			if variant, err = variants.Insert(variant); err != nil {
+				return err
+			}
+			response.Combinations[i].VariantID = variant.ID.String()
+			response.Created++
+		}
+		if product.HasVariants || response.Created == 0 {
+			return nil
+		}
+		product.HasVariants = true
+		product.UpdatedBy = &userID
+		return s.productDao.WithTx(tx).UpdateDetails(product)
+	})
+	if err != nil {
+		return productDto.VariantMatrixResponse{}, fiber.StatusInternalServerError, err
+	}
+
+	log.Infof("user_id=%s generated %d variants for product %s", userID.String(), response.Created, product.ID.String())
+	return response, fiber.StatusCreated, nil
+}
+
+func (s productService) findProduct(productID string, storeID string) (models.Product, int, error) {
+	return findStoreProduct(s.productDao, productID, storeID)
+}
+
+// findStoreProduct loads one of the store's live products.
+func findStoreProduct(products productDao.DataAccess, productID string, storeID string) (models.Product, int, error) {
+	if _, err := uuid.Parse(productID); err != nil {
+		return models.Product{}, fiber.StatusBadRequest, fiber.NewError(fiber.StatusBadRequest, "invalid product id format")
+	}
+	product, err := products.FindByIdAndStore(productID, storeID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.Product{}, fiber.StatusNotFound, fiber.NewError(fiber.StatusNotFound, "Product not found")
+		}
+		return models.Product{}, fiber.StatusInternalServerError, err
+	}
+	return product, fiber.StatusOK, nil
+}
+
+// checkBarcode fails with 409 when another product uses barcode. An empty barcode
+// clears it.
+func (s productService) checkBarcode(barcode string, productID string) (*string, int, error) {
+	barcode = strings.TrimSpace(barcode)
+	if barcode == "" {
+		return nil, fiber.StatusOK, nil
+	}
+	taken, err := s.productDao.BarcodeTaken(barcode, productID)
+	if err != nil {
+		return nil, fiber.StatusInternalServerError, err
+	}
+	if taken {
+		return nil, fiber.StatusConflict, fiber.NewError(fiber.StatusConflict, "Barcode "+barcode+" is already in use")
+	}
+	return &barcode, fiber.StatusOK, nil
+}
+
+// checkSKUs fails with 409 when a SKU is used by a variant of another product, or by a
+// deleted variant: SKUs stay unique across deleted variants too.
+func (s productService) checkSKUs(variants []productDto.VariantInput, productID uuid.UUID) (int, error) {
+	skus := make([]string, 0, len(variants))
+	for _, variant := range variants {
+		skus = append(skus, strings.TrimSpace(variant.SKU))
+	}
+	used, err := s.variantDao.FindBySKUs(skus)
+	if err != nil {
+		return fiber.StatusInternalServerError, err
+	}
+	for _, variant := range used {
+		if variant.ProductID != productID || variant.DelFlg {
+			return fiber.StatusConflict, fiber.NewError(fiber.StatusConflict, "SKU "+variant.SKU+" is already in use")
+		}
+	}
+	return fiber.StatusOK, nil
+}
+
+// checkVariantBarcodes fails with 409 when a variant barcode is used by a variant of
+// another product, or by a deleted variant.
+func (s productService) checkVariantBarcodes(variants []productDto.VariantInput, productID uuid.UUID) (int, error) {
+	barcodes := []string{}
+	for _, variant := range variants {
+		if barcode := strings.TrimSpace(variant.Barcode); barcode != "" {
+			barcodes = append(barcodes, barcode)
+		}
+	}
+	used, err := s.variantDao.FindByBarcodes(barcodes)
+	if err != nil {
+		return fiber.StatusInternalServerError, err
+	}
+	for _, variant := range used {
+		if variant.ProductID != productID || variant.DelFlg {
+			return fiber.StatusConflict, fiber.NewError(fiber.StatusConflict, "Barcode "+*variant.Barcode+" is already in use")
+		}
+	}
+	return fiber.StatusOK, nil
+}
+
+// checkVariantOptions fails with 400 unless every variant picks one value of each of
+// the options and no two variants pick the same combination. Without options,
+// variants are told apart by SKU alone.
+func checkVariantOptions(options []models.ProductOption, variants []productDto.VariantInput) (int, error) {
+	combinations := map[string]string{}
+	for i, variant := range variants {
+		if _, err := optionValues(options, variant.Options); err != nil {
+			return fiber.StatusBadRequest, fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("variants[%d] %s", i, err.Error()))
+		}
+		if len(options) == 0 {
+			continue
+		}
+		key := combinationKey(options, variant.Options)
+		if sku, ok := combinations[key]; ok {
+			return fiber.StatusBadRequest, fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("variants[%d] has the same options as %s", i, sku))
+		}
+		combinations[key] = variant.SKU
+	}
+	return fiber.StatusOK, nil
+}
+
+// saveVariants updates the variants sent with an ID, creates the others and deletes
+// the existing variants left out. The product's options must be saved already.
+func saveVariants(variants productvariantdao.DataAccess, product models.Product, existing map[uuid.UUID]models.ProductVariant, inputs []productDto.VariantInput, userID uuid.UUID) error {
+	kept := map[uuid.UUID]bool{}
+	for _, input := range inputs {
+		if input.ID == "" {
+			variant, err := newVariant(input, product, userID)
+			if err != nil {
+				return err
+			}
+			if _, err := variants.Insert(variant); err != nil {
+				return err
+			}
+			continue
+		}
+
+		values, err := optionValues(product.Options, input.Options)
+		if err != nil {
+			return err
+		}
+		variant := existing[uuid.MustParse(input.ID)]
+		variant.SKU = strings.TrimSpace(input.SKU)
+		variant.Barcode = variantBarcode(input.Barcode)
+		variant.PriceOverride = input.PriceOverride
+		variant.Stock = input.Stock
+		variant.OptionValues = values
+		variant.UpdatedBy = &userID
+		if err := variants.UpdateDetails(variant); err != nil {
+			return err
+		}
+		if err := variants.ReplaceOptionValues(variant); err != nil {
+			return err
+		}
+		if input.Images != nil {
+			variant.Images = productImages(input.Images)
+			if err := variants.ReplaceImages(variant); err != nil {
+				return err
+			}
+		}
+		kept[variant.ID] = true
+	}
+
+	for id := range existing {
+		if kept[id] {
+			continue
+		}
+		if err := variants.SoftDelete(id.String()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// newVariant builds a variant of product from input, linked to the product's saved
+// option values.
+func newVariant(input productDto.VariantInput, product models.Product, userID uuid.UUID) (models.ProductVariant, error) {
+	values, err := optionValues(product.Options, input.Options)
+	if err != nil {
+		return models.ProductVariant{}, err
+	}
+	images := productImages(input.Images)
+	for i := range images {
+		images[i].ProductID = product.ID
+	}
+	return models.ProductVariant{
+		ProductID:     product.ID,
+		SKU:           strings.TrimSpace(input.SKU),
+		Barcode:       variantBarcode(input.Barcode),
+		PriceOverride: input.PriceOverride,
+		Stock:         input.Stock,
+		OptionValues:  values,
+		Images:        images,
+		CreatedBy:     &userID,
+		UpdatedBy:     &userID,
+	}, nil
+}
+
+func variantBarcode(barcode string) *string {
+	barcode = strings.TrimSpace(barcode)
+	if barcode == "" {
+		return nil
+	}
+	return &barcode
+}
+
+// productOptions converts the request options; their order is the display order.
+func productOptions(inputs []productDto.OptionInput) []models.ProductOption {
+	options := []models.ProductOption{}
+	for i, input := range inputs {
+		option := models.ProductOption{Name: strings.TrimSpace(input.Name), Position: i}
+		for j, value := range input.Values {
+			option.Values = append(option.Values, models.ProductOptionValue{Value: strings.TrimSpace(value), Position: j})
+		}
+		options = append(options, option)
+	}
+	return options
+}
+
+// optionValues returns the value picked for each option, in option order.
+func optionValues(options []models.ProductOption, selected map[string]string) ([]models.ProductOptionValue, error) {
+	values := []models.ProductOptionValue{}
+	for _, option := range options {
+		picked, ok := selected[option.Name]
+		if !ok {
+			return nil, fmt.Errorf("has no value for option %s", option.Name)
+		}
+		picked = strings.TrimSpace(picked)
+		i := slices.IndexFunc(option.Values, func(value models.ProductOptionValue) bool { return value.Value == picked })
+		if i < 0 {
+			return nil, fmt.Errorf("value %s is not one of option %s", picked, option.Name)
+		}
+		values = append(values, option.Values[i])
+	}
+	if len(selected) > len(options) {
+		return nil, errors.New("has an option the product does not have")
+	}
+	return values, nil
+}
+
+// variantOptions maps the option names to the variant's values.
+func variantOptions(options []models.ProductOption, variant models.ProductVariant) map[string]string {
+	names := map[uuid.UUID]string{}
+	for _, option := range options {
+		for _, value := range option.Values {
+			names[value.ID] = option.Name
+		}
+	}
+	selected := map[string]string{}
+	for _, value := range variant.OptionValues {
+		if name, ok := names[value.ID]; ok {
+			selected[name] = value.Value
+		}
+	}
+	return selected
+}
+
+// combinationKey identifies the combination of option values picked in selected.
+func combinationKey(options []models.ProductOption, selected map[string]string) string {
+	parts := make([]string, 0, len(options))
+	for _, option := range options {
+		parts = append(parts, strings.TrimSpace(selected[option.Name]))
+	}
+	return strings.Join(parts, "\x00")
+}
+
+// optionCombinations returns every combination of one value of each option, in
+// display order.
+func optionCombinations(options []models.ProductOption) [][]models.ProductOptionValue {
+	combinations := [][]models.ProductOptionValue{{}}
+	for _, option := range options {
+		next := make([][]models.ProductOptionValue, 0, len(combinations)*len(option.Values))
+		for _, combination := range combinations {
+			for _, value := range option.Values {
+				next = append(next, append(slices.Clone(combination), value))
+			}
+		}
+		combinations = next
+	}
+	return combinations
+}
+
+// skuPart turns text into upper-case letters and digits separated by dashes.
+func skuPart(text string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToUpper(text) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			dash = false
+			continue
+		}
+		dash = true
+	}
+	return b.String()
+}
+
+// productImages converts the request images; the first image is primary unless
+// another one is marked.
+func productImages(inputs []productDto.ImageInput) []models.ProductImage {
+	images := []models.ProductImage{}
+	primary := false
+	for i, input := range inputs {
+		primary = primary || input.IsPrimary
+		images = append(images, models.ProductImage{ImageURL: strings.TrimSpace(input.ImageURL), IsPrimary: input.IsPrimary, Position: i})
+	}
+	if !primary && len(images) > 0 {
+		images[0].IsPrimary = true
+	}
+	return images
+}
+
+func toProductResponse(product models.Product) productDto.ProductResponse {
+	response := productDto.ProductResponse{
+		ID:             product.ID.String(),
+		StoreID:        product.StoreID.String(),
+		CategoryID:     product.CategoryID.String(),
+		SubCategoryIDs: []string{},
+		Name:           product.Name,
+		Description:    product.Description,
+		Price:          product.Price,
+		Stock:          product.Stock,
+		HasVariants:    product.HasVariants,
+		IsDiscounted:   product.IsDiscounted,
+		DiscountPct:    product.DiscountPct,
+		IsPopular:      product.IsPopular,
+		RatingAverage:  product.RatingAverage,
+		RatingCount:    product.RatingCount,
+		Options:        []productDto.OptionResponse{},
+		Variants:       []productDto.VariantResponse{},
+		Tags:           []string{},
+		CreatedAt:      product.CreatedAt,
+		UpdatedAt:      product.UpdatedAt,
+	}
+	if product.Barcode != nil {
+		response.Barcode = *product.Barcode
+	}
+	if product.CreatedBy != nil {
+		response.CreatedBy = product.CreatedBy.String()
+	}
+	if product.UpdatedBy != nil {
+		response.UpdatedBy = product.UpdatedBy.String()
+	}
+	for _, subCategory := range product.SubCategories {
+		response.SubCategoryIDs = append(response.SubCategoryIDs, subCategory.ID.String())
+	}
+	for _, option := range product.Options {
+		values := []string{}
+		for _, value := range option.Values {
+			values = append(values, value.Value)
+		}
+		response.Options = append(response.Options, productDto.OptionResponse{ID: option.ID.String(), Name: option.Name, Values: values})
+	}
+	for _, variant := range product.Variants {
+		if variant.DelFlg {
+			continue
+		}
+		variantResponse := productDto.VariantResponse{
+			ID:            variant.ID.String(),
+			SKU:           variant.SKU,
+			Options:       variantOptions(product.Options, variant),
+			PriceOverride: variant.PriceOverride,
+			Stock:         variant.Stock,
+			Images:        toImageResponses(variant.Images),
+		}
+		if variant.Barcode != nil {
+			variantResponse.Barcode = *variant.Barcode
+		}
+		response.Variants = append(response.Variants, variantResponse)
+	}
+	response.Images = toImageResponses(product.Images)
+	for _, tag := range product.Tags {
+		response.Tags = append(response.Tags, tag.Name)
+	}
+	return response
+}
+
+func toImageResponses(images []models.ProductImage) []productDto.ImageResponse {
+	response := []productDto.ImageResponse{}
+	for _, image := range images {
+		response = append(response, productDto.ImageResponse{
+			ID:        image.ID.String(),
+			ImageURL:  image.ImageURL,
+			SmallURL:  image.SmallURL,
+			MediumURL: image.MediumURL,
+			IsPrimary: image.IsPrimary,
+			Position:  image.Position,
+		})
+	}
+	return response
+}
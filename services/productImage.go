@@ -0,0 +1,250 @@
+package services
+
+import (
+	"errors"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/abdulmalikraji/e-commerce/db/dao/productDao"
+	productimagedao "github.com/abdulmalikraji/e-commerce/db/dao/productImageDao"
+	"github.com/abdulmalikraji/e-commerce/db/models"
+	"github.com/abdulmalikraji/e-commerce/dto/productDto"
+	"github.com/abdulmalikraji/e-commerce/storage"
+	"github.com/abdulmalikraji/e-commerce/utils"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/log"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// productThumbnails are the thumbnail sizes generated for every uploaded product image.
+var productThumbnails = []int{models.ThumbnailSmall, models.ThumbnailMedium}
+
+type ProductImageService interface {
+	UploadImage(ctx *fiber.Ctx, request productDto.UploadImageRequest) (productDto.ImageResponse, int, error)
+	SetPrimaryImage(ctx *fiber.Ctx, request productDto.ProductImageRequest) ([]productDto.ImageResponse, int, error)
+	ReorderImages(ctx *fiber.Ctx, request productDto.ReorderImagesRequest) ([]productDto.ImageResponse, int, error)
+	DeleteImage(ctx *fiber.Ctx, request productDto.ProductImageRequest) (int, error)
+}
+
+type productImageService struct {
+	productDao productDao.DataAccess
+	imageDao   productimagedao.DataAccess
+	blobs      storage.BlobStorage
+}
+
+func NewProductImageService(productDao productDao.DataAccess, imageDao productimagedao.DataAccess, blobs storage.BlobStorage) ProductImageService {
+	return productImageService{
+		productDao: productDao,
+		imageDao:   imageDao,
+		blobs:      blobs,
+	}
+}
+
+// UploadImage stores an uploaded image with its thumbnails and adds it after the
+// product's other images.
+func (s productImageService) UploadImage(ctx *fiber.Ctx, request productDto.UploadImageRequest) (productDto.ImageResponse, int, error) {
+	userID, err := utils.GetUserID(ctx)
+	if err != nil {
+		return productDto.ImageResponse{}, fiber.StatusUnauthorized, err
+	}
+	product, status, err := findStoreProduct(s.productDao, request.ProductID, request.StoreID)
+	if err != nil {
+		return productDto.ImageResponse{}, status, err
+	}
+	// Append enforces the limit under lock; this check only saves storing a file that
+	// would be turned away.
+	images, err := s.imageDao.FindByProductId(product.ID.String())
+	if err != nil {
+		return productDto.ImageResponse{}, fiber.StatusInternalServerError, err
+	}
+	if len(images) >= productDto.MaxImages {
+		return productDto.ImageResponse{}, fiber.StatusConflict, tooManyImages()
+	}
+
+	stored, status, err := storeImage(s.blobs, "products/"+product.ID.String()+"/"+uuid.New().String(), request.File, productThumbnails...)
+	if err != nil {
+		return productDto.ImageResponse{}, status, err
+	}
+	image, err := s.imageDao.Append(models.ProductImage{
+		ProductID:  product.ID,
+		ImageURL:   stored.URL,
+		SmallURL:   stored.Thumbnails[models.ThumbnailSmall],
+		MediumURL:  stored.Thumbnails[models.ThumbnailMedium],
+		StorageKey: stored.Key,
+	}, productDto.MaxImages)
+	if err != nil {
+		deleteImageBlobs(s.blobs, stored.Key, productThumbnails...)
+		switch {
+		case errors.Is(err, productimagedao.ErrTooManyImages):
+			return productDto.ImageResponse{}, fiber.StatusConflict, tooManyImages()
+		case errors.Is(err, productimagedao.ErrConcurrentChange):
+			return productDto.ImageResponse{}, fiber.StatusConflict, fiber.NewError(fiber.StatusConflict, "Another image was added at the same time, please try again")
+		}
+		return productDto.ImageResponse{}, fiber.StatusInternalServerError, err
+	}
+	if request.IsPrimary && !image.IsPrimary {
+		if err := s.imageDao.SetPrimary(product.ID.String(), image.ID.String()); err != nil {
+			return productDto.ImageResponse{}, fiber.StatusInternalServerError, err
+		}
+		image.IsPrimary = true
+	}
+
+	log.Infof("user_id=%s uploaded image %s to product %s", userID.String(), image.ID.String(), product.ID.String())
+	return toImageResponses([]models.ProductImage{image})[0], fiber.StatusCreated, nil
+}
+
+func tooManyImages() error {
+	return fiber.NewError(fiber.StatusConflict, "A product can have at most "+strconv.Itoa(productDto.MaxImages)+" images")
+}
+
+// SetPrimaryImage makes the image the product's only primary image.
+func (s productImageService) SetPrimaryImage(ctx *fiber.Ctx, request productDto.ProductImageRequest) ([]productDto.ImageResponse, int, error) {
+	image, status, err := s.findImage(request)
+	if err != nil {
+		return nil, status, err
+	}
+	if err := s.imageDao.SetPrimary(image.ProductID.String(), image.ID.String()); err != nil {
+		return nil, fiber.StatusInternalServerError, err
+	}
+	return s.listImages(image.ProductID.String())
+}
+
+// ReorderImages puts the product's images in the order of the request, which must
+// list each of them once.
+func (s productImageService) ReorderImages(ctx *fiber.Ctx, request productDto.ReorderImagesRequest) ([]productDto.ImageResponse, int, error) {
+	product, status, err := findStoreProduct(s.productDao, request.ProductID, request.StoreID)
+	if err != nil {
+		return nil, status, err
+	}
+	images, err := s.imageDao.FindByProductId(product.ID.String())
+	if err != nil {
+		return nil, fiber.StatusInternalServerError, err
+	}
+
+	unlisted := map[string]bool{}
+	for _, image := range images {
+		unlisted[image.ID.String()] = true
+	}
+	for _, id := range request.ImageIDs {
+		if !unlisted[id] {
+			return nil, fiber.StatusBadRequest, fiber.NewError(fiber.StatusBadRequest, "image "+id+" is not an image of this product or is listed twice")
+		}
+		delete(unlisted, id)
+	}
+	if len(unlisted) > 0 {
+		return nil, fiber.StatusBadRequest, fiber.NewError(fiber.StatusBadRequest, "image_ids must list every image of the product")
+	}
+
+	if err := s.imageDao.Reorder(product.ID.String(), request.ImageIDs); err != nil {
+		return nil, fiber.StatusInternalServerError, err
+	}
+	return s.listImages(product.ID.String())
+}
+
+// DeleteImage removes an image, and its files when it was uploaded. When it was the
+// primary image, the first image left becomes primary.
+func (s productImageService) DeleteImage(ctx *fiber.Ctx, request productDto.ProductImageRequest) (int, error) {
+	image, status, err := s.findImage(request)
+	if err != nil {
+		return status, err
+	}
+	if err := s.imageDao.Delete(image); err != nil {
+		return fiber.StatusInternalServerError, err
+	}
+	if image.StorageKey != "" {
+		deleteImageBlobs(s.blobs, image.StorageKey, productThumbnails...)
+	}
+	return fiber.StatusOK, nil
+}
+
+func (s productImageService) findImage(request productDto.ProductImageRequest) (models.ProductImage, int, error) {
+	product, status, err := findStoreProduct(s.productDao, request.ProductID, request.StoreID)
+	if err != nil {
+		return models.ProductImage{}, status, err
+	}
+	if _, err := uuid.Parse(request.ImageID); err != nil {
+		return models.ProductImage{}, fiber.StatusBadRequest, fiber.NewError(fiber.StatusBadRequest, "invalid image id format")
+	}
+	image, err := s.imageDao.FindById(request.ImageID, product.ID.String())
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.ProductImage{}, fiber.StatusNotFound, fiber.NewError(fiber.StatusNotFound, "Image not found")
+		}
+		return models.ProductImage{}, fiber.StatusInternalServerError, err
+	}
+	return image, fiber.StatusOK, nil
+}
+
+func (s productImageService) listImages(productID string) ([]productDto.ImageResponse, int, error) {
+	images, err := s.imageDao.FindByProductId(productID)
+	if err != nil {
+		return nil, fiber.StatusInternalServerError, err
+	}
+	return toImageResponses(images), fiber.StatusOK, nil
+}
+
+// storedImage is an uploaded image written to blob storage, with its thumbnail URLs by
+// size.
+type storedImage struct {
+	Key        string
+	URL        string
+	Thumbnails map[int]string
+}
+
+// storeImage validates an uploaded image and writes it under base, with a thumbnail
+// for each size. The image is re-encoded, which also drops its metadata. When a write
+// fails, the files already written are removed.
+func storeImage(blobs storage.BlobStorage, base string, file utils.File, sizes ...int) (storedImage, int, error) {
+	if file.File == nil || file.File.Len() == 0 {
+		return storedImage{}, fiber.StatusBadRequest, fiber.NewError(fiber.StatusBadRequest, "image file is required")
+	}
+	if file.File.Len() > utils.MaxImageSize {
+		return storedImage{}, fiber.StatusBadRequest, fiber.NewError(fiber.StatusBadRequest, "image cannot be larger than "+strconv.Itoa(utils.MaxImageSize>>20)+" MB")
+	}
+	img, contentType, err := utils.DecodeImage(file.File.Bytes())
+	if err != nil {
+		return storedImage{}, fiber.StatusBadRequest, fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	data, contentType, err := utils.EncodeImage(img, contentType)
+	if err != nil {
+		return storedImage{}, fiber.StatusInternalServerError, err
+	}
+	stored := storedImage{Key: base + utils.ImageExtensions[contentType], Thumbnails: map[int]string{}}
+	if stored.URL, err = blobs.Put(stored.Key, contentType, data); err != nil {
+		return storedImage{}, fiber.StatusInternalServerError, err
+	}
+	for i, size := range sizes {
+		data, _, err := utils.EncodeImage(utils.Thumbnail(img, size), contentType)
+		if err == nil {
+			stored.Thumbnails[size], err = blobs.Put(thumbnailKey(stored.Key, size), contentType, data)
+		}
+		if err != nil {
+			deleteImageBlobs(blobs, stored.Key, sizes[:i]...)
+			return storedImage{}, fiber.StatusInternalServerError, err
+		}
+	}
+	return stored, fiber.StatusOK, nil
+}
+
+// deleteImageBlobs removes an uploaded image and its thumbnails. Failures are only
+// logged: the database no longer points at the files.
+func deleteImageBlobs(blobs storage.BlobStorage, key string, sizes ...int) {
+	keys := []string{key}
+	for _, size := range sizes {
+		keys = append(keys, thumbnailKey(key, size))
+	}
+	for _, key := range keys {
+		if err := blobs.Delete(key); err != nil {
+			log.Errorf("could not delete stored file %s: %v", key, err)
+		}
+	}
+}
+
+// thumbnailKey is where the thumbnail of the given size of the image at key is stored.
+func thumbnailKey(key string, size int) string {
+	extension := path.Ext(key)
+	return strings.TrimSuffix(key, extension) + "_" + strconv.Itoa(size) + extension
+}
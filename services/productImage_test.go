@@ -0,0 +1,151 @@
+package services
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	productimagedao "github.com/abdulmalikraji/e-commerce/db/dao/productImageDao"
+	"github.com/abdulmalikraji/e-commerce/db/models"
+	"github.com/abdulmalikraji/e-commerce/dto/productDto"
+	"github.com/abdulmalikraji/e-commerce/mocks/dao/productDao"
+	"github.com/abdulmalikraji/e-commerce/mocks/dao/productImageDao"
+	"github.com/abdulmalikraji/e-commerce/storage"
+	"github.com/abdulmalikraji/e-commerce/utils"
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+)
+
+var imageMockDao *productImageDao.MockDataAccess
+
+var imageService ProductImageService
+
+var uploadDir string
+
+func setupProductImages(t *testing.T) func() {
+	ct := gomock.NewController(t)
+
+	app := fiber.New()
+	fiberCtx = app.AcquireCtx(&fasthttp.RequestCtx{})
+	utils.SetPrincipal(fiberCtx, staffOwner)
+
+	productMockDao = productDao.NewMockDataAccess(ct)
+	imageMockDao = productImageDao.NewMockDataAccess(ct)
+	uploadDir = t.TempDir()
+
+	imageService = NewProductImageService(productMockDao, imageMockDao, storage.NewLocalStorage(uploadDir, "/uploads"))
+	return func() {
+		imageService = nil
+		app.ReleaseCtx(fiberCtx)
+		ct.Finish()
+	}
+}
+
+func pngFile(t *testing.T, width int, height int) utils.File {
+	var buffer bytes.Buffer
+	assert.NoError(t, png.Encode(&buffer, image.NewRGBA(image.Rect(0, 0, width, height))))
+	return utils.File{File: &buffer, FileName: "mug.png", FileSize: buffer.Len(), FileType: "image/png"}
+}
+
+func TestProductImageService_Upload_Stores_Thumbnails(t *testing.T) {
+	teardown := setupProductImages(t)
+	defer teardown()
+
+	product := models.Product{ID: uuid.New(), StoreID: staffStore.ID}
+	first := models.ProductImage{ID: uuid.New(), ProductID: product.ID, IsPrimary: true, Position: 3}
+	productMockDao.EXPECT().FindByIdAndStore(product.ID.String(), staffStore.ID.String()).Return(product, nil)
+	imageMockDao.EXPECT().FindByProductId(product.ID.String()).Return([]models.ProductImage{first}, nil)
+	imageMockDao.EXPECT().Append(gomock.Any(), productDto.MaxImages).DoAndReturn(func(image models.ProductImage, limit int) (models.ProductImage, error) {
+		assert.Equal(t, product.ID, image.ProductID)
+		image.ID, image.Position = uuid.New(), first.Position+1
+		return image, nil
+	})
+	imageMockDao.EXPECT().SetPrimary(product.ID.String(), gomock.Any()).Return(nil)
+
+	response, status, err := imageService.UploadImage(fiberCtx, productDto.UploadImageRequest{
+		StoreID:   staffStore.ID.String(),
+		ProductID: product.ID.String(),
+		IsPrimary: true,
+		File:      pngFile(t, 1600, 400),
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, fiber.StatusCreated, status)
+	assert.True(t, response.IsPrimary)
+	assert.Regexp(t, `^/uploads/products/.+_200\.png$`, response.SmallURL)
+
+	small, err := os.Open(filepath.Join(uploadDir, response.SmallURL[len("/uploads/"):]))
+	assert.NoError(t, err)
+	defer small.Close()
+	config, err := png.DecodeConfig(small)
+	assert.NoError(t, err)
+	assert.Equal(t, 200, config.Width)
+	assert.Equal(t, 50, config.Height)
+}
+
+func TestProductImageService_Upload_Rejects_Non_Images(t *testing.T) {
+	teardown := setupProductImages(t)
+	defer teardown()
+
+	product := models.Product{ID: uuid.New(), StoreID: staffStore.ID}
+	productMockDao.EXPECT().FindByIdAndStore(product.ID.String(), staffStore.ID.String()).Return(product, nil)
+	imageMockDao.EXPECT().FindByProductId(product.ID.String()).Return(nil, nil)
+
+	_, status, err := imageService.UploadImage(fiberCtx, productDto.UploadImageRequest{
+		StoreID:   staffStore.ID.String(),
+		ProductID: product.ID.String(),
+		File:      utils.File{File: bytes.NewBufferString("<svg onload=alert(1)></svg>"), FileType: "image/png"},
+	})
+
+	assert.Error(t, err)
+	assert.Equal(t, fiber.StatusBadRequest, status)
+	entries, _ := os.ReadDir(uploadDir)
+	assert.Empty(t, entries)
+}
+
+func TestProductImageService_Upload_Loses_Race_For_Last_Slot(t *testing.T) {
+	teardown := setupProductImages(t)
+	defer teardown()
+
+	// The snapshot still had room, but a concurrent upload took the last slot first.
+	product := models.Product{ID: uuid.New(), StoreID: staffStore.ID}
+	productMockDao.EXPECT().FindByIdAndStore(product.ID.String(), staffStore.ID.String()).Return(product, nil)
+	imageMockDao.EXPECT().FindByProductId(product.ID.String()).Return(nil, nil)
+	imageMockDao.EXPECT().Append(gomock.Any(), productDto.MaxImages).Return(models.ProductImage{}, productimagedao.ErrTooManyImages)
+
+	_, status, err := imageService.UploadImage(fiberCtx, productDto.UploadImageRequest{
+		StoreID:   staffStore.ID.String(),
+		ProductID: product.ID.String(),
+		File:      pngFile(t, 400, 400),
+	})
+
+	assert.Error(t, err)
+	assert.Equal(t, fiber.StatusConflict, status)
+	entries, _ := os.ReadDir(filepath.Join(uploadDir, "products", product.ID.String()))
+	assert.Empty(t, entries)
+}
+
+func TestProductImageService_Reorder_Requires_Every_Image(t *testing.T) {
+	teardown := setupProductImages(t)
+	defer teardown()
+
+	product := models.Product{ID: uuid.New(), StoreID: staffStore.ID}
+	front, back := models.ProductImage{ID: uuid.New()}, models.ProductImage{ID: uuid.New()}
+	productMockDao.EXPECT().FindByIdAndStore(product.ID.String(), staffStore.ID.String()).Return(product, nil)
+	imageMockDao.EXPECT().FindByProductId(product.ID.String()).Return([]models.ProductImage{front, back}, nil)
+
+	_, status, err := imageService.ReorderImages(fiberCtx, productDto.ReorderImagesRequest{
+		StoreID:   staffStore.ID.String(),
+		ProductID: product.ID.String(),
+		ImageIDs:  []string{back.ID.String()},
+	})
+
+	assert.Error(t, err)
+	assert.Equal(t, fiber.StatusBadRequest, status)
+}
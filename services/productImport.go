@@ -0,0 +1,712 @@
+package services
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/abdulmalikraji/e-commerce/db/dao/categoryDao"
+	"github.com/abdulmalikraji/e-commerce/db/dao/importJobDao"
+	"github.com/abdulmalikraji/e-commerce/db/dao/productDao"
+	productvariantdao "github.com/abdulmalikraji/e-commerce/db/dao/productVariantDao"
+	"github.com/abdulmalikraji/e-commerce/db/dao/tagDao"
+	"github.com/abdulmalikraji/e-commerce/db/models"
+	"github.com/abdulmalikraji/e-commerce/dto/importDto"
+	"github.com/abdulmalikraji/e-commerce/dto/productDto"
+	"github.com/abdulmalikraji/e-commerce/utils"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/log"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	// importProgressEvery is how many products are imported between progress updates.
+	importProgressEvery = 25
+	// staleImportAfter is how long a running job may go without progress before another
+	// worker takes it over.
+	staleImportAfter = 10 * time.Minute
+	exportBatchSize  = 200
+)
+
+// errDryRun rolls back the transaction of a product imported in a dry run.
+var errDryRun = errors.New("dry run")
+
+type ProductImportService interface {
+	StartImport(ctx *fiber.Ctx, request importDto.StartImportRequest) (importDto.ImportJobResponse, int, error)
+	GetImportJob(ctx *fiber.Ctx, request importDto.GetImportJobRequest) (importDto.ImportJobResponse, int, error)
+	ExportProducts(ctx *fiber.Ctx, request importDto.ExportRequest) (importDto.ExportResponse, int, error)
+	// RunNextImport processes the oldest waiting import job, if any, and reports whether
+	// there was one. Once stop is closed the job is handed back to the queue before its
+	// next product.
+	RunNextImport(stop <-chan struct{}) (bool, error)
+}
+
+type productImportService struct {
+	importJobDao importJobDao.DataAccess
+	productDao   productDao.DataAccess
+	variantDao   productvariantdao.DataAccess
+	categoryDao  categoryDao.DataAccess
+	tagDao       tagDao.DataAccess
+}
+
+func NewProductImportService(
+	importJobDao importJobDao.DataAccess,
+	productDao productDao.DataAccess,
+	variantDao productvariantdao.DataAccess,
+	categoryDao categoryDao.DataAccess,
+	tagDao tagDao.DataAccess,
+) ProductImportService {
+	return productImportService{
+		importJobDao: importJobDao,
+		productDao:   productDao,
+		variantDao:   variantDao,
+		categoryDao:  categoryDao,
+		tagDao:       tagDao,
+	}
+}
+
+// StartImport checks that the file can be read and queues it; the products themselves
+// are checked and imported by the import worker.
+func (s productImportService) StartImport(ctx *fiber.Ctx, request importDto.StartImportRequest) (importDto.ImportJobResponse, int, error) {
+	userID, err := utils.GetUserID(ctx)
+	if err != nil {
+		return importDto.ImportJobResponse{}, fiber.StatusUnauthorized, err
+	}
+	storeID, err := uuid.Parse(request.StoreID)
+	if err != nil {
+		return importDto.ImportJobResponse{}, fiber.StatusBadRequest, fiber.NewError(fiber.StatusBadRequest, "invalid store id format")
+	}
+	if request.File.File == nil {
+		return importDto.ImportJobResponse{}, fiber.StatusBadRequest, fiber.NewError(fiber.StatusBadRequest, "file is required")
+	}
+	if request.File.FileSize > importDto.MaxImportSize {
+		return importDto.ImportJobResponse{}, fiber.StatusRequestEntityTooLarge, fiber.NewError(fiber.StatusRequestEntityTooLarge, fmt.Sprintf("file cannot be larger than %d MB", importDto.MaxImportSize>>20))
+	}
+
+	format := importFormat(request.Format, request.File.FileName)
+	data := request.File.File.Bytes()
+	products, rowErrors, err := importDto.Parse(format, data)
+	if err != nil {
+		return importDto.ImportJobResponse{}, fiber.StatusBadRequest, fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	job, err := s.importJobDao.Insert(models.ImportJob{
+		StoreID:   storeID,
+		Format:    format,
+		DryRun:    request.DryRun,
+		Status:    models.ImportStatusPending,
+		Payload:   data,
+		Total:     len(products) + len(rowErrors),
+		CreatedBy: userID,
+	})
+	if err != nil {
+		return importDto.ImportJobResponse{}, fiber.StatusInternalServerError, err
+	}
+
+	log.Infof("user_id=%s queued import %s of %d products in store %s", userID.String(), job.ID.String(), job.Total, storeID.String())
+	return toImportJobResponse(job), fiber.StatusAccepted, nil
+}
+
+func (s productImportService) GetImportJob(ctx *fiber.Ctx, request importDto.GetImportJobRequest) (importDto.ImportJobResponse, int, error) {
+	if _, err := uuid.Parse(request.JobID); err != nil {
+		return importDto.ImportJobResponse{}, fiber.StatusBadRequest, fiber.NewError(fiber.StatusBadRequest, "invalid job id format")
+	}
+	job, err := s.importJobDao.FindByIdAndStore(request.JobID, request.StoreID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return importDto.ImportJobResponse{}, fiber.StatusNotFound, fiber.NewError(fiber.StatusNotFound, "Import job not found")
+		}
+		return importDto.ImportJobResponse{}, fiber.StatusInternalServerError, err
+	}
+	return toImportJobResponse(job), fiber.StatusOK, nil
+}
+
+// ExportProducts streams the store's live catalog in the import format, so an export
+// can be edited and imported again.
+func (s productImportService) ExportProducts(ctx *fiber.Ctx, request importDto.ExportRequest) (importDto.ExportResponse, int, error) {
+	if _, err := uuid.Parse(request.StoreID); err != nil {
+		return importDto.ExportResponse{}, fiber.StatusBadRequest, fiber.NewError(fiber.StatusBadRequest, "invalid store id format")
+	}
+	format := strings.ToLower(strings.TrimSpace(request.Format))
+	if format == "" {
+		format = importDto.FormatCSV
+	}
+
+	response := importDto.ExportResponse{FileName: "products." + format}
+	switch format {
+	case importDto.FormatCSV:
+		response.ContentType = "text/csv; charset=utf-8"
+	case importDto.FormatJSONL:
+		response.ContentType = "application/x-ndjson"
+	default:
+		return importDto.ExportResponse{}, fiber.StatusBadRequest, fiber.NewError(fiber.StatusBadRequest, "format must be csv or jsonl")
+	}
+
+	// The first batch is read before answering, so a failing database is still an error
+	// response rather than a cut-off download.
+	storeID := request.StoreID
+	first, err := s.productDao.FindBatchByStore(storeID, uuid.Nil, exportBatchSize)
+	if err != nil {
+		log.Errorf("export of store %s failed: %v", storeID, err)
+		return importDto.ExportResponse{}, fiber.StatusInternalServerError, fiber.NewError(fiber.StatusInternalServerError, "failed to export products")
+	}
+
+	response.Write = func(out io.Writer) error {
+		w := bufio.NewWriter(out)
+		rows := csv.NewWriter(w)
+		if format == importDto.FormatCSV {
+			rows.Write(importDto.CSVColumns)
+		}
+		products, err := first, error(nil)
+		for {
+			for _, product := range products {
+				item := toImportProduct(product)
+				if format == importDto.FormatCSV {
+					for _, row := range importDto.CSVRows(item) {
+						rows.Write(row)
+					}
+					continue
+				}
+				line, err := json.Marshal(item)
+				if err != nil {
+					return err
+				}
+				w.Write(append(line, '\n'))
+			}
+			rows.Flush()
+			if err := rows.Error(); err != nil {
+				return err
+			}
+			if err := w.Flush(); err != nil {
+				return err
+			}
+			if len(products) < exportBatchSize {
+				return nil
+			}
+			after := products[len(products)-1].ID
+			if products, err = s.productDao.FindBatchByStore(storeID, after, exportBatchSize); err != nil {
+				log.Errorf("export of store %s failed: %v", storeID, err)
+				return err
+			}
+		}
+	}
+	return response, fiber.StatusOK, nil
+}
+
+func (s productImportService) RunNextImport(stop <-chan struct{}) (bool, error) {
+	job, found, err := s.importJobDao.ClaimNext(time.Now().Add(-staleImportAfter))
+	if err != nil || !found {
+		return false, err
+	}
+	err = s.runImport(job, stop)
+	if errors.Is(err, importJobDao.ErrJobLost) {
+		log.Warnf("import %s was taken over by another worker", job.ID.String())
+		return true, nil
+	}
+	return true, err
+}
+
+// runImport imports the job's products one by one, each in its own transaction, so a
+// bad product only fails itself. Jobs taken over from a stopped worker start over;
+// products imported before the stop are matched and updated again.
+func (s productImportService) runImport(job models.ImportJob, stop <-chan struct{}) error {
+	job.Processed, job.Created, job.Updated, job.Failed = 0, 0, 0, 0
+	job.RowErrors = models.ImportRowErrors{}
+
+	products, rowErrors, err := importDto.Parse(job.Format, job.Payload)
+	if err != nil {
+		job.Status, job.Error = models.ImportStatusFailed, err.Error()
+		return s.importJobDao.Finish(job)
+	}
+	for _, rowError := range rowErrors {
+		addImportError(&job, rowError.Line, rowError.Message)
+		job.Processed++
+	}
+
+	categories := map[string]string{}
+	for _, product := range products {
+		select {
+		case <-stop:
+			log.Infof("import %s stopped after %d of %d products, requeued", job.ID.String(), job.Processed, job.Total)
+			return s.importJobDao.Requeue(job)
+		default:
+		}
+
+		created, err := s.importProduct(job, product, categories)
+		switch {
+		case err != nil:
+			addImportError(&job, product.Line, err.Error())
+		case created:
+			job.Created++
+		default:
+			job.Updated++
+		}
+		job.Processed++
+		if job.Processed%importProgressEvery == 0 {
+			if err := s.importJobDao.UpdateProgress(job); err != nil {
+				if errors.Is(err, importJobDao.ErrJobLost) {
+					return err
+				}
+				log.Errorf("import %s: saving progress failed: %v", job.ID.String(), err)
+			}
+		}
+	}
+
+	sort.SliceStable(job.RowErrors, func(i, j int) bool { return job.RowErrors[i].Line < job.RowErrors[j].Line })
+	job.Status = models.ImportStatusCompleted
+	if err := s.importJobDao.Finish(job); err != nil {
+		return err
+	}
+	log.Infof("import %s in store %s done: %d created, %d updated, %d failed, dry run %t",
+		job.ID.String(), job.StoreID.String(), job.Created, job.Updated, job.Failed, job.DryRun)
+	return nil
+}
+
+// addImportError counts a failed product, keeping its error while there is room.
+func addImportError(job *models.ImportJob, line int, message string) {
+	job.Failed++
+	if len(job.RowErrors) < importDto.MaxRowErrors {
+		job.RowErrors = append(job.RowErrors, models.ImportRowError{Line: line, Message: message})
+	}
+}
+
+// importProduct creates or updates one product through the product service, so it is
+// checked exactly like a product sent to the API. categories caches category IDs by
+// name for the job.
+func (s productImportService) importProduct(job models.ImportJob, input importDto.ImportProduct, categories map[string]string) (created bool, err error) {
+	storeID := job.StoreID.String()
+	err = s.productDao.Transaction(func(tx *gorm.DB) error {
+		products := productService{
+			productDao: s.productDao.WithTx(tx),
+			variantDao: s.variantDao.WithTx(tx),
+			classifier: classifier{categoryDao: s.categoryDao, tagDao: s.tagDao.WithTx(tx)},
+		}
+
+		existing, found, err := products.findImported(input, storeID)
+		if err != nil {
+			return err
+		}
+		if found {
+			request, err := s.updateRequest(input, existing, categories)
+			if err != nil {
+				return err
+			}
+			if _, _, err := products.updateProduct(request, job.CreatedBy); err != nil {
+				return err
+			}
+		} else {
+			request, err := s.createRequest(input, storeID, categories)
+			if err != nil {
+				return err
+			}
+			if _, _, err := products.createProduct(request, job.CreatedBy); err != nil {
+				return err
+			}
+			created = true
+		}
+
+		if job.DryRun {
+			return errDryRun
+		}
+		return nil
+	})
+	if errors.Is(err, errDryRun) {
+		err = nil
+	}
+	return created, err
+}
+
+// findImported finds the store's product an imported product updates: the one with
+// its barcode, or else the one with one of its variants' SKUs.
+func (s productService) findImported(input importDto.ImportProduct, storeID string) (models.Product, bool, error) {
+	if input.Barcode != "" {
+		product, err := s.productDao.FindByBarcodeAndStore(input.Barcode, storeID)
+		if err == nil {
+			return product, true, nil
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.Product{}, false, err
+		}
+	}
+	if input.Variants == nil {
+		return models.Product{}, false, nil
+	}
+
+	skus := []string{}
+	for _, variant := range *input.Variants {
+		skus = append(skus, variant.SKU)
+	}
+	variants, err := s.variantDao.FindBySKUs(skus)
+	if err != nil {
+		return models.Product{}, false, err
+	}
+	for _, variant := range variants {
+		if variant.DelFlg {
+			continue
+		}
+		// SKUs of other stores' products are reported as taken by the product service.
+		product, status, err := s.findProduct(variant.ProductID.String(), storeID)
+		if status == fiber.StatusNotFound {
+			continue
+		}
+		if err != nil {
+			return models.Product{}, false, err
+		}
+		return product, true, nil
+	}
+	return models.Product{}, false, nil
+}
+
+func (s productImportService) createRequest(input importDto.ImportProduct, storeID string, categories map[string]string) (productDto.CreateProductRequest, error) {
+	if input.Category == nil {
+		return productDto.CreateProductRequest{}, errors.New("category is required for new products")
+	}
+	categoryID, err := s.categoryID(*input.Category, categories)
+	if err != nil {
+		return productDto.CreateProductRequest{}, err
+	}
+
+	request := productDto.CreateProductRequest{
+		StoreID:    storeID,
+		CategoryID: categoryID,
+		Barcode:    input.Barcode,
+		Options:    importOptions(input),
+		Variants:   importVariants(input, nil),
+		Images:     importImages(input, nil),
+	}
+	if input.Name != nil {
+		request.Name = *input.Name
+	}
+	if input.Description != nil {
+		request.Description = *input.Description
+	}
+	if input.Price != nil {
+		request.Price = *input.Price
+	}
+	if input.Stock != nil {
+		request.Stock = *input.Stock
+	}
+	if input.IsDiscounted != nil {
+		request.IsDiscounted = *input.IsDiscounted
+	}
+	if input.DiscountPct != nil {
+		request.DiscountPct = *input.DiscountPct
+	}
+	if input.Tags != nil {
+		request.Tags = *input.Tags
+	}
+	if input.SubCategories != nil {
+		if request.SubCategoryIDs, err = s.categoryIDs(*input.SubCategories, categories); err != nil {
+			return productDto.CreateProductRequest{}, err
+		}
+	}
+	return request, nil
+}
+
+// updateRequest only changes what the imported product sets. Its variants replace the
+// product's, keeping the ones with the same SKU.
+func (s productImportService) updateRequest(input importDto.ImportProduct, product models.Product, categories map[string]string) (productDto.UpdateProductRequest, error) {
+	request := productDto.UpdateProductRequest{
+		StoreID:      product.StoreID.String(),
+		ProductID:    product.ID.String(),
+		Name:         input.Name,
+		Description:  input.Description,
+		Price:        input.Price,
+		Stock:        input.Stock,
+		IsDiscounted: input.IsDiscounted,
+		DiscountPct:  input.DiscountPct,
+		Tags:         input.Tags,
+	}
+	if input.Barcode != "" {
+		request.Barcode = &input.Barcode
+	}
+	if input.Category != nil {
+		categoryID, err := s.categoryID(*input.Category, categories)
+		if err != nil {
+			return productDto.UpdateProductRequest{}, err
+		}
+		request.CategoryID = &categoryID
+	}
+	if input.SubCategories != nil {
+		subCategoryIDs, err := s.categoryIDs(*input.SubCategories, categories)
+		if err != nil {
+			return productDto.UpdateProductRequest{}, err
+		}
+		request.SubCategoryIDs = &subCategoryIDs
+	}
+	if input.Options != nil || input.Variants != nil {
+		options := importOptions(input)
+		request.Options = &options
+	}
+	if input.Variants != nil {
+		variants := importVariants(input, product.Variants)
+		request.Variants = &variants
+	}
+	if input.Images != nil {
+		images := importImages(input, product.Images)
+		request.Images = &images
+	}
+	return request, nil
+}
+
+// categoryID resolves a category given by ID or by name; category names are unique.
+func (s productImportService) categoryID(category string, cache map[string]string) (string, error) {
+	category = strings.TrimSpace(category)
+	if _, err := uuid.Parse(category); err == nil {
+		return category, nil
+	}
+	if id, ok := cache[category]; ok {
+		return id, nil
+	}
+	found, err := s.categoryDao.FindByName(category)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", fmt.Errorf("category %s does not exist", category)
+		}
+		return "", err
+	}
+	cache[category] = found.ID.String()
+	return cache[category], nil
+}
+
+func (s productImportService) categoryIDs(categories []string, cache map[string]string) ([]string, error) {
+	ids := make([]string, 0, len(categories))
+	for _, category := range categories {
+		id, err := s.categoryID(category, cache)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// importOptions returns the product's options, or when the file leaves them out, the
+// options its variants use, with the names in alphabetical order.
+func importOptions(input importDto.ImportProduct) []productDto.OptionInput {
+	options := []productDto.OptionInput{}
+	if input.Options != nil {
+		for _, option := range *input.Options {
+			options = append(options, productDto.OptionInput{Name: option.Name, Values: option.Values})
+		}
+		return options
+	}
+	if input.Variants == nil {
+		return options
+	}
+
+	for _, variant := range *input.Variants {
+		names := make([]string, 0, len(variant.Options))
+		for name := range variant.Options {
+			names = append(names, name)
+		}
+		slices.Sort(names)
+		for _, name := range names {
+			index := slices.IndexFunc(options, func(option productDto.OptionInput) bool { return option.Name == name })
+			if index < 0 {
+				options = append(options, productDto.OptionInput{Name: name})
+				index = len(options) - 1
+			}
+			if !slices.Contains(options[index].Values, variant.Options[name]) {
+				options[index].Values = append(options[index].Values, variant.Options[name])
+			}
+		}
+	}
+	return options
+}
+
+// importVariants turns the imported variants into variant inputs, with the ID of the
+// existing variant with the same SKU.
+func importVariants(input importDto.ImportProduct, existing []models.ProductVariant) []productDto.VariantInput {
+	variants := []productDto.VariantInput{}
+	if input.Variants == nil {
+		return variants
+	}
+	for _, variant := range *input.Variants {
+		item := productDto.VariantInput{
+			SKU:           variant.SKU,
+			Barcode:       variant.Barcode,
+			Options:       variant.Options,
+			PriceOverride: variant.PriceOverride,
+			Stock:         variant.Stock,
+		}
+		for _, current := range existing {
+			if current.SKU == strings.TrimSpace(variant.SKU) {
+				item.ID = current.ID.String()
+				break
+			}
+		}
+		variants = append(variants, item)
+	}
+	return variants
+}
+
+// importImages links the imported image URLs; the first is the primary image. URLs of
+// images uploaded to the product, as found in an export, are skipped as the product
+// keeps its uploads.
+func importImages(input importDto.ImportProduct, existing []models.ProductImage) []productDto.ImageInput {
+	images := []productDto.ImageInput{}
+	if input.Images == nil {
+		return images
+	}
+	for i, url := range *input.Images {
+		uploaded := slices.ContainsFunc(existing, func(image models.ProductImage) bool {
+			return image.StorageKey != "" && image.ImageURL == url
+		})
+		if uploaded {
+			continue
+		}
+		images = append(images, productDto.ImageInput{ImageURL: url, IsPrimary: i == 0})
+	}
+	return images
+}
+
+// importFormat is the requested format, or else the one the file name suggests.
+func importFormat(format string, fileName string) string {
+	format = strings.ToLower(strings.TrimSpace(format))
+	if format != "" {
+		return format
+	}
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".csv":
+		return importDto.FormatCSV
+	case ".jsonl", ".ndjson":
+		return importDto.FormatJSONL
+	}
+	return ""
+}
+
+// toImportProduct describes a product the way it is imported, by names instead of IDs.
+func toImportProduct(product models.Product) importDto.ImportProduct {
+	item := importDto.ImportProduct{
+		Name:         &product.Name,
+		Description:  &product.Description,
+		Price:        &product.Price,
+		Stock:        &product.Stock,
+		Category:     &product.Category.Name,
+		IsDiscounted: &product.IsDiscounted,
+		DiscountPct:  &product.DiscountPct,
+	}
+	if product.Barcode != nil {
+		item.Barcode = *product.Barcode
+	}
+
+	subCategories := []string{}
+	for _, subCategory := range product.SubCategories {
+		subCategories = append(subCategories, subCategory.Name)
+	}
+	item.SubCategories = &subCategories
+	tags := []string{}
+	for _, tag := range product.Tags {
+		tags = append(tags, tag.Name)
+	}
+	item.Tags = &tags
+
+	// The primary image goes first, as the first imported image becomes the primary.
+	images := []string{}
+	for _, image := range product.Images {
+		if image.IsPrimary {
+			images = append([]string{image.ImageURL}, images...)
+		} else {
+			images = append(images, image.ImageURL)
+		}
+	}
+	item.Images = &images
+
+	options := []importDto.ImportOption{}
+	for _, option := range product.Options {
+		values := []string{}
+		for _, value := range option.Values {
+			values = append(values, value.Value)
+		}
+		options = append(options, importDto.ImportOption{Name: option.Name, Values: values})
+	}
+	item.Options = &options
+	variants := []importDto.ImportVariant{}
+	for _, variant := range product.Variants {
+		item := importDto.ImportVariant{
+			SKU:           variant.SKU,
+			Options:       variantOptions(product.Options, variant),
+			PriceOverride: variant.PriceOverride,
+			Stock:         variant.Stock,
+		}
+		if variant.Barcode != nil {
+			item.Barcode = *variant.Barcode
+		}
+		variants = append(variants, item)
+	}
+	item.Variants = &variants
+	return item
+}
+
+func toImportJobResponse(job models.ImportJob) importDto.ImportJobResponse {
+	rowErrors := []importDto.RowError{}
+	for _, rowError := range job.RowErrors {
+		rowErrors = append(rowErrors, importDto.RowError{Line: rowError.Line, Message: rowError.Message})
+	}
+	return importDto.ImportJobResponse{
+		ID:         job.ID.String(),
+		Status:     job.Status,
+		Format:     job.Format,
+		DryRun:     job.DryRun,
+		Total:      job.Total,
+		Processed:  job.Processed,
+		Created:    job.Created,
+		Updated:    job.Updated,
+		Failed:     job.Failed,
+		RowErrors:  rowErrors,
+		Error:      job.Error,
+		CreatedAt:  job.CreatedAt,
+		StartedAt:  job.StartedAt,
+		FinishedAt: job.FinishedAt,
+	}
+}
+
+// StartImportWorker runs the waiting import jobs every interval until the returned stop
+// function is called. Stopping waits for the job in progress to reach its next product;
+// the job goes back to the queue and a later worker starts it over.
+func StartImportWorker(service ProductImportService, interval time.Duration) (stop func()) {
+	ticker := time.NewTicker(interval)
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+
+	go func() {
+		defer close(stopped)
+		for {
+			select {
+			case <-ticker.C:
+				for {
+					found, err := service.RunNextImport(done)
+					if err != nil {
+						log.Errorf("import worker failed: %v", err)
+					}
+					if !found {
+						break
+					}
+					select {
+					case <-done:
+						return
+					default:
+					}
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		ticker.Stop()
+		close(done)
+		<-stopped
+	}
+}
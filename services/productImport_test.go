@@ -0,0 +1,420 @@
+package services
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	importjobdao "github.com/abdulmalikraji/e-commerce/db/dao/importJobDao"
+	"github.com/abdulmalikraji/e-commerce/db/models"
+	"github.com/abdulmalikraji/e-commerce/dto/importDto"
+	"github.com/abdulmalikraji/e-commerce/mocks/dao/importJobDao"
+	"github.com/abdulmalikraji/e-commerce/utils"
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+var importJobMockDao *importJobDao.MockDataAccess
+
+var imports ProductImportService
+
+func setupImports(t *testing.T) func() {
+	teardown := setupProducts(t)
+	importJobMockDao = importJobDao.NewMockDataAccess(gomock.NewController(t))
+	imports = NewProductImportService(importJobMockDao, productMockDao, variantMockDao, categoryMockDao, tagMockDao)
+	return func() {
+		imports = nil
+		teardown()
+	}
+}
+
+func TestProductImportService_StartImport_Groups_CSV_Rows_By_Barcode(t *testing.T) {
+	teardown := setupImports(t)
+	defer teardown()
+
+	file := "barcode,name,price,category,sku,option1_name,option1_value\n" +
+		"M-1,Mug,12,Kitchen,MUG-RED,Color,Red\n" +
+		"M-1,,,,MUG-BLUE,Color,Blue\n" +
+		",Plate,8,Kitchen,,,\n"
+	importJobMockDao.EXPECT().Insert(gomock.Any()).DoAndReturn(func(job models.ImportJob) (models.ImportJob, error) {
+		assert.Equal(t, importDto.FormatCSV, job.Format)
+		assert.Equal(t, models.ImportStatusPending, job.Status)
+		assert.Equal(t, 2, job.Total)
+		assert.True(t, job.DryRun)
+		job.ID = uuid.New()
+		return job, nil
+	})
+
+	response, status, err := imports.StartImport(fiberCtx, importDto.StartImportRequest{
+		StoreID: staffStore.ID.String(),
+		DryRun:  true,
+		File:    utils.File{File: bytes.NewBufferString(file), FileName: "catalog.csv", FileSize: len(file)},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, fiber.StatusAccepted, status)
+	assert.Equal(t, 2, response.Total)
+}
+
+func TestProductImportService_Dry_Run_Reports_Without_Keeping_Changes(t *testing.T) {
+	teardown := setupImports(t)
+	defer teardown()
+
+	job := models.ImportJob{
+		ID:        uuid.New(),
+		StoreID:   staffStore.ID,
+		Format:    importDto.FormatJSONL,
+		DryRun:    true,
+		Status:    models.ImportStatusRunning,
+		Payload:   []byte(`{"barcode":"M-1","name":"Mug","price":12,"category":"Kitchen"}` + "\n" + `{"name":` + "\n"),
+		CreatedBy: staffOwner.UserID,
+	}
+	importJobMockDao.EXPECT().ClaimNext(gomock.Any()).DoAndReturn(func(staleAfter time.Time) (models.ImportJob, bool, error) {
+		assert.True(t, staleAfter.Before(time.Now()))
+		return job, true, nil
+	})
+	productMockDao.EXPECT().WithTx(gomock.Any()).Return(productMockDao).AnyTimes()
+	variantMockDao.EXPECT().WithTx(gomock.Any()).Return(variantMockDao).Times(2)
+	tagMockDao.EXPECT().WithTx(gomock.Any()).Return(tagMockDao)
+	productMockDao.EXPECT().FindByBarcodeAndStore("M-1", staffStore.ID.String()).Return(models.Product{}, gorm.ErrRecordNotFound)
+	categoryMockDao.EXPECT().FindByName("Kitchen").Return(productCategory, nil)
+	categoryMockDao.EXPECT().FindById(productCategory.ID.String()).Return(productCategory, nil)
+	productMockDao.EXPECT().BarcodeTaken("M-1", "").Return(false, nil)
+	variantMockDao.EXPECT().FindBySKUs([]string{}).Return(nil, nil)
+	variantMockDao.EXPECT().FindByBarcodes([]string{}).Return(nil, nil)
+	tagMockDao.EXPECT().FindOrCreate([]string{}, staffOwner.UserID).Return([]models.Tag{}, nil)
+
+	// The product's own transaction runs inside the import's, which the dry run rolls back.
+	var importErr error
+	productMockDao.EXPECT().Transaction(gomock.Any()).DoAndReturn(func(fn func(tx *gorm.DB) error) error {
+		importErr = fn(nil)
+		return importErr
+	})
+	productMockDao.EXPECT().Transaction(gomock.Any()).DoAndReturn(func(fn func(tx *gorm.DB) error) error {
+		return fn(nil)
+	})
+	productMockDao.EXPECT().Insert(gomock.Any()).DoAndReturn(func(product models.Product) (models.Product, error) {
+		assert.Equal(t, productCategory.ID, product.CategoryID)
+		assert.Equal(t, "M-1", *product.Barcode)
+		product.ID = uuid.New()
+		return product, nil
+	})
+	productMockDao.EXPECT().SetClassification(gomock.Any()).Return(nil)
+	importJobMockDao.EXPECT().Finish(gomock.Any()).DoAndReturn(func(finished models.ImportJob) error {
+		assert.Equal(t, models.ImportStatusCompleted, finished.Status)
+		assert.Equal(t, 2, finished.Processed)
+		assert.Equal(t, 1, finished.Created)
+		assert.Equal(t, 1, finished.Failed)
+		assert.Equal(t, 2, finished.RowErrors[0].Line)
+		return nil
+	})
+
+	found, err := imports.RunNextImport(nil)
+
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.ErrorIs(t, importErr, errDryRun)
+}
+
+// runningImport is a job a worker has claimed, with payload as its file.
+func runningImport(format string, payload string) models.ImportJob {
+	startedAt := time.Now()
+	return models.ImportJob{
+		ID:        uuid.New(),
+		StoreID:   staffStore.ID,
+		Format:    format,
+		Status:    models.ImportStatusRunning,
+		Payload:   []byte(payload),
+		CreatedBy: staffOwner.UserID,
+		StartedAt: &startedAt,
+	}
+}
+
+func TestProductImportService_Updates_The_Product_With_A_Matching_SKU(t *testing.T) {
+	teardown := setupImports(t)
+	defer teardown()
+
+	mug := models.Product{ID: uuid.New(), StoreID: staffStore.ID, Name: "Mug", Price: 12, CategoryID: productCategory.ID}
+	variant := models.ProductVariant{ID: uuid.New(), ProductID: mug.ID, SKU: "MUG-1"}
+	mug.Variants = []models.ProductVariant{variant}
+	job := runningImport(importDto.FormatJSONL, `{"price":15,"variants":[{"sku":"MUG-1 ","stock":4}]}`+"\n")
+	importJobMockDao.EXPECT().ClaimNext(gomock.Any()).Return(job, true, nil)
+	productMockDao.EXPECT().Transaction(gomock.Any()).DoAndReturn(func(fn func(tx *gorm.DB) error) error {
+		return fn(nil)
+	}).Times(2)
+	productMockDao.EXPECT().WithTx(gomock.Any()).Return(productMockDao).AnyTimes()
+	variantMockDao.EXPECT().WithTx(gomock.Any()).Return(variantMockDao).AnyTimes()
+	tagMockDao.EXPECT().WithTx(gomock.Any()).Return(tagMockDao).AnyTimes()
+	// Found by SKU as the product has no barcode; the SKU is then checked as its own.
+	variantMockDao.EXPECT().FindBySKUs([]string{"MUG-1"}).Return([]models.ProductVariant{variant}, nil).Times(2)
+	variantMockDao.EXPECT().FindByBarcodes([]string{}).Return(nil, nil)
+	productMockDao.EXPECT().FindByIdAndStore(mug.ID.String(), staffStore.ID.String()).Return(mug, nil).Times(3)
+	productMockDao.EXPECT().UpdateDetails(gomock.Any()).DoAndReturn(func(product models.Product) error {
+		assert.Equal(t, mug.ID, product.ID)
+		assert.Equal(t, 15.0, product.Price)
+		return nil
+	})
+	productMockDao.EXPECT().SaveOptions(mug.ID, gomock.Any()).Return(nil, nil)
+	variantMockDao.EXPECT().UpdateDetails(gomock.Any()).DoAndReturn(func(updated models.ProductVariant) error {
+		assert.Equal(t, variant.ID, updated.ID)
+		assert.Equal(t, 4, updated.Stock)
+		return nil
+	})
+	variantMockDao.EXPECT().ReplaceOptionValues(gomock.Any()).Return(nil)
+	productMockDao.EXPECT().SetClassification(gomock.Any()).Return(nil)
+	importJobMockDao.EXPECT().Finish(gomock.Any()).DoAndReturn(func(finished models.ImportJob) error {
+		assert.Equal(t, 1, finished.Updated)
+		assert.Equal(t, 0, finished.Created)
+		assert.Empty(t, finished.RowErrors)
+		return nil
+	})
+
+	found, err := imports.RunNextImport(nil)
+
+	assert.NoError(t, err)
+	assert.True(t, found)
+}
+
+func TestProductImportService_Reports_Row_Errors_By_Line(t *testing.T) {
+	tests := []struct {
+		name    string
+		format  string
+		payload string
+		lines   []int
+	}{
+		{
+			name:   "csv",
+			format: importDto.FormatCSV,
+			payload: "barcode,name,price,category\n" +
+				"M-1,Mug,cheap,Kitchen\n" +
+				",,9,\n" +
+				"M-2,Plate,8,Kitchen\n" +
+				"M-3,Bowl,9.5.0,Kitchen\n",
+			lines: []int{2, 3, 4, 5},
+		},
+		{
+			name:    "jsonl",
+			format:  importDto.FormatJSONL,
+			payload: "\n" + `{"name":` + "\n" + `{"name":"Mug","colour":"red"}` + "\n",
+			lines:   []int{2, 3},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			teardown := setupImports(t)
+			defer teardown()
+
+			job := runningImport(test.format, test.payload)
+			importJobMockDao.EXPECT().ClaimNext(gomock.Any()).Return(job, true, nil)
+			// The CSV file's only good product fails on its unknown category.
+			if test.format == importDto.FormatCSV {
+				productMockDao.EXPECT().Transaction(gomock.Any()).DoAndReturn(func(fn func(tx *gorm.DB) error) error {
+					return fn(nil)
+				})
+				productMockDao.EXPECT().WithTx(gomock.Any()).Return(productMockDao)
+				variantMockDao.EXPECT().WithTx(gomock.Any()).Return(variantMockDao)
+				tagMockDao.EXPECT().WithTx(gomock.Any()).Return(tagMockDao)
+				productMockDao.EXPECT().FindByBarcodeAndStore("M-2", staffStore.ID.String()).Return(models.Product{}, gorm.ErrRecordNotFound)
+				categoryMockDao.EXPECT().FindByName("Kitchen").Return(models.Category{}, gorm.ErrRecordNotFound)
+			}
+			importJobMockDao.EXPECT().Finish(gomock.Any()).DoAndReturn(func(finished models.ImportJob) error {
+				lines := []int{}
+				for _, rowError := range finished.RowErrors {
+					lines = append(lines, rowError.Line)
+				}
+				assert.Equal(t, test.lines, lines)
+				assert.Equal(t, len(test.lines), finished.Failed)
+				assert.Equal(t, len(test.lines), finished.Processed)
+				return nil
+			})
+
+			_, err := imports.RunNextImport(nil)
+
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestProductImportService_StartImport_Rejects_Too_Many_Products(t *testing.T) {
+	teardown := setupImports(t)
+	defer teardown()
+
+	var file bytes.Buffer
+	for i := 0; i <= importDto.MaxImportProducts; i++ {
+		fmt.Fprintf(&file, `{"name":"Product %d"}`+"\n", i)
+	}
+
+	_, status, err := imports.StartImport(fiberCtx, importDto.StartImportRequest{
+		StoreID: staffStore.ID.String(),
+		File:    utils.File{File: &file, FileName: "catalog.jsonl", FileSize: file.Len()},
+	})
+
+	assert.Error(t, err)
+	assert.Equal(t, fiber.StatusBadRequest, status)
+}
+
+func TestProductImportService_Keeps_At_Most_MaxRowErrors(t *testing.T) {
+	teardown := setupImports(t)
+	defer teardown()
+
+	bad := importDto.MaxRowErrors + 20
+	job := runningImport(importDto.FormatJSONL, strings.Repeat(`{"name":`+"\n", bad))
+	importJobMockDao.EXPECT().ClaimNext(gomock.Any()).Return(job, true, nil)
+	importJobMockDao.EXPECT().Finish(gomock.Any()).DoAndReturn(func(finished models.ImportJob) error {
+		assert.Equal(t, bad, finished.Failed)
+		assert.Len(t, finished.RowErrors, importDto.MaxRowErrors)
+		return nil
+	})
+
+	_, err := imports.RunNextImport(nil)
+
+	assert.NoError(t, err)
+}
+
+func TestProductImportService_Dry_Run_Reports_A_Repeated_SKU_Once(t *testing.T) {
+	teardown := setupImports(t)
+	defer teardown()
+
+	// Only the first product is imported; the second would overwrite it.
+	job := runningImport(importDto.FormatJSONL,
+		`{"name":"Mug","price":12,"category":"Kitchen","variants":[{"sku":"MUG-1"}]}`+"\n"+
+			`{"name":"Mug","price":14,"category":"Kitchen","variants":[{"sku":"MUG-1 "}]}`+"\n")
+	job.DryRun = true
+	importJobMockDao.EXPECT().ClaimNext(gomock.Any()).Return(job, true, nil)
+	productMockDao.EXPECT().Transaction(gomock.Any()).DoAndReturn(func(fn func(tx *gorm.DB) error) error {
+		return fn(nil)
+	})
+	productMockDao.EXPECT().WithTx(gomock.Any()).Return(productMockDao)
+	variantMockDao.EXPECT().WithTx(gomock.Any()).Return(variantMockDao)
+	tagMockDao.EXPECT().WithTx(gomock.Any()).Return(tagMockDao)
+	variantMockDao.EXPECT().FindBySKUs([]string{"MUG-1"}).Return(nil, nil)
+	categoryMockDao.EXPECT().FindByName("Kitchen").Return(models.Category{}, gorm.ErrRecordNotFound)
+	importJobMockDao.EXPECT().Finish(gomock.Any()).DoAndReturn(func(finished models.ImportJob) error {
+		assert.Equal(t, 0, finished.Created)
+		assert.Equal(t, 2, finished.Failed)
+		assert.Equal(t, 2, finished.RowErrors[1].Line)
+		assert.Equal(t, "sku MUG-1 is already used on line 1", finished.RowErrors[1].Message)
+		return nil
+	})
+
+	_, err := imports.RunNextImport(nil)
+
+	assert.NoError(t, err)
+}
+
+func TestProductImportService_Stopped_Import_Is_Requeued(t *testing.T) {
+	teardown := setupImports(t)
+	defer teardown()
+
+	job := runningImport(importDto.FormatJSONL, `{"name":"Mug","price":12,"category":"Kitchen"}`+"\n")
+	importJobMockDao.EXPECT().ClaimNext(gomock.Any()).Return(job, true, nil)
+	importJobMockDao.EXPECT().Requeue(gomock.Any()).DoAndReturn(func(requeued models.ImportJob) error {
+		assert.Equal(t, job.ID, requeued.ID)
+		assert.Equal(t, job.StartedAt, requeued.StartedAt)
+		return nil
+	})
+	stop := make(chan struct{})
+	close(stop)
+
+	found, err := imports.RunNextImport(stop)
+
+	assert.NoError(t, err)
+	assert.True(t, found)
+}
+
+func TestProductImportService_Taken_Over_Import_Is_Abandoned(t *testing.T) {
+	teardown := setupImports(t)
+	defer teardown()
+
+	job := runningImport(importDto.FormatJSONL, `{"name":`+"\n")
+	importJobMockDao.EXPECT().ClaimNext(gomock.Any()).Return(job, true, nil)
+	importJobMockDao.EXPECT().Finish(gomock.Any()).Return(importjobdao.ErrJobLost)
+
+	found, err := imports.RunNextImport(nil)
+
+	assert.NoError(t, err)
+	assert.True(t, found)
+}
+
+// blockingImports runs one import that only returns once it is stopped.
+type blockingImports struct {
+	ProductImportService
+	started  chan struct{}
+	finished atomic.Bool
+}
+
+func (b *blockingImports) RunNextImport(stop <-chan struct{}) (bool, error) {
+	close(b.started)
+	<-stop
+	time.Sleep(10 * time.Millisecond)
+	b.finished.Store(true)
+	return true, nil
+}
+
+func TestStartImportWorker_Stop_Waits_For_The_Running_Import(t *testing.T) {
+	service := &blockingImports{started: make(chan struct{})}
+	stop := StartImportWorker(service, time.Millisecond)
+	<-service.started
+
+	stop()
+
+	assert.True(t, service.finished.Load())
+}
+
+func TestProductImportService_Export_Fails_Before_Streaming(t *testing.T) {
+	teardown := setupImports(t)
+	defer teardown()
+
+	productMockDao.EXPECT().FindBatchByStore(staffStore.ID.String(), uuid.Nil, exportBatchSize).Return(nil, errors.New("connection refused"))
+
+	_, status, err := imports.ExportProducts(fiberCtx, importDto.ExportRequest{StoreID: staffStore.ID.String()})
+
+	assert.Error(t, err)
+	assert.Equal(t, fiber.StatusInternalServerError, status)
+}
+
+func TestProductImportService_Export_Writes_The_Catalog(t *testing.T) {
+	teardown := setupImports(t)
+	defer teardown()
+
+	barcode := "M-1"
+	mug := models.Product{ID: uuid.New(), Name: "Mug", Price: 12, Barcode: &barcode, Category: productCategory}
+	productMockDao.EXPECT().FindBatchByStore(staffStore.ID.String(), uuid.Nil, exportBatchSize).Return([]models.Product{mug}, nil)
+
+	response, status, err := imports.ExportProducts(fiberCtx, importDto.ExportRequest{StoreID: staffStore.ID.String()})
+	assert.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, status)
+
+	var out bytes.Buffer
+	assert.NoError(t, response.Write(&out))
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	assert.Len(t, lines, 2)
+	assert.Equal(t, strings.Join(importDto.CSVColumns, ","), lines[0])
+	assert.True(t, strings.HasPrefix(lines[1], "M-1,Mug,"))
+}
+
+func TestProductImportService_Export_Reports_A_Failed_Batch(t *testing.T) {
+	teardown := setupImports(t)
+	defer teardown()
+
+	batch := make([]models.Product, exportBatchSize)
+	for i := range batch {
+		batch[i] = models.Product{ID: uuid.New(), Name: fmt.Sprintf("Product %d", i), Category: productCategory}
+	}
+	productMockDao.EXPECT().FindBatchByStore(staffStore.ID.String(), uuid.Nil, exportBatchSize).Return(batch, nil)
+	productMockDao.EXPECT().FindBatchByStore(staffStore.ID.String(), batch[len(batch)-1].ID, exportBatchSize).Return(nil, errors.New("connection reset"))
+
+	response, _, err := imports.ExportProducts(fiberCtx, importDto.ExportRequest{StoreID: staffStore.ID.String(), Format: importDto.FormatJSONL})
+	assert.NoError(t, err)
+
+	var out bytes.Buffer
+	assert.Error(t, response.Write(&out))
+	assert.Equal(t, exportBatchSize, strings.Count(out.String(), "\n"))
+}
@@ -0,0 +1,267 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/abdulmalikraji/e-commerce/db/models"
+	"github.com/abdulmalikraji/e-commerce/dto/productDto"
+	"github.com/abdulmalikraji/e-commerce/mocks/dao/categoryDao"
+	"github.com/abdulmalikraji/e-commerce/mocks/dao/productDao"
+	"github.com/abdulmalikraji/e-commerce/mocks/dao/productVariantDao"
+	"github.com/abdulmalikraji/e-commerce/mocks/dao/tagDao"
+	"github.com/abdulmalikraji/e-commerce/utils"
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+	"gorm.io/gorm"
+)
+
+var productMockDao *productDao.MockDataAccess
+var variantMockDao *productVariantDao.MockDataAccess
+var categoryMockDao *categoryDao.MockDataAccess
+var tagMockDao *tagDao.MockDataAccess
+
+var products ProductService
+
+var productCategory = newCategory("Kitchen", nil)
+
+// newCategory returns a category under parent, or a root category, with its path set.
+func newCategory(name string, parent *models.Category) models.Category {
+	category := models.Category{ID: uuid.New(), Name: name}
+	if parent != nil {
+		category.ParentID = &parent.ID
+		category.Depth = parent.Depth + 1
+	}
+	category.Path = models.CategoryPath(category.ID, parent)
+	return category
+}
+
+func setupProducts(t *testing.T) func() {
+	ct := gomock.NewController(t)
+
+	app := fiber.New()
+	fiberCtx = app.AcquireCtx(&fasthttp.RequestCtx{})
+	utils.SetPrincipal(fiberCtx, staffOwner)
+
+	productMockDao = productDao.NewMockDataAccess(ct)
+	variantMockDao = productVariantDao.NewMockDataAccess(ct)
+	categoryMockDao = categoryDao.NewMockDataAccess(ct)
+	tagMockDao = tagDao.NewMockDataAccess(ct)
+
+	products = NewProductService(productMockDao, variantMockDao, categoryMockDao, tagMockDao)
+	return func() {
+		products = nil
+		app.ReleaseCtx(fiberCtx)
+		ct.Finish()
+	}
+}
+
+func TestProductService_Create_Records_The_Creator(t *testing.T) {
+	teardown := setupProducts(t)
+	defer teardown()
+
+	mugs := newCategory("Mugs", &productCategory)
+	tag := models.Tag{ID: uuid.New(), Name: "ceramic"}
+	categoryMockDao.EXPECT().FindById(productCategory.ID.String()).Return(productCategory, nil)
+	categoryMockDao.EXPECT().FindById(mugs.ID.String()).Return(mugs, nil)
+	variantMockDao.EXPECT().FindBySKUs([]string{"MUG-RED"}).Return(nil, nil)
+	variantMockDao.EXPECT().FindByBarcodes([]string{}).Return(nil, nil)
+	tagMockDao.EXPECT().FindOrCreate([]string{"ceramic"}, staffOwner.UserID).Return([]models.Tag{tag}, nil)
+	productMockDao.EXPECT().Transaction(gomock.Any()).DoAndReturn(func(fn func(tx *gorm.DB) error) error {
+		return fn(nil)
+	})
+	productMockDao.EXPECT().WithTx(gomock.Any()).Return(productMockDao)
+	productMockDao.EXPECT().Insert(gomock.Any()).DoAndReturn(func(product models.Product) (models.Product, error) {
+		assert.Equal(t, staffStore.ID, product.StoreID)
+		assert.Equal(t, staffOwner.UserID, *product.CreatedBy)
+		assert.True(t, product.HasVariants)
+		assert.True(t, product.Images[0].IsPrimary)
+		product.ID = uuid.New()
+		return product, nil
+	})
+	productMockDao.EXPECT().SetClassification(gomock.Any()).DoAndReturn(func(product models.Product) error {
+		assert.Equal(t, []models.Category{mugs}, product.SubCategories)
+		assert.Equal(t, []models.Tag{tag}, product.Tags)
+		return nil
+	})
+	red := models.ProductOptionValue{ID: uuid.New(), Value: "red"}
+	productMockDao.EXPECT().SaveOptions(gomock.Any(), gomock.Any()).DoAndReturn(func(productID uuid.UUID, options []models.ProductOption) ([]models.ProductOption, error) {
+		assert.Equal(t, "color", options[0].Name)
+		return []models.ProductOption{{ID: uuid.New(), ProductID: productID, Name: "color", Values: []models.ProductOptionValue{red}}}, nil
+	})
+	variantMockDao.EXPECT().WithTx(gomock.Any()).Return(variantMockDao)
+	variantMockDao.EXPECT().Insert(gomock.Any()).DoAndReturn(func(variant models.ProductVariant) (models.ProductVariant, error) {
+		assert.Equal(t, []models.ProductOptionValue{red}, variant.OptionValues)
+		variant.ID = uuid.New()
+		return variant, nil
+	})
+
+	response, status, err := products.CreateProduct(fiberCtx, productDto.CreateProductRequest{
+		StoreID:        staffStore.ID.String(),
+		Name:           "Mug",
+		Price:          12,
+		CategoryID:     productCategory.ID.String(),
+		SubCategoryIDs: []string{mugs.ID.String()},
+		Options:        []productDto.OptionInput{{Name: "color", Values: []string{"red"}}},
+		Variants:       []productDto.VariantInput{{SKU: "MUG-RED", Options: map[string]string{"color": "red"}, Stock: 3}},
+		Images:         []productDto.ImageInput{{ImageURL: "https://cdn.example.com/mug.png"}},
+		Tags:           []string{"ceramic", "Ceramic"},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, fiber.StatusCreated, status)
+	assert.Equal(t, staffOwner.UserID.String(), response.CreatedBy)
+	assert.Equal(t, []string{mugs.ID.String()}, response.SubCategoryIDs)
+	assert.Equal(t, []string{"ceramic"}, response.Tags)
+	assert.Equal(t, map[string]string{"color": "red"}, response.Variants[0].Options)
+}
+
+func TestProductService_Create_Rejects_Variant_Missing_An_Option(t *testing.T) {
+	teardown := setupProducts(t)
+	defer teardown()
+
+	categoryMockDao.EXPECT().FindById(productCategory.ID.String()).Return(productCategory, nil)
+
+	_, status, err := products.CreateProduct(fiberCtx, productDto.CreateProductRequest{
+		StoreID:    staffStore.ID.String(),
+		Name:       "Mug",
+		Price:      12,
+		CategoryID: productCategory.ID.String(),
+		Options: []productDto.OptionInput{
+			{Name: "color", Values: []string{"red", "blue"}},
+			{Name: "size", Values: []string{"S", "L"}},
+		},
+		Variants: []productDto.VariantInput{{SKU: "MUG-RED", Options: map[string]string{"color": "red"}}},
+	})
+
+	assert.Error(t, err)
+	assert.Equal(t, fiber.StatusBadRequest, status)
+}
+
+func TestProductService_Create_Rejects_Subcategory_Of_Another_Category(t *testing.T) {
+	teardown := setupProducts(t)
+	defer teardown()
+
+	lighting := newCategory("Lighting", nil)
+	other := newCategory("Lamps", &lighting)
+	categoryMockDao.EXPECT().FindById(productCategory.ID.String()).Return(productCategory, nil)
+	categoryMockDao.EXPECT().FindById(other.ID.String()).Return(other, nil)
+
+	_, status, err := products.CreateProduct(fiberCtx, productDto.CreateProductRequest{
+		StoreID:        staffStore.ID.String(),
+		Name:           "Mug",
+		Price:          12,
+		CategoryID:     productCategory.ID.String(),
+		SubCategoryIDs: []string{other.ID.String()},
+	})
+
+	assert.Error(t, err)
+	assert.Equal(t, fiber.StatusBadRequest, status)
+}
+
+func TestProductService_Update_Deletes_Variants_Left_Out(t *testing.T) {
+	teardown := setupProducts(t)
+	defer teardown()
+
+	product := models.Product{ID: uuid.New(), StoreID: staffStore.ID, CategoryID: productCategory.ID, Name: "Mug", Price: 12, HasVariants: true}
+	red := models.ProductVariant{ID: uuid.New(), ProductID: product.ID, SKU: "MUG-RED", Stock: 3}
+	blue := models.ProductVariant{ID: uuid.New(), ProductID: product.ID, SKU: "MUG-BLUE", Stock: 1}
+	product.Variants = []models.ProductVariant{red, blue}
+
+	productMockDao.EXPECT().FindByIdAndStore(product.ID.String(), staffStore.ID.String()).Return(product, nil).Times(2)
+	variantMockDao.EXPECT().FindBySKUs([]string{"MUG-RED"}).Return([]models.ProductVariant{red}, nil)
+	variantMockDao.EXPECT().FindByBarcodes([]string{}).Return(nil, nil)
+	productMockDao.EXPECT().Transaction(gomock.Any()).DoAndReturn(func(fn func(tx *gorm.DB) error) error {
+		return fn(nil)
+	})
+	productMockDao.EXPECT().WithTx(gomock.Any()).Return(productMockDao)
+	variantMockDao.EXPECT().WithTx(gomock.Any()).Return(variantMockDao)
+	productMockDao.EXPECT().UpdateDetails(gomock.Any()).DoAndReturn(func(product models.Product) error {
+		assert.Equal(t, staffOwner.UserID, *product.UpdatedBy)
+		return nil
+	})
+	variantMockDao.EXPECT().UpdateDetails(gomock.Any()).DoAndReturn(func(variant models.ProductVariant) error {
+		assert.Equal(t, red.ID, variant.ID)
+		assert.Equal(t, 5, variant.Stock)
+		return nil
+	})
+	variantMockDao.EXPECT().ReplaceOptionValues(gomock.Any()).Return(nil)
+	variantMockDao.EXPECT().SoftDelete(blue.ID.String()).Return(nil)
+	productMockDao.EXPECT().SetClassification(gomock.Any()).Return(nil)
+
+	variants := []productDto.VariantInput{{ID: red.ID.String(), SKU: "MUG-RED", Stock: 5}}
+	_, status, err := products.UpdateProduct(fiberCtx, productDto.UpdateProductRequest{
+		StoreID:   staffStore.ID.String(),
+		ProductID: product.ID.String(),
+		Variants:  &variants,
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, status)
+}
+
+func TestProductService_GenerateVariants_Flags_SKUs_In_Use(t *testing.T) {
+	teardown := setupProducts(t)
+	defer teardown()
+
+	red, blue := models.ProductOptionValue{ID: uuid.New(), Value: "Red"}, models.ProductOptionValue{ID: uuid.New(), Value: "Navy blue"}
+	small, large := models.ProductOptionValue{ID: uuid.New(), Value: "S"}, models.ProductOptionValue{ID: uuid.New(), Value: "L"}
+	product := models.Product{ID: uuid.New(), StoreID: staffStore.ID, Name: "Mug", HasVariants: true, Options: []models.ProductOption{
+		{ID: uuid.New(), Name: "color", Values: []models.ProductOptionValue{red, blue}},
+		{ID: uuid.New(), Name: "size", Values: []models.ProductOptionValue{small, large}},
+	}}
+	redSmall := models.ProductVariant{ID: uuid.New(), ProductID: product.ID, SKU: "MUG-RS", OptionValues: []models.ProductOptionValue{small, red}}
+	product.Variants = []models.ProductVariant{redSmall}
+
+	productMockDao.EXPECT().FindByIdAndStore(product.ID.String(), staffStore.ID.String()).Return(product, nil)
+	variantMockDao.EXPECT().FindBySKUs(gomock.Any()).DoAndReturn(func(skus []string) ([]models.ProductVariant, error) {
+		assert.ElementsMatch(t, []string{"MUG-RED-L", "MUG-NAVY-BLUE-S", "MUG-NAVY-BLUE-L"}, skus)
+		return []models.ProductVariant{{ID: uuid.New(), SKU: "MUG-NAVY-BLUE-L"}}, nil
+	})
+
+	response, status, err := products.GenerateVariants(fiberCtx, productDto.GenerateVariantsRequest{
+		StoreID:   staffStore.ID.String(),
+		ProductID: product.ID.String(),
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, status)
+	assert.Len(t, response.Combinations, 4)
+	assert.Equal(t, redSmall.ID.String(), response.Combinations[0].VariantID)
+	assert.Equal(t, map[string]string{"color": "Navy blue", "size": "L"}, response.Combinations[3].Options)
+	assert.NotEmpty(t, response.Combinations[3].Conflict)
+	assert.Empty(t, response.Combinations[1].Conflict)
+}
+
+func TestProductService_List_Applies_Defaults(t *testing.T) {
+	teardown := setupProducts(t)
+	defer teardown()
+
+	mugs, cups := uuid.New().String(), uuid.New().String()
+	productMockDao.EXPECT().FindByFilter(gomock.Any()).DoAndReturn(func(filter productDto.ProductFilter) ([]models.Product, int64, error) {
+		assert.Equal(t, 1, filter.Page)
+		assert.Equal(t, productDto.DefaultPageSize, filter.PageSize)
+		assert.Equal(t, productDto.SortNewest, filter.Sort)
+		assert.Equal(t, []string{mugs, cups}, filter.SubCatIDs)
+		return []models.Product{{ID: uuid.New(), Name: "Mug"}}, 41, nil
+	})
+
+	response, status, err := products.ListProducts(fiberCtx, productDto.ProductFilter{SubCatIDs: []string{mugs + "," + cups}})
+
+	assert.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, status)
+	assert.Equal(t, int64(41), response.Total)
+	assert.Len(t, response.Products, 1)
+}
+
+func TestProductService_List_Rejects_Unknown_Sort(t *testing.T) {
+	teardown := setupProducts(t)
+	defer teardown()
+
+	_, status, err := products.ListProducts(fiberCtx, productDto.ProductFilter{Sort: "cheapest"})
+
+	assert.Error(t, err)
+	assert.Equal(t, fiber.StatusBadRequest, status)
+}
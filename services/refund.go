@@ -0,0 +1,473 @@
+package services
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/abdulmalikraji/e-commerce/db/dao/orderDao"
+	"github.com/abdulmalikraji/e-commerce/db/dao/paymentDao"
+	"github.com/abdulmalikraji/e-commerce/db/dao/refundDao"
+	"github.com/abdulmalikraji/e-commerce/db/dao/stockReservationDao"
+	"github.com/abdulmalikraji/e-commerce/db/models"
+	"github.com/abdulmalikraji/e-commerce/dto/refundDto"
+	"github.com/abdulmalikraji/e-commerce/payments"
+	"github.com/abdulmalikraji/e-commerce/utils"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/log"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type RefundService interface {
+	RequestRefund(ctx *fiber.Ctx, request refundDto.CreateRefundRequest) (refundDto.RefundResponse, int, error)
+	GetOrderRefunds(ctx *fiber.Ctx, request refundDto.GetOrderRefundsRequest) ([]refundDto.RefundResponse, int, error)
+	ListStoreRefunds(ctx *fiber.Ctx, request refundDto.ListStoreRefundsRequest) ([]refundDto.RefundResponse, int, error)
+	ReviewRefund(ctx *fiber.Ctx, request refundDto.ReviewRefundRequest) (refundDto.RefundResponse, int, error)
+}
+
+type refundService struct {
+	orderDao       orderDao.DataAccess
+	refundDao      refundDao.DataAccess
+	paymentDao     paymentDao.DataAccess
+	reservationDao stockReservationDao.DataAccess
+	providers      payments.Registry
+}
+
+func NewRefundService(
+	orderDao orderDao.DataAccess,
+	refundDao refundDao.DataAccess,
+	paymentDao paymentDao.DataAccess,
+	reservationDao stockReservationDao.DataAccess,
+	providers payments.Registry,
+) RefundService {
+	return refundService{
+		orderDao:       orderDao,
+		refundDao:      refundDao,
+		paymentDao:     paymentDao,
+		reservationDao: reservationDao,
+		providers:      providers,
+	}
+}
+
+// RequestRefund lets a buyer ask for money back on some or all units of the items of a
+// paid order. Each refund covers items of a single store, since that store approves it.
+func (s refundService) RequestRefund(ctx *fiber.Ctx, request refundDto.CreateRefundRequest) (refundDto.RefundResponse, int, error) {
+	userID, err := utils.GetUserID(ctx)
+	if err != nil {
+		return refundDto.RefundResponse{}, fiber.StatusUnauthorized, err
+	}
+
+	if len(request.Items) == 0 {
+		return refundDto.RefundResponse{}, fiber.StatusBadRequest, fiber.NewError(fiber.StatusBadRequest, "At least one item is required")
+	}
+
+	order, err := s.orderDao.FindById(request.OrderID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return refundDto.RefundResponse{}, fiber.StatusNotFound, fiber.NewError(fiber.StatusNotFound, "Order not found")
+		}
+		return refundDto.RefundResponse{}, fiber.StatusInternalServerError, err
+	}
+	if order.BuyerID != userID {
+		return refundDto.RefundResponse{}, fiber.StatusForbidden, fiber.NewError(fiber.StatusForbidden, "You are not allowed to refund this order")
+	}
+	if !orderIsPaid(order.Status) {
+		return refundDto.RefundResponse{}, fiber.StatusConflict, fiber.NewError(fiber.StatusConflict, "Order is "+order.Status+" and cannot be refunded")
+	}
+
+	var refund models.Refund
+	err = s.orderDao.Transaction(func(tx *gorm.DB) error {
+		payment, err := lockCapturedPayment(tx, order.ID)
+		if err != nil {
+			return err
+		}
+		refundedQty, err := refundedQuantities(tx, order.ID)
+		if err != nil {
+			return err
+		}
+
+		refund, err = priceRefund(tx, order, payment, refundedQty, request.Items)
+		if err != nil {
+			return err
+		}
+		refund.Reason = request.Reason
+		refund.Status = models.RefundPending
+		refund.RequestedBy = userID
+
+		return tx.Table(refund.TableName()).Create(&refund).Error
+	})
+	if err != nil {
+		return refundDto.RefundResponse{}, errorStatus(err), err
+	}
+
+	log.Infof("refund %s of %.2f requested for order %s by user_id=%s", refund.ID.String(), refund.Amount, order.ID.String(), userID.String())
+	return toRefundResponse(refund), fiber.StatusCreated, nil
+}
+
+// lockCapturedPayment loads the order's captured payment inside tx. Locking it
+// serialises refunds against the same money.
+func lockCapturedPayment(tx *gorm.DB, orderID uuid.UUID) (models.Payment, error) {
+	var payment models.Payment
+	res := tx.Table(payment.TableName()).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("order_id = ? AND status = ? AND del_flg = ?", orderID, models.PaymentSuccessful, false).
+		Order("created_at DESC").
+		First(&payment)
+	if res.Error != nil {
+		if errors.Is(res.Error, gorm.ErrRecordNotFound) {
+			return payment, fiber.NewError(fiber.StatusConflict, "Order has no captured payment to refund")
+		}
+		return payment, res.Error
+	}
+	return payment, nil
+}
+
+// priceRefund builds a refund of the requested units of the order's items against its
+// locked payment, given the units earlier refunds already cover. Item amounts are the unit
+// prices scaled to what was actually paid, so order discounts are shared out and the
+// refunds of a payment can never add up to more than its amount.
+func priceRefund(tx *gorm.DB, order models.Order, payment models.Payment, refundedQty map[uuid.UUID]int, lines []refundDto.RefundItemRequest) (models.Refund, error) {
+	orderItems := map[string]models.OrderItem{}
+	itemsTotal := 0.0
+	for _, item := range order.Items {
+		orderItems[item.ID.String()] = item
+		itemsTotal += float64(item.Quantity) * item.UnitPrice
+	}
+
+	var refundedAmount float64
+	res := tx.Table(models.Refund{}.TableName()).
+		Select("COALESCE(SUM(amount), 0)").
+		Where("payment_id = ? AND status <> ? AND del_flg = ?", payment.ID, models.RefundRejected, false).
+		Scan(&refundedAmount)
+	if res.Error != nil {
+		return models.Refund{}, res.Error
+	}
+
+	ratio := 1.0
+	if itemsTotal > 0 {
+		ratio = payment.Amount / itemsTotal
+	}
+
+	refund := models.Refund{
+		PaymentID: payment.ID,
+		OrderID:   order.ID,
+	}
+	seen := map[string]bool{}
+	for i, line := range lines {
+		item, ok := orderItems[line.OrderItemID]
+		if !ok {
+			return models.Refund{}, fiber.NewError(fiber.StatusBadRequest, "Item "+line.OrderItemID+" is not part of this order")
+		}
+		if seen[line.OrderItemID] {
+			return models.Refund{}, fiber.NewError(fiber.StatusBadRequest, "Item "+line.OrderItemID+" is listed more than once")
+		}
+		seen[line.OrderItemID] = true
+		if i == 0 {
+			refund.StoreID = item.StoreID
+		} else if item.StoreID != refund.StoreID {
+			return models.Refund{}, fiber.NewError(fiber.StatusBadRequest, "A refund can only cover items from one store")
+		}
+
+		left := item.Quantity - refundedQty[item.ID]
+		quantity := line.Quantity
+		if quantity == 0 {
+			quantity = left
+		}
+		if quantity < 0 {
+			return models.Refund{}, fiber.NewError(fiber.StatusBadRequest, "quantity cannot be negative")
+		}
+		if quantity == 0 || quantity > left {
+			return models.Refund{}, fiber.NewError(fiber.StatusConflict, "Only "+strconv.Itoa(left)+" unit(s) of item "+line.OrderItemID+" can still be refunded")
+		}
+
+		amount := utils.Round(float64(quantity)*item.UnitPrice*ratio, 2)
+		refund.Amount += amount
+		refund.Items = append(refund.Items, models.RefundItem{
+			OrderItemID: item.ID,
+			Quantity:    quantity,
+			Amount:      amount,
+		})
+	}
+	refund.Amount = utils.Round(refund.Amount, 2)
+
+	available := utils.Round(payment.Amount-refundedAmount, 2)
+	if refund.Amount > available {
+		// Rounding each line may overshoot the last cent or two of the payment.
+		if refund.Amount-available > 0.01*float64(len(refund.Items)) {
+			return models.Refund{}, fiber.NewError(fiber.StatusConflict, "Refund would exceed the amount paid")
+		}
+		// Take the overshoot off the last lines so the items still add up to the refund.
+		over := utils.Round(refund.Amount-available, 2)
+		for i := len(refund.Items) - 1; i >= 0 && over > 0; i-- {
+			cut := math.Min(over, refund.Items[i].Amount)
+			refund.Items[i].Amount = utils.Round(refund.Items[i].Amount-cut, 2)
+			over = utils.Round(over-cut, 2)
+		}
+		refund.Amount = available
+	}
+	return refund, nil
+}
+
+func (s refundService) GetOrderRefunds(ctx *fiber.Ctx, request refundDto.GetOrderRefundsRequest) ([]refundDto.RefundResponse, int, error) {
+	userID, err := utils.GetUserID(ctx)
+	if err != nil {
+		return nil, fiber.StatusUnauthorized, err
+	}
+
+	order, err := s.orderDao.FindById(request.OrderID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fiber.StatusNotFound, fiber.NewError(fiber.StatusNotFound, "Order not found")
+		}
+		return nil, fiber.StatusInternalServerError, err
+	}
+	if order.BuyerID != userID {
+		return nil, fiber.StatusForbidden, fiber.NewError(fiber.StatusForbidden, "You are not allowed to view this order")
+	}
+
+	refunds, err := s.refundDao.FindByOrderId(order.ID.String())
+	if err != nil {
+		return nil, fiber.StatusInternalServerError, err
+	}
+
+	response := []refundDto.RefundResponse{}
+	for _, refund := range refunds {
+		response = append(response, toRefundResponse(refund))
+	}
+	return response, fiber.StatusOK, nil
+}
+
+func (s refundService) ListStoreRefunds(ctx *fiber.Ctx, request refundDto.ListStoreRefundsRequest) ([]refundDto.RefundResponse, int, error) {
+	var status *string
+	if request.Status != "" {
+		switch request.Status {
+		case models.RefundPending, models.RefundApproved, models.RefundRejected, models.RefundProcessed:
+		default:
+			return nil, fiber.StatusBadRequest, fiber.NewError(fiber.StatusBadRequest, "Unknown refund status "+request.Status)
+		}
+		status = &request.Status
+	}
+
+	refunds, err := s.refundDao.FindByStoreId(request.StoreID, status)
+	if err != nil {
+		return nil, fiber.StatusInternalServerError, err
+	}
+
+	response := []refundDto.RefundResponse{}
+	for _, refund := range refunds {
+		response = append(response, toRefundResponse(refund))
+	}
+	return response, fiber.StatusOK, nil
+}
+
+// ReviewRefund approves or rejects a pending refund for the store. Approval claims the
+// refund first so it cannot be paid out twice, then moves the money through the payment
+// provider and only afterwards records it as processed, restocking returned units and
+// closing out the payment and order once everything paid has been refunded.
+func (s refundService) ReviewRefund(ctx *fiber.Ctx, request refundDto.ReviewRefundRequest) (refundDto.RefundResponse, int, error) {
+	userID, err := utils.GetUserID(ctx)
+	if err != nil {
+		return refundDto.RefundResponse{}, fiber.StatusUnauthorized, err
+	}
+
+	to := models.RefundRejected
+	if request.Approve {
+		to = models.RefundApproved
+	}
+
+	var refund models.Refund
+	err = s.orderDao.Transaction(func(tx *gorm.DB) error {
+		res := tx.Table(refund.TableName()).
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND store_id = ? AND del_flg = ?", request.RefundID, request.StoreID, false).
+			First(&refund)
+		if res.Error != nil {
+			if errors.Is(res.Error, gorm.ErrRecordNotFound) {
+				return fiber.NewError(fiber.StatusNotFound, "Refund not found")
+			}
+			return res.Error
+		}
+		if refund.Status != models.RefundPending {
+			return fiber.NewError(fiber.StatusConflict, "Refund is already "+refund.Status)
+		}
+
+		return tx.Table(refund.TableName()).
+			Where("id = ?", refund.ID).
+			Updates(map[string]interface{}{
+				"status":      to,
+				"reviewed_by": userID,
+				"review_note": request.Note,
+			}).Error
+	})
+	if err != nil {
+		return refundDto.RefundResponse{}, errorStatus(err), err
+	}
+
+	if request.Approve {
+		if status, err := s.executeRefund(refund, request.Restock, userID); err != nil {
+			return refundDto.RefundResponse{}, status, err
+		}
+	}
+
+	log.Infof("refund %s %s by user_id=%s", refund.ID.String(), to, userID.String())
+	refund, err = s.refundDao.FindByIdAndStore(request.RefundID, request.StoreID)
+	if err != nil {
+		return refundDto.RefundResponse{}, fiber.StatusInternalServerError, err
+	}
+	return toRefundResponse(refund), fiber.StatusOK, nil
+}
+
+// executeRefund pays out an approved refund and records the outcome.
+func (s refundService) executeRefund(refund models.Refund, restock bool, userID uuid.UUID) (int, error) {
+	payment, err := s.paymentDao.FindById(refund.PaymentID.String())
+	if err != nil {
+		return fiber.StatusInternalServerError, err
+	}
+
+	provider, err := s.providers.Get(payment.Provider)
+	if err == nil {
+		_, err = provider.Refund(payment.TransactionRef, refund.Amount)
+	}
+	if err != nil {
+		log.Errorf("provider refund failed for refund_id=%s: %v", refund.ID.String(), err)
+		// Hand the refund back for another review rather than leaving it half done.
+		res := s.orderDao.Transaction(func(tx *gorm.DB) error {
+			return tx.Table(refund.TableName()).
+				Where("id = ? AND status = ?", refund.ID, models.RefundApproved).
+				Update("status", models.RefundPending).Error
+		})
+		if res != nil {
+			log.Errorf("failed to reopen refund_id=%s: %v", refund.ID.String(), res)
+		}
+		return fiber.StatusBadGateway, fiber.NewError(fiber.StatusBadGateway, "Payment provider error: "+err.Error())
+	}
+
+	err = s.orderDao.Transaction(func(tx *gorm.DB) error {
+		return completeRefund(tx, s.reservationDao, refund.ID, payment, restock, userID)
+	})
+	if err != nil {
+		// The money is already back with the buyer; this needs a manual fix-up.
+		log.Errorf("refund_id=%s paid out but could not be recorded: %v", refund.ID.String(), err)
+		return fiber.StatusInternalServerError, err
+	}
+	return fiber.StatusOK, nil
+}
+
+// completeRefund marks an approved refund processed inside tx, restocks its units when
+// the goods came back and, once the payment is refunded in full, moves the payment and
+// order to refunded. A partial refund rolls the fulfillments up into the order again.
+func completeRefund(tx *gorm.DB, reservationDao stockReservationDao.DataAccess, refundID uuid.UUID, payment models.Payment, restock bool, userID uuid.UUID) error {
+	res := tx.Table(models.Refund{}.TableName()).
+		Where("id = ?", refundID).
+		Updates(map[string]interface{}{
+			"status":       models.RefundProcessed,
+			"processed_at": time.Now(),
+			"restocked":    restock,
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+
+	if restock {
+		var items []models.RefundItem
+		res := tx.Table(models.RefundItem{}.TableName()).
+			Where("refund_id = ?", refundID).
+			Preload("OrderItem").
+			Find(&items)
+		if res.Error != nil {
+			return res.Error
+		}
+		// The order took its units from both the sellable stock and the warehouse
+		// reservations, so both get them back.
+		for _, item := range items {
+			if err := restockItem(tx, item.OrderItem.ProductID, item.OrderItem.VariantID, item.Quantity); err != nil {
+				return err
+			}
+			if err := reservationDao.WithTx(tx).ReleaseUnits(payment.OrderID, item.OrderItem.ProductID, item.Quantity); err != nil {
+				return err
+			}
+		}
+	}
+
+	var processed float64
+	res = tx.Table(models.Refund{}.TableName()).
+		Select("COALESCE(SUM(amount), 0)").
+		Where("payment_id = ? AND status = ? AND del_flg = ?", payment.ID, models.RefundProcessed, false).
+		Scan(&processed)
+	if res.Error != nil {
+		return res.Error
+	}
+	if processed+0.005 < payment.Amount {
+		// A partial refund may settle the last cancelled store the order was waiting on.
+		return syncOrderWithFulfillments(tx, reservationDao, payment.OrderID, &userID)
+	}
+
+	if err := setPaymentStatus(tx, payment.ID, models.PaymentSuccessful, models.PaymentRefunded); err != nil {
+		return err
+	}
+	var order models.Order
+	if res := tx.Table(order.TableName()).Where("id = ?", payment.OrderID).First(&order); res.Error != nil {
+		return res.Error
+	}
+	if !models.CanTransitionOrder(order.Status, models.OrderStatusRefunded) {
+		return nil
+	}
+	_, err := transitionOrderStatus(tx, reservationDao, orderStatusChange{
+		OrderID:   order.ID,
+		To:        models.OrderStatusRefunded,
+		ChangedBy: &userID,
+		Note:      "refunded in full",
+	})
+	return err
+}
+
+// refundedQuantities sums, per order item, the units covered by refunds that were not rejected.
+func refundedQuantities(tx *gorm.DB, orderID uuid.UUID) (map[uuid.UUID]int, error) {
+	var rows []struct {
+		OrderItemID uuid.UUID
+		Quantity    int
+	}
+	res := tx.Table(models.RefundItem{}.TableName()+" AS ri").
+		Select("ri.order_item_id, SUM(ri.quantity) AS quantity").
+		Joins("JOIN "+models.Refund{}.TableName()+" AS r ON r.id = ri.refund_id").
+		Where("r.order_id = ? AND r.status <> ? AND r.del_flg = ?", orderID, models.RefundRejected, false).
+		Group("ri.order_item_id").
+		Scan(&rows)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+
+	quantities := map[uuid.UUID]int{}
+	for _, row := range rows {
+		quantities[row.OrderItemID] = row.Quantity
+	}
+	return quantities, nil
+}
+
+func toRefundResponse(refund models.Refund) refundDto.RefundResponse {
+	response := refundDto.RefundResponse{
+		ID:          refund.ID.String(),
+		OrderID:     refund.OrderID.String(),
+		StoreID:     refund.StoreID.String(),
+		PaymentID:   refund.PaymentID.String(),
+		Amount:      refund.Amount,
+		Reason:      refund.Reason,
+		Status:      refund.Status,
+		ReviewNote:  refund.ReviewNote,
+		Restocked:   refund.Restocked,
+		CreatedAt:   refund.CreatedAt,
+		ProcessedAt: refund.ProcessedAt,
+		Items:       []refundDto.RefundItemResponse{},
+	}
+	for _, item := range refund.Items {
+		response.Items = append(response.Items, refundDto.RefundItemResponse{
+			OrderItemID: item.OrderItemID.String(),
+			Quantity:    item.Quantity,
+			Amount:      item.Amount,
+		})
+	}
+	return response
+}
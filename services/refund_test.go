@@ -0,0 +1,306 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/abdulmalikraji/e-commerce/db/models"
+	"github.com/abdulmalikraji/e-commerce/dto/refundDto"
+	"github.com/abdulmalikraji/e-commerce/mocks/dao/stockReservationDao"
+	"github.com/abdulmalikraji/e-commerce/payments"
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestRefundService_Request_Refund_Requires_Paid_Order(t *testing.T) {
+	teardown := setupPayment(t)
+	defer teardown()
+	rs := NewRefundService(orderMockDao, nil, paymentMockDao, nil, payments.NewRegistry(fakeProvider))
+
+	order := pendingOrder()
+	orderMockDao.EXPECT().FindById(order.ID.String()).Return(order, nil)
+
+	_, status, err := rs.RequestRefund(fiberCtx, refundDto.CreateRefundRequest{
+		OrderID: order.ID.String(),
+		Items:   []refundDto.RefundItemRequest{{OrderItemID: uuid.NewString()}},
+	})
+
+	assert.Error(t, err)
+	assert.Equal(t, fiber.StatusConflict, status)
+}
+
+func TestRefundService_Request_Refund_Rejects_Other_Buyers(t *testing.T) {
+	teardown := setupPayment(t)
+	defer teardown()
+	rs := NewRefundService(orderMockDao, nil, paymentMockDao, nil, payments.NewRegistry(fakeProvider))
+
+	order := pendingOrder()
+	order.Status = models.OrderStatusDelivered
+	order.BuyerID = uuid.New()
+	orderMockDao.EXPECT().FindById(order.ID.String()).Return(order, nil)
+
+	_, status, err := rs.RequestRefund(fiberCtx, refundDto.CreateRefundRequest{
+		OrderID: order.ID.String(),
+		Items:   []refundDto.RefundItemRequest{{OrderItemID: uuid.NewString()}},
+	})
+
+	assert.Error(t, err)
+	assert.Equal(t, fiber.StatusForbidden, status)
+}
+
+// refundableOrder is a delivered order with one item of three units at 10 each.
+func refundableOrder() models.Order {
+	order := pendingOrder()
+	order.Status = models.OrderStatusDelivered
+	order.TotalAmount = 30
+	order.Items = []models.OrderItem{{
+		ID:        uuid.New(),
+		OrderID:   order.ID,
+		StoreID:   uuid.New(),
+		ProductID: uuid.New(),
+		Quantity:  3,
+		UnitPrice: 10,
+	}}
+	return order
+}
+
+// expectRefundTotals scripts the payment lock and the refunded-so-far queries of RequestRefund.
+func expectRefundTotals(sqlMock sqlmock.Sqlmock, order models.Order, paid float64, refundedQty int, refundedAmount float64) uuid.UUID {
+	paymentID := uuid.New()
+	sqlMock.ExpectQuery(`FROM "ecom"."payments" .* FOR UPDATE`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "order_id", "amount", "status"}).
+			AddRow(paymentID, order.ID, paid, models.PaymentSuccessful))
+	rows := sqlmock.NewRows([]string{"order_item_id", "quantity"})
+	if refundedQty > 0 {
+		rows.AddRow(order.Items[0].ID, refundedQty)
+	}
+	sqlMock.ExpectQuery(`FROM ecom.refund_items AS ri`).WillReturnRows(rows)
+	sqlMock.ExpectQuery(`SELECT COALESCE\(SUM\(amount\), 0\) FROM "ecom"."refunds"`).
+		WillReturnRows(sqlmock.NewRows([]string{"coalesce"}).AddRow(refundedAmount))
+	return paymentID
+}
+
+func TestRefundService_Request_Refund_Covers_Part_Of_An_Item(t *testing.T) {
+	teardown := setupPayment(t)
+	defer teardown()
+	rs := NewRefundService(orderMockDao, nil, paymentMockDao, nil, payments.NewRegistry(fakeProvider))
+
+	order := refundableOrder()
+	tx, sqlMock := mockTx(t)
+	expectRefundTotals(sqlMock, order, 30, 1, 10)
+	sqlMock.ExpectQuery(`INSERT INTO "ecom"."refunds"`).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(uuid.New()))
+	sqlMock.ExpectQuery(`INSERT INTO "ecom"."refund_items"`).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(uuid.New()))
+
+	orderMockDao.EXPECT().FindById(order.ID.String()).Return(order, nil)
+	orderMockDao.EXPECT().Transaction(gomock.Any()).DoAndReturn(func(fn func(tx *gorm.DB) error) error {
+		return fn(tx)
+	})
+
+	response, status, err := rs.RequestRefund(fiberCtx, refundDto.CreateRefundRequest{
+		OrderID: order.ID.String(),
+		Items:   []refundDto.RefundItemRequest{{OrderItemID: order.Items[0].ID.String(), Quantity: 1}},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, fiber.StatusCreated, status)
+	assert.Equal(t, 10.0, response.Amount)
+	assert.Len(t, response.Items, 1)
+	assert.Equal(t, 1, response.Items[0].Quantity)
+	assert.NoError(t, sqlMock.ExpectationsWereMet())
+}
+
+func TestRefundService_Request_Refund_Rejects_More_Units_Than_Are_Left(t *testing.T) {
+	teardown := setupPayment(t)
+	defer teardown()
+	rs := NewRefundService(orderMockDao, nil, paymentMockDao, nil, payments.NewRegistry(fakeProvider))
+
+	order := refundableOrder()
+	tx, sqlMock := mockTx(t)
+	expectRefundTotals(sqlMock, order, 30, 2, 20)
+
+	orderMockDao.EXPECT().FindById(order.ID.String()).Return(order, nil)
+	orderMockDao.EXPECT().Transaction(gomock.Any()).DoAndReturn(func(fn func(tx *gorm.DB) error) error {
+		return fn(tx)
+	})
+
+	_, status, err := rs.RequestRefund(fiberCtx, refundDto.CreateRefundRequest{
+		OrderID: order.ID.String(),
+		Items:   []refundDto.RefundItemRequest{{OrderItemID: order.Items[0].ID.String(), Quantity: 2}},
+	})
+
+	assert.Error(t, err)
+	assert.Equal(t, fiber.StatusConflict, status)
+}
+
+func TestRefundService_Request_Refund_Is_Capped_At_What_Is_Left_Of_The_Payment(t *testing.T) {
+	teardown := setupPayment(t)
+	defer teardown()
+	rs := NewRefundService(orderMockDao, nil, paymentMockDao, nil, payments.NewRegistry(fakeProvider))
+
+	// A discount brought the payment down to 20, so each unit refunds 6.67 and the
+	// first two refunds took 13.34; the last unit only has 6.66 left.
+	order := refundableOrder()
+	tx, sqlMock := mockTx(t)
+	expectRefundTotals(sqlMock, order, 20, 2, 13.34)
+	sqlMock.ExpectQuery(`INSERT INTO "ecom"."refunds"`).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(uuid.New()))
+	sqlMock.ExpectQuery(`INSERT INTO "ecom"."refund_items"`).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(uuid.New()))
+
+	orderMockDao.EXPECT().FindById(order.ID.String()).Return(order, nil)
+	orderMockDao.EXPECT().Transaction(gomock.Any()).DoAndReturn(func(fn func(tx *gorm.DB) error) error {
+		return fn(tx)
+	})
+
+	response, status, err := rs.RequestRefund(fiberCtx, refundDto.CreateRefundRequest{
+		OrderID: order.ID.String(),
+		Items:   []refundDto.RefundItemRequest{{OrderItemID: order.Items[0].ID.String()}},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, fiber.StatusCreated, status)
+	assert.Equal(t, 6.66, response.Amount)
+	assert.Equal(t, 6.66, response.Items[0].Amount)
+}
+
+func TestRefundService_Request_Refund_Items_Add_Up_To_The_Capped_Amount(t *testing.T) {
+	teardown := setupPayment(t)
+	defer teardown()
+	rs := NewRefundService(orderMockDao, nil, paymentMockDao, nil, payments.NewRegistry(fakeProvider))
+
+	// Three single units at 10 on a payment of 20 price at 6.67 each, a cent over the
+	// payment; the last line gives that cent up.
+	order := refundableOrder()
+	storeID := order.Items[0].StoreID
+	order.Items = nil
+	var lines []refundDto.RefundItemRequest
+	for i := 0; i < 3; i++ {
+		item := models.OrderItem{ID: uuid.New(), OrderID: order.ID, StoreID: storeID, ProductID: uuid.New(), Quantity: 1, UnitPrice: 10}
+		order.Items = append(order.Items, item)
+		lines = append(lines, refundDto.RefundItemRequest{OrderItemID: item.ID.String()})
+	}
+	tx, sqlMock := mockTx(t)
+	expectRefundTotals(sqlMock, order, 20, 0, 0)
+	sqlMock.ExpectQuery(`INSERT INTO "ecom"."refunds"`).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(uuid.New()))
+	sqlMock.ExpectQuery(`INSERT INTO "ecom"."refund_items"`).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(uuid.New()).AddRow(uuid.New()).AddRow(uuid.New()))
+
+	orderMockDao.EXPECT().FindById(order.ID.String()).Return(order, nil)
+	orderMockDao.EXPECT().Transaction(gomock.Any()).DoAndReturn(func(fn func(tx *gorm.DB) error) error {
+		return fn(tx)
+	})
+
+	response, status, err := rs.RequestRefund(fiberCtx, refundDto.CreateRefundRequest{
+		OrderID: order.ID.String(),
+		Items:   lines,
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, fiber.StatusCreated, status)
+	assert.Equal(t, 20.0, response.Amount)
+	assert.Equal(t, 6.67, response.Items[0].Amount)
+	assert.Equal(t, 6.67, response.Items[1].Amount)
+	assert.Equal(t, 6.66, response.Items[2].Amount)
+	assert.NoError(t, sqlMock.ExpectationsWereMet())
+}
+
+func TestRefundService_Request_Refund_Rejects_Exceeding_The_Payment(t *testing.T) {
+	teardown := setupPayment(t)
+	defer teardown()
+	rs := NewRefundService(orderMockDao, nil, paymentMockDao, nil, payments.NewRegistry(fakeProvider))
+
+	// Earlier refunds already took 25 of the 30 paid, more than one unit's share.
+	order := refundableOrder()
+	tx, sqlMock := mockTx(t)
+	expectRefundTotals(sqlMock, order, 30, 1, 25)
+
+	orderMockDao.EXPECT().FindById(order.ID.String()).Return(order, nil)
+	orderMockDao.EXPECT().Transaction(gomock.Any()).DoAndReturn(func(fn func(tx *gorm.DB) error) error {
+		return fn(tx)
+	})
+
+	_, status, err := rs.RequestRefund(fiberCtx, refundDto.CreateRefundRequest{
+		OrderID: order.ID.String(),
+		Items:   []refundDto.RefundItemRequest{{OrderItemID: order.Items[0].ID.String(), Quantity: 1}},
+	})
+
+	assert.EqualError(t, err, "Refund would exceed the amount paid")
+	assert.Equal(t, fiber.StatusConflict, status)
+}
+
+func TestRefundService_Review_Reopens_Refund_When_Provider_Fails(t *testing.T) {
+	teardown := setupPayment(t)
+	defer teardown()
+	rs := NewRefundService(orderMockDao, nil, paymentMockDao, nil, payments.NewRegistry(fakeProvider))
+
+	order := refundableOrder()
+	payment := initiatedPayment(t, order, "card")
+	payment.Status = models.PaymentSuccessful
+	payment.TransactionRef = "fake_txn_unknown"
+	refundID := uuid.New()
+	storeID := order.Items[0].StoreID
+
+	tx, sqlMock := mockTx(t)
+	sqlMock.ExpectQuery(`FROM "ecom"."refunds" .* FOR UPDATE`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "payment_id", "order_id", "store_id", "amount", "status"}).
+			AddRow(refundID, payment.ID, order.ID, storeID, 10.0, models.RefundPending))
+	sqlMock.ExpectExec(`UPDATE "ecom"."refunds" SET`).WillReturnResult(sqlmock.NewResult(0, 1))
+	sqlMock.ExpectExec(`UPDATE "ecom"."refunds" SET "status"=\$1 WHERE id = \$2 AND status = \$3`).
+		WithArgs(models.RefundPending, refundID, models.RefundApproved).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	orderMockDao.EXPECT().Transaction(gomock.Any()).DoAndReturn(func(fn func(tx *gorm.DB) error) error {
+		return fn(tx)
+	}).Times(2)
+	paymentMockDao.EXPECT().FindById(payment.ID.String()).Return(payment, nil)
+
+	_, status, err := rs.ReviewRefund(fiberCtx, refundDto.ReviewRefundRequest{
+		StoreID:  storeID.String(),
+		RefundID: refundID.String(),
+		Approve:  true,
+	})
+
+	assert.Error(t, err)
+	assert.Equal(t, fiber.StatusBadGateway, status)
+	assert.NoError(t, sqlMock.ExpectationsWereMet())
+}
+
+func TestRefundService_Complete_Refund_Restocks_Both_Ledgers(t *testing.T) {
+	ct := gomock.NewController(t)
+	defer ct.Finish()
+	reservationMockDao := stockReservationDao.NewMockDataAccess(ct)
+
+	order := refundableOrder()
+	item := order.Items[0]
+	payment := models.Payment{ID: uuid.New(), OrderID: order.ID, Amount: 30, Status: models.PaymentSuccessful}
+	refundID := uuid.New()
+
+	tx, sqlMock := mockTx(t)
+	sqlMock.ExpectExec(`UPDATE "ecom"."refunds" SET`).WillReturnResult(sqlmock.NewResult(0, 1))
+	sqlMock.ExpectQuery(`FROM "ecom"."refund_items"`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "refund_id", "order_item_id", "quantity", "amount"}).
+			AddRow(uuid.New(), refundID, item.ID, 2, 20.0))
+	sqlMock.ExpectQuery(`FROM "ecom"."order_items"`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "order_id", "product_id", "quantity", "unit_price"}).
+			AddRow(item.ID, order.ID, item.ProductID, item.Quantity, item.UnitPrice))
+	sqlMock.ExpectExec(`UPDATE "ecom"."products" SET "stock"=stock \+ \$1`).
+		WithArgs(2, item.ProductID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	sqlMock.ExpectQuery(`SELECT COALESCE\(SUM\(amount\), 0\) FROM "ecom"."refunds"`).
+		WillReturnRows(sqlmock.NewRows([]string{"coalesce"}).AddRow(20.0))
+	expectFulfillments(sqlMock, order.ID)
+
+	reservationMockDao.EXPECT().WithTx(gomock.Any()).Return(reservationMockDao)
+	reservationMockDao.EXPECT().ReleaseUnits(order.ID, item.ProductID, 2).Return(nil)
+
+	err := completeRefund(tx, reservationMockDao, refundID, payment, true, buyerID)
+
+	assert.NoError(t, err)
+	assert.NoError(t, sqlMock.ExpectationsWereMet())
+}
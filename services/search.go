@@ -0,0 +1,136 @@
+package services
+
+import (
+	"html"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/abdulmalikraji/e-commerce/db/dao/analyticsDao"
+	"github.com/abdulmalikraji/e-commerce/db/dao/productDao"
+	"github.com/abdulmalikraji/e-commerce/db/models"
+	"github.com/abdulmalikraji/e-commerce/dto/productDto"
+	"github.com/abdulmalikraji/e-commerce/dto/searchDto"
+	"github.com/abdulmalikraji/e-commerce/utils"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/log"
+)
+
+type SearchService interface {
+	SearchProducts(ctx *fiber.Ctx, request searchDto.SearchProductsRequest) (searchDto.SearchProductsResponse, int, error)
+	ZeroResultSearches(ctx *fiber.Ctx, request searchDto.ZeroResultSearchesRequest) ([]searchDto.ZeroResultSearch, int, error)
+}
+
+type searchService struct {
+	productDao   productDao.DataAccess
+	analyticsDao analyticsDao.DataAccess
+}
+
+func NewSearchService(productDao productDao.DataAccess, analyticsDao analyticsDao.DataAccess) SearchService {
+	return searchService{
+		productDao:   productDao,
+		analyticsDao: analyticsDao,
+	}
+}
+
+// SearchProducts runs a full-text search over the catalog and records the query and
+// its result count in the search analytics.
+func (s searchService) SearchProducts(ctx *fiber.Ctx, request searchDto.SearchProductsRequest) (searchDto.SearchProductsResponse, int, error) {
+	query := strings.Join(strings.Fields(request.Query), " ")
+	if query == "" {
+		return searchDto.SearchProductsResponse{}, fiber.StatusBadRequest, fiber.NewError(fiber.StatusBadRequest, "q is required")
+	}
+	if utf8.RuneCountInString(query) > searchDto.MaxQueryLength {
+		return searchDto.SearchProductsResponse{}, fiber.StatusBadRequest, fiber.NewError(fiber.StatusBadRequest, "q is too long")
+	}
+	if request.Page < 0 {
+		return searchDto.SearchProductsResponse{}, fiber.StatusBadRequest, fiber.NewError(fiber.StatusBadRequest, "page cannot be negative")
+	}
+	if request.PageSize < 0 || request.PageSize > productDto.MaxPageSize {
+		return searchDto.SearchProductsResponse{}, fiber.StatusBadRequest, fiber.NewError(fiber.StatusBadRequest, "page_size is out of range")
+	}
+	if request.Page < 1 {
+		request.Page = 1
+	}
+	if request.PageSize == 0 {
+		request.PageSize = productDto.DefaultPageSize
+	}
+
+	found, total, err := s.productDao.Search(query, request.Page, request.PageSize)
+	if err != nil {
+		return searchDto.SearchProductsResponse{}, fiber.StatusInternalServerError, err
+	}
+
+	// Only the first page is a new search; later pages would count it twice.
+	if request.Page == 1 {
+		s.logSearch(ctx, query, total)
+	}
+
+	response := searchDto.SearchProductsResponse{
+		Results:  []searchDto.SearchResult{},
+		Total:    total,
+		Page:     request.Page,
+		PageSize: request.PageSize,
+	}
+	for _, result := range found {
+		response.Results = append(response.Results, searchDto.SearchResult{
+			ProductResponse: toProductResponse(result.Product),
+			Highlight: searchDto.Highlight{
+				Name:        highlightHTML(result.NameHighlight),
+				Description: highlightHTML(result.DescriptionHighlight),
+			},
+		})
+	}
+	return response, fiber.StatusOK, nil
+}
+
+// highlightMarks turns the DAO's match delimiters into <mark> tags.
+var highlightMarks = strings.NewReplacer(productDao.HighlightStart, "<mark>", productDao.HighlightStop, "</mark>")
+
+// highlightHTML escapes a search fragment, which is seller-written text, and marks its
+// matches so it can be rendered as HTML.
+func highlightHTML(fragment string) string {
+	return highlightMarks.Replace(html.EscapeString(fragment))
+}
+
+// ZeroResultSearches reports the most frequent searches that found nothing, so the
+// catalog or its synonyms can be improved.
+func (s searchService) ZeroResultSearches(ctx *fiber.Ctx, request searchDto.ZeroResultSearchesRequest) ([]searchDto.ZeroResultSearch, int, error) {
+	if request.Days <= 0 {
+		request.Days = 30
+	}
+	if request.Limit <= 0 || request.Limit > productDto.MaxPageSize {
+		request.Limit = productDto.DefaultPageSize
+	}
+
+	since := time.Now().AddDate(0, 0, -request.Days)
+	searches, err := s.analyticsDao.FindZeroResultSearches(since, request.Limit)
+	if err != nil {
+		return nil, fiber.StatusInternalServerError, err
+	}
+
+	response := []searchDto.ZeroResultSearch{}
+	for _, search := range searches {
+		response = append(response, searchDto.ZeroResultSearch{
+			Query:          search.Query,
+			Searches:       search.Searches,
+			LastSearchedAt: search.LastSearchedAt,
+		})
+	}
+	return response, fiber.StatusOK, nil
+}
+
+// logSearch records the search for the signed-in user or the guest session. A failure
+// is logged but does not fail the search.
+func (s searchService) logSearch(ctx *fiber.Ctx, query string, results int64) {
+	search := models.SearchAnalytics{Query: query, Results: int(results)}
+	if principal, ok := utils.GetPrincipal(ctx); ok {
+		search.UserID = &principal.UserID
+	} else if session := ctx.Cookies(GuestCartCookie); session != "" {
+		search.SessionID = &session
+	}
+
+	if err := s.analyticsDao.LogSearch(search); err != nil {
+		log.Errorf("failed to log search %q: %v", query, err)
+	}
+}
@@ -0,0 +1,113 @@
+package services
+
+import (
+	"testing"
+
+	productdao "github.com/abdulmalikraji/e-commerce/db/dao/productDao"
+	"github.com/abdulmalikraji/e-commerce/db/models"
+	"github.com/abdulmalikraji/e-commerce/dto/searchDto"
+	"github.com/abdulmalikraji/e-commerce/mocks/dao/analyticsDao"
+	"github.com/abdulmalikraji/e-commerce/mocks/dao/productDao"
+	"github.com/abdulmalikraji/e-commerce/utils"
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+)
+
+var analyticsMockDao *analyticsDao.MockDataAccess
+
+var searches SearchService
+
+func setupSearch(t *testing.T) func() {
+	ct := gomock.NewController(t)
+
+	app := fiber.New()
+	fiberCtx = app.AcquireCtx(&fasthttp.RequestCtx{})
+
+	productMockDao = productDao.NewMockDataAccess(ct)
+	analyticsMockDao = analyticsDao.NewMockDataAccess(ct)
+
+	searches = NewSearchService(productMockDao, analyticsMockDao)
+	return func() {
+		searches = nil
+		app.ReleaseCtx(fiberCtx)
+		ct.Finish()
+	}
+}
+
+func TestSearchService_Logs_Query_With_Result_Count(t *testing.T) {
+	teardown := setupSearch(t)
+	defer teardown()
+	userID := uuid.New()
+	utils.SetPrincipal(fiberCtx, utils.Principal{UserID: userID})
+
+	mug := models.Product{ID: uuid.New(), Name: "Red mug"}
+	productMockDao.EXPECT().Search("red mug", 1, 20).Return([]productdao.SearchResult{
+		{Product: mug, NameHighlight: productdao.HighlightStart + "Red" + productdao.HighlightStop + " " + productdao.HighlightStart + "mug" + productdao.HighlightStop},
+	}, int64(1), nil)
+	analyticsMockDao.EXPECT().LogSearch(gomock.Any()).DoAndReturn(func(search models.SearchAnalytics) error {
+		assert.Equal(t, "red mug", search.Query)
+		assert.Equal(t, 1, search.Results)
+		assert.Equal(t, userID, *search.UserID)
+		return nil
+	})
+
+	response, status, err := searches.SearchProducts(fiberCtx, searchDto.SearchProductsRequest{Query: "  red   mug "})
+
+	assert.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, status)
+	assert.Equal(t, "<mark>Red</mark> <mark>mug</mark>", response.Results[0].Highlight.Name)
+	assert.Equal(t, mug.ID.String(), response.Results[0].ID)
+}
+
+func TestSearchService_Escapes_Product_Text_In_Highlights(t *testing.T) {
+	teardown := setupSearch(t)
+	defer teardown()
+
+	product := models.Product{ID: uuid.New(), Name: "<script>alert(1)</script> mug"}
+	productMockDao.EXPECT().Search("mug", 1, 20).Return([]productdao.SearchResult{{
+		Product:              product,
+		NameHighlight:        "<script>alert(1)</script> " + productdao.HighlightStart + "mug" + productdao.HighlightStop,
+		DescriptionHighlight: `<img src=x onerror="alert(2)">`,
+	}}, int64(1), nil)
+	analyticsMockDao.EXPECT().LogSearch(gomock.Any()).Return(nil)
+
+	response, status, err := searches.SearchProducts(fiberCtx, searchDto.SearchProductsRequest{Query: "mug"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, status)
+	assert.Equal(t, "&lt;script&gt;alert(1)&lt;/script&gt; <mark>mug</mark>", response.Results[0].Highlight.Name)
+	assert.Equal(t, "&lt;img src=x onerror=&#34;alert(2)&#34;&gt;", response.Results[0].Highlight.Description)
+}
+
+func TestSearchService_Logs_Zero_Result_Guest_Search(t *testing.T) {
+	teardown := setupSearch(t)
+	defer teardown()
+	fiberCtx.Request().Header.SetCookie(GuestCartCookie, "session")
+
+	productMockDao.EXPECT().Search("teapot", 1, 20).Return([]productdao.SearchResult{}, int64(0), nil)
+	analyticsMockDao.EXPECT().LogSearch(gomock.Any()).DoAndReturn(func(search models.SearchAnalytics) error {
+		assert.Equal(t, 0, search.Results)
+		assert.Nil(t, search.UserID)
+		assert.Equal(t, "session", *search.SessionID)
+		return nil
+	})
+
+	response, status, err := searches.SearchProducts(fiberCtx, searchDto.SearchProductsRequest{Query: "teapot"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, status)
+	assert.Empty(t, response.Results)
+}
+
+func TestSearchService_Requires_A_Query(t *testing.T) {
+	teardown := setupSearch(t)
+	defer teardown()
+
+	_, status, err := searches.SearchProducts(fiberCtx, searchDto.SearchProductsRequest{Query: "   "})
+
+	assert.Error(t, err)
+	assert.Equal(t, fiber.StatusBadRequest, status)
+}
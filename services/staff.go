@@ -0,0 +1,453 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/abdulmalikraji/e-commerce/db/dao/storeDao"
+	"github.com/abdulmalikraji/e-commerce/db/dao/storeInvitationDao"
+	"github.com/abdulmalikraji/e-commerce/db/dao/storeRoleDao"
+	"github.com/abdulmalikraji/e-commerce/db/dao/storeUserDao"
+	"github.com/abdulmalikraji/e-commerce/db/dao/userDao"
+	"github.com/abdulmalikraji/e-commerce/db/models"
+	"github.com/abdulmalikraji/e-commerce/dto/staffDto"
+	"github.com/abdulmalikraji/e-commerce/mailer"
+	"github.com/abdulmalikraji/e-commerce/utils"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/log"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const staffInvitationLifetime = 7 * 24 * time.Hour
+
+type StaffService interface {
+	ListStaff(ctx *fiber.Ctx, request staffDto.ListStaffRequest) ([]staffDto.StaffMember, int, error)
+	InviteStaff(ctx *fiber.Ctx, request staffDto.InviteStaffRequest) (staffDto.InvitationResponse, int, error)
+	ListInvitations(ctx *fiber.Ctx, request staffDto.ListInvitationsRequest) ([]staffDto.InvitationResponse, int, error)
+	RevokeInvitation(ctx *fiber.Ctx, request staffDto.RevokeInvitationRequest) (staffDto.InvitationResponse, int, error)
+	AcceptInvitation(ctx *fiber.Ctx, request staffDto.AcceptInvitationRequest) (staffDto.StaffMember, int, error)
+	UpdateStaff(ctx *fiber.Ctx, request staffDto.UpdateStaffRequest) (staffDto.StaffMember, int, error)
+	RemoveStaff(ctx *fiber.Ctx, request staffDto.RemoveStaffRequest) (int, error)
+}
+
+type staffService struct {
+	storeDao      storeDao.DataAccess
+	storeUserDao  storeUserDao.DataAccess
+	invitationDao storeInvitationDao.DataAccess
+	storeRoleDao  storeRoleDao.DataAccess
+	userDao       userDao.DataAccess
+	mailer        mailer.Mailer
+}
+
+func NewStaffService(
+	storeDao storeDao.DataAccess,
+	storeUserDao storeUserDao.DataAccess,
+	invitationDao storeInvitationDao.DataAccess,
+	storeRoleDao storeRoleDao.DataAccess,
+	userDao userDao.DataAccess,
+	mailer mailer.Mailer,
+) StaffService {
+	return staffService{
+		storeDao:      storeDao,
+		storeUserDao:  storeUserDao,
+		invitationDao: invitationDao,
+		storeRoleDao:  storeRoleDao,
+		userDao:       userDao,
+		mailer:        mailer,
+	}
+}
+
+// ListStaff returns the owner followed by the store's staff.
+func (s staffService) ListStaff(ctx *fiber.Ctx, request staffDto.ListStaffRequest) ([]staffDto.StaffMember, int, error) {
+	store, status, err := s.findStore(request.StoreID)
+	if err != nil {
+		return nil, status, err
+	}
+
+	members, err := s.storeUserDao.FindByStoreID(store.ID.String())
+	if err != nil {
+		return nil, fiber.StatusInternalServerError, err
+	}
+
+	owner := models.StoreUser{StoreID: store.ID, UserID: store.OwnerID, Role: models.RoleOwner, User: store.Owner, CreatedAt: store.CreatedAt}
+	response := []staffDto.StaffMember{toStaffMember(owner)}
+	for _, member := range members {
+		if member.UserID == store.OwnerID {
+			continue
+		}
+		response = append(response, toStaffMember(member))
+	}
+	return response, fiber.StatusOK, nil
+}
+
+// InviteStaff emails an acceptance link to the invitee. Inviting an address with a
+// pending invitation replaces it, so the latest email is the only valid one. The inviter
+// may only hand out a role whose actions they have themselves.
+func (s staffService) InviteStaff(ctx *fiber.Ctx, request staffDto.InviteStaffRequest) (staffDto.InvitationResponse, int, error) {
+	inviterID, err := utils.GetUserID(ctx)
+	if err != nil {
+		return staffDto.InvitationResponse{}, fiber.StatusUnauthorized, err
+	}
+
+	request.Email = strings.TrimSpace(request.Email)
+	if !utils.EmailRegex(request.Email) {
+		return staffDto.InvitationResponse{}, fiber.StatusBadRequest, fiber.NewError(fiber.StatusBadRequest, "A valid email is required")
+	}
+	if request.Role == "" {
+		request.Role = models.RoleWorker
+	}
+
+	store, status, err := s.findStore(request.StoreID)
+	if err != nil {
+		return staffDto.InvitationResponse{}, status, err
+	}
+	role, status, err := s.resolveRole(ctx, store.ID, request.Role)
+	if err != nil {
+		return staffDto.InvitationResponse{}, status, err
+	}
+
+	if user, err := s.userDao.FindByEmail(request.Email); err == nil {
+		if user.ID == store.OwnerID {
+			return staffDto.InvitationResponse{}, fiber.StatusConflict, fiber.NewError(fiber.StatusConflict, "The store owner is already a member")
+		}
+		if _, err := s.storeUserDao.FindByStoreAndUser(store.ID, user.ID); err == nil {
+			return staffDto.InvitationResponse{}, fiber.StatusConflict, fiber.NewError(fiber.StatusConflict, request.Email+" is already a member of this store")
+		}
+	}
+
+	if previous, err := s.invitationDao.FindPendingByEmail(store.ID.String(), request.Email); err == nil {
+		if err := s.invitationDao.UpdateStatus(previous.ID.String(), models.InvitationRevoked); err != nil {
+			return staffDto.InvitationResponse{}, fiber.StatusInternalServerError, err
+		}
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return staffDto.InvitationResponse{}, fiber.StatusInternalServerError, err
+	}
+
+	token, err := newInvitationToken()
+	if err != nil {
+		return staffDto.InvitationResponse{}, fiber.StatusInternalServerError, err
+	}
+	invitation, err := s.invitationDao.Insert(models.StoreInvitation{
+		StoreID:   store.ID,
+		Email:     request.Email,
+		TokenHash: hashInvitationToken(token),
+		Status:    models.InvitationPending,
+		RoleID:    &role.ID,
+		Role:      role.Name,
+		InvitedBy: inviterID,
+		ExpiresAt: time.Now().Add(staffInvitationLifetime),
+	})
+	if err != nil {
+		return staffDto.InvitationResponse{}, fiber.StatusInternalServerError, err
+	}
+
+	if err := s.mailer.Send(invitationEmail(store, invitation, token)); err != nil {
+		log.Errorf("failed to send store invitation %s to %s: %v", invitation.ID.String(), invitation.Email, err)
+		if err := s.invitationDao.UpdateStatus(invitation.ID.String(), models.InvitationRevoked); err != nil {
+			log.Errorf("failed to revoke unsent store invitation %s: %v", invitation.ID.String(), err)
+		}
+		return staffDto.InvitationResponse{}, fiber.StatusBadGateway, fiber.NewError(fiber.StatusBadGateway, "Failed to send the invitation email")
+	}
+
+	log.Infof("user_id=%s invited %s to store %s as %s", inviterID.String(), invitation.Email, store.ID.String(), invitation.Role)
+	return toInvitationResponse(invitation), fiber.StatusCreated, nil
+}
+
+func (s staffService) ListInvitations(ctx *fiber.Ctx, request staffDto.ListInvitationsRequest) ([]staffDto.InvitationResponse, int, error) {
+	var status *string
+	if request.Status != "" {
+		status = &request.Status
+	}
+
+	invitations, err := s.invitationDao.FindByStoreId(request.StoreID, status)
+	if err != nil {
+		return nil, fiber.StatusInternalServerError, err
+	}
+
+	response := []staffDto.InvitationResponse{}
+	for _, invitation := range invitations {
+		response = append(response, toInvitationResponse(invitation))
+	}
+	return response, fiber.StatusOK, nil
+}
+
+func (s staffService) RevokeInvitation(ctx *fiber.Ctx, request staffDto.RevokeInvitationRequest) (staffDto.InvitationResponse, int, error) {
+	invitation, err := s.invitationDao.FindByIdAndStore(request.InvitationID, request.StoreID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return staffDto.InvitationResponse{}, fiber.StatusNotFound, fiber.NewError(fiber.StatusNotFound, "Invitation not found")
+		}
+		return staffDto.InvitationResponse{}, fiber.StatusInternalServerError, err
+	}
+	if invitation.Status != models.InvitationPending {
+		return staffDto.InvitationResponse{}, fiber.StatusConflict, fiber.NewError(fiber.StatusConflict, "Invitation is already "+invitation.Status)
+	}
+
+	if err := s.invitationDao.UpdateStatus(invitation.ID.String(), models.InvitationRevoked); err != nil {
+		return staffDto.InvitationResponse{}, fiber.StatusInternalServerError, err
+	}
+	invitation.Status = models.InvitationRevoked
+	return toInvitationResponse(invitation), fiber.StatusOK, nil
+}
+
+// AcceptInvitation adds the caller to the store's staff. The invitation must have been
+// sent to the caller's email address.
+func (s staffService) AcceptInvitation(ctx *fiber.Ctx, request staffDto.AcceptInvitationRequest) (staffDto.StaffMember, int, error) {
+	userID, err := utils.GetUserID(ctx)
+	if err != nil {
+		return staffDto.StaffMember{}, fiber.StatusUnauthorized, err
+	}
+	if request.Token == "" {
+		return staffDto.StaffMember{}, fiber.StatusBadRequest, fiber.NewError(fiber.StatusBadRequest, "token is required")
+	}
+
+	invitation, err := s.invitationDao.FindByTokenHash(hashInvitationToken(request.Token))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return staffDto.StaffMember{}, fiber.StatusNotFound, fiber.NewError(fiber.StatusNotFound, "Invitation not found")
+		}
+		return staffDto.StaffMember{}, fiber.StatusInternalServerError, err
+	}
+	if invitation.Status != models.InvitationPending {
+		return staffDto.StaffMember{}, fiber.StatusConflict, fiber.NewError(fiber.StatusConflict, "Invitation is already "+invitation.Status)
+	}
+	if time.Now().After(invitation.ExpiresAt) {
+		return staffDto.StaffMember{}, fiber.StatusGone, fiber.NewError(fiber.StatusGone, "Invitation has expired")
+	}
+
+	user, err := s.userDao.FindById(userID.String())
+	if err != nil {
+		return staffDto.StaffMember{}, fiber.StatusInternalServerError, err
+	}
+	if !strings.EqualFold(user.Email, invitation.Email) {
+		return staffDto.StaffMember{}, fiber.StatusForbidden, fiber.NewError(fiber.StatusForbidden, "This invitation was sent to another email address")
+	}
+	if invitation.Store.OwnerID == userID {
+		return staffDto.StaffMember{}, fiber.StatusConflict, fiber.NewError(fiber.StatusConflict, "The store owner is already a member")
+	}
+
+	member := models.StoreUser{
+		StoreID: invitation.StoreID,
+		UserID:  userID,
+		RoleID:  invitation.RoleID,
+		Role:    invitation.Role,
+	}
+	err = s.invitationDao.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		res := tx.Table(invitation.TableName()).
+			Where("id = ? AND status = ?", invitation.ID, models.InvitationPending).
+			Updates(map[string]interface{}{
+				"status":      models.InvitationAccepted,
+				"accepted_by": userID,
+				"accepted_at": now,
+			})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return fiber.NewError(fiber.StatusConflict, "Invitation was already used")
+		}
+
+		var existing int64
+		res = tx.Table(member.TableName()).
+			Where("store_id = ? AND user_id = ? AND del_flg = ?", member.StoreID, member.UserID, false).
+			Count(&existing)
+		if res.Error != nil {
+			return res.Error
+		}
+		if existing > 0 {
+			return fiber.NewError(fiber.StatusConflict, "You are already a member of this store")
+		}
+		return tx.Table(member.TableName()).Create(&member).Error
+	})
+	if err != nil {
+		return staffDto.StaffMember{}, errorStatus(err), err
+	}
+
+	log.Infof("user_id=%s joined store %s as %s", userID.String(), member.StoreID.String(), member.Role)
+	member.User = user
+	if member.RoleID != nil {
+		if role, err := s.storeRoleDao.FindByIdForStore(member.RoleID.String(), member.StoreID.String()); err == nil {
+			member.StoreRole = &role
+		}
+	}
+	return toStaffMember(member), fiber.StatusCreated, nil
+}
+
+func (s staffService) UpdateStaff(ctx *fiber.Ctx, request staffDto.UpdateStaffRequest) (staffDto.StaffMember, int, error) {
+	principal, ok := utils.GetPrincipal(ctx)
+	if !ok {
+		return staffDto.StaffMember{}, fiber.StatusUnauthorized, fiber.NewError(fiber.StatusUnauthorized, "missing user id")
+	}
+	if request.Role == "" {
+		return staffDto.StaffMember{}, fiber.StatusBadRequest, fiber.NewError(fiber.StatusBadRequest, "role is required")
+	}
+
+	member, status, err := s.findMember(request.StoreID, request.UserID)
+	if err != nil {
+		return staffDto.StaffMember{}, status, err
+	}
+	// Members who may manage the staff still cannot raise their own access.
+	if membership, _ := principal.Membership(member.StoreID); member.UserID == principal.UserID && membership.Role != models.RoleOwner {
+		return staffDto.StaffMember{}, fiber.StatusForbidden, fiber.NewError(fiber.StatusForbidden, "You cannot change your own access")
+	}
+
+	role, status, err := s.resolveRole(ctx, member.StoreID, request.Role)
+	if err != nil {
+		return staffDto.StaffMember{}, status, err
+	}
+	member.RoleID = &role.ID
+	member.Role = role.Name
+	member.StoreRole = &role
+
+	if err := s.storeUserDao.UpdateAccess(member); err != nil {
+		return staffDto.StaffMember{}, fiber.StatusInternalServerError, err
+	}
+	return toStaffMember(member), fiber.StatusOK, nil
+}
+
+func (s staffService) RemoveStaff(ctx *fiber.Ctx, request staffDto.RemoveStaffRequest) (int, error) {
+	member, status, err := s.findMember(request.StoreID, request.UserID)
+	if err != nil {
+		return status, err
+	}
+
+	if err := s.storeUserDao.SoftDelete(member.ID.String()); err != nil {
+		return fiber.StatusInternalServerError, err
+	}
+	return fiber.StatusOK, nil
+}
+
+// resolveRole finds the store or system role named name. The caller may only assign
+// roles whose actions they have themselves.
+func (s staffService) resolveRole(ctx *fiber.Ctx, storeID uuid.UUID, name string) (models.StoreRole, int, error) {
+	if name == models.RoleOwner {
+		return models.StoreRole{}, fiber.StatusBadRequest, fiber.NewError(fiber.StatusBadRequest, "The owner role cannot be assigned")
+	}
+	role, err := s.storeRoleDao.FindByName(storeID.String(), name)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.StoreRole{}, fiber.StatusBadRequest, fiber.NewError(fiber.StatusBadRequest, "Unknown role "+name)
+		}
+		return models.StoreRole{}, fiber.StatusInternalServerError, err
+	}
+	if _, status, err := validateRoleActions(ctx, storeID, role.Actions()); err != nil {
+		return models.StoreRole{}, status, err
+	}
+	return role, fiber.StatusOK, nil
+}
+
+func (s staffService) findStore(storeID string) (models.Store, int, error) {
+	store, err := s.storeDao.FindById(storeID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.Store{}, fiber.StatusNotFound, fiber.NewError(fiber.StatusNotFound, "Store not found")
+		}
+		return models.Store{}, fiber.StatusInternalServerError, err
+	}
+	return store, fiber.StatusOK, nil
+}
+
+// findMember loads a staff member. The owner is not a stored member and cannot be
+// changed or removed through the staff API.
+func (s staffService) findMember(storeID string, userID string) (models.StoreUser, int, error) {
+	storeUUID, err := uuid.Parse(storeID)
+	if err != nil {
+		return models.StoreUser{}, fiber.StatusBadRequest, fiber.NewError(fiber.StatusBadRequest, "invalid store id format")
+	}
+	userUUID, err := uuid.Parse(userID)
+	if err != nil {
+		return models.StoreUser{}, fiber.StatusBadRequest, fiber.NewError(fiber.StatusBadRequest, "invalid user id format")
+	}
+
+	member, err := s.storeUserDao.FindByStoreAndUser(storeUUID, userUUID)
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.StoreUser{}, fiber.StatusInternalServerError, err
+		}
+		store, status, err := s.findStore(storeID)
+		if err != nil {
+			return models.StoreUser{}, status, err
+		}
+		if store.OwnerID == userUUID {
+			return models.StoreUser{}, fiber.StatusConflict, fiber.NewError(fiber.StatusConflict, "The store owner cannot be changed or removed")
+		}
+		return models.StoreUser{}, fiber.StatusNotFound, fiber.NewError(fiber.StatusNotFound, "Staff member not found")
+	}
+	return member, fiber.StatusOK, nil
+}
+
+func newInvitationToken() (string, error) {
+	token := make([]byte, 32)
+	if _, err := rand.Read(token); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(token), nil
+}
+
+func hashInvitationToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// invitationEmail links to STORE_INVITATION_URL (the frontend page that accepts the
+// invitation) with the token appended; without it the token is sent on its own.
+func invitationEmail(store models.Store, invitation models.StoreInvitation, token string) mailer.Message {
+	accept := "Your invitation code is: " + token
+	if url := os.Getenv("STORE_INVITATION_URL"); url != "" {
+		accept = "Accept the invitation: " + url + "?token=" + token
+	}
+	return mailer.Message{
+		To:      invitation.Email,
+		Subject: "You have been invited to join " + store.Name,
+		Body: fmt.Sprintf("You have been invited to join %s as a %s.\n\n%s\n\nThe invitation expires on %s.\n",
+			store.Name, invitation.Role, accept, invitation.ExpiresAt.Format(time.RFC1123)),
+	}
+}
+
+func toStaffMember(member models.StoreUser) staffDto.StaffMember {
+	permissions := []string{}
+	for action, granted := range member.Permissions() {
+		if granted {
+			permissions = append(permissions, action)
+		}
+	}
+	slices.Sort(permissions)
+
+	var roleID string
+	if member.RoleID != nil {
+		roleID = member.RoleID.String()
+	}
+	return staffDto.StaffMember{
+		UserID:      member.UserID.String(),
+		Email:       member.User.Email,
+		FirstName:   member.User.FirstName,
+		LastName:    member.User.LastName,
+		RoleID:      roleID,
+		Role:        member.Role,
+		Permissions: permissions,
+		JoinedAt:    member.CreatedAt,
+	}
+}
+
+func toInvitationResponse(invitation models.StoreInvitation) staffDto.InvitationResponse {
+	return staffDto.InvitationResponse{
+		ID:         invitation.ID.String(),
+		StoreID:    invitation.StoreID.String(),
+		Email:      invitation.Email,
+		Role:       invitation.Role,
+		Status:     invitation.Status,
+		InvitedBy:  invitation.InvitedBy.String(),
+		ExpiresAt:  invitation.ExpiresAt,
+		AcceptedAt: invitation.AcceptedAt,
+		CreatedAt:  invitation.CreatedAt,
+	}
+}
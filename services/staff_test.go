@@ -0,0 +1,261 @@
+package services
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/abdulmalikraji/e-commerce/db/models"
+	"github.com/abdulmalikraji/e-commerce/dto/staffDto"
+	"github.com/abdulmalikraji/e-commerce/mailer"
+	"github.com/abdulmalikraji/e-commerce/mocks/dao/storeDao"
+	"github.com/abdulmalikraji/e-commerce/mocks/dao/storeInvitationDao"
+	"github.com/abdulmalikraji/e-commerce/mocks/dao/storeRoleDao"
+	"github.com/abdulmalikraji/e-commerce/mocks/dao/storeUserDao"
+	"github.com/abdulmalikraji/e-commerce/mocks/dao/userDao"
+	"github.com/abdulmalikraji/e-commerce/utils"
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+	"gorm.io/gorm"
+)
+
+type recordingMailer struct {
+	sent []mailer.Message
+}
+
+func (m *recordingMailer) Send(message mailer.Message) error {
+	m.sent = append(m.sent, message)
+	return nil
+}
+
+var storeMockDao *storeDao.MockDataAccess
+var storeUserMockDao *storeUserDao.MockDataAccess
+var invitationMockDao *storeInvitationDao.MockDataAccess
+var storeRoleMockDao *storeRoleDao.MockDataAccess
+var outbox *recordingMailer
+
+var staff StaffService
+
+var staffStore = models.Store{ID: uuid.New(), Name: "Corner Shop", OwnerID: uuid.New()}
+
+// staffOwner is the principal of the store owner, with the membership the token
+// middleware gives them.
+var staffOwner = utils.Principal{
+	UserID: staffStore.OwnerID,
+	Stores: []models.StoreUser{{StoreID: staffStore.ID, UserID: staffStore.OwnerID, Role: models.RoleOwner}},
+}
+
+func setupStaff(t *testing.T, principal utils.Principal) func() {
+	ct := gomock.NewController(t)
+
+	app := fiber.New()
+	fiberCtx = app.AcquireCtx(&fasthttp.RequestCtx{})
+	utils.SetPrincipal(fiberCtx, principal)
+
+	storeMockDao = storeDao.NewMockDataAccess(ct)
+	storeUserMockDao = storeUserDao.NewMockDataAccess(ct)
+	invitationMockDao = storeInvitationDao.NewMockDataAccess(ct)
+	storeRoleMockDao = storeRoleDao.NewMockDataAccess(ct)
+	userMockDao = userDao.NewMockDataAccess(ct)
+	outbox = &recordingMailer{}
+
+	staff = NewStaffService(storeMockDao, storeUserMockDao, invitationMockDao, storeRoleMockDao, userMockDao, outbox)
+	return func() {
+		staff = nil
+		app.ReleaseCtx(fiberCtx)
+		ct.Finish()
+	}
+}
+
+func TestStaffService_Invite_Emails_Token_And_Stores_Its_Hash(t *testing.T) {
+	teardown := setupStaff(t, staffOwner)
+	defer teardown()
+
+	worker := models.StoreRole{ID: uuid.New(), Name: models.RoleWorker, Permissions: []models.StoreRolePermission{{Action: models.ActionManageOrders}}}
+	storeMockDao.EXPECT().FindById(staffStore.ID.String()).Return(staffStore, nil)
+	storeRoleMockDao.EXPECT().FindByName(staffStore.ID.String(), models.RoleWorker).Return(worker, nil)
+	userMockDao.EXPECT().FindByEmail("jane@company.com").Return(models.User{}, gorm.ErrRecordNotFound)
+	invitationMockDao.EXPECT().FindPendingByEmail(staffStore.ID.String(), "jane@company.com").Return(models.StoreInvitation{}, gorm.ErrRecordNotFound)
+	var stored models.StoreInvitation
+	invitationMockDao.EXPECT().Insert(gomock.Any()).DoAndReturn(func(invitation models.StoreInvitation) (models.StoreInvitation, error) {
+		invitation.ID = uuid.New()
+		stored = invitation
+		return invitation, nil
+	})
+
+	response, status, err := staff.InviteStaff(fiberCtx, staffDto.InviteStaffRequest{
+		StoreID: staffStore.ID.String(),
+		Email:   "jane@company.com",
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, fiber.StatusCreated, status)
+	assert.Equal(t, models.RoleWorker, response.Role)
+	assert.Equal(t, worker.ID, *stored.RoleID)
+
+	assert.Len(t, outbox.sent, 1)
+	body := outbox.sent[0].Body
+	token := strings.Fields(body[strings.Index(body, "code is: ")+len("code is: "):])[0]
+	assert.Equal(t, hashInvitationToken(token), stored.TokenHash)
+	assert.NotContains(t, body, stored.TokenHash)
+}
+
+func TestStaffService_Accept_Rejects_Other_Email(t *testing.T) {
+	userID := uuid.New()
+	teardown := setupStaff(t, utils.Principal{UserID: userID})
+	defer teardown()
+
+	invitation := models.StoreInvitation{
+		ID:        uuid.New(),
+		StoreID:   staffStore.ID,
+		Email:     "jane@company.com",
+		Status:    models.InvitationPending,
+		ExpiresAt: time.Now().Add(time.Hour),
+		Store:     staffStore,
+	}
+	invitationMockDao.EXPECT().FindByTokenHash(hashInvitationToken("token")).Return(invitation, nil)
+	userMockDao.EXPECT().FindById(userID.String()).Return(models.User{ID: userID, Email: "mallory@company.com"}, nil)
+
+	_, status, err := staff.AcceptInvitation(fiberCtx, staffDto.AcceptInvitationRequest{Token: "token"})
+
+	assert.Error(t, err)
+	assert.Equal(t, fiber.StatusForbidden, status)
+}
+
+func TestStaffService_Accept_Rejects_Expired_Invitation(t *testing.T) {
+	teardown := setupStaff(t, utils.Principal{UserID: uuid.New()})
+	defer teardown()
+
+	invitationMockDao.EXPECT().FindByTokenHash(hashInvitationToken("token")).Return(models.StoreInvitation{
+		Status:    models.InvitationPending,
+		ExpiresAt: time.Now().Add(-time.Minute),
+	}, nil)
+
+	_, status, err := staff.AcceptInvitation(fiberCtx, staffDto.AcceptInvitationRequest{Token: "token"})
+
+	assert.Error(t, err)
+	assert.Equal(t, fiber.StatusGone, status)
+}
+
+func TestStaffService_Manager_Cannot_Change_Own_Access(t *testing.T) {
+	managerID := uuid.New()
+	settings := models.StoreRole{ID: uuid.New(), StoreID: &staffStore.ID, Name: "Settings", Permissions: []models.StoreRolePermission{{Action: models.ActionManageStoreSettings}}}
+	manager := models.StoreUser{ID: uuid.New(), StoreID: staffStore.ID, UserID: managerID, RoleID: &settings.ID, Role: settings.Name, StoreRole: &settings}
+	teardown := setupStaff(t, utils.Principal{UserID: managerID, Stores: []models.StoreUser{manager}})
+	defer teardown()
+
+	storeUserMockDao.EXPECT().FindByStoreAndUser(staffStore.ID, managerID).Return(manager, nil)
+
+	_, status, err := staff.UpdateStaff(fiberCtx, staffDto.UpdateStaffRequest{
+		StoreID: staffStore.ID.String(),
+		UserID:  managerID.String(),
+		Role:    models.RoleManager,
+	})
+
+	assert.Error(t, err)
+	assert.Equal(t, fiber.StatusForbidden, status)
+}
+
+func TestStaffService_Owner_Cannot_Be_Removed(t *testing.T) {
+	teardown := setupStaff(t, staffOwner)
+	defer teardown()
+
+	storeUserMockDao.EXPECT().FindByStoreAndUser(staffStore.ID, staffStore.OwnerID).Return(models.StoreUser{}, gorm.ErrRecordNotFound)
+	storeMockDao.EXPECT().FindById(staffStore.ID.String()).Return(staffStore, nil)
+
+	status, err := staff.RemoveStaff(fiberCtx, staffDto.RemoveStaffRequest{
+		StoreID: staffStore.ID.String(),
+		UserID:  staffStore.OwnerID.String(),
+	})
+
+	assert.Error(t, err)
+	assert.Equal(t, fiber.StatusConflict, status)
+}
+
+// staffLead is a member whose custom role lets them manage the staff and products but
+// not delete products.
+func staffLead() utils.Principal {
+	role := models.StoreRole{ID: uuid.New(), StoreID: &staffStore.ID, Name: "lead", Permissions: []models.StoreRolePermission{
+		{Action: models.ActionAddProduct},
+		{Action: models.ActionUpdateProduct},
+		{Action: models.ActionManageStoreSettings},
+	}}
+	userID := uuid.New()
+	return utils.Principal{UserID: userID, Stores: []models.StoreUser{
+		{ID: uuid.New(), StoreID: staffStore.ID, UserID: userID, RoleID: &role.ID, Role: role.Name, StoreRole: &role},
+	}}
+}
+
+// stocker is a store role that can delete products, which staffLead cannot.
+var stocker = models.StoreRole{ID: uuid.New(), StoreID: &staffStore.ID, Name: "stocker", Permissions: []models.StoreRolePermission{
+	{Action: models.ActionAddProduct},
+	{Action: models.ActionDeleteProduct},
+}}
+
+func TestStaffService_Invite_Cannot_Grant_A_Role_The_Inviter_Lacks(t *testing.T) {
+	teardown := setupStaff(t, staffLead())
+	defer teardown()
+
+	storeMockDao.EXPECT().FindById(staffStore.ID.String()).Return(staffStore, nil)
+	storeRoleMockDao.EXPECT().FindByName(staffStore.ID.String(), stocker.Name).Return(stocker, nil)
+
+	_, status, err := staff.InviteStaff(fiberCtx, staffDto.InviteStaffRequest{
+		StoreID: staffStore.ID.String(),
+		Email:   "jane@company.com",
+		Role:    stocker.Name,
+	})
+
+	assert.EqualError(t, err, "You cannot grant delete_product")
+	assert.Equal(t, fiber.StatusForbidden, status)
+	assert.Empty(t, outbox.sent)
+}
+
+func TestStaffService_Update_Role(t *testing.T) {
+	editor := models.StoreRole{ID: uuid.New(), StoreID: &staffStore.ID, Name: "editor", Permissions: []models.StoreRolePermission{
+		{Action: models.ActionAddProduct},
+		{Action: models.ActionUpdateProduct},
+	}}
+	tests := []struct {
+		name   string
+		role   *models.StoreRole
+		status int
+		err    string
+	}{
+		{name: "role with an action the lead lacks", role: &stocker, status: fiber.StatusForbidden, err: "You cannot grant delete_product"},
+		{name: "role within the lead's actions", role: &editor, status: fiber.StatusOK},
+		{name: "no role", status: fiber.StatusBadRequest, err: "role is required"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			teardown := setupStaff(t, staffLead())
+			defer teardown()
+
+			member := models.StoreUser{ID: uuid.New(), StoreID: staffStore.ID, UserID: uuid.New(), RoleID: &stocker.ID, Role: stocker.Name, StoreRole: &stocker}
+			request := staffDto.UpdateStaffRequest{StoreID: staffStore.ID.String(), UserID: member.UserID.String()}
+			if test.role != nil {
+				request.Role = test.role.Name
+				storeUserMockDao.EXPECT().FindByStoreAndUser(staffStore.ID, member.UserID).Return(member, nil)
+				storeRoleMockDao.EXPECT().FindByName(staffStore.ID.String(), test.role.Name).Return(*test.role, nil)
+			}
+			if test.status == fiber.StatusOK {
+				storeUserMockDao.EXPECT().UpdateAccess(gomock.Any()).DoAndReturn(func(updated models.StoreUser) error {
+					assert.Equal(t, editor.ID, *updated.RoleID)
+					return nil
+				})
+			}
+
+			response, status, err := staff.UpdateStaff(fiberCtx, request)
+
+			assert.Equal(t, test.status, status)
+			if test.err == "" {
+				assert.NoError(t, err)
+				assert.Equal(t, []string{models.ActionAddProduct, models.ActionUpdateProduct}, response.Permissions)
+			} else {
+				assert.EqualError(t, err, test.err)
+			}
+		})
+	}
+}
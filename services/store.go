@@ -1,74 +1,370 @@
 package services
 
 import (
-	"encoding/json"
+	"errors"
+	"net/url"
+	"strings"
 
+	"github.com/abdulmalikraji/e-commerce/db/dao/currencyDao"
+	"github.com/abdulmalikraji/e-commerce/db/dao/languageDao"
 	"github.com/abdulmalikraji/e-commerce/db/dao/storeDao"
 	"github.com/abdulmalikraji/e-commerce/db/dao/userDao"
 	"github.com/abdulmalikraji/e-commerce/db/models"
 	"github.com/abdulmalikraji/e-commerce/dto/storeDto"
+	"github.com/abdulmalikraji/e-commerce/storage"
+	"github.com/abdulmalikraji/e-commerce/utils"
 	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/log"
+	"github.com/google/uuid"
 	"github.com/supabase-community/auth-go"
+	"gorm.io/gorm"
 )
 
 type StoreService interface {
-	CreateStore(ctx *fiber.Ctx, request storeDto.CreateStoreRequest) (int, error)
+	CreateStore(ctx *fiber.Ctx, request storeDto.CreateStoreRequest) (storeDto.GetStoreByIDResponse, int, error)
 	GetStoreByID(ctx *fiber.Ctx, request storeDto.GetStoreByIDRequest) (storeDto.GetStoreByIDResponse, int, error)
 	GetStoreByOwnerID(ctx *fiber.Ctx, request storeDto.GetStoreByOwnerIDRequest) ([]storeDto.GetStoreByOwnerIDResponse, int, error)
 	FindStore(ctx *fiber.Ctx, request storeDto.FindStoreRequest) (storeDto.FindStoreResponse, int, error)
 	GetStoreProducts(ctx *fiber.Ctx, request storeDto.GetStoreProductsRequest) (storeDto.GetStoreProductsResponse, int, error)
+	UpdateStore(ctx *fiber.Ctx, request storeDto.UpdateStoreRequest) (storeDto.GetStoreByIDResponse, int, error)
+	UpdateStoreImage(ctx *fiber.Ctx, request storeDto.UpdateStoreImageRequest) (storeDto.GetStoreByIDResponse, int, error)
+	UploadStoreImage(ctx *fiber.Ctx, request storeDto.UploadStoreImageRequest) (storeDto.GetStoreByIDResponse, int, error)
+	DeleteStore(ctx *fiber.Ctx, request storeDto.DeleteStoreRequest) (int, error)
+	RestoreStore(ctx *fiber.Ctx, request storeDto.RestoreStoreRequest) (storeDto.GetStoreByIDResponse, int, error)
+	GetStoreSettings(ctx *fiber.Ctx, request storeDto.GetStoreSettingsRequest) (storeDto.StoreSettings, int, error)
+	UpdateStoreSettings(ctx *fiber.Ctx, request storeDto.UpdateStoreSettingsRequest) (storeDto.StoreSettings, int, error)
 }
 
 type storeService struct {
-	userDao    userDao.DataAccess
-	authClient auth.Client
-	storeDao   storeDao.DataAccess
+	userDao     userDao.DataAccess
+	authClient  auth.Client
+	storeDao    storeDao.DataAccess
+	currencyDao currencyDao.DataAccess
+	languageDao languageDao.DataAccess
+	blobs       storage.BlobStorage
 }
 
 func NewStoreService(
 	userDao userDao.DataAccess,
 	authClient auth.Client,
 	storeDao storeDao.DataAccess,
+	currencyDao currencyDao.DataAccess,
+	languageDao languageDao.DataAccess,
+	blobs storage.BlobStorage,
 ) StoreService {
 	return storeService{
-		userDao:    userDao,
-		authClient: authClient,
-		storeDao:   storeDao,
+		userDao:     userDao,
+		authClient:  authClient,
+		storeDao:    storeDao,
+		currencyDao: currencyDao,
+		languageDao: languageDao,
+		blobs:       blobs,
 	}
 }
 
-func (s storeService) CreateStore(ctx *fiber.Ctx, request storeDto.CreateStoreRequest) (int, error) {
-	// Verify Owner exists
-	owner, err := s.userDao.FindById(request.OwnerID)
+// CreateStore creates a store owned by the caller.
+func (s storeService) CreateStore(ctx *fiber.Ctx, request storeDto.CreateStoreRequest) (storeDto.GetStoreByIDResponse, int, error) {
+	ownerID, err := utils.GetUserID(ctx)
 	if err != nil {
-		return fiber.StatusInternalServerError, err
+		return storeDto.GetStoreByIDResponse{}, fiber.StatusUnauthorized, err
 	}
-	// Create Store
-	settingsBytes, err := json.Marshal(request.Settings)
+	if err := storeDto.ValidateCreateStore(request); err != nil {
+		return storeDto.GetStoreByIDResponse{}, fiber.StatusBadRequest, fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	// Verify Owner exists
+	owner, err := s.userDao.FindById(ownerID.String())
 	if err != nil {
-		return fiber.StatusInternalServerError, err
+		return storeDto.GetStoreByIDResponse{}, fiber.StatusInternalServerError, err
+	}
+
+	request.Name = strings.TrimSpace(request.Name)
+	if status, err := s.checkNameFree(request.Name, ""); err != nil {
+		return storeDto.GetStoreByIDResponse{}, status, err
+	}
+
+	storeSettings := models.DefaultStoreSettings()
+	if status, err := s.applySettings(&storeSettings, request.Settings); err != nil {
+		return storeDto.GetStoreByIDResponse{}, status, err
 	}
-	storeSettings := string(settingsBytes)
 
-	_, err = s.storeDao.Insert(models.Store{
+	// Create Store
+	store, err := s.storeDao.Insert(models.Store{
 		Name:        request.Name,
 		Description: request.Description,
 		OwnerID:     owner.ID,
 		Settings:    storeSettings,
 	})
 	if err != nil {
+		return storeDto.GetStoreByIDResponse{}, fiber.StatusInternalServerError, err
+	}
+
+	log.Infof("user_id=%s created store %s", owner.ID.String(), store.ID.String())
+	return toStoreResponse(store), fiber.StatusCreated, nil
+}
+
+func (s storeService) GetStoreByID(ctx *fiber.Ctx, request storeDto.GetStoreByIDRequest) (storeDto.GetStoreByIDResponse, int, error) {
+	store, status, err := s.findStore(request.StoreID)
+	if err != nil {
+		return storeDto.GetStoreByIDResponse{}, status, err
+	}
+
+	return toStoreResponse(store), fiber.StatusOK, nil
+}
+
+// UpdateStore changes the name and description of a store; settings are changed through
+// UpdateStoreSettings.
+func (s storeService) UpdateStore(ctx *fiber.Ctx, request storeDto.UpdateStoreRequest) (storeDto.GetStoreByIDResponse, int, error) {
+	if err := storeDto.ValidateUpdateStore(request); err != nil {
+		return storeDto.GetStoreByIDResponse{}, fiber.StatusBadRequest, fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	store, status, err := s.findStore(request.StoreID)
+	if err != nil {
+		return storeDto.GetStoreByIDResponse{}, status, err
+	}
+
+	if request.Name != nil {
+		name := strings.TrimSpace(*request.Name)
+		if status, err := s.checkNameFree(name, store.ID.String()); err != nil {
+			return storeDto.GetStoreByIDResponse{}, status, err
+		}
+		store.Name = name
+	}
+	if request.Description != nil {
+		store.Description = *request.Description
+	}
+
+	if err := s.storeDao.UpdateDetails(store); err != nil {
+		return storeDto.GetStoreByIDResponse{}, fiber.StatusInternalServerError, err
+	}
+	return toStoreResponse(store), fiber.StatusOK, nil
+}
+
+func (s storeService) GetStoreSettings(ctx *fiber.Ctx, request storeDto.GetStoreSettingsRequest) (storeDto.StoreSettings, int, error) {
+	store, status, err := s.findStore(request.StoreID)
+	if err != nil {
+		return storeDto.StoreSettings{}, status, err
+	}
+
+	return toStoreSettings(store.Settings), fiber.StatusOK, nil
+}
+
+// UpdateStoreSettings applies a partial update to the store settings.
+func (s storeService) UpdateStoreSettings(ctx *fiber.Ctx, request storeDto.UpdateStoreSettingsRequest) (storeDto.StoreSettings, int, error) {
+	store, status, err := s.findStore(request.StoreID)
+	if err != nil {
+		return storeDto.StoreSettings{}, status, err
+	}
+
+	if status, err := s.applySettings(&store.Settings, request.StoreSettingsPatch); err != nil {
+		return storeDto.StoreSettings{}, status, err
+	}
+	if err := s.storeDao.UpdateSettings(store.ID.String(), store.Settings); err != nil {
+		return storeDto.StoreSettings{}, fiber.StatusInternalServerError, err
+	}
+	return toStoreSettings(store.Settings), fiber.StatusOK, nil
+}
+
+// UpdateStoreImage sets the store logo to an http(s) URL, or removes it.
+func (s storeService) UpdateStoreImage(ctx *fiber.Ctx, request storeDto.UpdateStoreImageRequest) (storeDto.GetStoreByIDResponse, int, error) {
+	store, status, err := s.findStore(request.StoreID)
+	if err != nil {
+		return storeDto.GetStoreByIDResponse{}, status, err
+	}
+
+	var image *string
+	if request.Image = strings.TrimSpace(request.Image); request.Image != "" {
+		parsed, err := url.Parse(request.Image)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return storeDto.GetStoreByIDResponse{}, fiber.StatusBadRequest, fiber.NewError(fiber.StatusBadRequest, "image must be an http(s) URL")
+		}
+		image = &request.Image
+	}
+
+	if err := s.storeDao.UpdateImage(store.ID.String(), image, ""); err != nil {
+		return storeDto.GetStoreByIDResponse{}, fiber.StatusInternalServerError, err
+	}
+	if store.ImageKey != "" {
+		deleteImageBlobs(s.blobs, store.ImageKey)
+	}
+	store.Image = image
+	return toStoreResponse(store), fiber.StatusOK, nil
+}
+
+// UploadStoreImage replaces the store logo with an uploaded image, removing the
+// previous logo's file if it was uploaded too.
+func (s storeService) UploadStoreImage(ctx *fiber.Ctx, request storeDto.UploadStoreImageRequest) (storeDto.GetStoreByIDResponse, int, error) {
+	store, status, err := s.findStore(request.StoreID)
+	if err != nil {
+		return storeDto.GetStoreByIDResponse{}, status, err
+	}
+
+	stored, status, err := storeImage(s.blobs, "stores/"+store.ID.String()+"/"+uuid.New().String(), request.File)
+	if err != nil {
+		return storeDto.GetStoreByIDResponse{}, status, err
+	}
+	if err := s.storeDao.UpdateImage(store.ID.String(), &stored.URL, stored.Key); err != nil {
+		deleteImageBlobs(s.blobs, stored.Key)
+		return storeDto.GetStoreByIDResponse{}, fiber.StatusInternalServerError, err
+	}
+	if store.ImageKey != "" {
+		deleteImageBlobs(s.blobs, store.ImageKey)
+	}
+	store.Image, store.ImageKey = &stored.URL, stored.Key
+	return toStoreResponse(store), fiber.StatusOK, nil
+}
+
+// DeleteStore soft-deletes a store. Only its owner or an admin may delete it; its staff
+// lose access until it is restored.
+func (s storeService) DeleteStore(ctx *fiber.Ctx, request storeDto.DeleteStoreRequest) (int, error) {
+	store, status, err := s.findStore(request.StoreID)
+	if err != nil {
+		return status, err
+	}
+	if status, err := checkStoreOwner(ctx, store); err != nil {
+		return status, err
+	}
+
+	if err := s.storeDao.SoftDelete(store.ID.String()); err != nil {
 		return fiber.StatusInternalServerError, err
 	}
 
+	log.Infof("store %s deleted", store.ID.String())
 	return fiber.StatusOK, nil
 }
 
-func (s storeService) GetStoreByID(ctx *fiber.Ctx, request storeDto.GetStoreByIDRequest) (storeDto.GetStoreByIDResponse, int, error) {
-	store, err := s.storeDao.FindById(request.StoreID)
+// RestoreStore undoes DeleteStore. Only the owner or an admin may restore a store.
+func (s storeService) RestoreStore(ctx *fiber.Ctx, request storeDto.RestoreStoreRequest) (storeDto.GetStoreByIDResponse, int, error) {
+	if _, err := uuid.Parse(request.StoreID); err != nil {
+		return storeDto.GetStoreByIDResponse{}, fiber.StatusBadRequest, fiber.NewError(fiber.StatusBadRequest, "invalid store id format")
+	}
+	store, err := s.storeDao.FindDeletedById(request.StoreID)
 	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return storeDto.GetStoreByIDResponse{}, fiber.StatusNotFound, fiber.NewError(fiber.StatusNotFound, "Deleted store not found")
+		}
 		return storeDto.GetStoreByIDResponse{}, fiber.StatusInternalServerError, err
 	}
+	if status, err := checkStoreOwner(ctx, store); err != nil {
+		return storeDto.GetStoreByIDResponse{}, status, err
+	}
+
+	if err := s.storeDao.Restore(store.ID.String()); err != nil {
+		return storeDto.GetStoreByIDResponse{}, fiber.StatusInternalServerError, err
+	}
+
+	log.Infof("store %s restored", store.ID.String())
+	store.DelFlg = false
+	return toStoreResponse(store), fiber.StatusOK, nil
+}
+
+func (s storeService) findStore(storeID string) (models.Store, int, error) {
+	if _, err := uuid.Parse(storeID); err != nil {
+		return models.Store{}, fiber.StatusBadRequest, fiber.NewError(fiber.StatusBadRequest, "invalid store id format")
+	}
+	store, err := s.storeDao.FindById(storeID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.Store{}, fiber.StatusNotFound, fiber.NewError(fiber.StatusNotFound, "Store not found")
+		}
+		return models.Store{}, fiber.StatusInternalServerError, err
+	}
+	return store, fiber.StatusOK, nil
+}
+
+// applySettings validates patch and applies it to settings. The currency and language
+// must exist.
+func (s storeService) applySettings(settings *models.StoreSettings, patch storeDto.StoreSettingsPatch) (int, error) {
+	if err := storeDto.ValidateSettings(patch); err != nil {
+		return fiber.StatusBadRequest, fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	if patch.CurrencyID != nil {
+		currency, err := s.currencyDao.FindById(*patch.CurrencyID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return fiber.StatusBadRequest, fiber.NewError(fiber.StatusBadRequest, "settings.currency_id is not a known currency")
+			}
+			return fiber.StatusInternalServerError, err
+		}
+		settings.CurrencyID = currency.ID
+	}
+	if patch.LanguageID != nil {
+		language, err := s.languageDao.FindById(*patch.LanguageID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return fiber.StatusBadRequest, fiber.NewError(fiber.StatusBadRequest, "settings.language_id is not a known language")
+			}
+			return fiber.StatusInternalServerError, err
+		}
+		settings.LanguageID = language.ID
+	}
+
+	if patch.InventoryAlert != nil {
+		settings.InventoryAlert = *patch.InventoryAlert
+	}
+	if patch.InventoryAlertThreshold != nil {
+		settings.InventoryAlertThreshold = *patch.InventoryAlertThreshold
+	}
+	if tax := patch.Tax; tax != nil {
+		if tax.Enabled != nil {
+			settings.Tax.Enabled = *tax.Enabled
+		}
+		if tax.Rate != nil {
+			settings.Tax.Rate = *tax.Rate
+		}
+		if tax.IncludedInPrices != nil {
+			settings.Tax.IncludedInPrices = *tax.IncludedInPrices
+		}
+		if tax.TaxID != nil {
+			settings.Tax.TaxID = strings.TrimSpace(*tax.TaxID)
+		}
+	}
+	if origin := patch.ShippingOrigin; origin != nil {
+		settings.ShippingOrigin = models.StoreShippingOrigin{
+			AddressLine1: strings.TrimSpace(origin.AddressLine1),
+			AddressLine2: strings.TrimSpace(origin.AddressLine2),
+			City:         strings.TrimSpace(origin.City),
+			State:        strings.TrimSpace(origin.State),
+			PostalCode:   strings.TrimSpace(origin.PostalCode),
+			Country:      strings.ToUpper(origin.Country),
+		}
+	}
+	if patch.ReturnPolicyDays != nil {
+		settings.ReturnPolicyDays = *patch.ReturnPolicyDays
+	}
+	if patch.AutoAcceptOrders != nil {
+		settings.AutoAcceptOrders = *patch.AutoAcceptOrders
+	}
+	return fiber.StatusOK, nil
+}
+
+// checkNameFree fails with 409 when another store already uses name.
+func (s storeService) checkNameFree(name string, storeID string) (int, error) {
+	taken, err := s.storeDao.NameTaken(name, storeID)
+	if err != nil {
+		return fiber.StatusInternalServerError, err
+	}
+	if taken {
+		return fiber.StatusConflict, fiber.NewError(fiber.StatusConflict, "A store named "+name+" already exists")
+	}
+	return fiber.StatusOK, nil
+}
+
+// checkStoreOwner only lets the store owner and platform admins through.
+func checkStoreOwner(ctx *fiber.Ctx, store models.Store) (int, error) {
+	principal, ok := utils.GetPrincipal(ctx)
+	if !ok {
+		return fiber.StatusUnauthorized, fiber.NewError(fiber.StatusUnauthorized, "missing user id")
+	}
+	if principal.UserID != store.OwnerID && principal.Role != models.UserRoleAdmin {
+		return fiber.StatusForbidden, fiber.NewError(fiber.StatusForbidden, "Only the store owner can do this")
+	}
+	return fiber.StatusOK, nil
+}
 
+func toStoreResponse(store models.Store) storeDto.GetStoreByIDResponse {
 	var storeImage string
 	if store.Image != nil {
 		storeImage = *store.Image
@@ -80,8 +376,50 @@ func (s storeService) GetStoreByID(ctx *fiber.Ctx, request storeDto.GetStoreByID
 		Description: store.Description,
 		OwnerID:     store.OwnerID.String(),
 		Image:       storeImage,
-		Settings:    store.Settings,
-	}, fiber.StatusOK, nil
+		Settings:    toPublicStoreSettings(store.Settings),
+	}
+}
+
+func toPublicStoreSettings(settings models.StoreSettings) storeDto.PublicStoreSettings {
+	var response storeDto.PublicStoreSettings
+	if settings.CurrencyID != uuid.Nil {
+		response.CurrencyID = settings.CurrencyID.String()
+	}
+	if settings.LanguageID != uuid.Nil {
+		response.LanguageID = settings.LanguageID.String()
+	}
+	return response
+}
+
+func toStoreSettings(settings models.StoreSettings) storeDto.StoreSettings {
+	response := storeDto.StoreSettings{
+		Version:                 settings.Version,
+		InventoryAlert:          settings.InventoryAlert,
+		InventoryAlertThreshold: settings.InventoryAlertThreshold,
+		Tax: storeDto.TaxSettings{
+			Enabled:          settings.Tax.Enabled,
+			Rate:             settings.Tax.Rate,
+			IncludedInPrices: settings.Tax.IncludedInPrices,
+			TaxID:            settings.Tax.TaxID,
+		},
+		ShippingOrigin: storeDto.ShippingOrigin{
+			AddressLine1: settings.ShippingOrigin.AddressLine1,
+			AddressLine2: settings.ShippingOrigin.AddressLine2,
+			City:         settings.ShippingOrigin.City,
+			State:        settings.ShippingOrigin.State,
+			PostalCode:   settings.ShippingOrigin.PostalCode,
+			Country:      settings.ShippingOrigin.Country,
+		},
+		ReturnPolicyDays: settings.ReturnPolicyDays,
+		AutoAcceptOrders: settings.AutoAcceptOrders,
+	}
+	if settings.CurrencyID != uuid.Nil {
+		response.CurrencyID = settings.CurrencyID.String()
+	}
+	if settings.LanguageID != uuid.Nil {
+		response.LanguageID = settings.LanguageID.String()
+	}
+	return response
 }
 
 func (s storeService) FindStore(ctx *fiber.Ctx, request storeDto.FindStoreRequest) (storeDto.FindStoreResponse, int, error) {
@@ -111,8 +449,14 @@ func (s storeService) FindStore(ctx *fiber.Ctx, request storeDto.FindStoreReques
 }
 
 func (s storeService) GetStoreProducts(ctx *fiber.Ctx, request storeDto.GetStoreProductsRequest) (storeDto.GetStoreProductsResponse, int, error) {
+	if _, err := uuid.Parse(request.StoreID); err != nil {
+		return storeDto.GetStoreProductsResponse{}, fiber.StatusBadRequest, fiber.NewError(fiber.StatusBadRequest, "invalid store id format")
+	}
 	store, err := s.storeDao.FindStoreProducts(request.StoreID)
 	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return storeDto.GetStoreProductsResponse{}, fiber.StatusNotFound, fiber.NewError(fiber.StatusNotFound, "Store not found")
+		}
 		return storeDto.GetStoreProductsResponse{}, fiber.StatusInternalServerError, err
 	}
 	var storeImage string
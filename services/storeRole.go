@@ -0,0 +1,231 @@
+package services
+
+import (
+	"errors"
+	"slices"
+	"strings"
+
+	"github.com/abdulmalikraji/e-commerce/db/dao/storeRoleDao"
+	"github.com/abdulmalikraji/e-commerce/db/dao/storeUserDao"
+	"github.com/abdulmalikraji/e-commerce/db/models"
+	"github.com/abdulmalikraji/e-commerce/dto/storeRoleDto"
+	"github.com/abdulmalikraji/e-commerce/utils"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/log"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type StoreRoleService interface {
+	ListRoles(ctx *fiber.Ctx, request storeRoleDto.ListRolesRequest) ([]storeRoleDto.RoleResponse, int, error)
+	ListActions(ctx *fiber.Ctx) ([]string, int, error)
+	CreateRole(ctx *fiber.Ctx, request storeRoleDto.CreateRoleRequest) (storeRoleDto.RoleResponse, int, error)
+	UpdateRole(ctx *fiber.Ctx, request storeRoleDto.UpdateRoleRequest) (storeRoleDto.RoleResponse, int, error)
+	DeleteRole(ctx *fiber.Ctx, request storeRoleDto.DeleteRoleRequest) (int, error)
+}
+
+type storeRoleService struct {
+	storeRoleDao storeRoleDao.DataAccess
+	storeUserDao storeUserDao.DataAccess
+}
+
+func NewStoreRoleService(storeRoleDao storeRoleDao.DataAccess, storeUserDao storeUserDao.DataAccess) StoreRoleService {
+	return storeRoleService{
+		storeRoleDao: storeRoleDao,
+		storeUserDao: storeUserDao,
+	}
+}
+
+// ListRoles returns the system roles followed by the store's own roles.
+func (s storeRoleService) ListRoles(ctx *fiber.Ctx, request storeRoleDto.ListRolesRequest) ([]storeRoleDto.RoleResponse, int, error) {
+	roles, err := s.storeRoleDao.FindForStore(request.StoreID)
+	if err != nil {
+		return nil, fiber.StatusInternalServerError, err
+	}
+
+	response := []storeRoleDto.RoleResponse{}
+	for _, role := range roles {
+		response = append(response, toRoleResponse(role))
+	}
+	return response, fiber.StatusOK, nil
+}
+
+// ListActions returns every action a role may grant, from the action catalogue.
+func (s storeRoleService) ListActions(ctx *fiber.Ctx) ([]string, int, error) {
+	actions, err := s.storeRoleDao.FindActions()
+	if err != nil {
+		return nil, fiber.StatusInternalServerError, err
+	}
+	return actions, fiber.StatusOK, nil
+}
+
+func (s storeRoleService) CreateRole(ctx *fiber.Ctx, request storeRoleDto.CreateRoleRequest) (storeRoleDto.RoleResponse, int, error) {
+	storeID, err := uuid.Parse(request.StoreID)
+	if err != nil {
+		return storeRoleDto.RoleResponse{}, fiber.StatusBadRequest, fiber.NewError(fiber.StatusBadRequest, "invalid store id format")
+	}
+
+	name, status, err := s.validateRoleName(request.StoreID, request.Name, uuid.Nil)
+	if err != nil {
+		return storeRoleDto.RoleResponse{}, status, err
+	}
+	actions, status, err := validateRoleActions(ctx, storeID, request.Permissions)
+	if err != nil {
+		return storeRoleDto.RoleResponse{}, status, err
+	}
+
+	role := models.StoreRole{StoreID: &storeID, Name: name, Description: request.Description}
+	for _, action := range actions {
+		role.Permissions = append(role.Permissions, models.StoreRolePermission{Action: action})
+	}
+	role, err = s.storeRoleDao.Insert(role)
+	if err != nil {
+		return storeRoleDto.RoleResponse{}, fiber.StatusInternalServerError, err
+	}
+
+	log.Infof("store %s created role %s with %v", storeID.String(), role.Name, actions)
+	return toRoleResponse(role), fiber.StatusCreated, nil
+}
+
+// UpdateRole changes one of the store's own roles. Members holding the role get the
+// new permissions on their next request.
+func (s storeRoleService) UpdateRole(ctx *fiber.Ctx, request storeRoleDto.UpdateRoleRequest) (storeRoleDto.RoleResponse, int, error) {
+	role, status, err := s.findStoreRole(request.RoleID, request.StoreID)
+	if err != nil {
+		return storeRoleDto.RoleResponse{}, status, err
+	}
+
+	if request.Name != nil {
+		name, status, err := s.validateRoleName(request.StoreID, *request.Name, role.ID)
+		if err != nil {
+			return storeRoleDto.RoleResponse{}, status, err
+		}
+		role.Name = name
+	}
+	if request.Description != nil {
+		role.Description = *request.Description
+	}
+	var actions []string
+	if request.Permissions != nil {
+		actions, status, err = validateRoleActions(ctx, *role.StoreID, *request.Permissions)
+		if err != nil {
+			return storeRoleDto.RoleResponse{}, status, err
+		}
+	}
+
+	err = s.storeRoleDao.Transaction(func(tx *gorm.DB) error {
+		roles := s.storeRoleDao.WithTx(tx)
+		if err := roles.Update(role); err != nil {
+			return err
+		}
+		if request.Permissions == nil {
+			return nil
+		}
+		return roles.ReplacePermissions(role.ID, actions)
+	})
+	if err != nil {
+		return storeRoleDto.RoleResponse{}, fiber.StatusInternalServerError, err
+	}
+
+	if request.Permissions != nil {
+		role.Permissions = nil
+		for _, action := range actions {
+			role.Permissions = append(role.Permissions, models.StoreRolePermission{RoleID: role.ID, Action: action})
+		}
+	}
+	return toRoleResponse(role), fiber.StatusOK, nil
+}
+
+// DeleteRole removes one of the store's own roles. Roles still held by members cannot
+// be deleted.
+func (s storeRoleService) DeleteRole(ctx *fiber.Ctx, request storeRoleDto.DeleteRoleRequest) (int, error) {
+	role, status, err := s.findStoreRole(request.RoleID, request.StoreID)
+	if err != nil {
+		return status, err
+	}
+
+	members, err := s.storeUserDao.CountByRole(role.ID.String())
+	if err != nil {
+		return fiber.StatusInternalServerError, err
+	}
+	if members > 0 {
+		return fiber.StatusConflict, fiber.NewError(fiber.StatusConflict, "The role is assigned to staff members")
+	}
+
+	if err := s.storeRoleDao.Delete(role.ID.String()); err != nil {
+		return fiber.StatusInternalServerError, err
+	}
+	return fiber.StatusOK, nil
+}
+
+// findStoreRole loads a role the store may change: system roles are read-only.
+func (s storeRoleService) findStoreRole(roleID string, storeID string) (models.StoreRole, int, error) {
+	role, err := s.storeRoleDao.FindByIdForStore(roleID, storeID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.StoreRole{}, fiber.StatusNotFound, fiber.NewError(fiber.StatusNotFound, "Role not found")
+		}
+		return models.StoreRole{}, fiber.StatusInternalServerError, err
+	}
+	if role.IsSystem() {
+		return models.StoreRole{}, fiber.StatusForbidden, fiber.NewError(fiber.StatusForbidden, "System roles cannot be changed")
+	}
+	return role, fiber.StatusOK, nil
+}
+
+// validateRoleName checks that name is free in the store, including the system role
+// names. roleID is the role being renamed, if any.
+func (s storeRoleService) validateRoleName(storeID string, name string, roleID uuid.UUID) (string, int, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", fiber.StatusBadRequest, fiber.NewError(fiber.StatusBadRequest, "name is required")
+	}
+	if strings.EqualFold(name, models.RoleOwner) {
+		return "", fiber.StatusBadRequest, fiber.NewError(fiber.StatusBadRequest, "owner is reserved")
+	}
+
+	existing, err := s.storeRoleDao.FindByName(storeID, name)
+	if err == nil && existing.ID != roleID {
+		return "", fiber.StatusConflict, fiber.NewError(fiber.StatusConflict, "A role named "+name+" already exists")
+	}
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", fiber.StatusInternalServerError, err
+	}
+	return name, fiber.StatusOK, nil
+}
+
+// validateRoleActions checks and de-duplicates actions. Staff may only grant actions
+// they have themselves, so a role cannot be used to raise anyone's access above theirs.
+func validateRoleActions(ctx *fiber.Ctx, storeID uuid.UUID, actions []string) ([]string, int, error) {
+	principal, ok := utils.GetPrincipal(ctx)
+	if !ok {
+		return nil, fiber.StatusUnauthorized, fiber.NewError(fiber.StatusUnauthorized, "missing user id")
+	}
+
+	valid := []string{}
+	for _, action := range actions {
+		if !models.IsStoreAction(action) {
+			return nil, fiber.StatusBadRequest, fiber.NewError(fiber.StatusBadRequest, "unknown action "+action)
+		}
+		if !principal.Can(storeID, action) {
+			return nil, fiber.StatusForbidden, fiber.NewError(fiber.StatusForbidden, "You cannot grant "+action)
+		}
+		if !slices.Contains(valid, action) {
+			valid = append(valid, action)
+		}
+	}
+	return valid, fiber.StatusOK, nil
+}
+
+func toRoleResponse(role models.StoreRole) storeRoleDto.RoleResponse {
+	permissions := role.Actions()
+	slices.Sort(permissions)
+	return storeRoleDto.RoleResponse{
+		ID:          role.ID.String(),
+		Name:        role.Name,
+		Description: role.Description,
+		System:      role.IsSystem(),
+		Permissions: permissions,
+		CreatedAt:   role.CreatedAt,
+	}
+}
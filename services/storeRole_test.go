@@ -0,0 +1,109 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/abdulmalikraji/e-commerce/db/models"
+	"github.com/abdulmalikraji/e-commerce/dto/storeRoleDto"
+	"github.com/abdulmalikraji/e-commerce/mocks/dao/storeRoleDao"
+	"github.com/abdulmalikraji/e-commerce/mocks/dao/storeUserDao"
+	"github.com/abdulmalikraji/e-commerce/utils"
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+	"gorm.io/gorm"
+)
+
+var storeRoles StoreRoleService
+
+func setupStoreRoles(t *testing.T, principal utils.Principal) func() {
+	ct := gomock.NewController(t)
+
+	app := fiber.New()
+	fiberCtx = app.AcquireCtx(&fasthttp.RequestCtx{})
+	utils.SetPrincipal(fiberCtx, principal)
+
+	storeRoleMockDao = storeRoleDao.NewMockDataAccess(ct)
+	storeUserMockDao = storeUserDao.NewMockDataAccess(ct)
+
+	storeRoles = NewStoreRoleService(storeRoleMockDao, storeUserMockDao)
+	return func() {
+		storeRoles = nil
+		app.ReleaseCtx(fiberCtx)
+		ct.Finish()
+	}
+}
+
+func TestStoreRoleService_Create_Stores_Role_With_Permissions(t *testing.T) {
+	teardown := setupStoreRoles(t, staffOwner)
+	defer teardown()
+
+	storeRoleMockDao.EXPECT().FindByName(staffStore.ID.String(), "Analyst").Return(models.StoreRole{}, gorm.ErrRecordNotFound)
+	storeRoleMockDao.EXPECT().Insert(gomock.Any()).DoAndReturn(func(role models.StoreRole) (models.StoreRole, error) {
+		role.ID = uuid.New()
+		return role, nil
+	})
+
+	response, status, err := storeRoles.CreateRole(fiberCtx, storeRoleDto.CreateRoleRequest{
+		StoreID:     staffStore.ID.String(),
+		Name:        " Analyst ",
+		Permissions: []string{models.ActionViewAnalytics, models.ActionManageCoupons, models.ActionViewAnalytics},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, fiber.StatusCreated, status)
+	assert.Equal(t, "Analyst", response.Name)
+	assert.False(t, response.System)
+	assert.Equal(t, []string{models.ActionManageCoupons, models.ActionViewAnalytics}, response.Permissions)
+}
+
+func TestStoreRoleService_Create_Rejects_Actions_The_Caller_Lacks(t *testing.T) {
+	settings := models.StoreRole{ID: uuid.New(), StoreID: &staffStore.ID, Name: "Settings", Permissions: []models.StoreRolePermission{{Action: models.ActionManageStoreSettings}}}
+	member := models.StoreUser{StoreID: staffStore.ID, UserID: uuid.New(), RoleID: &settings.ID, Role: settings.Name, StoreRole: &settings}
+	teardown := setupStoreRoles(t, utils.Principal{UserID: member.UserID, Stores: []models.StoreUser{member}})
+	defer teardown()
+
+	storeRoleMockDao.EXPECT().FindByName(staffStore.ID.String(), "Cashier").Return(models.StoreRole{}, gorm.ErrRecordNotFound)
+
+	_, status, err := storeRoles.CreateRole(fiberCtx, storeRoleDto.CreateRoleRequest{
+		StoreID:     staffStore.ID.String(),
+		Name:        "Cashier",
+		Permissions: []string{models.ActionManageOrders},
+	})
+
+	assert.Error(t, err)
+	assert.Equal(t, fiber.StatusForbidden, status)
+}
+
+func TestStoreRoleService_Delete_Rejects_System_And_Assigned_Roles(t *testing.T) {
+	teardown := setupStoreRoles(t, staffOwner)
+	defer teardown()
+
+	system := models.StoreRole{ID: uuid.New(), Name: models.RoleWorker}
+	storeRoleMockDao.EXPECT().FindByIdForStore(system.ID.String(), staffStore.ID.String()).Return(system, nil)
+	status, err := storeRoles.DeleteRole(fiberCtx, storeRoleDto.DeleteRoleRequest{StoreID: staffStore.ID.String(), RoleID: system.ID.String()})
+	assert.Error(t, err)
+	assert.Equal(t, fiber.StatusForbidden, status)
+
+	custom := models.StoreRole{ID: uuid.New(), StoreID: &staffStore.ID, Name: "Packer"}
+	storeRoleMockDao.EXPECT().FindByIdForStore(custom.ID.String(), staffStore.ID.String()).Return(custom, nil)
+	storeUserMockDao.EXPECT().CountByRole(custom.ID.String()).Return(int64(2), nil)
+	status, err = storeRoles.DeleteRole(fiberCtx, storeRoleDto.DeleteRoleRequest{StoreID: staffStore.ID.String(), RoleID: custom.ID.String()})
+	assert.Error(t, err)
+	assert.Equal(t, fiber.StatusConflict, status)
+}
+
+func TestStoreRoleService_List_Actions_Reads_The_Catalogue(t *testing.T) {
+	teardown := setupStoreRoles(t, staffOwner)
+	defer teardown()
+
+	storeRoleMockDao.EXPECT().FindActions().Return([]string{models.ActionAddProduct, models.ActionManageOrders}, nil)
+
+	actions, status, err := storeRoles.ListActions(fiberCtx)
+
+	assert.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, status)
+	assert.Equal(t, []string{models.ActionAddProduct, models.ActionManageOrders}, actions)
+}
@@ -0,0 +1,176 @@
+package services
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/abdulmalikraji/e-commerce/db/models"
+	"github.com/abdulmalikraji/e-commerce/dto/storeDto"
+	"github.com/abdulmalikraji/e-commerce/mocks/dao/currencyDao"
+	"github.com/abdulmalikraji/e-commerce/mocks/dao/languageDao"
+	"github.com/abdulmalikraji/e-commerce/mocks/dao/storeDao"
+	"github.com/abdulmalikraji/e-commerce/mocks/dao/userDao"
+	"github.com/abdulmalikraji/e-commerce/utils"
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+	"gorm.io/gorm"
+)
+
+var currencyMockDao *currencyDao.MockDataAccess
+var languageMockDao *languageDao.MockDataAccess
+
+var stores StoreService
+
+var storeCurrency = models.Currency{ID: uuid.New(), Code: "USD"}
+var storeLanguage = models.Language{ID: uuid.New(), Code: "en"}
+
+func storeSettings() storeDto.StoreSettingsPatch {
+	currencyID, languageID := storeCurrency.ID.String(), storeLanguage.ID.String()
+	return storeDto.StoreSettingsPatch{CurrencyID: &currencyID, LanguageID: &languageID}
+}
+
+func setupStores(t *testing.T, principal utils.Principal) func() {
+	ct := gomock.NewController(t)
+
+	app := fiber.New()
+	fiberCtx = app.AcquireCtx(&fasthttp.RequestCtx{})
+	utils.SetPrincipal(fiberCtx, principal)
+
+	storeMockDao = storeDao.NewMockDataAccess(ct)
+	userMockDao = userDao.NewMockDataAccess(ct)
+	currencyMockDao = currencyDao.NewMockDataAccess(ct)
+	languageMockDao = languageDao.NewMockDataAccess(ct)
+
+	stores = NewStoreService(userMockDao, nil, storeMockDao, currencyMockDao, languageMockDao, nil)
+	return func() {
+		stores = nil
+		app.ReleaseCtx(fiberCtx)
+		ct.Finish()
+	}
+}
+
+func TestStoreService_Create_Is_Owned_By_The_Caller(t *testing.T) {
+	ownerID := uuid.New()
+	teardown := setupStores(t, utils.Principal{UserID: ownerID, Role: models.UserRoleSeller})
+	defer teardown()
+
+	userMockDao.EXPECT().FindById(ownerID.String()).Return(models.User{ID: ownerID}, nil)
+	storeMockDao.EXPECT().NameTaken("Corner Shop", "").Return(false, nil)
+	currencyMockDao.EXPECT().FindById(storeCurrency.ID.String()).Return(storeCurrency, nil)
+	languageMockDao.EXPECT().FindById(storeLanguage.ID.String()).Return(storeLanguage, nil)
+	storeMockDao.EXPECT().Insert(gomock.Any()).DoAndReturn(func(store models.Store) (models.Store, error) {
+		assert.Equal(t, ownerID, store.OwnerID)
+		assert.Equal(t, models.DefaultStoreSettings().ReturnPolicyDays, store.Settings.ReturnPolicyDays)
+		store.ID = uuid.New()
+		return store, nil
+	})
+
+	response, status, err := stores.CreateStore(fiberCtx, storeDto.CreateStoreRequest{Name: " Corner Shop ", Settings: storeSettings()})
+
+	assert.NoError(t, err)
+	assert.Equal(t, fiber.StatusCreated, status)
+	assert.Equal(t, ownerID.String(), response.OwnerID)
+	assert.Equal(t, storeCurrency.ID.String(), response.Settings.CurrencyID)
+}
+
+func TestStoreService_Create_Rejects_Taken_Name(t *testing.T) {
+	ownerID := uuid.New()
+	teardown := setupStores(t, utils.Principal{UserID: ownerID, Role: models.UserRoleSeller})
+	defer teardown()
+
+	userMockDao.EXPECT().FindById(ownerID.String()).Return(models.User{ID: ownerID}, nil)
+	storeMockDao.EXPECT().NameTaken("Corner Shop", "").Return(true, nil)
+
+	_, status, err := stores.CreateStore(fiberCtx, storeDto.CreateStoreRequest{Name: "Corner Shop", Settings: storeSettings()})
+
+	assert.Error(t, err)
+	assert.Equal(t, fiber.StatusConflict, status)
+}
+
+func TestStoreService_Get_Store_Only_Shows_Public_Settings(t *testing.T) {
+	teardown := setupStores(t, utils.Principal{UserID: uuid.New()})
+	defer teardown()
+
+	store := staffStore
+	store.Settings = models.DefaultStoreSettings()
+	store.Settings.CurrencyID = storeCurrency.ID
+	store.Settings.Tax = models.StoreTaxSettings{Enabled: true, Rate: 7.5, TaxID: "VAT-123"}
+	store.Settings.ShippingOrigin.AddressLine1 = "1 Warehouse Road"
+	storeMockDao.EXPECT().FindById(store.ID.String()).Return(store, nil)
+
+	response, status, err := stores.GetStoreByID(fiberCtx, storeDto.GetStoreByIDRequest{StoreID: store.ID.String()})
+
+	assert.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, status)
+	assert.Equal(t, storeCurrency.ID.String(), response.Settings.CurrencyID)
+	body, err := json.Marshal(response)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(body), "VAT-123")
+	assert.NotContains(t, string(body), "Warehouse Road")
+}
+
+func TestStoreService_Only_Owner_Deletes_Store(t *testing.T) {
+	manager := models.StoreUser{StoreID: staffStore.ID, UserID: uuid.New(), Role: models.RoleManager}
+	teardown := setupStores(t, utils.Principal{UserID: manager.UserID, Stores: []models.StoreUser{manager}})
+	defer teardown()
+
+	storeMockDao.EXPECT().FindById(staffStore.ID.String()).Return(staffStore, nil)
+
+	status, err := stores.DeleteStore(fiberCtx, storeDto.DeleteStoreRequest{StoreID: staffStore.ID.String()})
+
+	assert.Error(t, err)
+	assert.Equal(t, fiber.StatusForbidden, status)
+}
+
+func TestStoreService_UpdateSettings_Patches_Only_Given_Fields(t *testing.T) {
+	teardown := setupStores(t, staffOwner)
+	defer teardown()
+
+	store := staffStore
+	store.Settings = models.DefaultStoreSettings()
+	store.Settings.CurrencyID = storeCurrency.ID
+	store.Settings.Tax = models.StoreTaxSettings{Enabled: true, Rate: 7.5}
+	storeMockDao.EXPECT().FindById(store.ID.String()).Return(store, nil)
+	var saved models.StoreSettings
+	storeMockDao.EXPECT().UpdateSettings(store.ID.String(), gomock.Any()).DoAndReturn(func(id string, settings models.StoreSettings) error {
+		saved = settings
+		return nil
+	})
+
+	rate, days := 10.0, 30
+	response, status, err := stores.UpdateStoreSettings(fiberCtx, storeDto.UpdateStoreSettingsRequest{
+		StoreID: store.ID.String(),
+		StoreSettingsPatch: storeDto.StoreSettingsPatch{
+			Tax:              &storeDto.TaxSettingsPatch{Rate: &rate},
+			ReturnPolicyDays: &days,
+		},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, status)
+	assert.True(t, saved.Tax.Enabled)
+	assert.Equal(t, 10.0, saved.Tax.Rate)
+	assert.Equal(t, 30, saved.ReturnPolicyDays)
+	assert.Equal(t, storeCurrency.ID, saved.CurrencyID)
+	assert.Equal(t, storeCurrency.ID.String(), response.CurrencyID)
+}
+
+func TestStoreService_UpdateSettings_Rejects_Unknown_Language(t *testing.T) {
+	teardown := setupStores(t, staffOwner)
+	defer teardown()
+
+	languageID := uuid.NewString()
+	storeMockDao.EXPECT().FindById(staffStore.ID.String()).Return(staffStore, nil)
+	languageMockDao.EXPECT().FindById(languageID).Return(models.Language{}, gorm.ErrRecordNotFound)
+
+	_, status, err := stores.UpdateStoreSettings(fiberCtx, storeDto.UpdateStoreSettingsRequest{
+		StoreID:            staffStore.ID.String(),
+		StoreSettingsPatch: storeDto.StoreSettingsPatch{LanguageID: &languageID},
+	})
+
+	assert.Error(t, err)
+	assert.Equal(t, fiber.StatusBadRequest, status)
+}
@@ -0,0 +1,468 @@
+package services
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/abdulmalikraji/e-commerce/db/dao/categoryDao"
+	"github.com/abdulmalikraji/e-commerce/db/dao/productDao"
+	"github.com/abdulmalikraji/e-commerce/db/dao/tagDao"
+	"github.com/abdulmalikraji/e-commerce/db/models"
+	"github.com/abdulmalikraji/e-commerce/dto/taxonomyDto"
+	"github.com/abdulmalikraji/e-commerce/utils"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/log"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// TaxonomyService owns how products are classified: the category tree (main
+// categories and the subcategories below them) and tags.
+type TaxonomyService interface {
+	GetTree(ctx *fiber.Ctx) ([]taxonomyDto.CategoryNode, int, error)
+	GetSubtree(ctx *fiber.Ctx, request taxonomyDto.GetSubtreeRequest) (taxonomyDto.CategoryNode, int, error)
+	GetBreadcrumbs(ctx *fiber.Ctx, request taxonomyDto.GetBreadcrumbsRequest) (taxonomyDto.BreadcrumbsResponse, int, error)
+	CreateCategory(ctx *fiber.Ctx, request taxonomyDto.CreateCategoryRequest) (taxonomyDto.CategoryNode, int, error)
+	UpdateCategory(ctx *fiber.Ctx, request taxonomyDto.UpdateCategoryRequest) (taxonomyDto.CategoryNode, int, error)
+	MoveCategory(ctx *fiber.Ctx, request taxonomyDto.MoveCategoryRequest) (taxonomyDto.CategoryNode, int, error)
+	DeleteCategory(ctx *fiber.Ctx, request taxonomyDto.DeleteCategoryRequest) (int, error)
+	ListTags(ctx *fiber.Ctx) ([]taxonomyDto.TagResponse, int, error)
+	RecategorizeProducts(ctx *fiber.Ctx, request taxonomyDto.RecategorizeRequest) (taxonomyDto.RecategorizeResponse, int, error)
+}
+
+type taxonomyService struct {
+	categoryDao categoryDao.DataAccess
+	tagDao      tagDao.DataAccess
+	productDao  productDao.DataAccess
+	classifier  classifier
+}
+
+func NewTaxonomyService(categoryDao categoryDao.DataAccess, tagDao tagDao.DataAccess, productDao productDao.DataAccess) TaxonomyService {
+	return taxonomyService{
+		categoryDao: categoryDao,
+		tagDao:      tagDao,
+		productDao:  productDao,
+		classifier:  classifier{categoryDao: categoryDao, tagDao: tagDao},
+	}
+}
+
+// GetTree returns every root category with its subtree.
+func (s taxonomyService) GetTree(ctx *fiber.Ctx) ([]taxonomyDto.CategoryNode, int, error) {
+	categories, err := s.categoryDao.FindAll()
+	if err != nil {
+		return nil, fiber.StatusInternalServerError, err
+	}
+
+	children := childrenByParent(categories)
+	tree := []taxonomyDto.CategoryNode{}
+	for _, category := range categories {
+		if category.ParentID == nil {
+			tree = append(tree, toCategoryNode(category, children))
+		}
+	}
+	return tree, fiber.StatusOK, nil
+}
+
+func (s taxonomyService) GetSubtree(ctx *fiber.Ctx, request taxonomyDto.GetSubtreeRequest) (taxonomyDto.CategoryNode, int, error) {
+	if _, err := uuid.Parse(request.CategoryID); err != nil {
+		return taxonomyDto.CategoryNode{}, fiber.StatusBadRequest, fiber.NewError(fiber.StatusBadRequest, "invalid category id format")
+	}
+	return s.subtree(request.CategoryID)
+}
+
+// GetBreadcrumbs returns the path from the root category down to the product's main
+// category.
+func (s taxonomyService) GetBreadcrumbs(ctx *fiber.Ctx, request taxonomyDto.GetBreadcrumbsRequest) (taxonomyDto.BreadcrumbsResponse, int, error) {
+	if _, err := uuid.Parse(request.ProductID); err != nil {
+		return taxonomyDto.BreadcrumbsResponse{}, fiber.StatusBadRequest, fiber.NewError(fiber.StatusBadRequest, "invalid product id format")
+	}
+	product, err := s.productDao.FindById(request.ProductID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return taxonomyDto.BreadcrumbsResponse{}, fiber.StatusNotFound, fiber.NewError(fiber.StatusNotFound, "Product not found")
+		}
+		return taxonomyDto.BreadcrumbsResponse{}, fiber.StatusInternalServerError, err
+	}
+
+	ancestors, err := s.categoryDao.FindAncestors(product.CategoryID.String())
+	if err != nil {
+		return taxonomyDto.BreadcrumbsResponse{}, fiber.StatusInternalServerError, err
+	}
+
+	response := taxonomyDto.BreadcrumbsResponse{
+		ProductID:   product.ID.String(),
+		ProductName: product.Name,
+		Categories:  []taxonomyDto.Breadcrumb{},
+	}
+	for _, category := range ancestors {
+		response.Categories = append(response.Categories, taxonomyDto.Breadcrumb{ID: category.ID.String(), Name: category.Name})
+	}
+	return response, fiber.StatusOK, nil
+}
+
+// MoveCategory puts a category and its subtree under a new parent. A category cannot
+// be moved under itself or any of its descendants.
+func (s taxonomyService) MoveCategory(ctx *fiber.Ctx, request taxonomyDto.MoveCategoryRequest) (taxonomyDto.CategoryNode, int, error) {
+	userID, err := utils.GetUserID(ctx)
+	if err != nil {
+		return taxonomyDto.CategoryNode{}, fiber.StatusUnauthorized, err
+	}
+	if _, err := uuid.Parse(request.CategoryID); err != nil {
+		return taxonomyDto.CategoryNode{}, fiber.StatusBadRequest, fiber.NewError(fiber.StatusBadRequest, "invalid category id format")
+	}
+	category, status, err := s.findCategory(request.CategoryID)
+	if err != nil {
+		return taxonomyDto.CategoryNode{}, status, err
+	}
+
+	var parent *models.Category
+	if request.ParentID != nil {
+		if _, err := uuid.Parse(*request.ParentID); err != nil {
+			return taxonomyDto.CategoryNode{}, fiber.StatusBadRequest, fiber.NewError(fiber.StatusBadRequest, "parent_id must be a valid UUID")
+		}
+		found, err := s.categoryDao.FindById(*request.ParentID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return taxonomyDto.CategoryNode{}, fiber.StatusBadRequest, fiber.NewError(fiber.StatusBadRequest, "parent_id is not a known category")
+			}
+			return taxonomyDto.CategoryNode{}, fiber.StatusInternalServerError, err
+		}
+		// Move repeats this check under lock; this one just fails fast.
+		if found.ID == category.ID || found.IsDescendantOf(category) {
+			return taxonomyDto.CategoryNode{}, fiber.StatusBadRequest, fiber.NewError(fiber.StatusBadRequest, "A category cannot be moved under itself or its descendants")
+		}
+		parent = &found
+	}
+
+	category.UpdatedBy = &userID
+	if err := s.categoryDao.Move(category, parent); err != nil {
+		switch {
+		case errors.Is(err, categoryDao.ErrMoveIntoSubtree):
+			return taxonomyDto.CategoryNode{}, fiber.StatusBadRequest, fiber.NewError(fiber.StatusBadRequest, "A category cannot be moved under itself or its descendants")
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			return taxonomyDto.CategoryNode{}, fiber.StatusNotFound, fiber.NewError(fiber.StatusNotFound, "Category not found")
+		}
+		return taxonomyDto.CategoryNode{}, fiber.StatusInternalServerError, err
+	}
+
+	log.Infof("user_id=%s moved category %s under %v", userID.String(), category.ID.String(), request.ParentID)
+	return s.subtree(category.ID.String())
+}
+
+func (s taxonomyService) CreateCategory(ctx *fiber.Ctx, request taxonomyDto.CreateCategoryRequest) (taxonomyDto.CategoryNode, int, error) {
+	userID, err := utils.GetUserID(ctx)
+	if err != nil {
+		return taxonomyDto.CategoryNode{}, fiber.StatusUnauthorized, err
+	}
+	if err := taxonomyDto.ValidateCreateCategory(request); err != nil {
+		return taxonomyDto.CategoryNode{}, fiber.StatusBadRequest, fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	name := strings.TrimSpace(request.Name)
+	if status, err := s.checkCategoryName(name, ""); err != nil {
+		return taxonomyDto.CategoryNode{}, status, err
+	}
+
+	category := models.Category{Name: name, Description: request.Description, CreatedBy: &userID, UpdatedBy: &userID}
+	if request.ParentID != nil {
+		parent, err := s.categoryDao.FindById(*request.ParentID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return taxonomyDto.CategoryNode{}, fiber.StatusBadRequest, fiber.NewError(fiber.StatusBadRequest, "parent_id is not a known category")
+			}
+			return taxonomyDto.CategoryNode{}, fiber.StatusInternalServerError, err
+		}
+		category.ParentID = &parent.ID
+	}
+
+	category, err = s.categoryDao.Insert(category)
+	if err != nil {
+		return taxonomyDto.CategoryNode{}, fiber.StatusInternalServerError, err
+	}
+
+	log.Infof("user_id=%s created category %s", userID.String(), category.ID.String())
+	return toCategoryNode(category, nil), fiber.StatusCreated, nil
+}
+
+// UpdateCategory renames or describes a category. A rename refreshes the search
+// documents of the products classified under it.
+func (s taxonomyService) UpdateCategory(ctx *fiber.Ctx, request taxonomyDto.UpdateCategoryRequest) (taxonomyDto.CategoryNode, int, error) {
+	userID, err := utils.GetUserID(ctx)
+	if err != nil {
+		return taxonomyDto.CategoryNode{}, fiber.StatusUnauthorized, err
+	}
+	if _, err := uuid.Parse(request.CategoryID); err != nil {
+		return taxonomyDto.CategoryNode{}, fiber.StatusBadRequest, fiber.NewError(fiber.StatusBadRequest, "invalid category id format")
+	}
+	if err := taxonomyDto.ValidateUpdateCategory(request); err != nil {
+		return taxonomyDto.CategoryNode{}, fiber.StatusBadRequest, fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	category, status, err := s.findCategory(request.CategoryID)
+	if err != nil {
+		return taxonomyDto.CategoryNode{}, status, err
+	}
+
+	renamed := false
+	if request.Name != nil {
+		name := strings.TrimSpace(*request.Name)
+		if status, err := s.checkCategoryName(name, category.ID.String()); err != nil {
+			return taxonomyDto.CategoryNode{}, status, err
+		}
+		renamed = name != category.Name
+		category.Name = name
+	}
+	if request.Description != nil {
+		category.Description = *request.Description
+	}
+	category.UpdatedBy = &userID
+
+	if err := s.categoryDao.Update(category); err != nil {
+		return taxonomyDto.CategoryNode{}, fiber.StatusInternalServerError, err
+	}
+	if renamed {
+		if err := s.productDao.RefreshCategorySearchVectors(category.ID.String()); err != nil {
+			return taxonomyDto.CategoryNode{}, fiber.StatusInternalServerError, err
+		}
+	}
+	return s.subtree(category.ID.String())
+}
+
+// DeleteCategory soft-deletes a category that has no subcategories and no products.
+func (s taxonomyService) DeleteCategory(ctx *fiber.Ctx, request taxonomyDto.DeleteCategoryRequest) (int, error) {
+	userID, err := utils.GetUserID(ctx)
+	if err != nil {
+		return fiber.StatusUnauthorized, err
+	}
+	if _, err := uuid.Parse(request.CategoryID); err != nil {
+		return fiber.StatusBadRequest, fiber.NewError(fiber.StatusBadRequest, "invalid category id format")
+	}
+	category, status, err := s.findCategory(request.CategoryID)
+	if err != nil {
+		return status, err
+	}
+
+	children, err := s.categoryDao.FindChildren(category.ID.String())
+	if err != nil {
+		return fiber.StatusInternalServerError, err
+	}
+	if len(children) > 0 {
+		return fiber.StatusConflict, fiber.NewError(fiber.StatusConflict, "The category has subcategories")
+	}
+	products, err := s.categoryDao.CountProducts(category.ID.String())
+	if err != nil {
+		return fiber.StatusInternalServerError, err
+	}
+	if products > 0 {
+		return fiber.StatusConflict, fiber.NewError(fiber.StatusConflict, "The category has products; recategorize them first")
+	}
+
+	if err := s.categoryDao.SoftDelete(category.ID.String()); err != nil {
+		return fiber.StatusInternalServerError, err
+	}
+
+	log.Infof("user_id=%s deleted category %s", userID.String(), category.ID.String())
+	return fiber.StatusOK, nil
+}
+
+func (s taxonomyService) ListTags(ctx *fiber.Ctx) ([]taxonomyDto.TagResponse, int, error) {
+	tags, err := s.tagDao.FindAll()
+	if err != nil {
+		return nil, fiber.StatusInternalServerError, err
+	}
+
+	response := []taxonomyDto.TagResponse{}
+	for _, tag := range tags {
+		response = append(response, taxonomyDto.TagResponse{ID: tag.ID.String(), Name: tag.Name})
+	}
+	return response, fiber.StatusOK, nil
+}
+
+// RecategorizeProducts moves products to a new main category and subcategories in one
+// transaction: either every product is moved or none is.
+func (s taxonomyService) RecategorizeProducts(ctx *fiber.Ctx, request taxonomyDto.RecategorizeRequest) (taxonomyDto.RecategorizeResponse, int, error) {
+	userID, err := utils.GetUserID(ctx)
+	if err != nil {
+		return taxonomyDto.RecategorizeResponse{}, fiber.StatusUnauthorized, err
+	}
+	if err := taxonomyDto.ValidateRecategorize(request); err != nil {
+		return taxonomyDto.RecategorizeResponse{}, fiber.StatusBadRequest, fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	category, subCategories, status, err := s.classifier.categories(request.CategoryID, request.SubCategoryIDs)
+	if err != nil {
+		return taxonomyDto.RecategorizeResponse{}, status, err
+	}
+	var tags []models.Tag
+	if request.Tags != nil {
+		tags, status, err = s.classifier.tags(*request.Tags, userID)
+		if err != nil {
+			return taxonomyDto.RecategorizeResponse{}, status, err
+		}
+	}
+
+	products, err := s.productDao.FindByIds(request.ProductIDs)
+	if err != nil {
+		return taxonomyDto.RecategorizeResponse{}, fiber.StatusInternalServerError, err
+	}
+	if len(products) != len(request.ProductIDs) {
+		found := map[string]bool{}
+		for _, product := range products {
+			found[product.ID.String()] = true
+		}
+		missing := []string{}
+		for _, id := range request.ProductIDs {
+			if !found[id] {
+				missing = append(missing, id)
+			}
+		}
+		return taxonomyDto.RecategorizeResponse{}, fiber.StatusBadRequest, fiber.NewError(fiber.StatusBadRequest, "products not found: "+strings.Join(missing, ", "))
+	}
+
+	err = s.productDao.Transaction(func(tx *gorm.DB) error {
+		txProducts := s.productDao.WithTx(tx)
+		for _, product := range products {
+			product.CategoryID, product.SubCategories = category.ID, subCategories
+			if request.Tags != nil {
+				product.Tags = tags
+			}
+			product.UpdatedBy = &userID
+			if err := txProducts.SetClassification(product); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return taxonomyDto.RecategorizeResponse{}, fiber.StatusInternalServerError, err
+	}
+
+	log.Infof("user_id=%s moved %d products to category %s", userID.String(), len(products), category.ID.String())
+	return taxonomyDto.RecategorizeResponse{Updated: len(products)}, fiber.StatusOK, nil
+}
+
+// checkCategoryName fails with 409 when another category, even a deleted one, has
+// the name: category names are unique across the whole tree.
+func (s taxonomyService) checkCategoryName(name string, exceptID string) (int, error) {
+	taken, err := s.categoryDao.NameTaken(name, exceptID)
+	if err != nil {
+		return fiber.StatusInternalServerError, err
+	}
+	if taken {
+		return fiber.StatusConflict, fiber.NewError(fiber.StatusConflict, "A category named "+name+" already exists")
+	}
+	return fiber.StatusOK, nil
+}
+
+func (s taxonomyService) findCategory(id string) (models.Category, int, error) {
+	category, err := s.categoryDao.FindById(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.Category{}, fiber.StatusNotFound, fiber.NewError(fiber.StatusNotFound, "Category not found")
+		}
+		return models.Category{}, fiber.StatusInternalServerError, err
+	}
+	return category, fiber.StatusOK, nil
+}
+
+func (s taxonomyService) subtree(id string) (taxonomyDto.CategoryNode, int, error) {
+	categories, err := s.categoryDao.FindSubtree(id)
+	if err != nil {
+		return taxonomyDto.CategoryNode{}, fiber.StatusInternalServerError, err
+	}
+	for _, category := range categories {
+		if category.ID.String() == id {
+			return toCategoryNode(category, childrenByParent(categories)), fiber.StatusOK, nil
+		}
+	}
+	return taxonomyDto.CategoryNode{}, fiber.StatusNotFound, fiber.NewError(fiber.StatusNotFound, "Category not found")
+}
+
+// childrenByParent groups categories under their parent, keeping their order.
+func childrenByParent(categories []models.Category) map[uuid.UUID][]models.Category {
+	children := map[uuid.UUID][]models.Category{}
+	for _, category := range categories {
+		if category.ParentID != nil {
+			children[*category.ParentID] = append(children[*category.ParentID], category)
+		}
+	}
+	return children
+}
+
+func toCategoryNode(category models.Category, children map[uuid.UUID][]models.Category) taxonomyDto.CategoryNode {
+	node := taxonomyDto.CategoryNode{
+		ID:          category.ID.String(),
+		Name:        category.Name,
+		Description: category.Description,
+		Depth:       category.Depth,
+		Children:    []taxonomyDto.CategoryNode{},
+	}
+	if category.ParentID != nil {
+		node.ParentID = category.ParentID.String()
+	}
+	for _, child := range children[category.ID] {
+		node.Children = append(node.Children, toCategoryNode(child, children))
+	}
+	return node
+}
+
+// classifier resolves the categories and tags a product is classified under. Products
+// are classified through it everywhere, so the same rules apply to single edits and
+// bulk moves; the result is written with productDao.SetClassification.
+type classifier struct {
+	categoryDao categoryDao.DataAccess
+	tagDao      tagDao.DataAccess
+}
+
+// categories loads the main category and the subcategories, which must sit below it in
+// the category tree.
+func (c classifier) categories(categoryID string, subCategoryIDs []string) (models.Category, []models.Category, int, error) {
+	category, err := c.categoryDao.FindById(categoryID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.Category{}, nil, fiber.StatusBadRequest, fiber.NewError(fiber.StatusBadRequest, "category_id is not a known category")
+		}
+		return models.Category{}, nil, fiber.StatusInternalServerError, err
+	}
+
+	subCategories := []models.Category{}
+	seen := map[string]bool{}
+	for _, id := range subCategoryIDs {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		subCategory, err := c.categoryDao.FindById(id)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return models.Category{}, nil, fiber.StatusBadRequest, fiber.NewError(fiber.StatusBadRequest, "subcategory "+id+" is not a known category")
+			}
+			return models.Category{}, nil, fiber.StatusInternalServerError, err
+		}
+		if !subCategory.IsDescendantOf(category) {
+			return models.Category{}, nil, fiber.StatusBadRequest, fiber.NewError(fiber.StatusBadRequest, "subcategory "+id+" is not under the product's category")
+		}
+		subCategories = append(subCategories, subCategory)
+	}
+	return category, subCategories, fiber.StatusOK, nil
+}
+
+// tags trims and de-duplicates tag names, ignoring case, and returns the tags, creating
+// the missing ones.
+func (c classifier) tags(names []string, userID uuid.UUID) ([]models.Tag, int, error) {
+	unique := []string{}
+	seen := map[string]bool{}
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if seen[strings.ToLower(name)] {
+			continue
+		}
+		seen[strings.ToLower(name)] = true
+		unique = append(unique, name)
+	}
+
+	tags, err := c.tagDao.FindOrCreate(unique, userID)
+	if err != nil {
+		return nil, fiber.StatusInternalServerError, err
+	}
+	return tags, fiber.StatusOK, nil
+}
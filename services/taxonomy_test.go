@@ -0,0 +1,164 @@
+package services
+
+import (
+	"testing"
+
+	categorydao "github.com/abdulmalikraji/e-commerce/db/dao/categoryDao"
+	"github.com/abdulmalikraji/e-commerce/db/models"
+	"github.com/abdulmalikraji/e-commerce/dto/taxonomyDto"
+	"github.com/abdulmalikraji/e-commerce/mocks/dao/categoryDao"
+	"github.com/abdulmalikraji/e-commerce/mocks/dao/productDao"
+	"github.com/abdulmalikraji/e-commerce/mocks/dao/tagDao"
+	"github.com/abdulmalikraji/e-commerce/utils"
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+	"gorm.io/gorm"
+)
+
+var taxonomies TaxonomyService
+
+func setupTaxonomy(t *testing.T) func() {
+	ct := gomock.NewController(t)
+
+	app := fiber.New()
+	fiberCtx = app.AcquireCtx(&fasthttp.RequestCtx{})
+	utils.SetPrincipal(fiberCtx, utils.Principal{UserID: uuid.New(), Role: models.UserRoleAdmin})
+
+	categoryMockDao = categoryDao.NewMockDataAccess(ct)
+	tagMockDao = tagDao.NewMockDataAccess(ct)
+	productMockDao = productDao.NewMockDataAccess(ct)
+
+	taxonomies = NewTaxonomyService(categoryMockDao, tagMockDao, productMockDao)
+	return func() {
+		taxonomies = nil
+		app.ReleaseCtx(fiberCtx)
+		ct.Finish()
+	}
+}
+
+func TestTaxonomyService_Tree_Nests_Any_Depth(t *testing.T) {
+	teardown := setupTaxonomy(t)
+	defer teardown()
+
+	home := newCategory("Home", nil)
+	kitchen := newCategory("Kitchen", &home)
+	mugs := newCategory("Mugs", &kitchen)
+	garden := newCategory("Garden", nil)
+	categoryMockDao.EXPECT().FindAll().Return([]models.Category{garden, home, kitchen, mugs}, nil)
+
+	tree, status, err := taxonomies.GetTree(fiberCtx)
+
+	assert.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, status)
+	assert.Len(t, tree, 2)
+	assert.Equal(t, "Home", tree[1].Name)
+	assert.Equal(t, "Mugs", tree[1].Children[0].Children[0].Name)
+	assert.Equal(t, 2, tree[1].Children[0].Children[0].Depth)
+}
+
+func TestTaxonomyService_Move_Rejects_Cycles(t *testing.T) {
+	teardown := setupTaxonomy(t)
+	defer teardown()
+
+	home := newCategory("Home", nil)
+	kitchen := newCategory("Kitchen", &home)
+	mugs := newCategory("Mugs", &kitchen)
+	categoryMockDao.EXPECT().FindById(home.ID.String()).Return(home, nil)
+	categoryMockDao.EXPECT().FindById(mugs.ID.String()).Return(mugs, nil)
+
+	parentID := mugs.ID.String()
+	_, status, err := taxonomies.MoveCategory(fiberCtx, taxonomyDto.MoveCategoryRequest{CategoryID: home.ID.String(), ParentID: &parentID})
+
+	assert.Error(t, err)
+	assert.Equal(t, fiber.StatusBadRequest, status)
+}
+
+func TestTaxonomyService_Move_Rejects_Cycle_Made_By_A_Concurrent_Move(t *testing.T) {
+	teardown := setupTaxonomy(t)
+	defer teardown()
+
+	// Kitchen looked like a sibling of Garden when it was read, but another move put
+	// Kitchen under Garden before this one took its locks.
+	garden := newCategory("Garden", nil)
+	kitchen := newCategory("Kitchen", nil)
+	categoryMockDao.EXPECT().FindById(garden.ID.String()).Return(garden, nil)
+	categoryMockDao.EXPECT().FindById(kitchen.ID.String()).Return(kitchen, nil)
+	categoryMockDao.EXPECT().Move(gomock.Any(), gomock.Any()).Return(categorydao.ErrMoveIntoSubtree)
+
+	parentID := kitchen.ID.String()
+	_, status, err := taxonomies.MoveCategory(fiberCtx, taxonomyDto.MoveCategoryRequest{CategoryID: garden.ID.String(), ParentID: &parentID})
+
+	assert.Error(t, err)
+	assert.Equal(t, fiber.StatusBadRequest, status)
+}
+
+func TestTaxonomyService_Move_To_Root(t *testing.T) {
+	teardown := setupTaxonomy(t)
+	defer teardown()
+
+	home := newCategory("Home", nil)
+	kitchen := newCategory("Kitchen", &home)
+	categoryMockDao.EXPECT().FindById(kitchen.ID.String()).Return(kitchen, nil)
+	categoryMockDao.EXPECT().Move(gomock.Any(), gomock.Nil()).Return(nil)
+	moved := kitchen
+	moved.ParentID, moved.Depth, moved.Path = nil, 0, models.CategoryPath(kitchen.ID, nil)
+	categoryMockDao.EXPECT().FindSubtree(kitchen.ID.String()).Return([]models.Category{moved}, nil)
+
+	node, status, err := taxonomies.MoveCategory(fiberCtx, taxonomyDto.MoveCategoryRequest{CategoryID: kitchen.ID.String()})
+
+	assert.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, status)
+	assert.Empty(t, node.ParentID)
+	assert.Equal(t, 0, node.Depth)
+}
+
+func TestTaxonomyService_Recategorize_Moves_All_Products(t *testing.T) {
+	teardown := setupTaxonomy(t)
+	defer teardown()
+
+	kitchen := newCategory("Kitchen", nil)
+	mugs := newCategory("Mugs", &kitchen)
+	tag := models.Tag{ID: uuid.New(), Name: "ceramic"}
+	first := models.Product{ID: uuid.New(), Tags: []models.Tag{tag}}
+	second := models.Product{ID: uuid.New()}
+	categoryMockDao.EXPECT().FindById(kitchen.ID.String()).Return(kitchen, nil)
+	categoryMockDao.EXPECT().FindById(mugs.ID.String()).Return(mugs, nil)
+	productMockDao.EXPECT().FindByIds([]string{first.ID.String(), second.ID.String()}).Return([]models.Product{first, second}, nil)
+	productMockDao.EXPECT().Transaction(gomock.Any()).DoAndReturn(func(fn func(tx *gorm.DB) error) error {
+		return fn(nil)
+	})
+	productMockDao.EXPECT().WithTx(gomock.Any()).Return(productMockDao)
+	productMockDao.EXPECT().SetClassification(gomock.Any()).DoAndReturn(func(product models.Product) error {
+		assert.Equal(t, kitchen.ID, product.CategoryID)
+		assert.Equal(t, []models.Category{mugs}, product.SubCategories)
+		return nil
+	}).Times(2)
+
+	response, status, err := taxonomies.RecategorizeProducts(fiberCtx, taxonomyDto.RecategorizeRequest{
+		ProductIDs:     []string{first.ID.String(), second.ID.String()},
+		CategoryID:     kitchen.ID.String(),
+		SubCategoryIDs: []string{mugs.ID.String()},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, status)
+	assert.Equal(t, 2, response.Updated)
+}
+
+func TestTaxonomyService_Delete_Refuses_Category_With_Products(t *testing.T) {
+	teardown := setupTaxonomy(t)
+	defer teardown()
+
+	kitchen := newCategory("Kitchen", nil)
+	categoryMockDao.EXPECT().FindById(kitchen.ID.String()).Return(kitchen, nil)
+	categoryMockDao.EXPECT().FindChildren(kitchen.ID.String()).Return([]models.Category{}, nil)
+	categoryMockDao.EXPECT().CountProducts(kitchen.ID.String()).Return(int64(3), nil)
+
+	status, err := taxonomies.DeleteCategory(fiberCtx, taxonomyDto.DeleteCategoryRequest{CategoryID: kitchen.ID.String()})
+
+	assert.Error(t, err)
+	assert.Equal(t, fiber.StatusConflict, status)
+}
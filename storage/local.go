@@ -0,0 +1,78 @@
+package storage
+
+import (
+	"errors"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+)
+
+// LocalStorage keeps files in a directory on the local disk. The API serves that
+// directory itself at the path of BaseURL; see Route.
+type LocalStorage struct {
+	Dir     string
+	BaseURL string
+}
+
+func NewLocalStorage(dir string, baseURL string) LocalStorage {
+	return LocalStorage{Dir: dir, BaseURL: baseURL}
+}
+
+// Route is the path the directory is served at.
+func (s LocalStorage) Route() string {
+	parsed, err := url.Parse(s.BaseURL)
+	if err != nil || parsed.Path == "" {
+		return "/uploads"
+	}
+	return parsed.Path
+}
+
+// Put writes to a temporary file first, so a file is never served half written.
+func (s LocalStorage) Put(key string, contentType string, data []byte) (string, error) {
+	if !validKey(key) {
+		return "", ErrInvalidKey
+	}
+	path := filepath.Join(s.Dir, filepath.FromSlash(key))
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return "", err
+	}
+
+	file, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return "", err
+	}
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		return "", err
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(file.Name())
+		return "", err
+	}
+	if err := os.Chmod(file.Name(), 0o644); err != nil {
+		os.Remove(file.Name())
+		return "", err
+	}
+	if err := os.Rename(file.Name(), path); err != nil {
+		os.Remove(file.Name())
+		return "", err
+	}
+	return s.URL(key), nil
+}
+
+func (s LocalStorage) Delete(key string) error {
+	if !validKey(key) {
+		return ErrInvalidKey
+	}
+	err := os.Remove(filepath.Join(s.Dir, filepath.FromSlash(key)))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (s LocalStorage) URL(key string) string {
+	return joinURL(s.BaseURL, key)
+}
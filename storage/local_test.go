@@ -0,0 +1,37 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLocalStorage_Put_And_Delete(t *testing.T) {
+	dir := t.TempDir()
+	blobs := NewLocalStorage(dir, "https://shop.example.com/uploads/")
+
+	url, err := blobs.Put("products/mug/front view.jpg", "image/jpeg", []byte("jpeg"))
+
+	assert.NoError(t, err)
+	assert.Equal(t, "https://shop.example.com/uploads/products/mug/front%20view.jpg", url)
+	data, err := os.ReadFile(filepath.Join(dir, "products", "mug", "front view.jpg"))
+	assert.NoError(t, err)
+	assert.Equal(t, "jpeg", string(data))
+
+	assert.NoError(t, blobs.Delete("products/mug/front view.jpg"))
+	assert.NoError(t, blobs.Delete("products/mug/front view.jpg"))
+	_, err = os.Stat(filepath.Join(dir, "products", "mug", "front view.jpg"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestLocalStorage_Rejects_Keys_Outside_The_Root(t *testing.T) {
+	blobs := NewLocalStorage(t.TempDir(), "/uploads")
+
+	for _, key := range []string{"", "/etc/passwd", "../secret", "products/../../secret", "products//mug.jpg"} {
+		_, err := blobs.Put(key, "image/jpeg", []byte("jpeg"))
+		assert.ErrorIs(t, err, ErrInvalidKey, key)
+	}
+	assert.Equal(t, "/uploads", blobs.Route())
+}
@@ -0,0 +1,60 @@
+package storage
+
+import (
+	"errors"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// ErrInvalidKey is returned for keys that are empty, absolute or climb out of the
+// storage root.
+var ErrInvalidKey = errors.New("invalid storage key")
+
+// BlobStorage keeps uploaded files under slash-separated keys such as
+// "products/<id>/<image>.jpg" and serves them at public URLs.
+type BlobStorage interface {
+	// Put stores data under key, replacing any file already there, and returns its URL.
+	Put(key string, contentType string, data []byte) (string, error)
+	// Delete removes the file under key; a missing file is not an error.
+	Delete(key string) error
+	// URL is the public URL of the file under key.
+	URL(key string) string
+}
+
+// New returns the storage configured in the environment. Only the local disk is
+// supported for now.
+//
+//	STORAGE_LOCAL_DIR (default uploads), STORAGE_PUBLIC_URL (default /uploads)
+func New() BlobStorage {
+	dir := os.Getenv("STORAGE_LOCAL_DIR")
+	if dir == "" {
+		dir = "uploads"
+	}
+	baseURL := os.Getenv("STORAGE_PUBLIC_URL")
+	if baseURL == "" {
+		baseURL = "/uploads"
+	}
+	return NewLocalStorage(dir, baseURL)
+}
+
+// validKey rejects keys that could reach outside the storage root.
+func validKey(key string) bool {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") {
+		return false
+	}
+	for _, part := range strings.Split(key, "/") {
+		if part == "" || part == "." || part == ".." {
+			return false
+		}
+	}
+	return true
+}
+
+func joinURL(baseURL string, key string) string {
+	parts := strings.Split(key, "/")
+	for i, part := range parts {
+		parts[i] = url.PathEscape(part)
+	}
+	return strings.TrimRight(baseURL, "/") + "/" + strings.Join(parts, "/")
+}
@@ -11,6 +11,28 @@ type GenericResponse struct {
 	Success bool        `json:"success"`
 	Message string      `json:"message"`
 	Data    interface{} `json:"data"`
+	Meta    *PageMeta   `json:"meta,omitempty"`
+}
+
+// PageMeta describes the page returned by a paginated list endpoint.
+type PageMeta struct {
+	Page       int   `json:"page"`
+	PageSize   int   `json:"page_size"`
+	Total      int64 `json:"total"`
+	TotalPages int   `json:"total_pages"`
+}
+
+func NewPageMeta(page int, pageSize int, total int64) PageMeta {
+	totalPages := 0
+	if pageSize > 0 {
+		totalPages = int((total + int64(pageSize) - 1) / int64(pageSize))
+	}
+	return PageMeta{
+		Page:       page,
+		PageSize:   pageSize,
+		Total:      total,
+		TotalPages: totalPages,
+	}
 }
 
 func SuccessResponse(ctx *fiber.Ctx, status int, data interface{}, msg ...string) error {
@@ -25,6 +47,20 @@ func SuccessResponse(ctx *fiber.Ctx, status int, data interface{}, msg ...string
 	})
 }
 
+// PaginatedResponse is a SuccessResponse whose data is one page of a list.
+func PaginatedResponse(ctx *fiber.Ctx, status int, data interface{}, meta PageMeta, msg ...string) error {
+	if len(msg) == 0 {
+		msg = append(msg, "Success")
+	}
+
+	return ctx.Status(status).JSON(GenericResponse{
+		Success: true,
+		Message: msg[0],
+		Data:    data,
+		Meta:    &meta,
+	})
+}
+
 func ErrorResponse(ctx *fiber.Ctx, status int, msg string, data ...interface{}) error {
 	// If caller provided exactly one data argument, return it directly as Data.
 	// Otherwise return the slice of provided data (or empty slice if none).
@@ -3,11 +3,14 @@ package utils
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
+	"io"
 	"math"
 	"regexp"
 	"strconv"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
 )
 
 func ConvertStruct(sourceItem any, targetItem any) error {
@@ -32,6 +35,16 @@ func GetLanguage(c *fiber.Ctx) string {
 	return lang
 }
 
+// GetUserID returns the ID of the authenticated user making the request, as set by
+// the token middleware. Request headers are never trusted for identity.
+func GetUserID(c *fiber.Ctx) (uuid.UUID, error) {
+	principal, ok := GetPrincipal(c)
+	if !ok {
+		return uuid.Nil, fiber.NewError(fiber.StatusUnauthorized, "missing user id")
+	}
+	return principal.UserID, nil
+}
+
 const (
 	PgDuplicateErrorCode = "23505"
 )
@@ -67,6 +80,37 @@ type File struct {
 	FileType string
 }
 
+// FormFile reads the multipart file sent in field. Files over maxSize bytes are
+// refused without being read.
+func FormFile(c *fiber.Ctx, field string, maxSize int64) (File, error) {
+	header, err := c.FormFile(field)
+	if err != nil {
+		return File{}, fmt.Errorf("%s file is required", field)
+	}
+	if header.Size > maxSize {
+		return File{}, fmt.Errorf("%s cannot be larger than %d MB", field, maxSize>>20)
+	}
+	file, err := header.Open()
+	if err != nil {
+		return File{}, err
+	}
+	defer file.Close()
+
+	buffer := &bytes.Buffer{}
+	if _, err := io.Copy(buffer, io.LimitReader(file, maxSize+1)); err != nil {
+		return File{}, err
+	}
+	if int64(buffer.Len()) > maxSize {
+		return File{}, fmt.Errorf("%s cannot be larger than %d MB", field, maxSize>>20)
+	}
+	return File{
+		File:     buffer,
+		FileName: header.Filename,
+		FileSize: buffer.Len(),
+		FileType: header.Header.Get("Content-Type"),
+	}, nil
+}
+
 func StructToArrayMap(data any) (map[string][]string, error) {
 	jsonData, err := json.Marshal(data)
 	if err != nil {
@@ -0,0 +1,101 @@
+package utils
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	_ "image/gif" // registers the GIF decoder
+	"image/jpeg"
+	"image/png"
+	"net/http"
+)
+
+// ImageExtensions maps the image types accepted for upload to their file extension.
+// The type is sniffed from the file's bytes, never taken from the client.
+var ImageExtensions = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+}
+
+// MaxImageSize is the largest image file accepted for upload, in bytes.
+const MaxImageSize = 8 << 20
+
+// MaxImageSide is the longest side, in pixels, of an image accepted for upload. It is
+// checked before the image is decoded.
+const MaxImageSide = 8000
+
+var (
+	ErrUnsupportedImage = errors.New("image must be a JPEG, PNG or GIF")
+	ErrImageTooLarge    = errors.New("image is too large")
+)
+
+// DecodeImage decodes an uploaded image and returns it with its sniffed content type.
+func DecodeImage(data []byte) (image.Image, string, error) {
+	contentType := http.DetectContentType(data)
+	if _, ok := ImageExtensions[contentType]; !ok {
+		return nil, "", ErrUnsupportedImage
+	}
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", ErrUnsupportedImage
+	}
+	if config.Width > MaxImageSide || config.Height > MaxImageSide {
+		return nil, "", ErrImageTooLarge
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", ErrUnsupportedImage
+	}
+	return img, contentType, nil
+}
+
+// Thumbnail scales img down, keeping its aspect ratio, so that its longest side is at
+// most size pixels. Each target pixel averages the source pixels it covers. Images that
+// already fit are returned as they are.
+func Thumbnail(img image.Image, size int) image.Image {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width <= size && height <= size {
+		return img
+	}
+	targetWidth, targetHeight := size, height*size/width
+	if height > width {
+		targetWidth, targetHeight = width*size/height, size
+	}
+	targetWidth, targetHeight = max(targetWidth, 1), max(targetHeight, 1)
+
+	thumbnail := image.NewNRGBA(image.Rect(0, 0, targetWidth, targetHeight))
+	for y := 0; y < targetHeight; y++ {
+		top, bottom := y*height/targetHeight, max((y+1)*height/targetHeight, y*height/targetHeight+1)
+		for x := 0; x < targetWidth; x++ {
+			left, right := x*width/targetWidth, max((x+1)*width/targetWidth, x*width/targetWidth+1)
+			var r, g, b, a, n uint64
+			for sy := top; sy < bottom; sy++ {
+				for sx := left; sx < right; sx++ {
+					c := color.NRGBA64Model.Convert(img.At(bounds.Min.X+sx, bounds.Min.Y+sy)).(color.NRGBA64)
+					r, g, b, a, n = r+uint64(c.R), g+uint64(c.G), b+uint64(c.B), a+uint64(c.A), n+1
+				}
+			}
+			thumbnail.SetNRGBA(x, y, color.NRGBA{R: uint8(r / n >> 8), G: uint8(g / n >> 8), B: uint8(b / n >> 8), A: uint8(a / n >> 8)})
+		}
+	}
+	return thumbnail
+}
+
+// EncodeImage writes img as JPEG when contentType is JPEG and as PNG otherwise, so GIFs
+// keep their transparency but lose their animation. It returns the content type written.
+func EncodeImage(img image.Image, contentType string) ([]byte, string, error) {
+	var buffer bytes.Buffer
+	if contentType == "image/jpeg" {
+		if err := jpeg.Encode(&buffer, img, &jpeg.Options{Quality: 85}); err != nil {
+			return nil, "", err
+		}
+		return buffer.Bytes(), contentType, nil
+	}
+	if err := png.Encode(&buffer, img); err != nil {
+		return nil, "", err
+	}
+	return buffer.Bytes(), "image/png", nil
+}
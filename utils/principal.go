@@ -0,0 +1,57 @@
+package utils
+
+import (
+	"github.com/abdulmalikraji/e-commerce/db/models"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+const principalKey = "principal"
+
+// Principal is the authenticated caller. The token middleware builds it from the
+// verified access token and the caller's store memberships.
+type Principal struct {
+	UserID uuid.UUID
+	Email  string
+	Role   string
+	Stores []models.StoreUser
+
+	// permissions caches the actions allowed on each store for the rest of the
+	// request. SetPrincipal fills it from Stores.
+	permissions map[uuid.UUID]map[string]bool
+}
+
+// Membership returns the caller's membership of the store, if any.
+func (p Principal) Membership(storeID uuid.UUID) (models.StoreUser, bool) {
+	for _, membership := range p.Stores {
+		if membership.StoreID == storeID {
+			return membership, true
+		}
+	}
+	return models.StoreUser{}, false
+}
+
+// Can reports whether the caller may perform action (one of the models.Action*
+// constants) on the store.
+func (p Principal) Can(storeID uuid.UUID, action string) bool {
+	if p.permissions != nil {
+		return p.permissions[storeID][action]
+	}
+	membership, ok := p.Membership(storeID)
+	return ok && membership.Permissions()[action]
+}
+
+// SetPrincipal stores the authenticated caller in the request context.
+func SetPrincipal(c *fiber.Ctx, principal Principal) {
+	principal.permissions = make(map[uuid.UUID]map[string]bool, len(principal.Stores))
+	for _, membership := range principal.Stores {
+		principal.permissions[membership.StoreID] = membership.Permissions()
+	}
+	c.Locals(principalKey, principal)
+}
+
+// GetPrincipal returns the authenticated caller. ok is false for anonymous requests.
+func GetPrincipal(c *fiber.Ctx) (Principal, bool) {
+	principal, ok := c.Locals(principalKey).(Principal)
+	return principal, ok && principal.UserID != uuid.Nil
+}